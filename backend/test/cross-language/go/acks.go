@@ -0,0 +1,141 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrReplayUnavailable is returned when requested acks are no longer retained
+var ErrReplayUnavailable = errors.New("acks no longer retained for replay")
+
+// Order ack statuses
+const (
+	AckAccepted        = "accepted"
+	AckPartiallyFilled = "partially_filled"
+	AckFilled          = "filled"
+	AckPaused          = "paused" // held for price band review, still live
+	AckRejected        = "rejected"
+)
+
+// OrderAck acknowledges one submission. Seq increases by one per client.
+type OrderAck struct {
+	OrderID   string    `json:"order_id"`
+	Status    string    `json:"status"`
+	Reason    string    `json:"reason,omitempty"`
+	Seq       uint64    `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// AckStream delivers one client's acks. Delivery never blocks the
+// publisher: when the client's buffer is full the ack is skipped on the
+// channel but kept for replay, so a client seeing a jump in Seq can fetch
+// what it missed with Replay.
+type AckStream struct {
+	mu       sync.Mutex
+	ch       chan OrderAck
+	retained []OrderAck // the most recent acks, oldest first
+	retain   int
+	seq      uint64
+	dropped  uint64
+}
+
+// C returns the live ack channel
+func (s *AckStream) C() <-chan OrderAck {
+	return s.ch
+}
+
+// LastSeq returns the sequence number of the latest ack
+func (s *AckStream) LastSeq() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seq
+}
+
+// Dropped returns how many acks were skipped on the live channel
+func (s *AckStream) Dropped() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Replay returns retained acks from seq fromSeq onwards
+func (s *AckStream) Replay(fromSeq uint64) ([]OrderAck, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fromSeq == 0 {
+		fromSeq = 1
+	}
+	if fromSeq > s.seq {
+		return nil, nil
+	}
+	oldest := s.seq - uint64(len(s.retained)) + 1
+	if fromSeq < oldest {
+		return nil, fmt.Errorf("%w: requested from %d, oldest retained is %d", ErrReplayUnavailable, fromSeq, oldest)
+	}
+	return append([]OrderAck(nil), s.retained[fromSeq-oldest:]...), nil
+}
+
+func (s *AckStream) publish(ack OrderAck) OrderAck {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.seq++
+	ack.Seq = s.seq
+	s.retained = append(s.retained, ack)
+	if len(s.retained) > s.retain {
+		s.retained = s.retained[len(s.retained)-s.retain:]
+	}
+	// Sending under the lock keeps the channel in sequence order
+	select {
+	case s.ch <- ack:
+	default:
+		s.dropped++
+	}
+	return ack
+}
+
+// AckHub sequences acks into a stream per client
+type AckHub struct {
+	mu      sync.Mutex
+	streams map[string]*AckStream
+	buffer  int
+	retain  int
+	clock   func() time.Time
+}
+
+// NewAckHub creates a hub whose streams buffer buffer acks for live
+// delivery and retain the last retain acks for replay; a nil clock uses
+// time.Now
+func NewAckHub(buffer, retain int, clock func() time.Time) *AckHub {
+	if buffer <= 0 {
+		buffer = 64
+	}
+	if retain < buffer {
+		retain = buffer
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return &AckHub{streams: make(map[string]*AckStream), buffer: buffer, retain: retain, clock: clock}
+}
+
+// Stream returns the client's stream, creating it on first use
+func (h *AckHub) Stream(clientID string) *AckStream {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	s, ok := h.streams[clientID]
+	if !ok {
+		s = &AckStream{ch: make(chan OrderAck, h.buffer), retain: h.retain}
+		h.streams[clientID] = s
+	}
+	return s
+}
+
+// Publish sequences and delivers an ack to the client's stream
+func (h *AckHub) Publish(clientID, orderID, status, reason string) OrderAck {
+	return h.Stream(clientID).publish(OrderAck{OrderID: orderID, Status: status, Reason: reason, Timestamp: h.clock()})
+}
@@ -0,0 +1,71 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// TestAckStreamSequencesAndReplays verifies per-client monotonic acks, and replay of one missed by a slow client
+func TestAckStreamSequencesAndReplays(t *testing.T) {
+	hub := NewAckHub(2, 4, func() time.Time { return time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC) })
+	gateway := NewOrderGateway(NewOrderBook("crude_oil"), nil)
+	gateway.SetAcks(hub)
+	acme := hub.Stream("acme")
+
+	submit := func(o TradingOrder) {
+		t.Helper()
+		done := make(chan struct{})
+		go func() {
+			gateway.Submit(o, SubmitOptions{})
+			close(done)
+		}()
+		select {
+		case <-done:
+		case <-time.After(2 * time.Second):
+			t.Fatal("Submission blocked on a slow ack consumer")
+		}
+	}
+
+	submit(TradingOrder{OrderID: "a1", ClientID: "acme", Side: SideSell, Price: 75.60, Volume: 50})
+	submit(TradingOrder{OrderID: "g1", ClientID: "gulf", Side: SideBuy, Price: 75.60, Volume: 20})
+	submit(TradingOrder{OrderID: "a2", ClientID: "acme", Side: SideBuy, Price: 75.40, Volume: 0})
+	// acme is not reading; its buffer of two is now full
+	submit(TradingOrder{OrderID: "a3", ClientID: "acme", Side: SideBuy, Price: 75.40, Volume: 10})
+
+	var received []OrderAck
+	for len(received) < 2 {
+		received = append(received, <-acme.C())
+	}
+	if received[0].Seq != 1 || received[0].OrderID != "a1" || received[0].Status != AckAccepted {
+		t.Errorf("Unexpected first ack %+v", received[0])
+	}
+	if received[1].Seq != 2 || received[1].Status != AckRejected || received[1].Reason == "" {
+		t.Errorf("Unexpected second ack %+v", received[1])
+	}
+	if gulf := <-hub.Stream("gulf").C(); gulf.Seq != 1 || gulf.Status != AckFilled {
+		t.Errorf("Expected gulf's own sequence to start at 1 with a fill, got %+v", gulf)
+	}
+
+	submit(TradingOrder{OrderID: "a4", ClientID: "acme", Side: SideBuy, Price: 75.40, Volume: 10})
+	next := <-acme.C()
+	if next.Seq != 4 || acme.Dropped() != 1 {
+		t.Fatalf("Expected a gap at seq 3, got %+v (dropped %d)", next, acme.Dropped())
+	}
+	missed, err := acme.Replay(received[1].Seq + 1)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if len(missed) != 2 || missed[0].OrderID != "a3" || missed[0].Seq != 3 || missed[1].Seq != 4 {
+		t.Errorf("Expected replay of a3 and a4, got %+v", missed)
+	}
+
+	for i := 0; i < 4; i++ {
+		submit(TradingOrder{OrderID: fmt.Sprintf("b%d", i), ClientID: "acme", Side: SideBuy, Price: 75, Volume: 1})
+		<-acme.C()
+	}
+	if _, err := acme.Replay(3); !errors.Is(err, ErrReplayUnavailable) {
+		t.Errorf("Expected acks beyond the retention window to be unavailable, got %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+)
+
+// FillEvent reports an execution from one order's point of view
+type FillEvent struct {
+	TradeID   string    `json:"trade_id"`
+	OrderID   string    `json:"order_id"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Commodity string    `json:"commodity"`
+	Side      string    `json:"side"`
+	Price     float64   `json:"price"`
+	Volume    float64   `json:"volume"`
+	Remaining float64   `json:"remaining"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Anonymization modes
+const (
+	AnonymizeStrip = "strip" // remove identifiers entirely
+	AnonymizeHash  = "hash"  // replace identifiers with session pseudonyms
+)
+
+// Anonymizer removes client and order identity from events bound for
+// public feeds. Hashed pseudonyms are keyed per session, so the same ID
+// maps to the same pseudonym within a session but cannot be linked across
+// sessions or reversed without the key.
+type Anonymizer struct {
+	mode string
+	key  []byte
+}
+
+// NewAnonymizer creates an anonymizer; sessionKey should be random per session
+func NewAnonymizer(mode string, sessionKey []byte) *Anonymizer {
+	return &Anonymizer{mode: mode, key: sessionKey}
+}
+
+// Pseudonym returns the session pseudonym for an identifier
+func (a *Anonymizer) Pseudonym(id string) string {
+	if id == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, a.key)
+	mac.Write([]byte(id))
+	return "anon-" + hex.EncodeToString(mac.Sum(nil)[:8])
+}
+
+// Trade returns a copy of the trade safe for public channels
+func (a *Anonymizer) Trade(t Trade) Trade {
+	t.BuyOrderID = a.scrub(t.BuyOrderID)
+	t.SellOrderID = a.scrub(t.SellOrderID)
+	t.BuyClientID = a.scrub(t.BuyClientID)
+	t.SellClientID = a.scrub(t.SellClientID)
+	t.BuyFee, t.SellFee = 0, 0
+	return t
+}
+
+// Fill returns a copy of the fill event safe for public channels
+func (a *Anonymizer) Fill(f FillEvent) FillEvent {
+	f.OrderID = a.scrub(f.OrderID)
+	f.ClientID = a.scrub(f.ClientID)
+	return f
+}
+
+func (a *Anonymizer) scrub(id string) string {
+	if a.mode == AnonymizeHash {
+		return a.Pseudonym(id)
+	}
+	return ""
+}
+
+// FeedPublisher sends full events to internal consumers and anonymized
+// copies to public consumers
+type FeedPublisher struct {
+	anonymizer *Anonymizer
+	internal   func(event interface{})
+	public     func(event interface{})
+}
+
+// NewFeedPublisher creates a publisher with internal and public sinks
+func NewFeedPublisher(anonymizer *Anonymizer, internal, public func(event interface{})) *FeedPublisher {
+	return &FeedPublisher{anonymizer: anonymizer, internal: internal, public: public}
+}
+
+// PublishTrade publishes a trade to both audiences
+func (p *FeedPublisher) PublishTrade(t Trade) {
+	p.internal(t)
+	p.public(p.anonymizer.Trade(t))
+}
+
+// PublishFill publishes a fill event to both audiences
+func (p *FeedPublisher) PublishFill(f FillEvent) {
+	p.internal(f)
+	p.public(p.anonymizer.Fill(f))
+}
@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// TestFeedPublisherAnonymizesPublicEvents verifies public events never carry raw client IDs
+func TestFeedPublisherAnonymizesPublicEvents(t *testing.T) {
+	var internal, public []interface{}
+	publisher := NewFeedPublisher(
+		NewAnonymizer(AnonymizeHash, []byte("session-2024-03-01")),
+		func(e interface{}) { internal = append(internal, e) },
+		func(e interface{}) { public = append(public, e) },
+	)
+
+	publisher.PublishTrade(Trade{TradeID: "t1", Commodity: "crude_oil", Price: 75.5, Volume: 10,
+		BuyOrderID: "o-1", SellOrderID: "o-2", BuyClientID: "acme_energy", SellClientID: "gulf_trading"})
+	publisher.PublishFill(FillEvent{TradeID: "t1", OrderID: "o-1", ClientID: "acme_energy", Commodity: "crude_oil", Price: 75.5, Volume: 10})
+	publisher.PublishTrade(Trade{TradeID: "t2", Commodity: "crude_oil", Price: 75.6, Volume: 5,
+		BuyOrderID: "o-3", SellOrderID: "o-4", BuyClientID: "acme_energy", SellClientID: "north_sea"})
+
+	for _, e := range public {
+		raw, _ := json.Marshal(e)
+		for _, secret := range []string{"acme_energy", "gulf_trading", "north_sea", `"o-1"`, `"o-2"`} {
+			if strings.Contains(string(raw), secret) {
+				t.Errorf("Public event leaks %s: %s", secret, raw)
+			}
+		}
+	}
+
+	if trade := internal[0].(Trade); trade.BuyClientID != "acme_energy" || trade.BuyOrderID != "o-1" {
+		t.Errorf("Expected internal trade to keep full detail, got %+v", trade)
+	}
+	if fill := internal[1].(FillEvent); fill.ClientID != "acme_energy" {
+		t.Errorf("Expected internal fill to keep client ID, got %+v", fill)
+	}
+
+	first, second := public[0].(Trade), public[2].(Trade)
+	if first.BuyClientID == "" || first.BuyClientID != second.BuyClientID {
+		t.Errorf("Expected a stable pseudonym within the session, got %q and %q", first.BuyClientID, second.BuyClientID)
+	}
+	if fill := public[1].(FillEvent); fill.ClientID != first.BuyClientID {
+		t.Errorf("Expected fills and trades to share pseudonyms, got %q vs %q", fill.ClientID, first.BuyClientID)
+	}
+}
+
+// TestAnonymizerSessionsAndStrip verifies pseudonyms change per session and strip mode removes IDs
+func TestAnonymizerSessionsAndStrip(t *testing.T) {
+	a := NewAnonymizer(AnonymizeHash, []byte("session-a")).Pseudonym("acme_energy")
+	b := NewAnonymizer(AnonymizeHash, []byte("session-b")).Pseudonym("acme_energy")
+	if a == b {
+		t.Error("Expected pseudonyms to differ across sessions")
+	}
+
+	stripped := NewAnonymizer(AnonymizeStrip, nil).Trade(Trade{BuyClientID: "acme_energy", BuyOrderID: "o-1"})
+	if stripped.BuyClientID != "" || stripped.BuyOrderID != "" {
+		t.Errorf("Expected identifiers stripped, got %+v", stripped)
+	}
+}
@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ArbConfig controls which cross-venue price gaps count as opportunities
+type ArbConfig struct {
+	// Threshold is the minimum edge per unit after fees
+	Threshold float64 `json:"threshold"`
+	// TakerFees is each venue's taker fee as a fraction of notional;
+	// venues not listed pay DefaultTakerFee
+	TakerFees       map[string]float64 `json:"taker_fees"`
+	DefaultTakerFee float64            `json:"default_taker_fee"`
+}
+
+// ArbOpportunity is a commodity offered on one venue below the bid on
+// another by more than fees and the threshold
+type ArbOpportunity struct {
+	Commodity string    `json:"commodity"`
+	BuyVenue  string    `json:"buy_venue"`
+	BuyPrice  float64   `json:"buy_price"` // ask on the buy venue
+	SellVenue string    `json:"sell_venue"`
+	SellPrice float64   `json:"sell_price"` // bid on the sell venue
+	Volume    float64   `json:"volume"`     // the smaller of the two quoted sizes
+	Edge      float64   `json:"edge"`       // per unit, after fees
+	Profit    float64   `json:"profit"`     // Edge * Volume
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (o ArbOpportunity) key() string {
+	return o.Commodity + "|" + o.BuyVenue + "|" + o.SellVenue
+}
+
+// ArbDetector finds cross-venue arbitrage in consolidated quotes. Each
+// opportunity is reported once when it appears and again only if its
+// prices or size change, so repeated checks of a standing gap stay quiet.
+type ArbDetector struct {
+	mu     sync.Mutex
+	quotes ConsolidatedQuoteSource
+	config ArbConfig
+	onOpp  func(ArbOpportunity)
+	clock  func() time.Time
+	active map[string]ArbOpportunity
+}
+
+// NewArbDetector creates a detector; onOpportunity may be nil and a nil
+// clock uses time.Now
+func NewArbDetector(quotes ConsolidatedQuoteSource, config ArbConfig, onOpportunity func(ArbOpportunity), clock func() time.Time) *ArbDetector {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &ArbDetector{
+		quotes: quotes,
+		config: config,
+		onOpp:  onOpportunity,
+		clock:  clock,
+		active: make(map[string]ArbOpportunity),
+	}
+}
+
+// Check compares every pair of venues quoting the commodity and returns
+// the current opportunities, most profitable first. New or changed
+// opportunities are passed to the callback.
+func (d *ArbDetector) Check(commodity string) []ArbOpportunity {
+	quotes := d.quotes.VenueQuotes(commodity)
+	now := d.clock()
+
+	var found []ArbOpportunity
+	for _, buy := range quotes {
+		if buy.Ask <= 0 || buy.AskSize <= 0 {
+			continue
+		}
+		for _, sell := range quotes {
+			if sell.Venue == buy.Venue || sell.Bid <= 0 || sell.BidSize <= 0 {
+				continue
+			}
+			fees := buy.Ask*d.takerFee(buy.Venue) + sell.Bid*d.takerFee(sell.Venue)
+			edge := sell.Bid - buy.Ask - fees
+			if edge <= d.config.Threshold {
+				continue
+			}
+			volume := math.Min(buy.AskSize, sell.BidSize)
+			found = append(found, ArbOpportunity{
+				Commodity: commodity,
+				BuyVenue:  buy.Venue,
+				BuyPrice:  buy.Ask,
+				SellVenue: sell.Venue,
+				SellPrice: sell.Bid,
+				Volume:    volume,
+				Edge:      edge,
+				Profit:    edge * volume,
+				Timestamp: now,
+			})
+		}
+	}
+	sort.Slice(found, func(i, j int) bool { return found[i].Profit > found[j].Profit })
+
+	d.mu.Lock()
+	var emit []ArbOpportunity
+	current := make(map[string]bool, len(found))
+	for _, opp := range found {
+		current[opp.key()] = true
+		prev, ok := d.active[opp.key()]
+		if !ok || prev.BuyPrice != opp.BuyPrice || prev.SellPrice != opp.SellPrice || prev.Volume != opp.Volume {
+			emit = append(emit, opp)
+		}
+		d.active[opp.key()] = opp
+	}
+	for key, opp := range d.active {
+		if opp.Commodity == commodity && !current[key] {
+			delete(d.active, key)
+		}
+	}
+	d.mu.Unlock()
+
+	if d.onOpp != nil {
+		for _, opp := range emit {
+			d.onOpp(opp)
+		}
+	}
+	return found
+}
+
+func (d *ArbDetector) takerFee(venue string) float64 {
+	if fee, ok := d.config.TakerFees[venue]; ok {
+		return fee
+	}
+	return d.config.DefaultTakerFee
+}
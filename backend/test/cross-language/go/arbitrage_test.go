@@ -0,0 +1,60 @@
+package integration
+
+import (
+	"math"
+	"testing"
+)
+
+// TestArbDetectorFlagsCrossVenueGap verifies a gap beyond fees is reported once with its size
+func TestArbDetectorFlagsCrossVenueGap(t *testing.T) {
+	quotes := fakeConsolidatedQuotes{"crude_oil": {
+		{Venue: "NYMEX", Bid: 75.40, BidSize: 100, Ask: 75.50, AskSize: 40},
+		{Venue: "ICE", Bid: 75.70, BidSize: 25, Ask: 75.80, AskSize: 100},
+		{Venue: "DME", Bid: 75.45, BidSize: 10, Ask: 75.55, AskSize: 10},
+	}}
+	var events []ArbOpportunity
+	detector := NewArbDetector(quotes, ArbConfig{
+		Threshold:       0.01,
+		TakerFees:       map[string]float64{"ICE": 0.0005},
+		DefaultTakerFee: 0.0002,
+	}, func(o ArbOpportunity) { events = append(events, o) }, nil)
+
+	opps := detector.Check("crude_oil")
+	if len(opps) != 2 {
+		t.Fatalf("Expected NYMEX->ICE and DME->ICE, got %+v", opps)
+	}
+	best := opps[0]
+	if best.BuyVenue != "NYMEX" || best.SellVenue != "ICE" || best.Volume != 25 {
+		t.Errorf("Expected to buy 25 on NYMEX and sell on ICE, got %+v", best)
+	}
+	// 75.70 - 75.50 less 75.50*0.0002 and 75.70*0.0005
+	if want := 0.2 - 0.0151 - 0.03785; math.Abs(best.Edge-want) > 1e-9 || math.Abs(best.Profit-want*25) > 1e-9 {
+		t.Errorf("Expected edge %g, got %g (profit %g)", want, best.Edge, best.Profit)
+	}
+	if len(events) != 2 {
+		t.Errorf("Expected both opportunities emitted, got %d", len(events))
+	}
+
+	// A standing opportunity is not re-emitted
+	detector.Check("crude_oil")
+	if len(events) != 2 {
+		t.Errorf("Expected no repeat events, got %d", len(events))
+	}
+}
+
+// TestArbDetectorIgnoresGapsWithinFees verifies gaps eaten by fees or below threshold produce nothing
+func TestArbDetectorIgnoresGapsWithinFees(t *testing.T) {
+	quotes := fakeConsolidatedQuotes{"crude_oil": {
+		{Venue: "NYMEX", Bid: 75.40, BidSize: 100, Ask: 75.50, AskSize: 40},
+		{Venue: "ICE", Bid: 75.53, BidSize: 25, Ask: 75.80, AskSize: 100},
+		{Venue: "DME", Bid: 0, BidSize: 0, Ask: 70, AskSize: 0}, // no size behind the ask
+	}}
+	var events []ArbOpportunity
+	detector := NewArbDetector(quotes, ArbConfig{Threshold: 0.01, DefaultTakerFee: 0.0002},
+		func(o ArbOpportunity) { events = append(events, o) }, nil)
+
+	// A 0.03 gap less about 0.03 of fees is not worth the threshold
+	if opps := detector.Check("crude_oil"); len(opps) != 0 || len(events) != 0 {
+		t.Errorf("Expected no opportunity, got %+v", opps)
+	}
+}
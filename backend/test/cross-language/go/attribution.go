@@ -0,0 +1,115 @@
+package integration
+
+import "sort"
+
+// CommodityPnL is one commodity's share of a PnL attribution
+type CommodityPnL struct {
+	Commodity  string  `json:"commodity"`
+	MarketMove float64 `json:"market_move"` // starting position marked from start to end price
+	Trading    float64 `json:"trading"`     // each trade marked from its price to the end price
+	Fees       float64 `json:"fees"`        // negative for fees paid
+	Total      float64 `json:"total"`
+}
+
+// PnLAttribution splits PnL over a period into market move, trading and fees
+type PnLAttribution struct {
+	MarketMove  float64        `json:"market_move"`
+	Trading     float64        `json:"trading"`
+	Fees        float64        `json:"fees"`
+	Total       float64        `json:"total"`
+	Commodities []CommodityPnL `json:"commodities"`
+	// Unpriced lists commodities held with no end price and no trades to
+	// mark them at, which are left out of every figure
+	Unpriced []string `json:"unpriced,omitempty"`
+}
+
+// PnLAttributor attributes one client's PnL given its positions at the
+// start of the period
+type PnLAttributor struct {
+	ClientID       string
+	StartPositions map[string]float64 // commodity -> signed volume
+}
+
+// AttributePnL decomposes the client's PnL between the start and end
+// prices. Total is computed independently from the change in marked
+// position value less trade cash flows and fees, so the components sum to
+// it up to rounding. A commodity without an end price is marked at its
+// last trade, and one with neither is reported as unpriced rather than
+// marked at zero; one without a start price has no market move.
+func (a PnLAttributor) AttributePnL(trades []Trade, startPrices, endPrices map[string]float64) PnLAttribution {
+	type flow struct {
+		start, end    float64 // positions
+		cash, fees    float64
+		notional      float64 // signed volume * price over trades
+		bought        float64 // signed volume over trades
+		lastPrice     float64
+		haveLastPrice bool
+	}
+	flows := make(map[string]*flow)
+	get := func(commodity string) *flow {
+		f, ok := flows[commodity]
+		if !ok {
+			f = &flow{start: a.StartPositions[commodity]}
+			f.end = f.start
+			flows[commodity] = f
+		}
+		return f
+	}
+	for commodity, volume := range a.StartPositions {
+		if volume != 0 {
+			get(commodity)
+		}
+	}
+	for _, trade := range trades {
+		for _, leg := range []struct {
+			client string
+			sign   float64
+			fee    float64
+		}{
+			{trade.BuyClientID, 1, trade.BuyFee},
+			{trade.SellClientID, -1, trade.SellFee},
+		} {
+			if leg.client != a.ClientID {
+				continue
+			}
+			f := get(trade.Commodity)
+			f.end += leg.sign * trade.Volume
+			f.cash -= leg.sign * trade.Volume * trade.Price
+			f.notional += leg.sign * trade.Volume * trade.Price
+			f.bought += leg.sign * trade.Volume
+			f.fees -= leg.fee
+			f.lastPrice, f.haveLastPrice = trade.Price, true
+		}
+	}
+
+	var out PnLAttribution
+	for commodity, f := range flows {
+		end, ok := endPrices[commodity]
+		if !ok {
+			if !f.haveLastPrice {
+				out.Unpriced = append(out.Unpriced, commodity)
+				continue
+			}
+			end = f.lastPrice
+		}
+		start, ok := startPrices[commodity]
+		if !ok {
+			start = end
+		}
+		c := CommodityPnL{
+			Commodity:  commodity,
+			MarketMove: f.start * (end - start),
+			Trading:    f.bought*end - f.notional,
+			Fees:       f.fees,
+			Total:      f.end*end - f.start*start + f.cash + f.fees,
+		}
+		out.MarketMove += c.MarketMove
+		out.Trading += c.Trading
+		out.Fees += c.Fees
+		out.Total += c.Total
+		out.Commodities = append(out.Commodities, c)
+	}
+	sort.Slice(out.Commodities, func(i, j int) bool { return out.Commodities[i].Commodity < out.Commodities[j].Commodity })
+	sort.Strings(out.Unpriced)
+	return out
+}
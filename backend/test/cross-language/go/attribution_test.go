@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"math"
+	"testing"
+)
+
+// TestAttributePnLReconciles verifies a held position plus trading decomposes into components summing to the total
+func TestAttributePnLReconciles(t *testing.T) {
+	attributor := PnLAttributor{ClientID: "acme", StartPositions: map[string]float64{"crude_oil": 100, "natural_gas": -50}}
+	trades := []Trade{
+		{Commodity: "crude_oil", Price: 76, Volume: 40, BuyClientID: "acme", SellClientID: "gulf", BuyFee: 2},
+		{Commodity: "crude_oil", Price: 77.5, Volume: 60, BuyClientID: "gulf", SellClientID: "acme", SellFee: 3},
+		{Commodity: "natural_gas", Price: 2.6, Volume: 50, BuyClientID: "acme", SellClientID: "gulf", BuyFee: 1},
+		{Commodity: "heating_oil", Price: 2.4, Volume: 10, BuyClientID: "gulf", SellClientID: "delta"},
+	}
+	start := map[string]float64{"crude_oil": 75, "natural_gas": 2.5}
+	end := map[string]float64{"crude_oil": 78, "natural_gas": 2.7}
+
+	got := attributor.AttributePnL(trades, start, end)
+
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-6 }
+	// Market: 100*(78-75) - 50*(2.7-2.5) = 300 - 10
+	if !near(got.MarketMove, 290) {
+		t.Errorf("Expected market move 290, got %g", got.MarketMove)
+	}
+	// Trading: 40*(78-76) - 60*(78-77.5) + 50*(2.7-2.6) = 80 - 30 + 5
+	if !near(got.Trading, 55) {
+		t.Errorf("Expected trading 55, got %g", got.Trading)
+	}
+	if !near(got.Fees, -6) {
+		t.Errorf("Expected fees -6, got %g", got.Fees)
+	}
+	if !near(got.MarketMove+got.Trading+got.Fees, got.Total) || !near(got.Total, 339) {
+		t.Errorf("Expected components to reconcile to 339, got %+v", got)
+	}
+	if len(got.Commodities) != 2 || got.Commodities[0].Commodity != "crude_oil" {
+		t.Fatalf("Expected crude and gas lines only, got %+v", got.Commodities)
+	}
+	for _, c := range got.Commodities {
+		if !near(c.MarketMove+c.Trading+c.Fees, c.Total) {
+			t.Errorf("%s does not reconcile: %+v", c.Commodity, c)
+		}
+	}
+}
+
+// TestAttributePnLReportsUnpricedPositions verifies a position with no end
+// price and no trades is reported instead of being marked to zero
+func TestAttributePnLReportsUnpricedPositions(t *testing.T) {
+	attributor := PnLAttributor{ClientID: "acme", StartPositions: map[string]float64{"crude_oil": 100, "power": 20}}
+	start := map[string]float64{"crude_oil": 75, "power": 50}
+	end := map[string]float64{"crude_oil": 76}
+
+	got := attributor.AttributePnL(nil, start, end)
+	if len(got.Unpriced) != 1 || got.Unpriced[0] != "power" {
+		t.Errorf("Expected power reported unpriced, got %v", got.Unpriced)
+	}
+	if len(got.Commodities) != 1 || got.MarketMove != 100 || got.Total != 100 {
+		t.Errorf("Expected only crude's 100 market move, got %+v", got)
+	}
+}
@@ -0,0 +1,173 @@
+package integration
+
+import (
+	"math"
+	"sort"
+)
+
+// StartAuction switches the book to call-auction mode. Orders rest without
+// matching, so the book may cross, until Uncross runs.
+func (b *OrderBook) StartAuction() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.record(BookEvent{Type: BookEventAuctionStart})
+	b.auction = true
+}
+
+// InAuction reports whether the book is collecting orders for an auction
+func (b *OrderBook) InAuction() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.auction
+}
+
+// Uncross ends the auction by executing every crossing order at the single
+// price that maximises matched volume. Among prices with equal volume the
+// one with the smallest imbalance between demand and supply wins, and any
+// remaining tie takes the lowest price. Orders fill in price-time priority;
+// unmatched volume stays resting and continuous matching resumes. A
+// clearing price of zero means nothing crossed. The whole uncross happens
+// under the book lock, so no other operation observes a partial auction.
+// Market-on-close orders are held for UncrossClose.
+func (b *OrderBook) Uncross() (clearingPrice float64, trades []Trade) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.record(BookEvent{Type: BookEventUncross})
+	price, trades := b.uncrossLocked(nil, nil)
+	b.changedLocked()
+	return price, trades
+}
+
+// UncrossClose runs the closing uncross. It works as Uncross, with
+// market-on-close orders joining at the clearing price ahead of limit
+// orders. MOC volume left unfilled is cancelled and returned, or carried to
+// the next close under MOCRemainderCarry. Without limit orders to set a
+// price nothing trades.
+func (b *OrderBook) UncrossClose() (clearingPrice float64, trades []Trade, cancelled []TradingOrder) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.record(BookEvent{Type: BookEventCloseUncross})
+	var buys, sells []*restingOrder
+	for _, ro := range b.moc {
+		if ro.Side == SideBuy {
+			buys = append(buys, ro)
+		} else {
+			sells = append(sells, ro)
+		}
+	}
+	price, trades := b.uncrossLocked(buys, sells)
+
+	if b.mocRemainder != MOCRemainderCarry {
+		for _, ro := range b.moc {
+			if ro.Volume > volumeEpsilon {
+				cancelled = append(cancelled, ro.TradingOrder)
+			}
+			delete(b.orders, ro.OrderID)
+		}
+		b.moc = nil
+		b.metrics.OrdersCanceled(b.commodity, len(cancelled))
+	}
+	b.changedLocked()
+	return price, trades, cancelled
+}
+
+// uncrossLocked clears the book at one price, filling the given
+// market-on-close orders before limit orders on each side
+func (b *OrderBook) uncrossLocked(mocBuys, mocSells []*restingOrder) (float64, []Trade) {
+	b.auction = false
+
+	price, volume := b.clearingPriceLocked(totalVolume(mocBuys), totalVolume(mocSells))
+	if volume < volumeEpsilon {
+		return 0, nil
+	}
+	next := func(moc *[]*restingOrder, levels []*bookLevel) *restingOrder {
+		for len(*moc) > 0 && (*moc)[0].Volume <= volumeEpsilon {
+			*moc = (*moc)[1:]
+		}
+		if len(*moc) > 0 {
+			return (*moc)[0]
+		}
+		return levels[0].orders[0]
+	}
+	var trades []Trade
+	for remaining := volume; remaining > volumeEpsilon; {
+		buy, sell := next(&mocBuys, b.bids), next(&mocSells, b.asks)
+		fill := math.Min(remaining, math.Min(buy.Volume, sell.Volume))
+		trades = append(trades, b.recordTrade(Trade{
+			Price:        price,
+			Volume:       fill,
+			BuyOrderID:   buy.OrderID,
+			SellOrderID:  sell.OrderID,
+			BuyClientID:  buy.ClientID,
+			SellClientID: sell.ClientID,
+		}))
+		remaining -= fill
+		for _, ro := range []*restingOrder{buy, sell} {
+			ro.Volume -= fill
+			if ro.Volume <= volumeEpsilon {
+				b.removeLocked(ro)
+				if b.refreshLocked(ro) {
+					b.insertLocked(ro)
+				}
+			}
+		}
+	}
+	return price, trades
+}
+
+// clearingPriceLocked evaluates every resting price as a candidate, with
+// market-on-close volume counted at every price
+func (b *OrderBook) clearingPriceLocked(mocBuy, mocSell float64) (price, volume float64) {
+	bestImbalance := math.Inf(1)
+	for _, candidate := range auctionCandidates(b.bids, b.asks) {
+		demand, supply := mocBuy, mocSell
+		for _, level := range b.bids {
+			if level.price >= candidate {
+				demand += levelVolume(level)
+			}
+		}
+		for _, level := range b.asks {
+			if level.price <= candidate {
+				supply += levelVolume(level)
+			}
+		}
+		matched := math.Min(demand, supply)
+		imbalance := math.Abs(demand - supply)
+		better := matched > volume+volumeEpsilon ||
+			(math.Abs(matched-volume) <= volumeEpsilon && imbalance < bestImbalance-volumeEpsilon)
+		if better {
+			price, volume, bestImbalance = candidate, matched, imbalance
+		}
+	}
+	return price, volume
+}
+
+// auctionCandidates returns the distinct resting prices in ascending order
+func auctionCandidates(bids, asks []*bookLevel) []float64 {
+	seen := make(map[float64]bool)
+	var prices []float64
+	for _, levels := range [][]*bookLevel{bids, asks} {
+		for _, level := range levels {
+			if !seen[level.price] {
+				seen[level.price] = true
+				prices = append(prices, level.price)
+			}
+		}
+	}
+	sort.Float64s(prices)
+	return prices
+}
+
+func levelVolume(level *bookLevel) float64 {
+	return totalVolume(level.orders)
+}
+
+func totalVolume(orders []*restingOrder) float64 {
+	total := 0.0
+	for _, ro := range orders {
+		total += ro.Volume
+	}
+	return total
+}
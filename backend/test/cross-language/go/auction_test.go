@@ -0,0 +1,156 @@
+package integration
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestUncrossClassicSchedule verifies the volume-maximising clearing price and remaining book
+func TestUncrossClassicSchedule(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log))
+	book.StartAuction()
+
+	orders := []TradingOrder{
+		{OrderID: "b102", Side: SideBuy, Price: 102, Volume: 10},
+		{OrderID: "b101", Side: SideBuy, Price: 101, Volume: 20},
+		{OrderID: "b100", Side: SideBuy, Price: 100, Volume: 30},
+		{OrderID: "b99", Side: SideBuy, Price: 99, Volume: 40},
+		{OrderID: "s98", Side: SideSell, Price: 98, Volume: 15},
+		{OrderID: "s99", Side: SideSell, Price: 99, Volume: 20},
+		{OrderID: "s100", Side: SideSell, Price: 100, Volume: 25},
+		{OrderID: "s101", Side: SideSell, Price: 101, Volume: 30},
+	}
+	for _, o := range orders {
+		trades, err := book.Add(o)
+		if err != nil || len(trades) != 0 {
+			t.Fatalf("Expected %s to rest during the auction, got %+v, %v", o.OrderID, trades, err)
+		}
+	}
+	if _, err := book.Add(TradingOrder{OrderID: "mkt", Side: SideBuy, Type: OrderTypeMarket, Volume: 5}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected market orders to be refused during the auction, got %v", err)
+	}
+
+	price, trades := book.Uncross()
+	if price != 100 {
+		t.Fatalf("Expected clearing price 100, got %g", price)
+	}
+	matched := 0.0
+	for _, tr := range trades {
+		if tr.Price != 100 {
+			t.Errorf("Expected every trade at 100, got %+v", tr)
+		}
+		matched += tr.Volume
+	}
+	if matched != 60 {
+		t.Errorf("Expected 60 matched, got %g", matched)
+	}
+
+	snap := book.Snapshot()
+	if len(snap.Bids) != 1 || snap.Bids[0].Price != 99 || snap.Bids[0].Volume != 40 ||
+		len(snap.Asks) != 1 || snap.Asks[0].Price != 101 || snap.Asks[0].Volume != 30 {
+		t.Errorf("Expected 40@99 bid and 30@101 ask left resting, got %+v", snap)
+	}
+	if book.InAuction() {
+		t.Error("Expected continuous trading to resume after the uncross")
+	}
+
+	rebuilt, err := Rebuild(log)
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	if !reflect.DeepEqual(rebuilt.Snapshot(), snap) {
+		t.Errorf("Expected the rebuilt book to match, got %+v", rebuilt.Snapshot())
+	}
+}
+
+// TestUncrossTieBreak verifies equal-volume, equal-imbalance prices resolve to the lowest
+func TestUncrossTieBreak(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	book.StartAuction()
+	book.Add(TradingOrder{OrderID: "b", Side: SideBuy, Price: 101, Volume: 10})
+	book.Add(TradingOrder{OrderID: "s", Side: SideSell, Price: 100, Volume: 10})
+
+	if price, trades := book.Uncross(); price != 100 || len(trades) != 1 {
+		t.Errorf("Expected a single trade at 100, got %g, %+v", price, trades)
+	}
+}
+
+// TestMarketOnCloseFillsAtClearingPrice verifies MOC orders wait for the close and fill at the uncross price
+func TestMarketOnCloseFillsAtClearingPrice(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log))
+	book.Add(TradingOrder{OrderID: "s100", ClientID: "gulf", Side: SideSell, Price: 100, Volume: 20})
+
+	trades, err := book.Add(TradingOrder{OrderID: "moc-buy", ClientID: "acme", Side: SideBuy, Type: OrderTypeMarketOnClose, Volume: 25})
+	if err != nil || len(trades) != 0 {
+		t.Fatalf("Expected the MOC order to be held without trading, got %+v, %v", trades, err)
+	}
+	if _, ask, _ := book.BestAsk(); ask != 20 {
+		t.Errorf("Expected continuous liquidity untouched, got %g at the ask", ask)
+	}
+	book.Add(TradingOrder{OrderID: "moc-sell", ClientID: "delta", Side: SideSell, Type: OrderTypeMarketOnClose, Volume: 10})
+
+	book.StartAuction()
+	for _, o := range []TradingOrder{
+		{OrderID: "b101", Side: SideBuy, Price: 101, Volume: 10},
+		{OrderID: "b99", Side: SideBuy, Price: 99, Volume: 30},
+		{OrderID: "s102", Side: SideSell, Price: 102, Volume: 15},
+	} {
+		book.Add(o)
+	}
+
+	// Demand at 100: MOC 25 + b101 10 = 35; supply: MOC 10 + s100 20 = 30
+	price, trades, cancelled := book.UncrossClose()
+	if price != 100 {
+		t.Fatalf("Expected clearing price 100, got %g", price)
+	}
+	mocFilled := 0.0
+	for _, tr := range trades {
+		if tr.Price != 100 {
+			t.Errorf("Expected every trade at the clearing price, got %+v", tr)
+		}
+		if tr.BuyOrderID == "moc-buy" {
+			mocFilled += tr.Volume
+		}
+	}
+	if mocFilled != 25 {
+		t.Errorf("Expected the MOC buy to fill completely ahead of limit bids, got %g", mocFilled)
+	}
+	if len(cancelled) != 0 {
+		t.Errorf("Expected no MOC remainder, got %+v", cancelled)
+	}
+	if _, ok := book.Order("b101"); !ok {
+		t.Error("Expected b101 to keep 5 resting behind the MOC buy")
+	}
+
+	if _, err := Rebuild(log); err != nil {
+		t.Errorf("Expected the closing uncross to replay, got %v", err)
+	}
+}
+
+// TestMarketOnCloseRemainderPolicies verifies unfilled MOC volume is cancelled or carried
+func TestMarketOnCloseRemainderPolicies(t *testing.T) {
+	for _, policy := range []string{MOCRemainderCancel, MOCRemainderCarry} {
+		book := NewOrderBook("crude_oil", WithMOCRemainder(policy))
+		book.Add(TradingOrder{OrderID: "moc", Side: SideBuy, Type: OrderTypeMarketOnClose, Volume: 50})
+		book.Add(TradingOrder{OrderID: "s100", Side: SideSell, Price: 100, Volume: 20})
+
+		_, trades, cancelled := book.UncrossClose()
+		if len(trades) != 1 || trades[0].Volume != 20 {
+			t.Fatalf("%s: expected 20 to fill, got %+v", policy, trades)
+		}
+		remaining, held := book.Order("moc")
+		switch policy {
+		case MOCRemainderCancel:
+			if held || len(cancelled) != 1 || cancelled[0].Volume != 30 {
+				t.Errorf("Expected the 30 remainder cancelled, got %+v (held %v)", cancelled, held)
+			}
+		case MOCRemainderCarry:
+			if !held || remaining.Volume != 30 || len(cancelled) != 0 {
+				t.Errorf("Expected the 30 remainder carried, got %+v (held %v)", remaining, held)
+			}
+		}
+	}
+}
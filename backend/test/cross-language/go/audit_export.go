@@ -0,0 +1,280 @@
+package integration
+
+import (
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+	"unicode"
+)
+
+// auditFields maps audit field names to how each is read from an event.
+// Order fields come from the submitted order on adds and from the trade on
+// trade events; fields that do not apply to an event export empty, while a
+// zero that does apply exports as 0.
+var auditFields = map[string]func(BookEvent) string{
+	"seq":       func(ev BookEvent) string { return strconv.FormatUint(ev.Seq, 10) },
+	"timestamp": func(ev BookEvent) string { return ev.Timestamp.UTC().Format(time.RFC3339Nano) },
+	"commodity": func(ev BookEvent) string { return ev.Commodity },
+	"type":      func(ev BookEvent) string { return ev.Type },
+	"order_id":  func(ev BookEvent) string { return ev.OrderID },
+	"client_id": func(ev BookEvent) string {
+		if ev.Order != nil {
+			return ev.Order.ClientID
+		}
+		return ""
+	},
+	"side": func(ev BookEvent) string {
+		switch {
+		case ev.Order != nil:
+			return ev.Order.Side
+		case ev.Trade != nil:
+			return ev.Trade.Aggressor
+		}
+		return ""
+	},
+	"price": func(ev BookEvent) string {
+		switch {
+		case ev.Order != nil:
+			return formatAuditNumber(ev.Order.Price)
+		case ev.Trade != nil:
+			return formatAuditNumber(ev.Trade.Price)
+		}
+		if ev.Type == BookEventAmend || ev.Type == BookEventTickSize {
+			return formatAuditNumber(ev.Price)
+		}
+		return ""
+	},
+	"volume": func(ev BookEvent) string {
+		switch {
+		case ev.Order != nil:
+			return formatAuditNumber(ev.Order.Volume)
+		case ev.Trade != nil:
+			return formatAuditNumber(ev.Trade.Volume)
+		}
+		// A cancel carries a volume only for an IOC remainder; a full
+		// cancel records none
+		if ev.Type == BookEventAmend || ev.Type == BookEventReduce || (ev.Type == BookEventCancel && ev.Volume != 0) {
+			return formatAuditNumber(ev.Volume)
+		}
+		return ""
+	},
+	"trade_id":       auditTradeField(func(t *Trade) string { return t.TradeID }),
+	"buy_order_id":   auditTradeField(func(t *Trade) string { return t.BuyOrderID }),
+	"sell_order_id":  auditTradeField(func(t *Trade) string { return t.SellOrderID }),
+	"buy_client_id":  auditTradeField(func(t *Trade) string { return t.BuyClientID }),
+	"sell_client_id": auditTradeField(func(t *Trade) string { return t.SellClientID }),
+}
+
+func auditTradeField(get func(*Trade) string) func(BookEvent) string {
+	return func(ev BookEvent) string {
+		if ev.Trade == nil {
+			return ""
+		}
+		return get(ev.Trade)
+	}
+}
+
+func formatAuditNumber(v float64) string {
+	return strconv.FormatFloat(v, 'f', -1, 64)
+}
+
+// AuditColumn selects an audit field and names it in the export. An empty
+// Header uses the field name. XML exports use the header as element name,
+// so WriteXML rejects headers that are not valid XML names.
+type AuditColumn struct {
+	Field  string `json:"field"`
+	Header string `json:"header,omitempty"`
+}
+
+// DefaultAuditColumns exports every field under its own name
+var DefaultAuditColumns = []AuditColumn{
+	{Field: "seq"}, {Field: "timestamp"}, {Field: "commodity"}, {Field: "type"},
+	{Field: "order_id"}, {Field: "client_id"}, {Field: "side"}, {Field: "price"}, {Field: "volume"},
+	{Field: "trade_id"}, {Field: "buy_order_id"}, {Field: "sell_order_id"},
+	{Field: "buy_client_id"}, {Field: "sell_client_id"},
+}
+
+// AuditFilter restricts an export. Zero times leave the range open; From
+// is inclusive and To exclusive. An empty Commodities exports them all.
+type AuditFilter struct {
+	From        time.Time
+	To          time.Time
+	Commodities []string
+}
+
+func (f AuditFilter) match(ev BookEvent) bool {
+	if !f.From.IsZero() && ev.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !ev.Timestamp.Before(f.To) {
+		return false
+	}
+	if len(f.Commodities) == 0 {
+		return true
+	}
+	for _, c := range f.Commodities {
+		if c == ev.Commodity {
+			return true
+		}
+	}
+	return false
+}
+
+// EventScanner is implemented by event logs that can stream their events
+// without copying the whole log
+type EventScanner interface {
+	Scan(fn func(BookEvent) error) error
+}
+
+// AuditExporter writes book event logs as CSV or XML with a configurable
+// column schema. Events are streamed to the writer one at a time, reading
+// logs through EventScanner where they support it.
+type AuditExporter struct {
+	columns []AuditColumn
+	values  []func(BookEvent) string
+}
+
+// NewAuditExporter creates an exporter for the columns; nil columns use
+// DefaultAuditColumns
+func NewAuditExporter(columns []AuditColumn) (*AuditExporter, error) {
+	if columns == nil {
+		columns = DefaultAuditColumns
+	}
+	e := &AuditExporter{}
+	for _, col := range columns {
+		value, ok := auditFields[col.Field]
+		if !ok {
+			return nil, fmt.Errorf("unknown audit field %q", col.Field)
+		}
+		if col.Header == "" {
+			col.Header = col.Field
+		}
+		e.columns = append(e.columns, col)
+		e.values = append(e.values, value)
+	}
+	return e, nil
+}
+
+// WriteCSV writes a header row then one row per matching event, returning
+// the number of events written
+func (e *AuditExporter) WriteCSV(w io.Writer, filter AuditFilter, logs ...EventLog) (int, error) {
+	out := csv.NewWriter(w)
+	header := make([]string, len(e.columns))
+	for i, col := range e.columns {
+		header[i] = col.Header
+	}
+	if err := out.Write(header); err != nil {
+		return 0, err
+	}
+
+	row := make([]string, len(e.columns))
+	n, err := e.each(filter, logs, func(ev BookEvent) error {
+		for i, value := range e.values {
+			row[i] = value(ev)
+		}
+		if err := out.Write(row); err != nil {
+			return err
+		}
+		// Flush per row so a large export never accumulates in the buffer
+		out.Flush()
+		return out.Error()
+	})
+	out.Flush()
+	if err == nil {
+		err = out.Error()
+	}
+	return n, err
+}
+
+// WriteXML writes an AuditLog document holding one Event element per
+// matching event, returning the number of events written
+func (e *AuditExporter) WriteXML(w io.Writer, filter AuditFilter, logs ...EventLog) (int, error) {
+	for _, col := range e.columns {
+		if !isXMLName(col.Header) {
+			return 0, fmt.Errorf("audit header %q for field %s is not a valid XML element name", col.Header, col.Field)
+		}
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return 0, err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	root := xml.StartElement{Name: xml.Name{Local: "AuditLog"}}
+	if err := enc.EncodeToken(root); err != nil {
+		return 0, err
+	}
+
+	n, err := e.each(filter, logs, func(ev BookEvent) error {
+		event := xml.StartElement{Name: xml.Name{Local: "Event"}}
+		if err := enc.EncodeToken(event); err != nil {
+			return err
+		}
+		for i, value := range e.values {
+			field := xml.StartElement{Name: xml.Name{Local: e.columns[i].Header}}
+			if err := enc.EncodeElement(value(ev), field); err != nil {
+				return err
+			}
+		}
+		if err := enc.EncodeToken(event.End()); err != nil {
+			return err
+		}
+		return enc.Flush()
+	})
+	if err != nil {
+		return n, err
+	}
+	if err := enc.EncodeToken(root.End()); err != nil {
+		return n, err
+	}
+	if err := enc.Flush(); err != nil {
+		return n, err
+	}
+	_, err = io.WriteString(w, "\n")
+	return n, err
+}
+
+// isXMLName reports whether name can be used as an unprefixed element name:
+// a letter or underscore followed by letters, digits, '-', '_' or '.'
+func isXMLName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, r := range name {
+		switch {
+		case unicode.IsLetter(r) || r == '_':
+		case i > 0 && (unicode.IsDigit(r) || r == '-' || r == '.'):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// each streams the matching events of every log, in log order
+func (e *AuditExporter) each(filter AuditFilter, logs []EventLog, fn func(BookEvent) error) (int, error) {
+	n := 0
+	visit := func(ev BookEvent) error {
+		if !filter.match(ev) {
+			return nil
+		}
+		n++
+		return fn(ev)
+	}
+	for _, log := range logs {
+		if scanner, ok := log.(EventScanner); ok {
+			if err := scanner.Scan(visit); err != nil {
+				return n, err
+			}
+			continue
+		}
+		for _, ev := range log.Events() {
+			if err := visit(ev); err != nil {
+				return n, err
+			}
+		}
+	}
+	return n, nil
+}
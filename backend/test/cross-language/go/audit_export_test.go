@@ -0,0 +1,177 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+)
+
+// auditSampleLogs builds a crude log with an add, a trade and a cancel, and a gas log with one add
+func auditSampleLogs(t *testing.T) (crude, gas *MemoryEventLog, start time.Time) {
+	t.Helper()
+	start = time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	now := start
+	clock := func() time.Time { return now }
+	crude, gas = NewMemoryEventLog(), NewMemoryEventLog()
+	crudeBook := NewOrderBook("crude_oil", WithClock(clock), WithEventLog(crude))
+	gasBook := NewOrderBook("natural_gas", WithClock(clock), WithEventLog(gas))
+
+	steps := []func() error{
+		func() error {
+			_, err := crudeBook.Add(TradingOrder{OrderID: "ask1", ClientID: "acme", Side: SideSell, Price: 75.5, Volume: 10})
+			return err
+		},
+		func() error {
+			_, err := crudeBook.Add(TradingOrder{OrderID: "bid1", ClientID: "gulf", Side: SideBuy, Price: 75.5, Volume: 4})
+			return err
+		},
+		func() error { return crudeBook.Cancel("ask1") },
+		func() error {
+			_, err := gasBook.Add(TradingOrder{OrderID: "gas1", ClientID: "acme", Side: SideBuy, Price: 2.5, Volume: 100})
+			return err
+		},
+	}
+	for i, step := range steps {
+		now = start.Add(time.Duration(i) * time.Minute)
+		if err := step(); err != nil {
+			t.Fatalf("Step %d failed: %v", i, err)
+		}
+	}
+	return crude, gas, start
+}
+
+// TestAuditExporterCSV verifies the configured columns, headers and filters in CSV output
+func TestAuditExporterCSV(t *testing.T) {
+	crude, gas, start := auditSampleLogs(t)
+	exporter, err := NewAuditExporter([]AuditColumn{
+		{Field: "timestamp", Header: "EventTime"},
+		{Field: "type"},
+		{Field: "order_id"},
+		{Field: "price"},
+		{Field: "volume"},
+		{Field: "buy_client_id"},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create exporter: %v", err)
+	}
+
+	var out bytes.Buffer
+	n, err := exporter.WriteCSV(&out, AuditFilter{}, crude, gas)
+	if err != nil || n != 5 {
+		t.Fatalf("Expected 5 events exported, got %d: %v", n, err)
+	}
+	rows, err := csv.NewReader(&out).ReadAll()
+	if err != nil {
+		t.Fatalf("Export is not valid CSV: %v", err)
+	}
+	want := [][]string{
+		{"EventTime", "type", "order_id", "price", "volume", "buy_client_id"},
+		{"2024-01-02T14:00:00Z", "add", "ask1", "75.5", "10", ""},
+		{"2024-01-02T14:01:00Z", "add", "bid1", "75.5", "4", ""},
+		{"2024-01-02T14:01:00Z", "trade", "", "75.5", "4", "gulf"},
+		{"2024-01-02T14:02:00Z", "cancel", "ask1", "", "", ""},
+		{"2024-01-02T14:03:00Z", "add", "gas1", "2.5", "100", ""},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %v", len(want), rows)
+	}
+	for i := range want {
+		if strings.Join(rows[i], ",") != strings.Join(want[i], ",") {
+			t.Errorf("Row %d: expected %v, got %v", i, want[i], rows[i])
+		}
+	}
+
+	// One commodity within a window that excludes the first add
+	out.Reset()
+	n, err = exporter.WriteCSV(&out, AuditFilter{
+		From:        start.Add(time.Minute),
+		To:          start.Add(3 * time.Minute),
+		Commodities: []string{"crude_oil"},
+	}, crude, gas)
+	if err != nil || n != 3 {
+		t.Errorf("Expected 3 filtered events, got %d: %v", n, err)
+	}
+	if _, err := NewAuditExporter([]AuditColumn{{Field: "nonsense"}}); err == nil {
+		t.Error("Expected an unknown field to be rejected")
+	}
+}
+
+// TestAuditExporterXML verifies the XML document structure for the default schema
+func TestAuditExporterXML(t *testing.T) {
+	crude, _, _ := auditSampleLogs(t)
+	exporter, err := NewAuditExporter(nil)
+	if err != nil {
+		t.Fatalf("Failed to create exporter: %v", err)
+	}
+
+	var out bytes.Buffer
+	if n, err := exporter.WriteXML(&out, AuditFilter{}, crude); err != nil || n != 4 {
+		t.Fatalf("Expected 4 events exported, got %d: %v", n, err)
+	}
+	if !strings.HasPrefix(out.String(), xml.Header) {
+		t.Errorf("Expected an XML declaration, got %q", out.String()[:40])
+	}
+
+	var doc struct {
+		XMLName xml.Name `xml:"AuditLog"`
+		Events  []struct {
+			Seq          uint64 `xml:"seq"`
+			Type         string `xml:"type"`
+			OrderID      string `xml:"order_id"`
+			ClientID     string `xml:"client_id"`
+			Side         string `xml:"side"`
+			TradeID      string `xml:"trade_id"`
+			SellClientID string `xml:"sell_client_id"`
+		} `xml:"Event"`
+	}
+	if err := xml.Unmarshal(out.Bytes(), &doc); err != nil {
+		t.Fatalf("Export is not valid XML: %v", err)
+	}
+	if len(doc.Events) != 4 {
+		t.Fatalf("Expected 4 Event elements, got %d", len(doc.Events))
+	}
+	add, trade := doc.Events[0], doc.Events[2]
+	if add.Seq != 1 || add.Type != "add" || add.OrderID != "ask1" || add.ClientID != "acme" || add.Side != SideSell {
+		t.Errorf("Unexpected add element %+v", add)
+	}
+	if trade.Type != "trade" || trade.TradeID != "crude_oil-1" || trade.SellClientID != "acme" || trade.Side != SideBuy {
+		t.Errorf("Unexpected trade element %+v", trade)
+	}
+}
+
+// TestAuditExporterZeroAndInvalidHeaders verifies a zero price exports as 0 and XML rejects headers that are not element names
+func TestAuditExporterZeroAndInvalidHeaders(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("power", WithSignedPrices(), WithEventLog(log))
+	if _, err := book.Add(TradingOrder{OrderID: "ask1", ClientID: "acme", Side: SideSell, Price: 0, Volume: 5}); err != nil {
+		t.Fatalf("Failed to add a zero-priced order: %v", err)
+	}
+	exporter, err := NewAuditExporter([]AuditColumn{{Field: "type"}, {Field: "price"}, {Field: "volume"}})
+	if err != nil {
+		t.Fatalf("Failed to create exporter: %v", err)
+	}
+	var out bytes.Buffer
+	if _, err := exporter.WriteCSV(&out, AuditFilter{}, log); err != nil {
+		t.Fatalf("CSV export failed: %v", err)
+	}
+	if want := "type,price,volume\nadd,0,5\n"; out.String() != want {
+		t.Errorf("Expected %q, got %q", want, out.String())
+	}
+
+	for _, header := range []string{"order id", "1price", "ns:price", "<price>"} {
+		exporter, err := NewAuditExporter([]AuditColumn{{Field: "price", Header: header}})
+		if err != nil {
+			t.Fatalf("Failed to create exporter: %v", err)
+		}
+		out.Reset()
+		if _, err := exporter.WriteXML(&out, AuditFilter{}, log); err == nil || out.Len() != 0 {
+			t.Errorf("Expected header %q to be rejected before writing, got %v with %q", header, err, out.String())
+		}
+		if _, err := exporter.WriteCSV(&out, AuditFilter{}, log); err != nil {
+			t.Errorf("Expected header %q to be fine in CSV, got %v", header, err)
+		}
+	}
+}
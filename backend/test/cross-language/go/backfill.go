@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// MarketDataSource is a feed that can stream live ticks and serve history
+type MarketDataSource interface {
+	Name() string
+	Subscribe(ctx context.Context, commodity string) (<-chan MarketData, error)
+	Fetch(ctx context.Context, commodity string, from, to time.Time) ([]MarketData, error)
+}
+
+// Gap is a hole in a tick series between two observed ticks
+type Gap struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// BackfillReport summarizes a backfill run
+type BackfillReport struct {
+	Gaps       int `json:"gaps"`
+	Backfilled int `json:"backfilled"`
+	Duplicates int `json:"duplicates"`
+}
+
+// Backfiller fills gaps in a tick series from a secondary source
+type Backfiller struct {
+	source    MarketDataSource
+	cadence   time.Duration
+	tolerance float64 // a gap is any spacing above cadence*tolerance
+}
+
+// NewBackfiller creates a backfiller expecting ticks every cadence
+func NewBackfiller(source MarketDataSource, cadence time.Duration) *Backfiller {
+	return &Backfiller{source: source, cadence: cadence, tolerance: 1.5}
+}
+
+// DetectGaps returns the spans where consecutive ticks are further apart
+// than the expected cadence allows. Ticks must be in timestamp order.
+func (b *Backfiller) DetectGaps(ticks []MarketData) []Gap {
+	limit := time.Duration(float64(b.cadence) * b.tolerance)
+	var gaps []Gap
+	for i := 1; i < len(ticks); i++ {
+		if ticks[i].Timestamp.Sub(ticks[i-1].Timestamp) > limit {
+			gaps = append(gaps, Gap{From: ticks[i-1].Timestamp, To: ticks[i].Timestamp})
+		}
+	}
+	return gaps
+}
+
+// Backfill fetches every detected gap from the secondary source and merges
+// the results into the series in timestamp order. Ticks whose timestamp is
+// already present, including ticks returned for overlapping ranges, are
+// dropped and counted as duplicates.
+func (b *Backfiller) Backfill(ctx context.Context, commodity string, ticks []MarketData) ([]MarketData, BackfillReport, error) {
+	gaps := b.DetectGaps(ticks)
+	report := BackfillReport{Gaps: len(gaps)}
+	if len(gaps) == 0 {
+		return ticks, report, nil
+	}
+
+	seen := make(map[int64]struct{}, len(ticks))
+	for _, tick := range ticks {
+		seen[tick.Timestamp.UnixNano()] = struct{}{}
+	}
+
+	merged := make([]MarketData, len(ticks), len(ticks)+len(gaps))
+	copy(merged, ticks)
+	for _, gap := range gaps {
+		fetched, err := b.source.Fetch(ctx, commodity, gap.From, gap.To)
+		if err != nil {
+			return nil, report, fmt.Errorf("backfill %s %s-%s from %s: %w",
+				commodity, gap.From.Format(time.RFC3339), gap.To.Format(time.RFC3339), b.source.Name(), err)
+		}
+		for _, tick := range fetched {
+			key := tick.Timestamp.UnixNano()
+			if _, dup := seen[key]; dup {
+				report.Duplicates++
+				continue
+			}
+			seen[key] = struct{}{}
+			merged = append(merged, tick)
+			report.Backfilled++
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Timestamp.Before(merged[j].Timestamp)
+	})
+	return merged, report, nil
+}
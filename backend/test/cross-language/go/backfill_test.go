@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// fakeMarketDataSource serves recorded ticks for history and streaming
+type fakeMarketDataSource struct {
+	name  string
+	ticks []MarketData
+}
+
+func (f *fakeMarketDataSource) Name() string { return f.name }
+
+func (f *fakeMarketDataSource) Subscribe(ctx context.Context, commodity string) (<-chan MarketData, error) {
+	out := make(chan MarketData, len(f.ticks))
+	for _, tick := range f.ticks {
+		if tick.Commodity == commodity {
+			out <- tick
+		}
+	}
+	close(out)
+	return out, nil
+}
+
+func (f *fakeMarketDataSource) Fetch(ctx context.Context, commodity string, from, to time.Time) ([]MarketData, error) {
+	var out []MarketData
+	for _, tick := range f.ticks {
+		if tick.Commodity == commodity && !tick.Timestamp.Before(from) && !tick.Timestamp.After(to) {
+			out = append(out, tick)
+		}
+	}
+	return out, nil
+}
+
+// TestBackfillSyntheticGap verifies missing ticks are fetched and merged without duplicates
+func TestBackfillSyntheticGap(t *testing.T) {
+	full := recordedTicks(10, time.Second)
+
+	// Primary feed dropped ticks 3 through 6.
+	var primary []MarketData
+	primary = append(primary, full[:3]...)
+	primary = append(primary, full[7:]...)
+
+	backfiller := NewBackfiller(&fakeMarketDataSource{name: "secondary", ticks: full}, time.Second)
+	if gaps := backfiller.DetectGaps(primary); len(gaps) != 1 {
+		t.Fatalf("Expected 1 gap, got %d", len(gaps))
+	}
+
+	merged, report, err := backfiller.Backfill(context.Background(), "crude_oil", primary)
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+
+	if report.Backfilled != 4 {
+		t.Errorf("Expected 4 backfilled ticks, got %d", report.Backfilled)
+	}
+	if report.Duplicates != 2 {
+		t.Errorf("Expected 2 boundary duplicates skipped, got %d", report.Duplicates)
+	}
+	if len(merged) != len(full) {
+		t.Fatalf("Expected %d ticks after backfill, got %d", len(full), len(merged))
+	}
+	for i := range full {
+		if !merged[i].Timestamp.Equal(full[i].Timestamp) {
+			t.Errorf("Tick %d: expected %v, got %v", i, full[i].Timestamp, merged[i].Timestamp)
+		}
+	}
+}
+
+// TestBackfillNoGaps verifies a complete series is left untouched
+func TestBackfillNoGaps(t *testing.T) {
+	ticks := recordedTicks(5, time.Second)
+	backfiller := NewBackfiller(&fakeMarketDataSource{name: "secondary"}, time.Second)
+
+	merged, report, err := backfiller.Backfill(context.Background(), "crude_oil", ticks)
+	if err != nil {
+		t.Fatalf("Backfill failed: %v", err)
+	}
+	if report.Gaps != 0 || report.Backfilled != 0 || len(merged) != len(ticks) {
+		t.Errorf("Expected no changes, got report %+v with %d ticks", report, len(merged))
+	}
+}
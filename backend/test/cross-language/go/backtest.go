@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"sort"
+	"time"
+)
+
+// Remainder policies for volume the recorded depth cannot fill
+const (
+	RemainderRest   = "rest"   // keep the remainder working against later snapshots
+	RemainderReport = "report" // report the remainder as unfilled immediately
+)
+
+// DepthSnapshot is recorded book depth at a point in time
+type DepthSnapshot struct {
+	Timestamp time.Time    `json:"timestamp"`
+	Book      BookSnapshot `json:"book"`
+}
+
+// BacktestExecution is one simulated fill against a recorded level
+type BacktestExecution struct {
+	Price     float64   `json:"price"`
+	Volume    float64   `json:"volume"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BacktestFill is the simulated outcome of one order
+type BacktestFill struct {
+	OrderID    string              `json:"order_id"`
+	Side       string              `json:"side"`
+	Requested  float64             `json:"requested"`
+	Filled     float64             `json:"filled"`
+	AvgPrice   float64             `json:"avg_price"`
+	Unfilled   float64             `json:"unfilled"`
+	Resting    bool                `json:"resting"`
+	Executions []BacktestExecution `json:"executions"`
+}
+
+// DepthBacktester simulates fills by walking recorded book depth rather than
+// filling everything at the last tick price. Volume taken from a snapshot
+// stays consumed until the next snapshot replaces it.
+type DepthBacktester struct {
+	depth     []DepthSnapshot
+	remainder string
+}
+
+// NewDepthBacktester creates a backtester over recorded depth; an empty
+// remainder policy reports unfilled volume
+func NewDepthBacktester(depth []DepthSnapshot, remainder string) *DepthBacktester {
+	sorted := append([]DepthSnapshot(nil), depth...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Timestamp.Before(sorted[j].Timestamp) })
+	if remainder == "" {
+		remainder = RemainderReport
+	}
+	return &DepthBacktester{depth: sorted, remainder: remainder}
+}
+
+// Run simulates orders in timestamp order and returns one fill per order in
+// the order given. Orders are matched against the latest snapshot at or
+// before their timestamp; orders before the first snapshot wait for it.
+func (b *DepthBacktester) Run(orders []TradingOrder) []BacktestFill {
+	fills := make([]BacktestFill, len(orders))
+	pending := make([]int, len(orders))
+	for i, order := range orders {
+		fills[i] = BacktestFill{OrderID: order.OrderID, Side: order.Side, Requested: order.Volume, Unfilled: order.Volume}
+		pending[i] = i
+	}
+	sort.SliceStable(pending, func(i, j int) bool {
+		return orders[pending[i]].Timestamp.Before(orders[pending[j]].Timestamp)
+	})
+
+	var resting []int
+	next := 0
+	for s, snap := range b.depth {
+		bids := append([]PriceLevel(nil), snap.Book.Bids...)
+		asks := append([]PriceLevel(nil), snap.Book.Asks...)
+
+		stillResting := resting[:0]
+		for _, idx := range resting {
+			b.walk(orders[idx], &fills[idx], bids, asks, snap.Timestamp)
+			if fills[idx].Unfilled > volumeEpsilon {
+				stillResting = append(stillResting, idx)
+			}
+		}
+		resting = stillResting
+
+		for ; next < len(pending); next++ {
+			idx := pending[next]
+			if s+1 < len(b.depth) && !orders[idx].Timestamp.Before(b.depth[s+1].Timestamp) {
+				break
+			}
+			b.walk(orders[idx], &fills[idx], bids, asks, snap.Timestamp)
+			if fills[idx].Unfilled > volumeEpsilon && b.remainder == RemainderRest && orders[idx].Type != OrderTypeMarket {
+				resting = append(resting, idx)
+			}
+		}
+	}
+
+	for _, idx := range resting {
+		fills[idx].Resting = true
+	}
+	for i := range fills {
+		if fills[i].Filled > volumeEpsilon {
+			notional := 0.0
+			for _, e := range fills[i].Executions {
+				notional += e.Price * e.Volume
+			}
+			fills[i].AvgPrice = notional / fills[i].Filled
+		}
+		if fills[i].Unfilled < volumeEpsilon {
+			fills[i].Unfilled = 0
+		}
+	}
+	return fills
+}
+
+// walk consumes opposite-side levels within the order's limit price
+func (b *DepthBacktester) walk(order TradingOrder, fill *BacktestFill, bids, asks []PriceLevel, at time.Time) {
+	levels := asks
+	if order.Side == SideSell {
+		levels = bids
+	}
+	for i := range levels {
+		if fill.Unfilled < volumeEpsilon {
+			return
+		}
+		level := &levels[i]
+		if level.Volume < volumeEpsilon {
+			continue
+		}
+		if order.Type != OrderTypeMarket {
+			if order.Side == SideBuy && level.Price > order.Price {
+				return
+			}
+			if order.Side == SideSell && level.Price < order.Price {
+				return
+			}
+		}
+		volume := fill.Unfilled
+		if level.Volume < volume {
+			volume = level.Volume
+		}
+		level.Volume -= volume
+		fill.Unfilled -= volume
+		fill.Filled += volume
+		fill.Executions = append(fill.Executions, BacktestExecution{Price: level.Price, Volume: volume, Timestamp: at})
+	}
+}
@@ -0,0 +1,69 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func recordedDepth() []DepthSnapshot {
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	return []DepthSnapshot{
+		{Timestamp: start, Book: BookSnapshot{Commodity: "crude_oil", Seq: 1,
+			Bids: []PriceLevel{{Price: 75.40, Volume: 100, Orders: 2}},
+			Asks: []PriceLevel{{Price: 75.50, Volume: 100, Orders: 1}, {Price: 75.60, Volume: 200, Orders: 3}, {Price: 75.75, Volume: 150, Orders: 2}}}},
+		{Timestamp: start.Add(time.Minute), Book: BookSnapshot{Commodity: "crude_oil", Seq: 2,
+			Bids: []PriceLevel{{Price: 75.45, Volume: 100, Orders: 2}},
+			Asks: []PriceLevel{{Price: 75.55, Volume: 300, Orders: 4}}}},
+	}
+}
+
+// TestDepthBacktesterWalksLevels verifies a large order fills across several recorded levels
+func TestDepthBacktesterWalksLevels(t *testing.T) {
+	depth := recordedDepth()
+	backtester := NewDepthBacktester(depth, RemainderReport)
+
+	fills := backtester.Run([]TradingOrder{
+		{OrderID: "big", Commodity: "crude_oil", Side: SideBuy, Type: OrderTypeLimit, Price: 75.75, Volume: 400, Timestamp: depth[0].Timestamp.Add(time.Second)},
+		{OrderID: "after", Commodity: "crude_oil", Side: SideBuy, Type: OrderTypeLimit, Price: 75.75, Volume: 100, Timestamp: depth[0].Timestamp.Add(2 * time.Second)},
+	})
+
+	big := fills[0]
+	if len(big.Executions) != 3 || big.Filled != 400 || big.Unfilled != 0 {
+		t.Fatalf("Expected 400 filled over 3 levels, got %+v", big)
+	}
+	expectedAvg := (75.50*100 + 75.60*200 + 75.75*100) / 400
+	if math.Abs(big.AvgPrice-expectedAvg) > 1e-9 {
+		t.Errorf("Expected average price %.4f, got %.4f", expectedAvg, big.AvgPrice)
+	}
+
+	// Only 50 remains at 75.75 in the same snapshot
+	after := fills[1]
+	if after.Filled != 50 || after.Unfilled != 50 || after.Resting {
+		t.Errorf("Expected partial fill of 50 with 50 reported unfilled, got %+v", after)
+	}
+}
+
+// TestDepthBacktesterRestsRemainder verifies the remainder works against the next snapshot
+func TestDepthBacktesterRestsRemainder(t *testing.T) {
+	depth := recordedDepth()
+	backtester := NewDepthBacktester(depth, RemainderRest)
+
+	fills := backtester.Run([]TradingOrder{
+		{OrderID: "limit", Commodity: "crude_oil", Side: SideBuy, Type: OrderTypeLimit, Price: 75.55, Volume: 250, Timestamp: depth[0].Timestamp.Add(time.Second)},
+		{OrderID: "mkt", Commodity: "crude_oil", Side: SideSell, Type: OrderTypeMarket, Volume: 150, Timestamp: depth[0].Timestamp.Add(time.Second)},
+	})
+
+	limit := fills[0]
+	if limit.Filled != 250 || len(limit.Executions) != 2 || limit.Resting {
+		t.Fatalf("Expected 100 then 150 filled across snapshots, got %+v", limit)
+	}
+	if limit.Executions[1].Price != 75.55 || !limit.Executions[1].Timestamp.Equal(depth[1].Timestamp) {
+		t.Errorf("Expected the rested remainder to fill at the next snapshot, got %+v", limit.Executions[1])
+	}
+
+	mkt := fills[1]
+	if mkt.Filled != 100 || mkt.Unfilled != 50 || mkt.Resting {
+		t.Errorf("Expected market remainder reported unfilled, got %+v", mkt)
+	}
+}
@@ -0,0 +1,280 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrNoHealthyEndpoint is returned when every endpoint's circuit is open
+var ErrNoHealthyEndpoint = errors.New("no healthy endpoint")
+
+// ServiceConn is a client connection to one service endpoint, shaped after
+// grpc.ClientConnInterface so a gRPC connection can be adapted directly
+type ServiceConn interface {
+	Invoke(ctx context.Context, method string, req, resp interface{}) error
+	Close() error
+}
+
+// ServiceDialer opens a connection to an endpoint address
+type ServiceDialer func(ctx context.Context, address string) (ServiceConn, error)
+
+// Circuit states
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// CircuitBreaker opens after consecutive failures and lets a single trial
+// call through once the cool-down has elapsed
+type CircuitBreaker struct {
+	mu        sync.Mutex
+	threshold int
+	cooldown  time.Duration
+	clock     func() time.Time
+	failures  int
+	openedAt  time.Time
+	state     string
+}
+
+// NewCircuitBreaker creates a closed breaker
+func NewCircuitBreaker(threshold int, cooldown time.Duration, clock func() time.Time) *CircuitBreaker {
+	if threshold <= 0 {
+		threshold = 1
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return &CircuitBreaker{threshold: threshold, cooldown: cooldown, clock: clock, state: CircuitClosed}
+}
+
+// Allow reports whether a call may proceed, moving an expired open circuit to half-open
+func (c *CircuitBreaker) Allow() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch c.state {
+	case CircuitOpen:
+		if c.clock().Sub(c.openedAt) < c.cooldown {
+			return false
+		}
+		c.state = CircuitHalfOpen
+		return true
+	case CircuitHalfOpen:
+		return false // a trial call is already in flight
+	}
+	return true
+}
+
+// Available reports whether Allow would currently let a call through,
+// without changing state
+func (c *CircuitBreaker) Available() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	switch c.state {
+	case CircuitOpen:
+		return c.clock().Sub(c.openedAt) >= c.cooldown
+	case CircuitHalfOpen:
+		return false
+	}
+	return true
+}
+
+// Success closes the circuit
+func (c *CircuitBreaker) Success() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures = 0
+	c.state = CircuitClosed
+}
+
+// Failure records a failed call, opening the circuit at the threshold or
+// immediately when a half-open trial fails
+func (c *CircuitBreaker) Failure() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.failures++
+	if c.state == CircuitHalfOpen || c.failures >= c.threshold {
+		c.state = CircuitOpen
+		c.openedAt = c.clock()
+	}
+}
+
+// Abandon records a call that ended without a verdict on the endpoint,
+// such as one the caller cancelled. A half-open trial is handed back so the
+// next call can make it; failures so far are kept.
+func (c *CircuitBreaker) Abandon() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.state == CircuitHalfOpen {
+		c.state = CircuitOpen
+	}
+}
+
+// State returns the current circuit state
+func (c *CircuitBreaker) State() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.state
+}
+
+// BalancerConfig configures a ServiceBalancer
+type BalancerConfig struct {
+	FailureThreshold int
+	OpenDuration     time.Duration
+	Clock            func() time.Time
+}
+
+type balancedEndpoint struct {
+	address string
+	breaker *CircuitBreaker
+
+	mu   sync.Mutex
+	conn ServiceConn
+}
+
+// ServiceBalancer spreads calls round-robin across endpoints, skipping any
+// whose circuit is open. Connections are dialed lazily and dropped after a
+// failed call so the next call to that endpoint reconnects.
+type ServiceBalancer struct {
+	dial   ServiceDialer
+	config BalancerConfig
+
+	mu        sync.RWMutex
+	endpoints []*balancedEndpoint
+	next      uint64
+}
+
+// NewServiceBalancer creates a balancer over the initial endpoint addresses
+func NewServiceBalancer(dial ServiceDialer, config BalancerConfig, addresses ...string) *ServiceBalancer {
+	b := &ServiceBalancer{dial: dial, config: config}
+	b.UpdateEndpoints(addresses)
+	return b
+}
+
+// UpdateEndpoints replaces the endpoint list. Endpoints that remain keep
+// their connection and circuit state; removed endpoints are closed.
+func (b *ServiceBalancer) UpdateEndpoints(addresses []string) {
+	b.mu.Lock()
+	existing := make(map[string]*balancedEndpoint, len(b.endpoints))
+	for _, ep := range b.endpoints {
+		existing[ep.address] = ep
+	}
+	updated := make([]*balancedEndpoint, 0, len(addresses))
+	for _, address := range addresses {
+		if ep, ok := existing[address]; ok {
+			updated = append(updated, ep)
+			delete(existing, address)
+			continue
+		}
+		updated = append(updated, &balancedEndpoint{
+			address: address,
+			breaker: NewCircuitBreaker(b.config.FailureThreshold, b.config.OpenDuration, b.config.Clock),
+		})
+	}
+	b.endpoints = updated
+	b.mu.Unlock()
+
+	for _, ep := range existing {
+		ep.reset()
+	}
+}
+
+// Endpoints returns the current endpoint addresses with their circuit states
+func (b *ServiceBalancer) Endpoints() map[string]string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	states := make(map[string]string, len(b.endpoints))
+	for _, ep := range b.endpoints {
+		states[ep.address] = ep.breaker.State()
+	}
+	return states
+}
+
+// Invoke sends the call to the next healthy endpoint. A call that fails
+// because ctx was cancelled or ran out of time is not held against the
+// endpoint: its breaker is not charged and its connection is kept.
+func (b *ServiceBalancer) Invoke(ctx context.Context, method string, req, resp interface{}) error {
+	ep, err := b.pick()
+	if err != nil {
+		return err
+	}
+
+	conn, err := ep.connect(ctx, b.dial)
+	if err == nil {
+		err = conn.Invoke(ctx, method, req, resp)
+	}
+	if err != nil && ctx.Err() != nil {
+		ep.breaker.Abandon()
+		return fmt.Errorf("%s %s: %w", ep.address, method, err)
+	}
+	if err != nil {
+		ep.breaker.Failure()
+		ep.reset()
+		return fmt.Errorf("%s %s: %w", ep.address, method, err)
+	}
+	ep.breaker.Success()
+	return nil
+}
+
+// Close closes every endpoint connection
+func (b *ServiceBalancer) Close() {
+	b.mu.Lock()
+	endpoints := b.endpoints
+	b.endpoints = nil
+	b.mu.Unlock()
+	for _, ep := range endpoints {
+		ep.reset()
+	}
+}
+
+// pick rotates a shared counter over the healthy endpoints only, so traffic
+// from an open circuit is spread evenly rather than landing on its neighbour
+func (b *ServiceBalancer) pick() (*balancedEndpoint, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	healthy := make([]*balancedEndpoint, 0, len(b.endpoints))
+	for _, ep := range b.endpoints {
+		if ep.breaker.Available() {
+			healthy = append(healthy, ep)
+		}
+	}
+	n := uint64(len(healthy))
+	start := atomic.AddUint64(&b.next, 1) - 1
+	for i := uint64(0); i < n; i++ {
+		// Allow can still refuse if another caller claimed a half-open trial
+		if ep := healthy[(start+i)%n]; ep.breaker.Allow() {
+			return ep, nil
+		}
+	}
+	return nil, ErrNoHealthyEndpoint
+}
+
+func (ep *balancedEndpoint) connect(ctx context.Context, dial ServiceDialer) (ServiceConn, error) {
+	ep.mu.Lock()
+	defer ep.mu.Unlock()
+	if ep.conn != nil {
+		return ep.conn, nil
+	}
+	conn, err := dial(ctx, ep.address)
+	if err != nil {
+		return nil, err
+	}
+	ep.conn = conn
+	return conn, nil
+}
+
+func (ep *balancedEndpoint) reset() {
+	ep.mu.Lock()
+	conn := ep.conn
+	ep.conn = nil
+	ep.mu.Unlock()
+	if conn != nil {
+		conn.Close()
+	}
+}
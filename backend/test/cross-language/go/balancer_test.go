@@ -0,0 +1,180 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEndpoints simulates a set of service backends
+type fakeEndpoints struct {
+	mu      sync.Mutex
+	down    map[string]bool
+	calls   map[string]int
+	dials   map[string]int
+	closeds map[string]int
+}
+
+func newFakeEndpoints() *fakeEndpoints {
+	return &fakeEndpoints{down: map[string]bool{}, calls: map[string]int{}, dials: map[string]int{}, closeds: map[string]int{}}
+}
+
+func (f *fakeEndpoints) setDown(address string, down bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.down[address] = down
+}
+
+func (f *fakeEndpoints) count(m map[string]int, address string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return m[address]
+}
+
+func (f *fakeEndpoints) dial(ctx context.Context, address string) (ServiceConn, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.dials[address]++
+	return &fakeServiceConn{endpoints: f, address: address}, nil
+}
+
+type fakeServiceConn struct {
+	endpoints *fakeEndpoints
+	address   string
+}
+
+func (c *fakeServiceConn) Invoke(ctx context.Context, method string, req, resp interface{}) error {
+	c.endpoints.mu.Lock()
+	defer c.endpoints.mu.Unlock()
+	c.endpoints.calls[c.address]++
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if c.endpoints.down[c.address] {
+		return errors.New("connection refused")
+	}
+	return nil
+}
+
+func (c *fakeServiceConn) Close() error {
+	c.endpoints.mu.Lock()
+	defer c.endpoints.mu.Unlock()
+	c.endpoints.closeds[c.address]++
+	return nil
+}
+
+// TestServiceBalancerAvoidsUnhealthyEndpoint verifies traffic skips an endpoint with an open circuit
+func TestServiceBalancerAvoidsUnhealthyEndpoint(t *testing.T) {
+	endpoints := newFakeEndpoints()
+	endpoints.setDown("pricing-b:9090", true)
+	balancer := NewServiceBalancer(endpoints.dial, BalancerConfig{FailureThreshold: 2, OpenDuration: time.Hour},
+		"pricing-a:9090", "pricing-b:9090", "pricing-c:9090")
+	defer balancer.Close()
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	failures := 0
+	for w := 0; w < 10; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 30; i++ {
+				if err := balancer.Invoke(context.Background(), "/pricing.Quote/Get", nil, nil); err != nil {
+					mu.Lock()
+					failures++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := endpoints.count(endpoints.calls, "pricing-b:9090"); calls != 2 || failures != 2 {
+		t.Errorf("Expected the unhealthy endpoint to see 2 calls before opening, got %d calls and %d failures", calls, failures)
+	}
+	if state := balancer.Endpoints()["pricing-b:9090"]; state != CircuitOpen {
+		t.Errorf("Expected pricing-b circuit open, got %s", state)
+	}
+	a := endpoints.count(endpoints.calls, "pricing-a:9090")
+	c := endpoints.count(endpoints.calls, "pricing-c:9090")
+	if a+c != 298 || a < 120 || c < 120 {
+		t.Errorf("Expected healthy endpoints to share traffic fairly, got a=%d c=%d", a, c)
+	}
+}
+
+// TestServiceBalancerReconnectsAndUpdates verifies redial after recovery and runtime endpoint updates
+func TestServiceBalancerReconnectsAndUpdates(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	endpoints := newFakeEndpoints()
+	endpoints.setDown("risk-a:9090", true)
+	balancer := NewServiceBalancer(endpoints.dial, BalancerConfig{FailureThreshold: 1, OpenDuration: 30 * time.Second, Clock: clock},
+		"risk-a:9090")
+	defer balancer.Close()
+	ctx := context.Background()
+
+	if err := balancer.Invoke(ctx, "/risk.Check/Order", nil, nil); err == nil {
+		t.Fatal("Expected first call to fail")
+	}
+	if err := balancer.Invoke(ctx, "/risk.Check/Order", nil, nil); !errors.Is(err, ErrNoHealthyEndpoint) {
+		t.Fatalf("Expected ErrNoHealthyEndpoint while open, got %v", err)
+	}
+
+	endpoints.setDown("risk-a:9090", false)
+	now = now.Add(31 * time.Second)
+	if err := balancer.Invoke(ctx, "/risk.Check/Order", nil, nil); err != nil {
+		t.Fatalf("Expected half-open trial to succeed, got %v", err)
+	}
+	if dials := endpoints.count(endpoints.dials, "risk-a:9090"); dials != 2 {
+		t.Errorf("Expected a reconnect after the failed call, got %d dials", dials)
+	}
+
+	balancer.UpdateEndpoints([]string{"risk-b:9090"})
+	if err := balancer.Invoke(ctx, "/risk.Check/Order", nil, nil); err != nil {
+		t.Fatalf("Expected call to the new endpoint to succeed, got %v", err)
+	}
+	if endpoints.count(endpoints.calls, "risk-b:9090") != 1 || endpoints.count(endpoints.closeds, "risk-a:9090") != 2 {
+		t.Errorf("Expected traffic moved to risk-b and risk-a closed, got %+v", endpoints)
+	}
+}
+
+// TestServiceBalancerIgnoresCallerCancellation verifies calls the caller cancelled do not trip the breaker or drop the connection
+func TestServiceBalancerIgnoresCallerCancellation(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	endpoints := newFakeEndpoints()
+	balancer := NewServiceBalancer(endpoints.dial, BalancerConfig{FailureThreshold: 1, OpenDuration: 30 * time.Second, Clock: clock},
+		"risk-a:9090")
+	defer balancer.Close()
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := balancer.Invoke(cancelled, "/risk.Check/Order", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	expired, cancelExpired := context.WithDeadline(context.Background(), now.Add(-time.Second))
+	defer cancelExpired()
+	if err := balancer.Invoke(expired, "/risk.Check/Order", nil, nil); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Expected context.DeadlineExceeded, got %v", err)
+	}
+	if state := balancer.Endpoints()["risk-a:9090"]; state != CircuitClosed {
+		t.Errorf("Expected the circuit to stay closed, got %s", state)
+	}
+	if dials := endpoints.count(endpoints.dials, "risk-a:9090"); dials != 1 {
+		t.Errorf("Expected the connection kept, got %d dials", dials)
+	}
+
+	// A cancelled half-open trial hands the trial to the next call
+	endpoints.setDown("risk-a:9090", true)
+	balancer.Invoke(context.Background(), "/risk.Check/Order", nil, nil)
+	endpoints.setDown("risk-a:9090", false)
+	now = now.Add(31 * time.Second)
+	if err := balancer.Invoke(cancelled, "/risk.Check/Order", nil, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected the trial to be cancelled, got %v", err)
+	}
+	if err := balancer.Invoke(context.Background(), "/risk.Check/Order", nil, nil); err != nil {
+		t.Errorf("Expected the next call to make the trial, got %v", err)
+	}
+}
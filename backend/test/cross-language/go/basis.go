@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ErrUnknownLocation is returned for a commodity/location pair without a basis
+var ErrUnknownLocation = errors.New("unknown delivery location")
+
+// BasisTable holds location basis differentials: the amount a commodity
+// trades above (positive) or below (negative) the benchmark price when
+// delivered at a location
+type BasisTable struct {
+	mu    sync.RWMutex
+	basis map[string]map[string]float64 // commodity -> location -> differential
+}
+
+// NewBasisTable creates a table from commodity -> location -> differential
+func NewBasisTable(basis map[string]map[string]float64) *BasisTable {
+	t := &BasisTable{basis: make(map[string]map[string]float64, len(basis))}
+	for commodity, locations := range basis {
+		for location, differential := range locations {
+			t.SetBasis(commodity, location, differential)
+		}
+	}
+	return t
+}
+
+// LoadBasisTable loads differentials from a CSV file of
+// commodity,location,differential rows
+func LoadBasisTable(path string) (*BasisTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open basis table: %w", err)
+	}
+	defer f.Close()
+	return ParseBasisCSV(f)
+}
+
+// ParseBasisCSV parses rows of commodity,location,differential. A leading
+// header row is skipped.
+func ParseBasisCSV(r io.Reader) (*BasisTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	t := NewBasisTable(nil)
+	line, rows := 0, 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read basis csv: %w", err)
+		}
+		line++
+
+		differential, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: invalid basis differential %q", line, record[2])
+		}
+		commodity, location := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if commodity == "" || location == "" {
+			return nil, fmt.Errorf("line %d: commodity and location are required", line)
+		}
+		t.SetBasis(commodity, location, differential)
+		rows++
+	}
+	if rows == 0 {
+		return nil, fmt.Errorf("basis table is empty")
+	}
+	return t, nil
+}
+
+// SetBasis sets the differential for delivering commodity at location
+func (t *BasisTable) SetBasis(commodity, location string, differential float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	locations, ok := t.basis[commodity]
+	if !ok {
+		locations = make(map[string]float64)
+		t.basis[commodity] = locations
+	}
+	locations[location] = differential
+}
+
+// LocationAdjustedPrice applies the location's basis to a benchmark price
+func (t *BasisTable) LocationAdjustedPrice(basePrice float64, commodity, location string) (float64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	differential, ok := t.basis[commodity][location]
+	if !ok {
+		return 0, fmt.Errorf("%w: no basis for %s at %s", ErrUnknownLocation, commodity, location)
+	}
+	return basePrice + differential, nil
+}
+
+// PositionValue marks a position at its delivery location: volume times
+// the mark price adjusted for the location's basis
+func (t *BasisTable) PositionValue(position Position, location string) (float64, error) {
+	price, err := t.LocationAdjustedPrice(position.MarkPrice, position.Commodity, location)
+	if err != nil {
+		return 0, err
+	}
+	return position.Volume * price, nil
+}
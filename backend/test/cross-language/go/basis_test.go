@@ -0,0 +1,35 @@
+package integration
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestLocationAdjustedPriceAppliesBasis verifies two delivery points of one commodity price off their own basis
+func TestLocationAdjustedPriceAppliesBasis(t *testing.T) {
+	table, err := LoadBasisTable("testdata/location_basis.csv")
+	if err != nil {
+		t.Fatalf("Failed to load basis table: %v", err)
+	}
+
+	waha, err := table.LocationAdjustedPrice(2.50, "natural_gas", "waha")
+	if err != nil || math.Abs(waha-0.65) > 1e-9 {
+		t.Errorf("Expected Waha at 0.65, got %g (%v)", waha, err)
+	}
+	algonquin, err := table.LocationAdjustedPrice(2.50, "natural_gas", "algonquin")
+	if err != nil || math.Abs(algonquin-4.90) > 1e-9 {
+		t.Errorf("Expected Algonquin at 4.90, got %g (%v)", algonquin, err)
+	}
+
+	long := Position{Commodity: "natural_gas", Volume: 1000, MarkPrice: 2.50}
+	if value, err := table.PositionValue(long, "algonquin"); err != nil || math.Abs(value-4900) > 1e-9 {
+		t.Errorf("Expected the position worth 4900 at Algonquin, got %g (%v)", value, err)
+	}
+
+	for _, pair := range [][2]string{{"natural_gas", "cushing"}, {"power", "waha"}} {
+		if _, err := table.LocationAdjustedPrice(2.50, pair[0], pair[1]); !errors.Is(err, ErrUnknownLocation) {
+			t.Errorf("%s at %s: expected ErrUnknownLocation, got %v", pair[0], pair[1], err)
+		}
+	}
+}
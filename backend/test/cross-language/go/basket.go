@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// weightTolerance is how far basket weights may sum from 1.0
+const weightTolerance = 1e-6
+
+// Basket index errors
+var (
+	ErrInvalidWeights = errors.New("basket weights must sum to 1")
+	ErrMissingPrice   = errors.New("missing price for basket component")
+)
+
+// BasketIndex returns the weighted value of a basket. Weights must sum to
+// 1.0 within tolerance and every weighted commodity must have a price.
+func BasketIndex(weights map[string]float64, prices map[string]float64) (float64, error) {
+	if err := validateBasketWeights(weights); err != nil {
+		return 0, err
+	}
+	names := make([]string, 0, len(weights))
+	for commodity := range weights {
+		names = append(names, commodity)
+	}
+	sort.Strings(names) // fixed summation order keeps results reproducible
+
+	value := 0.0
+	for _, commodity := range names {
+		price, ok := prices[commodity]
+		if !ok {
+			return 0, fmt.Errorf("%w: %s", ErrMissingPrice, commodity)
+		}
+		value += weights[commodity] * price
+	}
+	return value, nil
+}
+
+func validateBasketWeights(weights map[string]float64) error {
+	sum := 0.0
+	for commodity, w := range weights {
+		if w < 0 {
+			return fmt.Errorf("%w: %s has negative weight %g", ErrInvalidWeights, commodity, w)
+		}
+		sum += w
+	}
+	if math.Abs(sum-1) > weightTolerance {
+		return fmt.Errorf("%w: got %g", ErrInvalidWeights, sum)
+	}
+	return nil
+}
+
+// IndexValue is one published basket index value
+type IndexValue struct {
+	Name      string    `json:"name"`
+	Value     float64   `json:"value"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// BasketIndexStream recomputes a basket index as component ticks arrive
+type BasketIndexStream struct {
+	name    string
+	weights map[string]float64
+	prices  map[string]float64
+}
+
+// NewBasketIndexStream validates the weights and creates a stream
+func NewBasketIndexStream(name string, weights map[string]float64) (*BasketIndexStream, error) {
+	if err := validateBasketWeights(weights); err != nil {
+		return nil, err
+	}
+	copied := make(map[string]float64, len(weights))
+	for commodity, w := range weights {
+		copied[commodity] = w
+	}
+	return &BasketIndexStream{name: name, weights: copied, prices: make(map[string]float64)}, nil
+}
+
+// Update applies a tick and returns the new index value once every
+// component has been priced. Ticks for commodities outside the basket are ignored.
+func (s *BasketIndexStream) Update(tick MarketData) (IndexValue, bool) {
+	if _, ok := s.weights[tick.Commodity]; !ok {
+		return IndexValue{}, false
+	}
+	s.prices[tick.Commodity] = tick.Price
+	value, err := BasketIndex(s.weights, s.prices)
+	if err != nil {
+		return IndexValue{}, false
+	}
+	return IndexValue{Name: s.name, Value: value, Timestamp: tick.Timestamp}, true
+}
+
+// Run reads ticks from in and publishes index values to out until in closes
+// or ctx is done. It does not close out.
+func (s *BasketIndexStream) Run(ctx context.Context, in <-chan MarketData, out chan<- IndexValue) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case tick, ok := <-in:
+			if !ok {
+				return nil
+			}
+			value, ok := s.Update(tick)
+			if !ok {
+				continue
+			}
+			select {
+			case out <- value:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
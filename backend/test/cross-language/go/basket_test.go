@@ -0,0 +1,62 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+var crudeBasketWeights = map[string]float64{"brent": 0.5, "wti": 0.3, "dubai": 0.2}
+
+// TestBasketIndexValue verifies the weighted index value
+func TestBasketIndexValue(t *testing.T) {
+	value, err := BasketIndex(crudeBasketWeights, map[string]float64{"brent": 80, "wti": 76, "dubai": 78})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if math.Abs(value-78.4) > 1e-9 {
+		t.Errorf("Expected index 78.4, got %g", value)
+	}
+
+	if _, err := BasketIndex(map[string]float64{"brent": 0.5, "wti": 0.4}, nil); !errors.Is(err, ErrInvalidWeights) {
+		t.Errorf("Expected ErrInvalidWeights, got %v", err)
+	}
+}
+
+// TestBasketIndexMissingPrice verifies an unpriced component is an error
+func TestBasketIndexMissingPrice(t *testing.T) {
+	_, err := BasketIndex(crudeBasketWeights, map[string]float64{"brent": 80, "wti": 76})
+	if !errors.Is(err, ErrMissingPrice) {
+		t.Errorf("Expected ErrMissingPrice, got %v", err)
+	}
+}
+
+// TestBasketIndexStream verifies the index is published once fully priced and on each tick after
+func TestBasketIndexStream(t *testing.T) {
+	stream, err := NewBasketIndexStream("crude_basket", crudeBasketWeights)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	in := make(chan MarketData, 5)
+	out := make(chan IndexValue, 5)
+	for _, tick := range []MarketData{
+		{Commodity: "brent", Price: 80}, {Commodity: "wti", Price: 76}, {Commodity: "natural_gas", Price: 2.5},
+		{Commodity: "dubai", Price: 78}, {Commodity: "brent", Price: 82},
+	} {
+		in <- tick
+	}
+	close(in)
+
+	if err := stream.Run(context.Background(), in, out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	close(out)
+	var values []float64
+	for v := range out {
+		values = append(values, v.Value)
+	}
+	if len(values) != 2 || math.Abs(values[0]-78.4) > 1e-9 || math.Abs(values[1]-79.4) > 1e-9 {
+		t.Errorf("Expected index values [78.4 79.4], got %v", values)
+	}
+}
@@ -0,0 +1,126 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Level change operations
+const (
+	LevelAdd    = "add"
+	LevelUpdate = "update"
+	LevelRemove = "remove"
+)
+
+// ErrSequenceGap means a diff does not follow the snapshot it is applied to
+// and the client should request a full snapshot
+var ErrSequenceGap = errors.New("book diff sequence gap")
+
+// LevelChange is a single price level operation within a diff
+type LevelChange struct {
+	Side   string  `json:"side"`
+	Op     string  `json:"op"`
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume,omitempty"`
+	Orders int     `json:"orders,omitempty"`
+}
+
+// BookDiff transforms the snapshot at FromSeq into the snapshot at ToSeq
+type BookDiff struct {
+	Commodity string        `json:"commodity"`
+	FromSeq   uint64        `json:"from_seq"`
+	ToSeq     uint64        `json:"to_seq"`
+	Changes   []LevelChange `json:"changes"`
+}
+
+// Diff computes the minimal set of level operations turning prev into curr.
+// Unchanged levels produce no operation.
+func Diff(prev, curr BookSnapshot) BookDiff {
+	diff := BookDiff{Commodity: curr.Commodity, FromSeq: prev.Seq, ToSeq: curr.Seq}
+	diff.Changes = append(diff.Changes, diffSide(SideBuy, prev.Bids, curr.Bids)...)
+	diff.Changes = append(diff.Changes, diffSide(SideSell, prev.Asks, curr.Asks)...)
+	return diff
+}
+
+// diffSide compares two levels lists keyed by price
+func diffSide(side string, prev, curr []PriceLevel) []LevelChange {
+	before := make(map[float64]PriceLevel, len(prev))
+	for _, l := range prev {
+		before[l.Price] = l
+	}
+
+	var changes []LevelChange
+	seen := make(map[float64]bool, len(curr))
+	for _, l := range curr {
+		seen[l.Price] = true
+		old, ok := before[l.Price]
+		switch {
+		case !ok:
+			changes = append(changes, LevelChange{Side: side, Op: LevelAdd, Price: l.Price, Volume: l.Volume, Orders: l.Orders})
+		case old.Volume != l.Volume || old.Orders != l.Orders:
+			changes = append(changes, LevelChange{Side: side, Op: LevelUpdate, Price: l.Price, Volume: l.Volume, Orders: l.Orders})
+		}
+	}
+	for _, l := range prev {
+		if !seen[l.Price] {
+			changes = append(changes, LevelChange{Side: side, Op: LevelRemove, Price: l.Price})
+		}
+	}
+	return changes
+}
+
+// Apply reconstructs the next snapshot from a snapshot and a diff. It
+// returns ErrSequenceGap when the diff was not taken from this snapshot.
+func Apply(snapshot BookSnapshot, diff BookDiff) (BookSnapshot, error) {
+	if diff.FromSeq != snapshot.Seq {
+		return snapshot, fmt.Errorf("%w: snapshot at %d, diff from %d", ErrSequenceGap, snapshot.Seq, diff.FromSeq)
+	}
+
+	bids := levelMap(snapshot.Bids)
+	asks := levelMap(snapshot.Asks)
+	for _, c := range diff.Changes {
+		levels := asks
+		if c.Side == SideBuy {
+			levels = bids
+		}
+		switch c.Op {
+		case LevelAdd, LevelUpdate:
+			levels[c.Price] = PriceLevel{Price: c.Price, Volume: c.Volume, Orders: c.Orders}
+		case LevelRemove:
+			delete(levels, c.Price)
+		default:
+			return snapshot, fmt.Errorf("unknown level operation %q", c.Op)
+		}
+	}
+
+	return BookSnapshot{
+		Commodity: snapshot.Commodity,
+		Seq:       diff.ToSeq,
+		Bids:      sortedLevels(bids, true),
+		Asks:      sortedLevels(asks, false),
+	}, nil
+}
+
+func levelMap(levels []PriceLevel) map[float64]PriceLevel {
+	m := make(map[float64]PriceLevel, len(levels))
+	for _, l := range levels {
+		m[l.Price] = l
+	}
+	return m
+}
+
+// sortedLevels orders levels best first: descending for bids, ascending for asks
+func sortedLevels(m map[float64]PriceLevel, descending bool) []PriceLevel {
+	out := make([]PriceLevel, 0, len(m))
+	for _, l := range m {
+		out = append(out, l)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if descending {
+			return out[i].Price > out[j].Price
+		}
+		return out[i].Price < out[j].Price
+	})
+	return out
+}
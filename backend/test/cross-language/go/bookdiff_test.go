@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+// TestBookDiffApplySeries verifies applying successive diffs reproduces each snapshot
+func TestBookDiffApplySeries(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	steps := []func() error{
+		func() error {
+			_, err := book.Add(TradingOrder{OrderID: "b1", Side: SideBuy, Price: 75.40, Volume: 10})
+			return err
+		},
+		func() error {
+			_, err := book.Add(TradingOrder{OrderID: "s1", Side: SideSell, Price: 75.60, Volume: 20})
+			return err
+		},
+		func() error {
+			_, err := book.Add(TradingOrder{OrderID: "b2", Side: SideBuy, Price: 75.40, Volume: 5})
+			return err
+		},
+		func() error {
+			_, err := book.Add(TradingOrder{OrderID: "b3", Side: SideBuy, Price: 75.60, Volume: 8})
+			return err
+		},
+		func() error { return book.Cancel("b1") },
+		func() error { _, err := book.Amend("s1", 75.70, 12); return err },
+	}
+
+	client := book.Snapshot()
+	for i, step := range steps {
+		prev := book.Snapshot()
+		if err := step(); err != nil {
+			t.Fatalf("Step %d failed: %v", i, err)
+		}
+		curr := book.Snapshot()
+
+		diff := Diff(prev, curr)
+		var err error
+		client, err = Apply(client, diff)
+		if err != nil {
+			t.Fatalf("Step %d: apply failed: %v", i, err)
+		}
+		if !reflect.DeepEqual(client, curr) {
+			t.Fatalf("Step %d: expected %+v, got %+v", i, curr, client)
+		}
+	}
+}
+
+// TestBookDiffIsMinimal verifies unchanged levels produce no operations
+func TestBookDiffIsMinimal(t *testing.T) {
+	prev := BookSnapshot{Seq: 1,
+		Bids: []PriceLevel{{Price: 75.4, Volume: 10, Orders: 1}, {Price: 75.3, Volume: 5, Orders: 1}},
+		Asks: []PriceLevel{{Price: 75.6, Volume: 7, Orders: 2}},
+	}
+	curr := BookSnapshot{Seq: 2,
+		Bids: []PriceLevel{{Price: 75.4, Volume: 4, Orders: 1}, {Price: 75.3, Volume: 5, Orders: 1}},
+		Asks: []PriceLevel{{Price: 75.7, Volume: 1, Orders: 1}},
+	}
+
+	diff := Diff(prev, curr)
+	expected := []LevelChange{
+		{Side: SideBuy, Op: LevelUpdate, Price: 75.4, Volume: 4, Orders: 1},
+		{Side: SideSell, Op: LevelAdd, Price: 75.7, Volume: 1, Orders: 1},
+		{Side: SideSell, Op: LevelRemove, Price: 75.6},
+	}
+	if !reflect.DeepEqual(diff.Changes, expected) {
+		t.Errorf("Expected changes %+v, got %+v", expected, diff.Changes)
+	}
+}
+
+// TestBookDiffSequenceGap verifies a missed diff is detected
+func TestBookDiffSequenceGap(t *testing.T) {
+	_, err := Apply(BookSnapshot{Seq: 3}, BookDiff{FromSeq: 4, ToSeq: 5})
+	if !errors.Is(err, ErrSequenceGap) {
+		t.Errorf("Expected ErrSequenceGap, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultEmissionFactors gives combustion tonnes CO2e per native trading
+// unit (EPA emission factors): barrels for crude and heating oil, MCF for
+// natural gas, gallons for gasoline
+var DefaultEmissionFactors = map[string]float64{
+	"crude_oil":   0.43,
+	"heating_oil": 0.428,
+	"natural_gas": 0.0551,
+	"gasoline":    0.008887,
+}
+
+// EmissionTable converts commodity volumes to tonnes of CO2 equivalent
+type EmissionTable struct {
+	mu      sync.RWMutex
+	factors map[string]float64
+}
+
+// NewEmissionTable creates a table from tonnes-CO2e-per-unit factors; nil
+// uses DefaultEmissionFactors
+func NewEmissionTable(factors map[string]float64) *EmissionTable {
+	if factors == nil {
+		factors = DefaultEmissionFactors
+	}
+	copied := make(map[string]float64, len(factors))
+	for commodity, factor := range factors {
+		copied[commodity] = factor
+	}
+	return &EmissionTable{factors: copied}
+}
+
+// LoadEmissionTable loads factors from a CSV file of commodity,factor rows
+func LoadEmissionTable(path string) (*EmissionTable, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open emission table: %w", err)
+	}
+	defer f.Close()
+	return ParseEmissionCSV(f)
+}
+
+// ParseEmissionCSV parses rows of commodity,tonnes_co2e_per_unit. A leading
+// header row is skipped.
+func ParseEmissionCSV(r io.Reader) (*EmissionTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	factors := make(map[string]float64)
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read emission csv: %w", err)
+		}
+		line++
+
+		factor, err := strconv.ParseFloat(strings.TrimSpace(record[1]), 64)
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: invalid emission factor %q", line, record[1])
+		}
+		commodity := strings.TrimSpace(record[0])
+		if commodity == "" || factor < 0 {
+			return nil, fmt.Errorf("line %d: commodity and a non-negative factor are required", line)
+		}
+		factors[commodity] = factor
+	}
+	if len(factors) == 0 {
+		return nil, fmt.Errorf("emission table is empty")
+	}
+	return &EmissionTable{factors: factors}, nil
+}
+
+// SetFactor sets the tonnes CO2e per native unit for a commodity
+func (t *EmissionTable) SetFactor(commodity string, tonnesPerUnit float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.factors[commodity] = tonnesPerUnit
+}
+
+// CarbonEquivalent converts a volume in the commodity's native unit to tonnes CO2e
+func (t *EmissionTable) CarbonEquivalent(volume float64, commodity string) (float64, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	factor, ok := t.factors[commodity]
+	if !ok {
+		return 0, fmt.Errorf("%w: no emission factor for %s", ErrUnknownCommodity, commodity)
+	}
+	return volume * factor, nil
+}
+
+// TradeFootprint returns the tonnes CO2e of the volume a trade transfers
+func (t *EmissionTable) TradeFootprint(trade Trade) (float64, error) {
+	return t.CarbonEquivalent(trade.Volume, trade.Commodity)
+}
@@ -0,0 +1,36 @@
+package integration
+
+import (
+	"errors"
+	"math"
+	"path/filepath"
+	"testing"
+)
+
+// TestCarbonEquivalentCrudeAndGas verifies crude barrels and gas MCF convert to tonnes CO2e
+func TestCarbonEquivalentCrudeAndGas(t *testing.T) {
+	table, err := LoadEmissionTable(filepath.Join("testdata", "emission_factors.csv"))
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+
+	crude, err := table.CarbonEquivalent(1000, "crude_oil")
+	if err != nil || math.Abs(crude-430) > 1e-9 {
+		t.Errorf("Expected 1000 bbl crude = 430 t CO2e, got %g, %v", crude, err)
+	}
+	gas, err := table.TradeFootprint(Trade{Commodity: "natural_gas", Volume: 10000})
+	if err != nil || math.Abs(gas-551) > 1e-9 {
+		t.Errorf("Expected a 10000 MCF gas trade = 551 t CO2e, got %g, %v", gas, err)
+	}
+
+	table.SetFactor("natural_gas", 0.053)
+	if gas, _ := table.CarbonEquivalent(10000, "natural_gas"); math.Abs(gas-530) > 1e-9 {
+		t.Errorf("Expected the configured factor to apply, got %g", gas)
+	}
+	if _, err := table.CarbonEquivalent(10, "gasoline"); !errors.Is(err, ErrUnknownCommodity) {
+		t.Errorf("Expected ErrUnknownCommodity for a commodity missing from the table, got %v", err)
+	}
+	if _, err := NewEmissionTable(nil).CarbonEquivalent(10, "gasoline"); err != nil {
+		t.Errorf("Expected the defaults to cover gasoline, got %v", err)
+	}
+}
@@ -0,0 +1,70 @@
+package integration
+
+import "math"
+
+// PriceBucket is resting volume grouped into a fixed-width price band
+type PriceBucket struct {
+	Side   string  `json:"side"`
+	Low    float64 `json:"low"`  // inclusive
+	High   float64 `json:"high"` // exclusive
+	Volume float64 `json:"volume"`
+	Orders int     `json:"orders"`
+}
+
+// CoalesceLevels groups resting depth into buckets of bucketSize. Bucket
+// edges are multiples of bucketSize, so they stay fixed as the book moves.
+// Bids are returned best first followed by asks best first; includeEmpty
+// fills in empty buckets between the best and worst bucket of each side.
+func CoalesceLevels(book *OrderBook, bucketSize float64, includeEmpty bool) []PriceBucket {
+	if bucketSize <= 0 {
+		return nil
+	}
+	snap := book.Snapshot()
+	buckets := coalesceSide(SideBuy, snap.Bids, bucketSize, includeEmpty)
+	return append(buckets, coalesceSide(SideSell, snap.Asks, bucketSize, includeEmpty)...)
+}
+
+// coalesceSide buckets levels that are already ordered best first
+func coalesceSide(side string, levels []PriceLevel, bucketSize float64, includeEmpty bool) []PriceBucket {
+	var out []PriceBucket
+	step := int64(1)
+	if side == SideBuy {
+		step = -1
+	}
+
+	for _, level := range levels {
+		idx := bucketIndex(level.Price, bucketSize)
+		if n := len(out); n > 0 {
+			last := bucketIndex(out[n-1].Low, bucketSize)
+			if idx == last {
+				out[n-1].Volume += level.Volume
+				out[n-1].Orders += level.Orders
+				continue
+			}
+			if includeEmpty {
+				for gap := last + step; gap != idx; gap += step {
+					out = append(out, newPriceBucket(side, gap, bucketSize))
+				}
+			}
+		}
+		bucket := newPriceBucket(side, idx, bucketSize)
+		bucket.Volume = level.Volume
+		bucket.Orders = level.Orders
+		out = append(out, bucket)
+	}
+	return out
+}
+
+// bucketIndex returns the bucket number containing price. A small epsilon
+// keeps prices that sit exactly on an edge from falling into the bucket below.
+func bucketIndex(price, bucketSize float64) int64 {
+	return int64(math.Floor(price/bucketSize + 1e-9))
+}
+
+func newPriceBucket(side string, idx int64, bucketSize float64) PriceBucket {
+	return PriceBucket{
+		Side: side,
+		Low:  float64(idx) * bucketSize,
+		High: float64(idx+1) * bucketSize,
+	}
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"math"
+	"testing"
+)
+
+// TestCoalesceLevels verifies resting volume is summed into stable price buckets
+func TestCoalesceLevels(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	orders := []TradingOrder{
+		{OrderID: "b1", Side: SideBuy, Price: 75.40, Volume: 10},
+		{OrderID: "b2", Side: SideBuy, Price: 75.10, Volume: 5},
+		{OrderID: "b3", Side: SideBuy, Price: 74.30, Volume: 7},
+		{OrderID: "s1", Side: SideSell, Price: 75.50, Volume: 3},
+		{OrderID: "s2", Side: SideSell, Price: 75.90, Volume: 4},
+		{OrderID: "s3", Side: SideSell, Price: 76.00, Volume: 6},
+	}
+	for _, o := range orders {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Failed to add %s: %v", o.OrderID, err)
+		}
+	}
+
+	sparse := CoalesceLevels(book, 0.5, false)
+	expected := []PriceBucket{
+		{Side: SideBuy, Low: 75.0, High: 75.5, Volume: 15, Orders: 2},
+		{Side: SideBuy, Low: 74.0, High: 74.5, Volume: 7, Orders: 1},
+		{Side: SideSell, Low: 75.5, High: 76.0, Volume: 7, Orders: 2},
+		{Side: SideSell, Low: 76.0, High: 76.5, Volume: 6, Orders: 1},
+	}
+	assertBuckets(t, expected, sparse)
+
+	dense := CoalesceLevels(book, 0.5, true)
+	if len(dense) != 5 {
+		t.Fatalf("Expected 5 buckets with empties, got %d", len(dense))
+	}
+	if gap := dense[1]; gap.Low != 74.5 || gap.Volume != 0 {
+		t.Errorf("Expected empty bid bucket at 74.5, got %+v", gap)
+	}
+
+	// Bucket edges do not move as the book changes.
+	book.Cancel("b3")
+	book.Add(TradingOrder{OrderID: "b4", Side: SideBuy, Price: 75.49, Volume: 1})
+	after := CoalesceLevels(book, 0.5, false)
+	if after[0].Low != 75.0 || after[0].Volume != 16 {
+		t.Errorf("Expected stable bucket [75.0, 75.5) with 16, got %+v", after[0])
+	}
+}
+
+func assertBuckets(t *testing.T, expected, got []PriceBucket) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("Expected %d buckets, got %d: %+v", len(expected), len(got), got)
+	}
+	for i := range expected {
+		e, g := expected[i], got[i]
+		if e.Side != g.Side || math.Abs(e.Low-g.Low) > 1e-9 || math.Abs(e.High-g.High) > 1e-9 ||
+			e.Volume != g.Volume || e.Orders != g.Orders {
+			t.Errorf("Bucket %d: expected %+v, got %+v", i, e, g)
+		}
+	}
+}
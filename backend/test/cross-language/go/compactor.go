@@ -0,0 +1,37 @@
+package integration
+
+import "time"
+
+// Compactor collapses runs of consecutive ticks at an unchanged price into a
+// single tick carrying the run's last timestamp and total volume. Every
+// price change starts a new tick, so price history is preserved exactly.
+type Compactor struct {
+	// Window caps how long a run may span; zero means unbounded
+	Window time.Duration
+}
+
+// Compact returns the compacted ticks. A run breaks when the price,
+// commodity or exchange changes, or when the next tick falls outside the
+// window measured from the run's first tick.
+func (c Compactor) Compact(ticks []MarketData) []MarketData {
+	compacted := make([]MarketData, 0, len(ticks))
+	var runStart time.Time
+	for _, tick := range ticks {
+		if n := len(compacted); n > 0 && c.extends(compacted[n-1], runStart, tick) {
+			last := &compacted[n-1]
+			last.Volume += tick.Volume
+			last.Timestamp = tick.Timestamp
+			continue
+		}
+		compacted = append(compacted, tick)
+		runStart = tick.Timestamp
+	}
+	return compacted
+}
+
+func (c Compactor) extends(run MarketData, runStart time.Time, tick MarketData) bool {
+	if tick.Price != run.Price || tick.Commodity != run.Commodity || tick.Exchange != run.Exchange {
+		return false
+	}
+	return c.Window <= 0 || tick.Timestamp.Sub(runStart) <= c.Window
+}
@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// TestCompactorCollapsesFlatRuns verifies flat ticks merge while price changes are kept
+func TestCompactorCollapsesFlatRuns(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	tick := func(sec int, price float64, volume int64) MarketData {
+		return MarketData{Commodity: "crude_oil", Price: price, Volume: volume, Exchange: "NYMEX", Timestamp: start.Add(time.Duration(sec) * time.Second)}
+	}
+	ticks := []MarketData{
+		tick(0, 75.50, 10), tick(1, 75.50, 20), tick(2, 75.50, 5), tick(3, 75.50, 15),
+		tick(4, 75.52, 30),
+		tick(5, 75.50, 10),
+	}
+
+	compacted := Compactor{}.Compact(ticks)
+	if len(compacted) != 3 {
+		t.Fatalf("Expected 3 ticks after compaction, got %+v", compacted)
+	}
+	if compacted[0].Volume != 50 || !compacted[0].Timestamp.Equal(start.Add(3*time.Second)) {
+		t.Errorf("Expected flat run to sum to 50 ending at +3s, got %+v", compacted[0])
+	}
+	prices := []float64{compacted[0].Price, compacted[1].Price, compacted[2].Price}
+	if prices[0] != 75.50 || prices[1] != 75.52 || prices[2] != 75.50 {
+		t.Errorf("Expected price path 75.50 -> 75.52 -> 75.50, got %v", prices)
+	}
+
+	var total int64
+	for _, c := range compacted {
+		total += c.Volume
+	}
+	if total != 90 {
+		t.Errorf("Expected volume total 90 preserved, got %d", total)
+	}
+
+	windowed := Compactor{Window: 2 * time.Second}.Compact(ticks)
+	if len(windowed) != 4 || windowed[0].Volume != 35 || windowed[1].Volume != 15 {
+		t.Errorf("Expected the window to split the flat run into 35 and 15, got %+v", windowed)
+	}
+}
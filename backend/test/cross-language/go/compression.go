@@ -0,0 +1,200 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// CompressedTrade replaces a group of trades between one pair of
+// counterparties in one commodity. Net volume moves from seller to buyer
+// at a price that preserves the group's net cash. When the group nets to
+// zero volume the Trade has none and Cash carries what is left, paid by
+// BuyClientID to SellClientID.
+type CompressedTrade struct {
+	Trade    Trade    `json:"trade"`
+	Cash     float64  `json:"cash,omitempty"`
+	Replaces []string `json:"replaces"`
+}
+
+// TradeCompressor nets trades for reporting
+type TradeCompressor struct{}
+
+// pairKey identifies a compression group with counterparties in sorted order
+type pairKey struct {
+	commodity string
+	a, b      string
+}
+
+// pairNet accumulates a group from party a's side: volume bought from b
+// and cash paid to b
+type pairNet struct {
+	volume, cash float64
+	aFees, bFees float64
+	ids          []string
+	latest       time.Time
+}
+
+// pairOf returns a trade's group, the sign that orients it to party a and
+// the fees of parties a and b
+func pairOf(t Trade) (key pairKey, sign, aFee, bFee float64) {
+	key = pairKey{commodity: t.Commodity, a: t.BuyClientID, b: t.SellClientID}
+	if key.b < key.a {
+		key.a, key.b = key.b, key.a
+		return key, -1, t.SellFee, t.BuyFee
+	}
+	return key, 1, t.BuyFee, t.SellFee
+}
+
+// Compress nets trades per commodity and counterparty pair. Groups of a
+// single trade are passed through unchanged. Trades without both client
+// IDs cannot be netted and are also passed through.
+func (TradeCompressor) Compress(trades []Trade) []CompressedTrade {
+	groups := make(map[pairKey]*pairNet)
+	var keys []pairKey
+	var out []CompressedTrade
+	originals := make(map[pairKey]Trade)
+	for _, t := range trades {
+		if t.BuyClientID == "" || t.SellClientID == "" {
+			out = append(out, CompressedTrade{Trade: t, Replaces: []string{t.TradeID}})
+			continue
+		}
+		key, sign, aFee, bFee := pairOf(t)
+		g, ok := groups[key]
+		if !ok {
+			g = &pairNet{}
+			groups[key] = g
+			keys = append(keys, key)
+			originals[key] = t
+		}
+		g.volume += sign * t.Volume
+		g.cash += sign * t.Volume * t.Price
+		g.aFees += aFee
+		g.bFees += bFee
+		g.ids = append(g.ids, t.TradeID)
+		if t.Timestamp.After(g.latest) {
+			g.latest = t.Timestamp
+		}
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].commodity != keys[j].commodity {
+			return keys[i].commodity < keys[j].commodity
+		}
+		if keys[i].a != keys[j].a {
+			return keys[i].a < keys[j].a
+		}
+		return keys[i].b < keys[j].b
+	})
+	for _, key := range keys {
+		g := groups[key]
+		if len(g.ids) == 1 {
+			out = append(out, CompressedTrade{Trade: originals[key], Replaces: g.ids})
+			continue
+		}
+		out = append(out, g.compressed(key))
+	}
+	return out
+}
+
+func (g *pairNet) compressed(key pairKey) CompressedTrade {
+	buyer, seller := key.a, key.b
+	volume, cash, buyFee, sellFee := g.volume, g.cash, g.aFees, g.bFees
+	if volume < 0 || (math.Abs(volume) <= volumeEpsilon && cash < 0) {
+		buyer, seller = seller, buyer
+		volume, cash, buyFee, sellFee = -volume, -cash, sellFee, buyFee
+	}
+	ct := CompressedTrade{
+		Trade: Trade{
+			TradeID:      fmt.Sprintf("cmp-%s-%s-%s", key.commodity, key.a, key.b),
+			Commodity:    key.commodity,
+			BuyClientID:  buyer,
+			SellClientID: seller,
+			BuyFee:       buyFee,
+			SellFee:      sellFee,
+			Timestamp:    g.latest,
+		},
+		Replaces: g.ids,
+	}
+	if volume <= volumeEpsilon {
+		ct.Cash = cash
+		return ct
+	}
+	ct.Trade.Volume = volume
+	ct.Trade.Price = cash / volume
+	return ct
+}
+
+// CompressionBreak is a group whose compressed form does not reconcile
+type CompressionBreak struct {
+	Commodity      string  `json:"commodity"`
+	ClientA        string  `json:"client_a"`
+	ClientB        string  `json:"client_b"`
+	VolumeExpected float64 `json:"volume_expected"`
+	VolumeActual   float64 `json:"volume_actual"`
+	CashExpected   float64 `json:"cash_expected"`
+	CashActual     float64 `json:"cash_actual"`
+}
+
+// Reconcile compares the net volume, cash and fees of each counterparty
+// pair before and after compression and returns the groups that differ
+func (TradeCompressor) Reconcile(original []Trade, compressed []CompressedTrade) []CompressionBreak {
+	expected := netByPair(original, nil)
+	actual := netByPair(nil, compressed)
+
+	keys := make(map[pairKey]bool)
+	for k := range expected {
+		keys[k] = true
+	}
+	for k := range actual {
+		keys[k] = true
+	}
+	var breaks []CompressionBreak
+	for k := range keys {
+		e, a := expected[k], actual[k]
+		if e == nil {
+			e = &pairNet{}
+		}
+		if a == nil {
+			a = &pairNet{}
+		}
+		tolerance := volumeEpsilon * math.Max(1, math.Abs(e.cash))
+		if math.Abs(e.volume-a.volume) > volumeEpsilon || math.Abs(e.cash-a.cash) > tolerance ||
+			math.Abs(e.aFees-a.aFees) > volumeEpsilon || math.Abs(e.bFees-a.bFees) > volumeEpsilon {
+			breaks = append(breaks, CompressionBreak{
+				Commodity: k.commodity, ClientA: k.a, ClientB: k.b,
+				VolumeExpected: e.volume, VolumeActual: a.volume,
+				CashExpected: e.cash, CashActual: a.cash,
+			})
+		}
+	}
+	sort.Slice(breaks, func(i, j int) bool {
+		return breaks[i].Commodity+breaks[i].ClientA+breaks[i].ClientB < breaks[j].Commodity+breaks[j].ClientA+breaks[j].ClientB
+	})
+	return breaks
+}
+
+// netByPair sums trades and compressed lines per counterparty pair
+func netByPair(trades []Trade, compressed []CompressedTrade) map[pairKey]*pairNet {
+	nets := make(map[pairKey]*pairNet)
+	add := func(t Trade, extraCash float64) {
+		key, sign, aFee, bFee := pairOf(t)
+		n, ok := nets[key]
+		if !ok {
+			n = &pairNet{}
+			nets[key] = n
+		}
+		n.volume += sign * t.Volume
+		n.cash += sign * (t.Volume*t.Price + extraCash)
+		n.aFees += aFee
+		n.bFees += bFee
+	}
+	for _, t := range trades {
+		add(t, 0)
+	}
+	for _, c := range compressed {
+		add(c.Trade, c.Cash)
+	}
+	return nets
+}
@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTradeCompressorNetsOffsettingTrades verifies offsetting trades compress to fewer lines that reconcile
+func TestTradeCompressorNetsOffsettingTrades(t *testing.T) {
+	at := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	trades := []Trade{
+		{TradeID: "t1", Commodity: "crude_oil", Price: 75.00, Volume: 100, BuyClientID: "acme", SellClientID: "gulf", BuyFee: 1, SellFee: 1, Timestamp: at},
+		{TradeID: "t2", Commodity: "crude_oil", Price: 75.50, Volume: 60, BuyClientID: "gulf", SellClientID: "acme", BuyFee: 0.6, SellFee: 0.6, Timestamp: at.Add(time.Minute)},
+		{TradeID: "t3", Commodity: "crude_oil", Price: 75.20, Volume: 10, BuyClientID: "acme", SellClientID: "gulf", Timestamp: at.Add(2 * time.Minute)},
+		// Fully offsetting volume leaves only cash
+		{TradeID: "t4", Commodity: "natural_gas", Price: 2.50, Volume: 1000, BuyClientID: "acme", SellClientID: "delta", Timestamp: at},
+		{TradeID: "t5", Commodity: "natural_gas", Price: 2.60, Volume: 1000, BuyClientID: "delta", SellClientID: "acme", Timestamp: at},
+		{TradeID: "t6", Commodity: "crude_oil", Price: 76.00, Volume: 5, BuyClientID: "delta", SellClientID: "gulf", Timestamp: at},
+	}
+	var compressor TradeCompressor
+	compressed := compressor.Compress(trades)
+	if len(compressed) != 3 {
+		t.Fatalf("Expected 3 lines from 6 trades, got %+v", compressed)
+	}
+
+	crude := compressed[0].Trade
+	if crude.BuyClientID != "acme" || crude.SellClientID != "gulf" || crude.Volume != 50 {
+		t.Errorf("Expected acme net long 50 from gulf, got %+v", crude)
+	}
+	// Cash: 100*75 - 60*75.5 + 10*75.2 = 3722, so 50 at 74.44
+	if diff := crude.Price - 74.44; diff > 1e-9 || diff < -1e-9 {
+		t.Errorf("Expected cash-preserving price 74.44, got %g", crude.Price)
+	}
+	if crude.BuyFee != 1.6 || crude.SellFee != 1.6 || !crude.Timestamp.Equal(at.Add(2*time.Minute)) {
+		t.Errorf("Expected summed fees and the latest timestamp, got %+v", crude)
+	}
+	if len(compressed[0].Replaces) != 3 {
+		t.Errorf("Expected the crude line to replace 3 trades, got %v", compressed[0].Replaces)
+	}
+	if compressed[1].Trade.Commodity != "crude_oil" || compressed[1].Trade.TradeID != "t6" {
+		t.Errorf("Expected the single delta/gulf trade passed through, got %+v", compressed[1])
+	}
+	gas := compressed[2]
+	if gas.Trade.Volume != 0 || gas.Trade.BuyClientID != "delta" || gas.Trade.SellClientID != "acme" || gas.Cash < 99.99 || gas.Cash > 100.01 {
+		t.Errorf("Expected a zero-volume line with delta paying acme 100, got %+v", gas)
+	}
+
+	if breaks := compressor.Reconcile(trades, compressed); len(breaks) != 0 {
+		t.Errorf("Expected the compressed set to reconcile, got %+v", breaks)
+	}
+	compressed[0].Trade.Volume = 49
+	if breaks := compressor.Reconcile(trades, compressed); len(breaks) != 1 {
+		t.Errorf("Expected a tampered line to break reconciliation, got %+v", breaks)
+	}
+}
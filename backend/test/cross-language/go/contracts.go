@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Order validation errors
+var (
+	ErrBelowMinNotional = errors.New("order notional below minimum")
+	ErrInvalidTick      = errors.New("price is not a multiple of the tick size")
+	ErrInvalidLot       = errors.New("volume is not a multiple of the lot size")
+)
+
+// ContractSpec holds the trading parameters of a commodity contract
+type ContractSpec struct {
+	Commodity   string  `json:"commodity"`
+	TickSize    float64 `json:"tick_size"`
+	LotSize     float64 `json:"lot_size"`
+	MinNotional float64 `json:"min_notional"` // zero means no minimum
+	// AllowNegativePrices admits zero and negative limit prices, as crude
+	// futures have traded; spot commodities leave it off
+	AllowNegativePrices bool `json:"allow_negative_prices"`
+}
+
+// ContractSpecs is a concurrency-safe registry of contract specs
+type ContractSpecs struct {
+	mu    sync.RWMutex
+	specs map[string]ContractSpec
+}
+
+// NewContractSpecs creates a registry from the given specs
+func NewContractSpecs(specs ...ContractSpec) *ContractSpecs {
+	r := &ContractSpecs{specs: make(map[string]ContractSpec, len(specs))}
+	for _, spec := range specs {
+		r.specs[spec.Commodity] = spec
+	}
+	return r
+}
+
+// Get returns the spec for a commodity
+func (r *ContractSpecs) Get(commodity string) (ContractSpec, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	spec, ok := r.specs[commodity]
+	return spec, ok
+}
+
+// Set adds or replaces a commodity's spec
+func (r *ContractSpecs) Set(spec ContractSpec) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.specs[spec.Commodity] = spec
+}
+
+// UpdateTickSize changes a commodity's tick size and applies it to the
+// books trading it, returning any orders the books cancelled. Readers of
+// the spec are held off until every book has switched, so no order is
+// validated against the new tick while a book still accepts the old one.
+func (r *ContractSpecs) UpdateTickSize(commodity string, tick float64, books ...*OrderBook) ([]TradingOrder, error) {
+	if tick < 0 {
+		return nil, fmt.Errorf("%w: tick size %g", ErrInvalidTick, tick)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	spec, ok := r.specs[commodity]
+	if !ok {
+		spec = ContractSpec{Commodity: commodity}
+	}
+	spec.TickSize = tick
+	r.specs[commodity] = spec
+
+	var cancelled []TradingOrder
+	for _, book := range books {
+		if book.Commodity() != commodity {
+			continue
+		}
+		removed, err := book.SetTickSize(tick)
+		if err != nil {
+			return cancelled, fmt.Errorf("book %s: %w", commodity, err)
+		}
+		cancelled = append(cancelled, removed...)
+	}
+	return cancelled, nil
+}
+
+// ValidationRule checks one aspect of an order
+type ValidationRule func(order TradingOrder) error
+
+// OrderValidator runs a chain of validation rules, stopping at the first failure
+type OrderValidator struct {
+	rules []ValidationRule
+}
+
+// NewOrderValidator creates a validator from rules applied in order
+func NewOrderValidator(rules ...ValidationRule) *OrderValidator {
+	return &OrderValidator{rules: rules}
+}
+
+// Use appends rules to the chain
+func (v *OrderValidator) Use(rules ...ValidationRule) {
+	v.rules = append(v.rules, rules...)
+}
+
+// Validate applies every rule and returns the first error
+func (v *OrderValidator) Validate(order TradingOrder) error {
+	for _, rule := range v.rules {
+		if err := rule(order); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BasicOrderRule checks identity, side, type, and positive quantities.
+// Limit prices must be positive unless the commodity's spec allows negative
+// prices; with nil specs every commodity requires positive prices.
+func BasicOrderRule(specs *ContractSpecs) ValidationRule {
+	return func(order TradingOrder) error {
+		switch {
+		case order.OrderID == "":
+			return fmt.Errorf("%w: missing order id", ErrInvalidOrder)
+		case order.Commodity == "":
+			return fmt.Errorf("%w: missing commodity", ErrInvalidOrder)
+		case order.Side != SideBuy && order.Side != SideSell:
+			return fmt.Errorf("%w: unknown side %q", ErrInvalidOrder, order.Side)
+		case order.Volume <= 0:
+			return fmt.Errorf("%w: volume must be positive", ErrInvalidOrder)
+		case order.Type != OrderTypeMarket && order.Price <= 0 && !allowsNegativePrices(specs, order.Commodity):
+			return fmt.Errorf("%w: limit price must be positive for %s", ErrInvalidOrder, order.Commodity)
+		}
+		return nil
+	}
+}
+
+// allowsNegativePrices reports whether commodity's spec admits prices at or below zero
+func allowsNegativePrices(specs *ContractSpecs, commodity string) bool {
+	if specs == nil {
+		return false
+	}
+	spec, ok := specs.Get(commodity)
+	return ok && spec.AllowNegativePrices
+}
+
+// TickSizeRule rejects limit prices off the commodity's tick grid
+func TickSizeRule(specs *ContractSpecs) ValidationRule {
+	return func(order TradingOrder) error {
+		spec, ok := specs.Get(order.Commodity)
+		if !ok || spec.TickSize <= 0 || order.Type == OrderTypeMarket {
+			return nil
+		}
+		if !isMultiple(order.Price, spec.TickSize) {
+			return fmt.Errorf("%w: %g with tick %g", ErrInvalidTick, order.Price, spec.TickSize)
+		}
+		return nil
+	}
+}
+
+// LotSizeRule rejects volumes that are not whole lots
+func LotSizeRule(specs *ContractSpecs) ValidationRule {
+	return func(order TradingOrder) error {
+		spec, ok := specs.Get(order.Commodity)
+		if !ok || spec.LotSize <= 0 {
+			return nil
+		}
+		if !isMultiple(order.Volume, spec.LotSize) {
+			return fmt.Errorf("%w: %g with lot %g", ErrInvalidLot, order.Volume, spec.LotSize)
+		}
+		return nil
+	}
+}
+
+// MinNotionalRule rejects limit orders whose |volume*price| is below the
+// commodity's minimum, so negative-priced orders are sized like any other.
+// Commodities without a minimum, and market orders which carry no price,
+// pass.
+func MinNotionalRule(specs *ContractSpecs) ValidationRule {
+	return func(order TradingOrder) error {
+		spec, ok := specs.Get(order.Commodity)
+		if !ok || spec.MinNotional <= 0 || order.Type == OrderTypeMarket {
+			return nil
+		}
+		if notional := math.Abs(order.Volume * order.Price); notional < spec.MinNotional {
+			return fmt.Errorf("%w: %g < %g for %s", ErrBelowMinNotional, notional, spec.MinNotional, order.Commodity)
+		}
+		return nil
+	}
+}
+
+// isMultiple reports whether value is an integer multiple of step within
+// floating point tolerance
+func isMultiple(value, step float64) bool {
+	ratio := value / step
+	return math.Abs(ratio-math.Round(ratio)) < 1e-6
+}
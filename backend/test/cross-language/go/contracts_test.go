@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestMinNotionalRule verifies orders just below the minimum are rejected and just above pass
+func TestMinNotionalRule(t *testing.T) {
+	specs := NewContractSpecs(
+		ContractSpec{Commodity: "crude_oil", TickSize: 0.01, LotSize: 1, MinNotional: 1000},
+		ContractSpec{Commodity: "natural_gas", TickSize: 0.001, LotSize: 10},
+	)
+	validator := NewOrderValidator(BasicOrderRule(specs), TickSizeRule(specs), LotSizeRule(specs), MinNotionalRule(specs))
+
+	testCases := []struct {
+		name    string
+		order   TradingOrder
+		wantErr error
+	}{
+		{"just below minimum", TradingOrder{OrderID: "o1", Commodity: "crude_oil", Side: SideBuy, Volume: 13, Price: 76.92}, ErrBelowMinNotional},
+		{"just above minimum", TradingOrder{OrderID: "o2", Commodity: "crude_oil", Side: SideBuy, Volume: 13, Price: 76.93}, nil},
+		{"no minimum configured", TradingOrder{OrderID: "o3", Commodity: "natural_gas", Side: SideSell, Volume: 10, Price: 3.25}, nil},
+		{"unknown commodity", TradingOrder{OrderID: "o4", Commodity: "power", Side: SideSell, Volume: 1, Price: 1}, nil},
+		{"earlier rule wins", TradingOrder{OrderID: "o5", Commodity: "crude_oil", Side: SideBuy, Volume: 1, Price: 75.005}, ErrInvalidTick},
+		{"lot size", TradingOrder{OrderID: "o6", Commodity: "natural_gas", Side: SideBuy, Volume: 15, Price: 3.25}, ErrInvalidLot},
+		{"basic rule", TradingOrder{Commodity: "crude_oil", Side: SideBuy, Volume: 100, Price: 75}, ErrInvalidOrder},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := validator.Validate(tc.order)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expected %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestBasicOrderRuleNegativePrices verifies negative prices pass only for commodities whose spec allows them
+func TestBasicOrderRuleNegativePrices(t *testing.T) {
+	specs := NewContractSpecs(
+		ContractSpec{Commodity: "crude_oil_futures", TickSize: 0.01, AllowNegativePrices: true},
+		ContractSpec{Commodity: "natural_gas_spot", TickSize: 0.001},
+	)
+	validator := NewOrderValidator(BasicOrderRule(specs), TickSizeRule(specs))
+
+	if err := validator.Validate(TradingOrder{OrderID: "f1", Commodity: "crude_oil_futures", Side: SideBuy, Volume: 10, Price: -37.63}); err != nil {
+		t.Errorf("Expected a negative crude futures price to pass, got %v", err)
+	}
+	if err := validator.Validate(TradingOrder{OrderID: "s1", Commodity: "natural_gas_spot", Side: SideSell, Volume: 10, Price: -0.25}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected a negative spot gas price to be rejected, got %v", err)
+	}
+	if err := BasicOrderRule(nil)(TradingOrder{OrderID: "f2", Commodity: "crude_oil_futures", Side: SideBuy, Volume: 10, Price: -1}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected negative prices rejected without specs, got %v", err)
+	}
+}
+
+// TestMinNotionalRuleNegativePrices verifies negative-priced orders are held to the minimum by absolute notional
+func TestMinNotionalRuleNegativePrices(t *testing.T) {
+	specs := NewContractSpecs(ContractSpec{Commodity: "crude_oil_futures", TickSize: 0.01, LotSize: 1, MinNotional: 1000, AllowNegativePrices: true})
+	validator := NewOrderValidator(BasicOrderRule(specs), MinNotionalRule(specs))
+
+	if err := validator.Validate(TradingOrder{OrderID: "n1", Commodity: "crude_oil_futures", Side: SideBuy, Volume: 1, Price: -37.63}); !errors.Is(err, ErrBelowMinNotional) {
+		t.Errorf("Expected a small negative-priced order to be rejected, got %v", err)
+	}
+	if err := validator.Validate(TradingOrder{OrderID: "n2", Commodity: "crude_oil_futures", Side: SideBuy, Volume: 30, Price: -37.63}); err != nil {
+		t.Errorf("Expected a negative-priced order above the minimum to pass, got %v", err)
+	}
+}
+
+// TestTickSizeChangeMidSession verifies both policies for resting orders left off a coarser tick grid
+func TestTickSizeChangeMidSession(t *testing.T) {
+	for _, policy := range []string{TickChangeGrandfather, TickChangeCancel} {
+		t.Run(policy, func(t *testing.T) {
+			specs := NewContractSpecs(ContractSpec{Commodity: "crude_oil", TickSize: 0.01})
+			log := NewMemoryEventLog()
+			book := NewOrderBook("crude_oil", WithTickSize(0.01, policy), WithEventLog(log))
+			for _, o := range []TradingOrder{
+				{OrderID: "on_grid", Side: SideBuy, Price: 75.40, Volume: 10},
+				{OrderID: "off_grid", Side: SideBuy, Price: 75.43, Volume: 10},
+				{OrderID: "ask", Side: SideSell, Price: 75.55, Volume: 10},
+			} {
+				if _, err := book.Add(o); err != nil {
+					t.Fatalf("Failed to seed book: %v", err)
+				}
+			}
+
+			cancelled, err := specs.UpdateTickSize("crude_oil", 0.05, book)
+			if err != nil {
+				t.Fatalf("UpdateTickSize failed: %v", err)
+			}
+			if spec, _ := specs.Get("crude_oil"); spec.TickSize != 0.05 || book.TickSize() != 0.05 {
+				t.Errorf("Expected spec and book at 0.05, got %g and %g", spec.TickSize, book.TickSize())
+			}
+			_, resting := book.Order("off_grid")
+			switch policy {
+			case TickChangeCancel:
+				if len(cancelled) != 1 || cancelled[0].OrderID != "off_grid" || resting {
+					t.Errorf("Expected off_grid cancelled, got %+v (resting %v)", cancelled, resting)
+				}
+			default:
+				if len(cancelled) != 0 || !resting {
+					t.Errorf("Expected off_grid grandfathered, got %+v (resting %v)", cancelled, resting)
+				}
+				if price, _, _ := book.BestBid(); price != 75.43 {
+					t.Errorf("Expected the grandfathered bid to stay best, got %g", price)
+				}
+				if _, err := book.Amend("off_grid", 75.43, 5); err != nil {
+					t.Errorf("Expected a grandfathered order to keep amending volume, got %v", err)
+				}
+				if _, err := book.Amend("off_grid", 75.44, 5); !errors.Is(err, ErrInvalidTick) {
+					t.Errorf("Expected a reprice off the new grid to fail, got %v", err)
+				}
+			}
+
+			if _, err := book.Add(TradingOrder{OrderID: "late", Side: SideSell, Price: 75.57, Volume: 1}); !errors.Is(err, ErrInvalidTick) {
+				t.Errorf("Expected a new off-grid order to be rejected, got %v", err)
+			}
+			if _, err := book.Add(TradingOrder{OrderID: "late", Side: SideSell, Price: 75.60, Volume: 1}); err != nil {
+				t.Errorf("Expected an on-grid order to be accepted, got %v", err)
+			}
+			if _, err := Rebuild(log); err != nil {
+				t.Errorf("Expected the log to replay across the change, got %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,165 @@
+package integration
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// correlationTolerance is the allowed deviation for symmetry and diagonal checks
+const correlationTolerance = 1e-9
+
+// CorrelationMatrix holds pairwise correlations between commodities
+type CorrelationMatrix struct {
+	values map[string]map[string]float64
+}
+
+// LoadCorrelationMatrix loads a correlation matrix from a .csv or .json file
+func LoadCorrelationMatrix(path string) (*CorrelationMatrix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open correlation matrix: %w", err)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCorrelationCSV(f)
+	case ".json":
+		return ParseCorrelationJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported correlation matrix format %q", filepath.Ext(path))
+	}
+}
+
+// ParseCorrelationCSV parses rows of commodity_a,commodity_b,correlation.
+// A leading header row is skipped.
+func ParseCorrelationCSV(r io.Reader) (*CorrelationMatrix, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 3
+	reader.TrimLeadingSpace = true
+
+	values := make(map[string]map[string]float64)
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read correlation csv: %w", err)
+		}
+		line++
+
+		corr, err := strconv.ParseFloat(strings.TrimSpace(record[2]), 64)
+		if err != nil {
+			if line == 1 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: invalid correlation %q", line, record[2])
+		}
+		a, b := strings.TrimSpace(record[0]), strings.TrimSpace(record[1])
+		if a == "" || b == "" {
+			return nil, fmt.Errorf("line %d: commodity names must not be empty", line)
+		}
+		if _, dup := values[a][b]; dup {
+			return nil, fmt.Errorf("line %d: duplicate pair %s/%s", line, a, b)
+		}
+		if values[a] == nil {
+			values[a] = make(map[string]float64)
+		}
+		values[a][b] = corr
+	}
+
+	return newCorrelationMatrix(values)
+}
+
+// ParseCorrelationJSON parses a nested object of the form {"a": {"b": 0.5}}
+func ParseCorrelationJSON(r io.Reader) (*CorrelationMatrix, error) {
+	var values map[string]map[string]float64
+	if err := json.NewDecoder(r).Decode(&values); err != nil {
+		return nil, fmt.Errorf("decode correlation json: %w", err)
+	}
+	return newCorrelationMatrix(values)
+}
+
+// newCorrelationMatrix validates the raw values and builds the matrix
+func newCorrelationMatrix(values map[string]map[string]float64) (*CorrelationMatrix, error) {
+	if len(values) == 0 {
+		return nil, fmt.Errorf("correlation matrix is empty")
+	}
+
+	commodities := make(map[string]struct{})
+	for a, row := range values {
+		commodities[a] = struct{}{}
+		for b := range row {
+			commodities[b] = struct{}{}
+		}
+	}
+	names := make([]string, 0, len(commodities))
+	for name := range commodities {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var missing []string
+	for i, a := range names {
+		for _, b := range names[i:] {
+			ab, okAB := values[a][b]
+			ba, okBA := values[b][a]
+			if !okAB {
+				missing = append(missing, a+"/"+b)
+			}
+			if a != b && !okBA {
+				missing = append(missing, b+"/"+a)
+			}
+			if !okAB || !okBA {
+				continue
+			}
+
+			if !finite(ab, ba) {
+				return nil, fmt.Errorf("correlation %s/%s is not a finite number", a, b)
+			}
+			if a == b {
+				if math.Abs(ab-1) > correlationTolerance {
+					return nil, fmt.Errorf("diagonal entry %s/%s is %g, expected 1.0", a, a, ab)
+				}
+				continue
+			}
+			if ab < -1 || ab > 1 {
+				return nil, fmt.Errorf("correlation %s/%s is %g, outside [-1, 1]", a, b, ab)
+			}
+			if math.Abs(ab-ba) > correlationTolerance {
+				return nil, fmt.Errorf("matrix is not symmetric: %s/%s is %g but %s/%s is %g", a, b, ab, b, a, ba)
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("correlation matrix is missing pairs: %s", strings.Join(missing, ", "))
+	}
+
+	return &CorrelationMatrix{values: values}, nil
+}
+
+// Get returns the correlation between two commodities and whether it is known
+func (m *CorrelationMatrix) Get(a, b string) (float64, bool) {
+	corr, ok := m.values[a][b]
+	return corr, ok
+}
+
+// Commodities returns the commodities covered by the matrix in sorted order
+func (m *CorrelationMatrix) Commodities() []string {
+	names := make([]string, 0, len(m.values))
+	for name := range m.values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
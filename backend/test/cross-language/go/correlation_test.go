@@ -0,0 +1,109 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestCorrelationMatrixLoadValid verifies a complete symmetric matrix loads from CSV
+func TestCorrelationMatrixLoadValid(t *testing.T) {
+	matrix, err := LoadCorrelationMatrix("testdata/correlations.csv")
+	if err != nil {
+		t.Fatalf("Failed to load correlation matrix: %v", err)
+	}
+
+	corr, ok := matrix.Get("crude_oil", "heating_oil")
+	if !ok || corr != 0.82 {
+		t.Errorf("Expected crude_oil/heating_oil correlation 0.82, got %f (ok=%v)", corr, ok)
+	}
+	if corr, _ := matrix.Get("heating_oil", "crude_oil"); corr != 0.82 {
+		t.Errorf("Expected symmetric lookup 0.82, got %f", corr)
+	}
+	if corr, _ := matrix.Get("natural_gas", "natural_gas"); corr != 1.0 {
+		t.Errorf("Expected diagonal 1.0, got %f", corr)
+	}
+	if _, ok := matrix.Get("crude_oil", "power"); ok {
+		t.Error("Expected unknown pair to be reported as missing")
+	}
+	if len(matrix.Commodities()) != 3 {
+		t.Errorf("Expected 3 commodities, got %d", len(matrix.Commodities()))
+	}
+}
+
+// TestCorrelationMatrixJSON verifies the nested JSON format loads
+func TestCorrelationMatrixJSON(t *testing.T) {
+	input := `{
+		"crude_oil": {"crude_oil": 1.0, "natural_gas": -0.2},
+		"natural_gas": {"crude_oil": -0.2, "natural_gas": 1.0}
+	}`
+	matrix, err := ParseCorrelationJSON(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Failed to parse correlation json: %v", err)
+	}
+	if corr, ok := matrix.Get("natural_gas", "crude_oil"); !ok || corr != -0.2 {
+		t.Errorf("Expected -0.2, got %f (ok=%v)", corr, ok)
+	}
+}
+
+// TestCorrelationMatrixRejectsInvalid verifies descriptive errors for invalid matrices
+func TestCorrelationMatrixRejectsInvalid(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr string
+	}{
+		{
+			name: "asymmetric",
+			input: "crude_oil,crude_oil,1\ncrude_oil,natural_gas,0.4\n" +
+				"natural_gas,crude_oil,0.5\nnatural_gas,natural_gas,1\n",
+			wantErr: "not symmetric",
+		},
+		{
+			name: "bad diagonal",
+			input: "crude_oil,crude_oil,0.9\ncrude_oil,natural_gas,0.4\n" +
+				"natural_gas,crude_oil,0.4\nnatural_gas,natural_gas,1\n",
+			wantErr: "expected 1.0",
+		},
+		{
+			name:    "missing pair",
+			input:   "crude_oil,crude_oil,1\ncrude_oil,natural_gas,0.4\nnatural_gas,natural_gas,1\n",
+			wantErr: "missing pairs: natural_gas/crude_oil",
+		},
+		{
+			name: "out of range",
+			input: "crude_oil,crude_oil,1\ncrude_oil,natural_gas,1.4\n" +
+				"natural_gas,crude_oil,1.4\nnatural_gas,natural_gas,1\n",
+			wantErr: "outside [-1, 1]",
+		},
+		{
+			name: "nan correlation",
+			input: "crude_oil,crude_oil,1\ncrude_oil,natural_gas,NaN\n" +
+				"natural_gas,crude_oil,NaN\nnatural_gas,natural_gas,1\n",
+			wantErr: "not a finite number",
+		},
+		{
+			name: "nan diagonal",
+			input: "crude_oil,crude_oil,NaN\ncrude_oil,natural_gas,0.4\n" +
+				"natural_gas,crude_oil,0.4\nnatural_gas,natural_gas,1\n",
+			wantErr: "not a finite number",
+		},
+		{
+			name: "infinite mirror",
+			input: "crude_oil,crude_oil,1\ncrude_oil,natural_gas,0.4\n" +
+				"natural_gas,crude_oil,+Inf\nnatural_gas,natural_gas,1\n",
+			wantErr: "not a finite number",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := ParseCorrelationCSV(strings.NewReader(tc.input))
+			if err == nil {
+				t.Fatal("Expected loading to fail")
+			}
+			if !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tc.wantErr, err.Error())
+			}
+		})
+	}
+}
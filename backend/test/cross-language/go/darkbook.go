@@ -0,0 +1,172 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DarkBook matches orders at the reference midpoint without displaying
+// depth. Only executed trades are ever revealed.
+type DarkBook struct {
+	mu        sync.Mutex
+	commodity string
+	reference QuoteSource
+	buys      []*TradingOrder // arrival order
+	sells     []*TradingOrder // arrival order
+	orders    map[string]*TradingOrder
+	clock     func() time.Time
+	tradeSeq  uint64
+}
+
+// NewDarkBook creates a dark book pricing off the reference quote source,
+// typically the lit OrderBook for the same commodity
+func NewDarkBook(commodity string, reference QuoteSource) *DarkBook {
+	return &DarkBook{
+		commodity: commodity,
+		reference: reference,
+		orders:    make(map[string]*TradingOrder),
+		clock:     time.Now,
+	}
+}
+
+// Mid returns the reference midpoint, if the reference is two-sided
+func (d *DarkBook) Mid() (float64, bool) {
+	bid, _, okBid := d.reference.BestBid()
+	ask, _, okAsk := d.reference.BestAsk()
+	if !okBid || !okAsk {
+		return 0, false
+	}
+	return (bid + ask) / 2, true
+}
+
+// Add submits an order. A non-zero Price is a limit on the acceptable
+// midpoint. The order matches immediately if contra interest exists at the
+// current mid, otherwise it rests invisibly.
+func (d *DarkBook) Add(order TradingOrder) ([]Trade, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	switch {
+	case order.OrderID == "":
+		return nil, fmt.Errorf("%w: missing order id", ErrInvalidOrder)
+	case order.Side != SideBuy && order.Side != SideSell:
+		return nil, fmt.Errorf("%w: unknown side %q", ErrInvalidOrder, order.Side)
+	case order.Volume <= 0:
+		return nil, fmt.Errorf("%w: volume must be positive", ErrInvalidOrder)
+	}
+	if _, exists := d.orders[order.OrderID]; exists {
+		return nil, fmt.Errorf("%w: %s", ErrDuplicateOrder, order.OrderID)
+	}
+	order.Commodity = d.commodity
+
+	resting := &order
+	d.orders[order.OrderID] = resting
+	if order.Side == SideBuy {
+		d.buys = append(d.buys, resting)
+	} else {
+		d.sells = append(d.sells, resting)
+	}
+	return d.matchLocked(resting.Side), nil
+}
+
+// Match re-runs matching at the current mid, for use when the reference
+// quote moves
+func (d *DarkBook) Match() []Trade {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.matchLocked(SideBuy)
+}
+
+// Cancel withdraws a resting dark order
+func (d *DarkBook) Cancel(orderID string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	order, ok := d.orders[orderID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	delete(d.orders, orderID)
+	if order.Side == SideBuy {
+		d.buys = removeDarkOrder(d.buys, order)
+	} else {
+		d.sells = removeDarkOrder(d.sells, order)
+	}
+	return nil
+}
+
+// Snapshot never exposes resting interest; its sequence counts executions only
+func (d *DarkBook) Snapshot() BookSnapshot {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return BookSnapshot{Commodity: d.commodity, Seq: d.tradeSeq, Bids: []PriceLevel{}, Asks: []PriceLevel{}}
+}
+
+// matchLocked pairs eligible buys and sells at the mid by size then time. The
+// aggressor is the side of the order that triggered matching.
+func (d *DarkBook) matchLocked(aggressor string) []Trade {
+	mid, ok := d.Mid()
+	if !ok {
+		return nil
+	}
+
+	var trades []Trade
+	for {
+		buy := bestEligible(d.buys, func(o *TradingOrder) bool { return o.Price == 0 || o.Price >= mid })
+		sell := bestEligible(d.sells, func(o *TradingOrder) bool { return o.Price == 0 || o.Price <= mid })
+		if buy == nil || sell == nil {
+			return trades
+		}
+
+		fill := buy.Volume
+		if sell.Volume < fill {
+			fill = sell.Volume
+		}
+		d.tradeSeq++
+		trades = append(trades, Trade{
+			TradeID:      fmt.Sprintf("%s-dark-%d", d.commodity, d.tradeSeq),
+			Commodity:    d.commodity,
+			Price:        mid,
+			Volume:       fill,
+			BuyOrderID:   buy.OrderID,
+			SellOrderID:  sell.OrderID,
+			BuyClientID:  buy.ClientID,
+			SellClientID: sell.ClientID,
+			Aggressor:    aggressor,
+			Timestamp:    d.clock(),
+		})
+
+		buy.Volume -= fill
+		sell.Volume -= fill
+		if buy.Volume <= volumeEpsilon {
+			delete(d.orders, buy.OrderID)
+			d.buys = removeDarkOrder(d.buys, buy)
+		}
+		if sell.Volume <= volumeEpsilon {
+			delete(d.orders, sell.OrderID)
+			d.sells = removeDarkOrder(d.sells, sell)
+		}
+	}
+}
+
+// bestEligible applies size-then-time priority: the largest eligible order
+// wins and equal sizes fall back to arrival order
+func bestEligible(queue []*TradingOrder, eligible func(*TradingOrder) bool) *TradingOrder {
+	var best *TradingOrder
+	for _, o := range queue {
+		if eligible(o) && (best == nil || o.Volume > best.Volume) {
+			best = o
+		}
+	}
+	return best
+}
+
+func removeDarkOrder(queue []*TradingOrder, order *TradingOrder) []*TradingOrder {
+	for i, o := range queue {
+		if o == order {
+			return append(queue[:i], queue[i+1:]...)
+		}
+	}
+	return queue
+}
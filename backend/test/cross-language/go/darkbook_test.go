@@ -0,0 +1,71 @@
+package integration
+
+import "testing"
+
+// TestDarkBookMatchesAtMid verifies executions occur at the reference midpoint by size then time
+func TestDarkBookMatchesAtMid(t *testing.T) {
+	dark := NewDarkBook("crude_oil", newQuotedBook(t)) // 75.40 / 75.60
+
+	for _, o := range []TradingOrder{
+		{OrderID: "blk_s1", Side: SideSell, Volume: 5000},
+		{OrderID: "blk_s2", Side: SideSell, Volume: 5000},
+	} {
+		if trades, err := dark.Add(o); err != nil || len(trades) != 0 {
+			t.Fatalf("Expected %s to rest silently, got %v / %v", o.OrderID, trades, err)
+		}
+	}
+
+	trades, err := dark.Add(TradingOrder{OrderID: "blk_b1", Side: SideBuy, Volume: 7000, Price: 75.55})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(trades) != 2 {
+		t.Fatalf("Expected 2 trades, got %d", len(trades))
+	}
+	for _, trade := range trades {
+		if trade.Price != 75.50 {
+			t.Errorf("Expected execution at mid 75.50, got %f", trade.Price)
+		}
+	}
+	if trades[0].SellOrderID != "blk_s1" || trades[0].Volume != 5000 || trades[1].Volume != 2000 {
+		t.Errorf("Expected time priority between equal sizes, got %+v", trades)
+	}
+
+	// A larger block outranks an older smaller one.
+	if err := dark.Cancel("blk_s2"); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	dark.Add(TradingOrder{OrderID: "blk_b2", Side: SideBuy, Volume: 1000})
+	dark.Add(TradingOrder{OrderID: "blk_b3", Side: SideBuy, Volume: 4000})
+	trades, _ = dark.Add(TradingOrder{OrderID: "blk_s3", Side: SideSell, Volume: 3000})
+	if len(trades) != 1 || trades[0].BuyOrderID != "blk_b3" {
+		t.Errorf("Expected size priority to fill blk_b3 first, got %+v", trades)
+	}
+}
+
+// TestDarkBookLimitExcludesMid verifies a limit worse than the mid does not execute
+func TestDarkBookLimitExcludesMid(t *testing.T) {
+	dark := NewDarkBook("crude_oil", newQuotedBook(t))
+	dark.Add(TradingOrder{OrderID: "s1", Side: SideSell, Volume: 100, Price: 75.55})
+
+	if trades, _ := dark.Add(TradingOrder{OrderID: "b1", Side: SideBuy, Volume: 100}); len(trades) != 0 {
+		t.Errorf("Expected no match when sell limit is above the mid, got %+v", trades)
+	}
+}
+
+// TestDarkBookSnapshotRevealsNothing verifies resting interest never leaks
+func TestDarkBookSnapshotRevealsNothing(t *testing.T) {
+	dark := NewDarkBook("crude_oil", newQuotedBook(t))
+	empty := dark.Snapshot()
+
+	dark.Add(TradingOrder{OrderID: "s1", Side: SideSell, Volume: 100, Price: 80})
+	dark.Add(TradingOrder{OrderID: "b1", Side: SideBuy, Volume: 250, Price: 70})
+
+	snap := dark.Snapshot()
+	if len(snap.Bids) != 0 || len(snap.Asks) != 0 {
+		t.Errorf("Expected no depth in snapshot, got %+v", snap)
+	}
+	if snap.Seq != empty.Seq {
+		t.Errorf("Expected resting orders not to move the sequence, got %d vs %d", snap.Seq, empty.Seq)
+	}
+}
@@ -0,0 +1,119 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// DBPoolConfig configures connection pooling for the platform database
+type DBPoolConfig struct {
+	Driver          string        `json:"driver"`
+	DatabaseURL     string        `json:"database_url"`
+	MaxOpenConns    int           `json:"max_open_conns"`
+	MaxIdleConns    int           `json:"max_idle_conns"`
+	ConnMaxLifetime time.Duration `json:"conn_max_lifetime"`
+	PingTimeout     time.Duration `json:"ping_timeout"`
+}
+
+// DBPoolStats reports pool usage for observability
+type DBPoolStats struct {
+	OpenConnections int           `json:"open_connections"`
+	InUse           int           `json:"in_use"`
+	Idle            int           `json:"idle"`
+	WaitCount       int64         `json:"wait_count"`
+	WaitDuration    time.Duration `json:"wait_duration"`
+}
+
+// DBPool wraps a pooled database handle with transactional helpers
+type DBPool struct {
+	db          *sql.DB
+	pingTimeout time.Duration
+}
+
+// NewDBPool opens a pool using the configured driver and limits. The driver
+// (e.g. "postgres") must be registered by the importing binary.
+func NewDBPool(cfg DBPoolConfig) (*DBPool, error) {
+	if cfg.Driver == "" {
+		cfg.Driver = "postgres"
+	}
+	if cfg.DatabaseURL == "" {
+		return nil, fmt.Errorf("database url is required")
+	}
+
+	db, err := sql.Open(cfg.Driver, cfg.DatabaseURL)
+	if err != nil {
+		return nil, fmt.Errorf("open database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	pingTimeout := cfg.PingTimeout
+	if pingTimeout <= 0 {
+		pingTimeout = 5 * time.Second
+	}
+	return &DBPool{db: db, pingTimeout: pingTimeout}, nil
+}
+
+// DB exposes the underlying handle for plain queries
+func (p *DBPool) DB() *sql.DB {
+	return p.db
+}
+
+// Ping verifies a connection can be established within the ping timeout
+func (p *DBPool) Ping(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, p.pingTimeout)
+	defer cancel()
+
+	if err := p.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("database health check: %w", err)
+	}
+	return nil
+}
+
+// WithTx runs fn inside a transaction, committing on success and rolling
+// back if fn returns an error or panics. Cancelling ctx aborts waiting for
+// a free connection.
+func (p *DBPool) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) (err error) {
+	tx, err := p.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			_ = tx.Rollback()
+			panic(r)
+		}
+	}()
+
+	if err := fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w (rollback failed: %v)", err, rbErr)
+		}
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	return nil
+}
+
+// Stats returns the current pool usage
+func (p *DBPool) Stats() DBPoolStats {
+	s := p.db.Stats()
+	return DBPoolStats{
+		OpenConnections: s.OpenConnections,
+		InUse:           s.InUse,
+		Idle:            s.Idle,
+		WaitCount:       s.WaitCount,
+		WaitDuration:    s.WaitDuration,
+	}
+}
+
+// Close releases all pooled connections
+func (p *DBPool) Close() error {
+	return p.db.Close()
+}
@@ -0,0 +1,260 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeDriver is a minimal database/sql driver that records transaction
+// outcomes. Each DSN is its own database holding an in-memory book_events
+// table, and commits can be made to fail before or after applying their
+// writes to mimic a crash or a lost commit acknowledgement.
+type fakeDriver struct {
+	mu        sync.Mutex
+	begins    int
+	commits   int
+	rollbacks int
+	dbs       map[string]*fakeDatabase
+}
+
+type fakeDatabase struct {
+	rows        map[string]map[int64]fakeEventRow // commodity -> seq
+	inserts     int
+	failCommits []bool // pending failures; true applies the writes before failing
+}
+
+type fakeEventRow struct {
+	kind    string
+	payload []byte
+}
+
+type fakeConn struct {
+	driver *fakeDriver
+	db     *fakeDatabase
+	staged [][]driver.Value
+}
+
+type fakeTx struct{ conn *fakeConn }
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+type fakeRows struct {
+	payloads [][]byte
+	next     int
+}
+
+var fakeDB = &fakeDriver{dbs: make(map[string]*fakeDatabase)}
+
+func init() {
+	sql.Register("fakedb", fakeDB)
+}
+
+func (d *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{driver: d, db: d.database(name)}, nil
+}
+
+func (d *fakeDriver) database(name string) *fakeDatabase {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	db, ok := d.dbs[name]
+	if !ok {
+		db = &fakeDatabase{rows: make(map[string]map[int64]fakeEventRow)}
+		d.dbs[name] = db
+	}
+	return db
+}
+
+func (d *fakeDriver) counts() (begins, commits, rollbacks int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.begins, d.commits, d.rollbacks
+}
+
+func (c *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: c, query: query}, nil
+}
+
+func (c *fakeConn) Close() error { return nil }
+
+func (c *fakeConn) Begin() (driver.Tx, error) {
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+	c.driver.begins++
+	c.staged = nil
+	return &fakeTx{conn: c}, nil
+}
+
+func (c *fakeConn) Ping(ctx context.Context) error { return nil }
+
+func (tx *fakeTx) Commit() error {
+	c := tx.conn
+	c.driver.mu.Lock()
+	defer c.driver.mu.Unlock()
+
+	apply, fail := true, false
+	if len(c.db.failCommits) > 0 {
+		apply, fail = c.db.failCommits[0], true
+		c.db.failCommits = c.db.failCommits[1:]
+	}
+	if apply {
+		for _, args := range c.staged {
+			commodity, seq := args[0].(string), args[1].(int64)
+			if c.db.rows[commodity] == nil {
+				c.db.rows[commodity] = make(map[int64]fakeEventRow)
+			}
+			if _, exists := c.db.rows[commodity][seq]; exists {
+				continue // ON CONFLICT DO NOTHING
+			}
+			c.db.rows[commodity][seq] = fakeEventRow{kind: args[2].(string), payload: args[3].([]byte)}
+		}
+	}
+	c.staged = nil
+	if fail {
+		return errors.New("fakedb: connection lost during commit")
+	}
+	c.driver.commits++
+	return nil
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.conn.driver.mu.Lock()
+	defer tx.conn.driver.mu.Unlock()
+	tx.conn.driver.rollbacks++
+	tx.conn.staged = nil
+	return nil
+}
+
+func (s *fakeStmt) Close() error  { return nil }
+func (s *fakeStmt) NumInput() int { return -1 }
+
+func (s *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	if !strings.HasPrefix(s.query, "INSERT INTO book_events") {
+		return nil, errors.New("fakedb: unexpected statement " + s.query)
+	}
+	s.conn.driver.mu.Lock()
+	s.conn.db.inserts++
+	s.conn.driver.mu.Unlock()
+	s.conn.staged = append(s.conn.staged, append([]driver.Value(nil), args...))
+	return driver.RowsAffected(1), nil
+}
+
+func (s *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(s.query, "SELECT payload FROM book_events") {
+		return nil, errors.New("fakedb: unexpected query " + s.query)
+	}
+	s.conn.driver.mu.Lock()
+	defer s.conn.driver.mu.Unlock()
+
+	table := s.conn.db.rows[args[0].(string)]
+	seqs := make([]int64, 0, len(table))
+	for seq := range table {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	rows := &fakeRows{}
+	for _, seq := range seqs {
+		rows.payloads = append(rows.payloads, table[seq].payload)
+	}
+	return rows, nil
+}
+
+func (r *fakeRows) Columns() []string { return []string{"payload"} }
+func (r *fakeRows) Close() error      { return nil }
+
+func (r *fakeRows) Next(dest []driver.Value) error {
+	if r.next >= len(r.payloads) {
+		return io.EOF
+	}
+	dest[0] = r.payloads[r.next]
+	r.next++
+	return nil
+}
+
+func newFakePool(t *testing.T, maxOpen int) *DBPool {
+	t.Helper()
+	pool, err := NewDBPool(DBPoolConfig{
+		Driver:       "fakedb",
+		DatabaseURL:  "postgres://localhost:5432/quantenergx",
+		MaxOpenConns: maxOpen,
+		MaxIdleConns: maxOpen,
+	})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool
+}
+
+// TestDBPoolWithTxRollsBackOnError verifies failed work is rolled back and successful work committed
+func TestDBPoolWithTxRollsBackOnError(t *testing.T) {
+	pool := newFakePool(t, 2)
+	ctx := context.Background()
+
+	if err := pool.Ping(ctx); err != nil {
+		t.Fatalf("Expected ping to succeed, got %v", err)
+	}
+
+	_, commitsBefore, rollbacksBefore := fakeDB.counts()
+
+	workErr := errors.New("insert failed")
+	err := pool.WithTx(ctx, func(tx *sql.Tx) error { return workErr })
+	if !errors.Is(err, workErr) {
+		t.Errorf("Expected work error to propagate, got %v", err)
+	}
+
+	if err := pool.WithTx(ctx, func(tx *sql.Tx) error { return nil }); err != nil {
+		t.Errorf("Expected successful transaction, got %v", err)
+	}
+
+	_, commits, rollbacks := fakeDB.counts()
+	if rollbacks-rollbacksBefore != 1 {
+		t.Errorf("Expected 1 rollback, got %d", rollbacks-rollbacksBefore)
+	}
+	if commits-commitsBefore != 1 {
+		t.Errorf("Expected 1 commit, got %d", commits-commitsBefore)
+	}
+}
+
+// TestDBPoolContextCancelAbortsWait verifies a cancelled context stops waiting for a connection
+func TestDBPoolContextCancelAbortsWait(t *testing.T) {
+	pool := newFakePool(t, 1)
+
+	held, err := pool.DB().Conn(context.Background())
+	if err != nil {
+		t.Fatalf("Failed to acquire connection: %v", err)
+	}
+	defer held.Close()
+
+	if stats := pool.Stats(); stats.InUse != 1 {
+		t.Errorf("Expected 1 connection in use, got %d", stats.InUse)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	called := false
+	err = pool.WithTx(ctx, func(tx *sql.Tx) error {
+		called = true
+		return nil
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected deadline exceeded, got %v", err)
+	}
+	if called {
+		t.Error("Transaction function should not run without a connection")
+	}
+	if stats := pool.Stats(); stats.WaitCount < 1 {
+		t.Errorf("Expected wait count to be recorded, got %d", stats.WaitCount)
+	}
+}
@@ -0,0 +1,214 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// SessionRecorderConfig configures a recorded matching session
+type SessionRecorderConfig struct {
+	// Seed drives every random draw the book makes; zero draws a seed from
+	// the clock, which is recorded so the session still replays exactly
+	Seed int64
+	// Jitter is the iceberg slice jitter spread, as WithIcebergJitter
+	Jitter float64
+	// Clock times each operation; nil uses time.Now
+	Clock func() time.Time
+}
+
+// RecordedStep is one operation of a recorded session with the time it
+// ran at and exactly what it produced
+type RecordedStep struct {
+	ScenarioOperation
+	At     time.Time `json:"at"`
+	Trades []Trade   `json:"trades,omitempty"`
+	Error  string    `json:"error,omitempty"`
+}
+
+// SessionRecording is everything needed to replay a matching session bit
+// for bit: the seed, and every operation in the order the book applied it
+// with its time and output
+type SessionRecording struct {
+	Commodity string         `json:"commodity"`
+	Seed      int64          `json:"seed"`
+	Jitter    float64        `json:"jitter,omitempty"`
+	Steps     []RecordedStep `json:"steps"`
+	Final     BookSnapshot   `json:"final"`
+}
+
+// SessionRecorder is a MatchingEngine that records every operation it
+// passes to its book. Operations are serialised, so the recording holds
+// them in the order the book applied them even under concurrent callers.
+type SessionRecorder struct {
+	mu     sync.Mutex
+	book   *OrderBook
+	clock  func() time.Time
+	now    time.Time
+	record SessionRecording
+}
+
+// NewSessionRecorder creates a book for commodity that records its session.
+// The recorder owns the book's clock and iceberg jitter seed; replays must
+// pass the same opts.
+func NewSessionRecorder(commodity string, config SessionRecorderConfig, opts ...BookOption) *SessionRecorder {
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+	if config.Seed == 0 {
+		config.Seed = config.Clock().UnixNano()
+	}
+	r := &SessionRecorder{
+		clock:  config.Clock,
+		record: SessionRecording{Commodity: commodity, Seed: config.Seed, Jitter: config.Jitter},
+	}
+	r.book = newSessionBook(r.record, func() time.Time { return r.now }, opts)
+	return r
+}
+
+// newSessionBook builds the book a recording runs on, with its clock and
+// seed applied after opts so they cannot be overridden
+func newSessionBook(rec SessionRecording, clock func() time.Time, opts []BookOption) *OrderBook {
+	opts = append(append([]BookOption(nil), opts...),
+		WithIcebergJitter(rec.Jitter, rec.Seed),
+		WithClock(clock))
+	return NewOrderBook(rec.Commodity, opts...)
+}
+
+// Book returns the recorded book for reads; mutations made directly on it
+// are not recorded and will make the session diverge on replay
+func (r *SessionRecorder) Book() *OrderBook {
+	return r.book
+}
+
+// Add implements MatchingEngine
+func (r *SessionRecorder) Add(order TradingOrder) ([]Trade, error) {
+	return r.apply(ScenarioOperation{Op: ScenarioOpAdd, Order: &order})
+}
+
+// Cancel implements MatchingEngine
+func (r *SessionRecorder) Cancel(orderID string) error {
+	_, err := r.apply(ScenarioOperation{Op: ScenarioOpCancel, OrderID: orderID})
+	return err
+}
+
+// Amend implements MatchingEngine
+func (r *SessionRecorder) Amend(orderID string, price, volume float64) ([]Trade, error) {
+	return r.apply(ScenarioOperation{Op: ScenarioOpAmend, OrderID: orderID, Price: price, Volume: volume})
+}
+
+func (r *SessionRecorder) apply(op ScenarioOperation) ([]Trade, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.now = r.clock()
+	trades, err := applyScenarioOperation(r.book, op)
+	step := RecordedStep{ScenarioOperation: op, At: r.now, Trades: append([]Trade(nil), trades...)}
+	if err != nil {
+		step.Error = err.Error()
+	}
+	r.record.Steps = append(r.record.Steps, step)
+	return trades, err
+}
+
+// Recording returns the session so far with the book's current depth
+func (r *SessionRecorder) Recording() SessionRecording {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rec := r.record
+	rec.Steps = append([]RecordedStep(nil), r.record.Steps...)
+	rec.Final = r.book.Snapshot()
+	return rec
+}
+
+// Save writes the recording as JSON
+func (r *SessionRecorder) Save(path string) error {
+	data, err := json.MarshalIndent(r.Recording(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode session: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("write session: %w", err)
+	}
+	return nil
+}
+
+// LoadSessionRecording reads a recording written by Save
+func LoadSessionRecording(path string) (SessionRecording, error) {
+	var rec SessionRecording
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return rec, fmt.Errorf("read session: %w", err)
+	}
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return rec, fmt.Errorf("decode session %s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// ReplaySession re-runs a recording on a fresh book built with opts, each
+// operation at its recorded time, and reports every step whose trades or
+// error differ from the recording, and any difference in final depth.
+// Outputs are compared in their JSON encoding, which is exact for floats
+// and timestamps, so any nondeterminism in matching shows up as a
+// difference. It fails if a trade or snapshot on either side cannot be
+// encoded, as happens for non-finite prices.
+func ReplaySession(rec SessionRecording, opts ...BookOption) (ScenarioResult, error) {
+	var now time.Time
+	book := newSessionBook(rec, func() time.Time { return now }, opts)
+	var result ScenarioResult
+
+	for i, step := range rec.Steps {
+		now = step.At
+		trades, err := applyScenarioOperation(book, step.ScenarioOperation)
+		result.Trades = append(result.Trades, trades...)
+
+		var errText string
+		if err != nil {
+			errText = err.Error()
+		}
+		if errText != step.Error {
+			result.Differences = append(result.Differences,
+				fmt.Sprintf("step %d (%s): error %q, recorded %q", i, step.Op, errText, step.Error))
+		}
+		if len(trades) != len(step.Trades) {
+			result.Differences = append(result.Differences,
+				fmt.Sprintf("step %d (%s): %d trades, recorded %d", i, step.Op, len(trades), len(step.Trades)))
+			continue
+		}
+		for j := range trades {
+			got, want, err := encodePair(trades[j], step.Trades[j])
+			if err != nil {
+				return result, fmt.Errorf("step %d (%s) trade %d: %w", i, step.Op, j, err)
+			}
+			if !bytes.Equal(got, want) {
+				result.Differences = append(result.Differences,
+					fmt.Sprintf("step %d (%s) trade %d: got %s, recorded %s", i, step.Op, j, got, want))
+			}
+		}
+	}
+
+	got, want, err := encodePair(book.Snapshot(), rec.Final)
+	if err != nil {
+		return result, fmt.Errorf("final book: %w", err)
+	}
+	if !bytes.Equal(got, want) {
+		result.Differences = append(result.Differences, fmt.Sprintf("final book: got %s, recorded %s", got, want))
+	}
+	return result, nil
+}
+
+// encodePair encodes a replayed value and its recording for comparison
+func encodePair(got, want interface{}) ([]byte, []byte, error) {
+	gotData, err := json.Marshal(got)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode replayed: %w", err)
+	}
+	wantData, err := json.Marshal(want)
+	if err != nil {
+		return nil, nil, fmt.Errorf("encode recorded: %w", err)
+	}
+	return gotData, wantData, nil
+}
@@ -0,0 +1,136 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordRandomSession drives a recorder with a seeded mix of icebergs,
+// limit and market orders, cancels and amends
+func recordRandomSession(t *testing.T, opts ...BookOption) *SessionRecorder {
+	t.Helper()
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	rec := NewSessionRecorder("crude_oil", SessionRecorderConfig{
+		Seed:   42,
+		Jitter: 0.3,
+		Clock: func() time.Time {
+			now = now.Add(time.Millisecond)
+			return now
+		},
+	}, opts...)
+
+	rng := rand.New(rand.NewSource(7))
+	var ids []string
+	for i := 0; i < 400; i++ {
+		side := SideBuy
+		if rng.Intn(2) == 0 {
+			side = SideSell
+		}
+		switch n := rng.Intn(10); {
+		case n < 6:
+			order := TradingOrder{
+				OrderID: fmt.Sprintf("o%d", i),
+				Side:    side,
+				Type:    OrderTypeLimit,
+				Price:   74.5 + float64(rng.Intn(11))*0.1,
+				Volume:  float64(1 + rng.Intn(20)),
+			}
+			if rng.Intn(4) == 0 {
+				order.Volume *= 5
+				order.DisplayVolume = 10
+			}
+			rec.Add(order)
+			ids = append(ids, order.OrderID)
+		case n < 7:
+			rec.Add(TradingOrder{OrderID: fmt.Sprintf("m%d", i), Side: side, Type: OrderTypeMarket, Volume: float64(1 + rng.Intn(30))})
+		case n < 9 && len(ids) > 0:
+			rec.Cancel(ids[rng.Intn(len(ids))])
+		case len(ids) > 0:
+			rec.Amend(ids[rng.Intn(len(ids))], 74.5+float64(rng.Intn(11))*0.1, float64(1+rng.Intn(20)))
+		}
+	}
+	return rec
+}
+
+// TestReplaySessionReproducesTradesExactly verifies a recorded session,
+// saved and reloaded, replays to identical trades and depth
+func TestReplaySessionReproducesTradesExactly(t *testing.T) {
+	for name, opts := range map[string][]BookOption{
+		"fifo":      nil,
+		"pro_rata":  {WithProRata(1)},
+		"lot_sized": {WithLotSize(1, LotResidualCancel)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := recordRandomSession(t, opts...)
+			var trades int
+			for _, step := range rec.Recording().Steps {
+				trades += len(step.Trades)
+			}
+			if trades == 0 {
+				t.Fatal("Expected the session to produce trades")
+			}
+
+			path := filepath.Join(t.TempDir(), "session.json")
+			if err := rec.Save(path); err != nil {
+				t.Fatalf("Failed to save session: %v", err)
+			}
+			loaded, err := LoadSessionRecording(path)
+			if err != nil {
+				t.Fatalf("Failed to load session: %v", err)
+			}
+
+			result, err := ReplaySession(loaded, opts...)
+			if err != nil {
+				t.Fatalf("Replay failed: %v", err)
+			}
+			for _, diff := range result.Differences {
+				t.Error(diff)
+			}
+			if len(result.Trades) != trades {
+				t.Errorf("Expected %d replayed trades, got %d", trades, len(result.Trades))
+			}
+		})
+	}
+}
+
+// TestReplaySessionSurfacesNondeterminism verifies a replay that draws
+// differently or matches differently from the recording is reported
+func TestReplaySessionSurfacesNondeterminism(t *testing.T) {
+	recorded := recordRandomSession(t).Recording()
+
+	reseeded := recorded
+	reseeded.Seed++
+	if result, err := ReplaySession(reseeded); err != nil || result.Passed() {
+		t.Errorf("Expected a different iceberg seed to diverge, got %v", err)
+	}
+
+	// A book configured differently on replay stands in for an engine whose
+	// output is not a function of its inputs
+	result, err := ReplaySession(recorded, WithProRata(1))
+	if err != nil || result.Passed() {
+		t.Fatal("Expected pro-rata matching to diverge from a FIFO recording")
+	}
+	if !strings.HasPrefix(result.Differences[0], "step ") {
+		t.Errorf("Expected differences to name the diverging step, got %q", result.Differences[0])
+	}
+}
+
+// TestReplaySessionReportsUnencodableValues verifies a recording holding a
+// value JSON cannot encode fails the replay instead of panicking
+func TestReplaySessionReportsUnencodableValues(t *testing.T) {
+	rec := recordRandomSession(t).Recording()
+	for i := range rec.Steps {
+		if len(rec.Steps[i].Trades) > 0 {
+			rec.Steps[i].Trades[0].Price = math.NaN()
+			break
+		}
+	}
+	if _, err := ReplaySession(rec); err == nil || !strings.Contains(err.Error(), "encode recorded") {
+		t.Errorf("Expected an encoding error, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+)
+
+// SettlementDetails are a client's standing settlement instructions
+type SettlementDetails struct {
+	Agent    string `json:"agent"`   // settlement bank or clearing agent
+	Account  string `json:"account"` // account at the agent
+	Currency string `json:"currency"`
+}
+
+// ClientReference is the reference data held for a trading client
+type ClientReference struct {
+	ClientID       string            `json:"client_id"`
+	CounterpartyID string            `json:"counterparty_id"`
+	LegalEntity    string            `json:"legal_entity"`
+	Settlement     SettlementDetails `json:"settlement"`
+}
+
+// missing lists what the reference lacks for settlement
+func (r ClientReference) missing() []string {
+	var missing []string
+	if r.CounterpartyID == "" {
+		missing = append(missing, "counterparty id")
+	}
+	if r.LegalEntity == "" {
+		missing = append(missing, "legal entity")
+	}
+	if r.Settlement.Agent == "" || r.Settlement.Account == "" {
+		missing = append(missing, "settlement instructions")
+	}
+	return missing
+}
+
+// ClientReferenceSource looks up client reference data
+type ClientReferenceSource interface {
+	Lookup(clientID string) (ClientReference, bool)
+}
+
+// ClientReferenceTable is an in-memory ClientReferenceSource
+type ClientReferenceTable struct {
+	mu   sync.RWMutex
+	refs map[string]ClientReference
+}
+
+// NewClientReferenceTable creates a table from references keyed by their ClientID
+func NewClientReferenceTable(refs ...ClientReference) *ClientReferenceTable {
+	t := &ClientReferenceTable{refs: make(map[string]ClientReference, len(refs))}
+	for _, ref := range refs {
+		t.Set(ref)
+	}
+	return t
+}
+
+// Set adds or replaces a client's reference data
+func (t *ClientReferenceTable) Set(ref ClientReference) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refs[ref.ClientID] = ref
+}
+
+// Lookup implements ClientReferenceSource
+func (t *ClientReferenceTable) Lookup(clientID string) (ClientReference, bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	ref, ok := t.refs[clientID]
+	return ref, ok
+}
+
+// EnrichedTrade is a trade with both counterparties' reference data. A
+// trade whose reference data is missing or incomplete is still enriched as
+// far as possible and flagged for manual review with the reasons.
+type EnrichedTrade struct {
+	Trade
+	Buyer         ClientReference `json:"buyer"`
+	Seller        ClientReference `json:"seller"`
+	NeedsReview   bool            `json:"needs_review"`
+	ReviewReasons []string        `json:"review_reasons,omitempty"`
+}
+
+// TradeEnricher attaches counterparty reference data to trades for
+// settlement. Gaps in the data never block a trade; they route it to
+// manual review.
+type TradeEnricher struct {
+	refs     ClientReferenceSource
+	onReview func(EnrichedTrade)
+}
+
+// NewTradeEnricher creates an enricher; onReview, called for every trade
+// flagged for review, may be nil
+func NewTradeEnricher(refs ClientReferenceSource, onReview func(EnrichedTrade)) *TradeEnricher {
+	return &TradeEnricher{refs: refs, onReview: onReview}
+}
+
+// Enrich looks up both sides of a trade
+func (e *TradeEnricher) Enrich(trade Trade) EnrichedTrade {
+	enriched := EnrichedTrade{Trade: trade}
+	enriched.Buyer = e.side(&enriched, "buy", trade.BuyClientID)
+	enriched.Seller = e.side(&enriched, "sell", trade.SellClientID)
+	enriched.NeedsReview = len(enriched.ReviewReasons) > 0
+	if enriched.NeedsReview && e.onReview != nil {
+		e.onReview(enriched)
+	}
+	return enriched
+}
+
+// EnrichAll enriches trades in order
+func (e *TradeEnricher) EnrichAll(trades []Trade) []EnrichedTrade {
+	out := make([]EnrichedTrade, len(trades))
+	for i, trade := range trades {
+		out[i] = e.Enrich(trade)
+	}
+	return out
+}
+
+func (e *TradeEnricher) side(enriched *EnrichedTrade, side, clientID string) ClientReference {
+	if clientID == "" {
+		enriched.ReviewReasons = append(enriched.ReviewReasons, fmt.Sprintf("%s side has no client", side))
+		return ClientReference{}
+	}
+	ref, ok := e.refs.Lookup(clientID)
+	if !ok {
+		enriched.ReviewReasons = append(enriched.ReviewReasons, fmt.Sprintf("%s client %s has no reference data", side, clientID))
+		return ClientReference{ClientID: clientID}
+	}
+	for _, field := range ref.missing() {
+		enriched.ReviewReasons = append(enriched.ReviewReasons, fmt.Sprintf("%s client %s has no %s", side, clientID, field))
+	}
+	return ref
+}
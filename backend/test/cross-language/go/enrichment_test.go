@@ -0,0 +1,43 @@
+package integration
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestTradeEnricherKnownAndUnknownCounterparties verifies known clients are attached and gaps flag review
+func TestTradeEnricherKnownAndUnknownCounterparties(t *testing.T) {
+	acme := ClientReference{
+		ClientID:       "acme",
+		CounterpartyID: "CP-1001",
+		LegalEntity:    "Acme Energy Trading Ltd",
+		Settlement:     SettlementDetails{Agent: "Clearing Bank A", Account: "GB00-1234", Currency: "USD"},
+	}
+	zenith := ClientReference{
+		ClientID:       "zenith",
+		CounterpartyID: "CP-2002",
+		LegalEntity:    "Zenith Power AG",
+		Settlement:     SettlementDetails{Agent: "Clearing Bank B", Account: "DE00-5678", Currency: "EUR"},
+	}
+	var reviewed []EnrichedTrade
+	enricher := NewTradeEnricher(NewClientReferenceTable(acme, zenith), func(e EnrichedTrade) { reviewed = append(reviewed, e) })
+
+	enriched := enricher.EnrichAll([]Trade{
+		{TradeID: "T1", Commodity: "crude_oil", BuyClientID: "acme", SellClientID: "zenith", Price: 75, Volume: 10},
+		{TradeID: "T2", Commodity: "crude_oil", BuyClientID: "acme", SellClientID: "ghost", Price: 75, Volume: 5},
+	})
+
+	if e := enriched[0]; e.NeedsReview || !reflect.DeepEqual(e.Buyer, acme) || !reflect.DeepEqual(e.Seller, zenith) {
+		t.Errorf("Expected T1 fully enriched, got %+v", e)
+	}
+	e := enriched[1]
+	if !e.NeedsReview || e.Buyer.LegalEntity != acme.LegalEntity || e.Seller.ClientID != "ghost" || e.Seller.CounterpartyID != "" {
+		t.Errorf("Expected T2 enriched for acme and flagged for ghost, got %+v", e)
+	}
+	if want := []string{"sell client ghost has no reference data"}; !reflect.DeepEqual(e.ReviewReasons, want) {
+		t.Errorf("Expected reasons %v, got %v", want, e.ReviewReasons)
+	}
+	if len(reviewed) != 1 || reviewed[0].TradeID != "T2" {
+		t.Errorf("Expected only T2 sent to review, got %+v", reviewed)
+	}
+}
@@ -0,0 +1,299 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Book event types
+const (
+	BookEventAdd    = "add"
+	BookEventCancel = "cancel"
+	BookEventAmend  = "amend"
+	BookEventReduce = "reduce"
+	BookEventTrade  = "trade"
+
+	BookEventTickSize = "tick_size"
+
+	BookEventPause   = "pause"
+	BookEventRelease = "release"
+
+	BookEventAuctionStart = "auction_start"
+	BookEventUncross      = "uncross"
+	BookEventCloseUncross = "close_uncross"
+	BookEventPegMarket    = "peg_market"
+)
+
+// BookEvent is a single order book mutation
+type BookEvent struct {
+	Seq       uint64        `json:"seq"`
+	Type      string        `json:"type"`
+	Commodity string        `json:"commodity"`
+	OrderID   string        `json:"order_id,omitempty"`
+	Order     *TradingOrder `json:"order,omitempty"`  // as submitted, for adds and pauses
+	Price     float64       `json:"price,omitempty"`  // for amends, tick sizes and pauses
+	Volume    float64       `json:"volume,omitempty"` // for amends, reductions and IOC remainders
+	Trade     *Trade        `json:"trade,omitempty"`
+	Bid       float64       `json:"bid,omitempty"` // peg reference market
+	Ask       float64       `json:"ask,omitempty"`
+	Reference float64       `json:"reference,omitempty"` // for orders paused outside the price band
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+// EventLog is an append-only record of book mutations
+type EventLog interface {
+	Append(event BookEvent)
+	Events() []BookEvent
+}
+
+// MemoryEventLog is an in-memory EventLog
+type MemoryEventLog struct {
+	mu     sync.Mutex
+	events []BookEvent
+}
+
+// NewMemoryEventLog creates an empty log
+func NewMemoryEventLog() *MemoryEventLog {
+	return &MemoryEventLog{}
+}
+
+// Append implements EventLog
+func (l *MemoryEventLog) Append(event BookEvent) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events = append(l.events, event)
+}
+
+// Events returns a copy of all events in append order
+func (l *MemoryEventLog) Events() []BookEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]BookEvent(nil), l.events...)
+}
+
+// Scan implements EventScanner, calling fn for each event in append order.
+// Events are copied out in small batches so fn runs without the log locked
+// and appends are never held up by a slow reader.
+func (l *MemoryEventLog) Scan(fn func(BookEvent) error) error {
+	const batch = 256
+	buf := make([]BookEvent, 0, batch)
+	for next := 0; ; {
+		l.mu.Lock()
+		end := next + batch
+		if end > len(l.events) {
+			end = len(l.events)
+		}
+		buf = append(buf[:0], l.events[next:end]...)
+		l.mu.Unlock()
+		if len(buf) == 0 {
+			return nil
+		}
+		for _, ev := range buf {
+			if err := fn(ev); err != nil {
+				return err
+			}
+		}
+		next = end
+	}
+}
+
+// record stamps and appends an event if the book has a log
+func (b *OrderBook) record(event BookEvent) {
+	// Every operation records its own event before mutating the book, so
+	// this fixes a single timestamp for the operation and the trades it
+	// produces. Replays then reproduce trade timestamps exactly.
+	if event.Type != BookEventTrade {
+		b.opTime = b.clock()
+	}
+	if b.events == nil {
+		return
+	}
+	b.eventSeq++
+	event.Seq = b.eventSeq
+	event.Commodity = b.commodity
+	event.Timestamp = b.opTime
+	b.events.Append(event)
+}
+
+// Rebuild reconstructs a book by replaying the add, cancel, amend, reduce,
+// pause and auction events in log. Trades are re-derived by matching and
+// checked against the recorded trades, so any divergence from the original
+// book is an error. Matching options in opts are in force during the
+// replay, so the book must be rebuilt with the options it ran with. The
+// clock, event log, metrics, tick size and reference band take effect once
+// replay completes: the log already holds every tick size change and band
+// decision, and a WithEventLog option only receives mutations made after
+// the rebuild. By default a fresh memory log holding the replay is used.
+func Rebuild(log EventLog, opts ...BookOption) (*OrderBook, error) {
+	events := log.Events()
+	if len(events) == 0 {
+		return nil, fmt.Errorf("cannot rebuild from an empty event log")
+	}
+
+	configured := NewOrderBook(events[0].Commodity, opts...)
+	book := configured.replicaLocked()
+	var now time.Time
+	book.clock = func() time.Time { return now }
+	book.events = NewMemoryEventLog()
+	if err := replayEvents(book, &now, events); err != nil {
+		return nil, err
+	}
+
+	book.clock = configured.clock
+	book.metrics = configured.metrics
+	if configured.events != nil {
+		book.events = configured.events
+	}
+	book.tickSize, book.tickChange = configured.tickSize, configured.tickChange
+	book.refPrice, book.refBand, book.onPause = configured.refPrice, configured.refBand, configured.onPause
+	return book, nil
+}
+
+// SnapshotAt reconstructs the book's depth as it stood at t by replaying
+// its event log up to and including t into a book with the same matching
+// configuration. Before the first event the snapshot is empty; after the
+// last it matches the current book. It fails if the book has no event log.
+func (b *OrderBook) SnapshotAt(t time.Time) (BookSnapshot, error) {
+	b.mu.Lock()
+	log := b.events
+	replica := b.replicaLocked()
+	b.mu.Unlock()
+	if log == nil {
+		return BookSnapshot{}, fmt.Errorf("book %s has no event log", b.commodity)
+	}
+
+	events := log.Events()
+	end := 0
+	for end < len(events) && !events[end].Timestamp.After(t) {
+		end++
+	}
+	var now time.Time
+	replica.clock = func() time.Time { return now }
+	if err := replayEvents(replica, &now, events[:end]); err != nil {
+		return BookSnapshot{}, fmt.Errorf("snapshot at %s: %w", t.Format(time.RFC3339Nano), err)
+	}
+	return replica.Snapshot(), nil
+}
+
+// ProcessedOrders returns the IDs of every order the book's event log shows
+// it accepted or paused, whether it still rests, filled or was cancelled
+// since. Orders rejected before reaching the book are not logged and so are
+// not included. It fails if the book has no event log.
+func (b *OrderBook) ProcessedOrders() (map[string]bool, error) {
+	b.mu.Lock()
+	log := b.events
+	b.mu.Unlock()
+	if log == nil {
+		return nil, fmt.Errorf("book %s has no event log", b.commodity)
+	}
+
+	seen := make(map[string]bool)
+	note := func(ev BookEvent) error {
+		if (ev.Type == BookEventAdd || ev.Type == BookEventPause) && ev.Order != nil {
+			seen[ev.Order.OrderID] = true
+		}
+		return nil
+	}
+	if scanner, ok := log.(EventScanner); ok {
+		if err := scanner.Scan(note); err != nil {
+			return nil, err
+		}
+		return seen, nil
+	}
+	for _, ev := range log.Events() {
+		note(ev)
+	}
+	return seen, nil
+}
+
+// replicaLocked returns an empty book with b's matching configuration to
+// replay b's log into. The tick size and reference band are left out: the
+// log records tick size changes and band pauses, orders from before a tick
+// change may be off the current grid, and the reference price has moved on.
+func (b *OrderBook) replicaLocked() *OrderBook {
+	return &OrderBook{
+		commodity:       b.commodity,
+		orders:          make(map[string]*restingOrder),
+		metrics:         NoopMetrics{},
+		amendCross:      b.amendCross,
+		boostAfter:      b.boostAfter,
+		mocRemainder:    b.mocRemainder,
+		pegStep:         b.pegStep,
+		pegRetain:       b.pegRetain,
+		signedPrices:    b.signedPrices,
+		collarWidth:     b.collarWidth,
+		collarRemainder: b.collarRemainder,
+		proRata:         b.proRata,
+		proRataUnit:     b.proRataUnit,
+		lotSize:         b.lotSize,
+		lotResidual:     b.lotResidual,
+		jitter:          b.jitter,
+		jitterSeed:      b.jitterSeed,
+	}
+}
+
+// replayEvents applies events to book, setting *now to each event's time,
+// and checks the trades it produces against the recorded ones
+func replayEvents(book *OrderBook, now *time.Time, events []BookEvent) error {
+	var produced []Trade
+	var recorded []Trade
+	for _, ev := range events {
+		*now = ev.Timestamp
+		var trades []Trade
+		var err error
+		switch ev.Type {
+		case BookEventAdd:
+			trades, err = book.Add(*ev.Order)
+		case BookEventCancel:
+			// An IOC remainder is cancelled by replaying its add
+			if ev.Volume == 0 {
+				err = book.Cancel(ev.OrderID)
+			}
+		case BookEventAmend:
+			trades, err = book.Amend(ev.OrderID, ev.Price, ev.Volume)
+		case BookEventReduce:
+			err = book.ReduceQuantity(ev.OrderID, ev.Volume)
+		case BookEventTickSize:
+			book.mu.Lock()
+			_, err = book.setTickSizeLocked(ev.Price, false)
+			book.mu.Unlock()
+		case BookEventPause:
+			book.mu.Lock()
+			// A repriced peg or an amended order pauses from the book
+			if ro, ok := book.orders[ev.OrderID]; ok {
+				book.removeLocked(ro)
+			}
+			book.pauseLocked(PausedOrder{Order: *ev.Order, Reference: ev.Reference, Price: ev.Price})
+			book.mu.Unlock()
+		case BookEventRelease:
+			trades, err = book.ReleasePaused(ev.OrderID)
+		case BookEventAuctionStart:
+			book.StartAuction()
+		case BookEventUncross:
+			_, trades = book.Uncross()
+		case BookEventPegMarket:
+			trades = book.UpdatePegReference(ev.Bid, ev.Ask)
+		case BookEventCloseUncross:
+			_, trades, _ = book.UncrossClose()
+		case BookEventTrade:
+			recorded = append(recorded, *ev.Trade)
+		default:
+			err = fmt.Errorf("unknown event type %q", ev.Type)
+		}
+		if err != nil {
+			return fmt.Errorf("replay event %d (%s %s): %w", ev.Seq, ev.Type, ev.OrderID, err)
+		}
+		produced = append(produced, trades...)
+	}
+
+	if len(produced) != len(recorded) {
+		return fmt.Errorf("replay produced %d trades, log recorded %d", len(produced), len(recorded))
+	}
+	for i := range produced {
+		if produced[i] != recorded[i] {
+			return fmt.Errorf("replay trade %d diverged: got %+v, recorded %+v", i, produced[i], recorded[i])
+		}
+	}
+	return nil
+}
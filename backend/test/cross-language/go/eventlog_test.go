@@ -0,0 +1,220 @@
+package integration
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestRebuildFromEventLog verifies a rebuilt book matches the original state and future matching
+func TestRebuildFromEventLog(t *testing.T) {
+	clock := func() time.Time { return time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC) }
+	log := NewMemoryEventLog()
+	original := NewOrderBook("crude_oil", WithClock(clock), WithEventLog(log))
+
+	ops := []func() error{
+		func() error {
+			_, err := original.Add(TradingOrder{OrderID: "s1", Side: SideSell, Price: 75.60, Volume: 100})
+			return err
+		},
+		func() error {
+			_, err := original.Add(TradingOrder{OrderID: "s2", Side: SideSell, Price: 75.50, Volume: 40})
+			return err
+		},
+		func() error {
+			_, err := original.Add(TradingOrder{OrderID: "b1", Side: SideBuy, Price: 75.40, Volume: 60})
+			return err
+		},
+		func() error {
+			_, err := original.Add(TradingOrder{OrderID: "b2", Side: SideBuy, Price: 75.30, Volume: 10, TimeInForce: TimeInForceDay})
+			return err
+		},
+		func() error {
+			_, err := original.Add(TradingOrder{OrderID: "b3", Side: SideBuy, Price: 75.55, Volume: 50})
+			return err
+		},
+		func() error { return original.Cancel("b1") },
+		func() error { _, err := original.Amend("s1", 75.45, 80); return err },
+		func() error { original.ExpireOrders(TimeInForceDay); return nil },
+		func() error {
+			_, err := original.Add(TradingOrder{OrderID: "b4", Side: SideBuy, Price: 75.45, Volume: 30})
+			return err
+		},
+	}
+	for i, op := range ops {
+		if err := op(); err != nil {
+			t.Fatalf("Operation %d failed: %v", i, err)
+		}
+	}
+
+	rebuilt, err := Rebuild(log, WithClock(clock))
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+
+	if want, got := original.Snapshot(), rebuilt.Snapshot(); !reflect.DeepEqual(want.Bids, got.Bids) || !reflect.DeepEqual(want.Asks, got.Asks) {
+		t.Fatalf("Expected depth %+v, got %+v", want, got)
+	}
+	for _, id := range []string{"s1", "s2", "b1", "b2", "b3", "b4"} {
+		wantOrder, wantOK := original.Order(id)
+		gotOrder, gotOK := rebuilt.Order(id)
+		if wantOK != gotOK || wantOrder != gotOrder {
+			t.Errorf("Order %s: expected %+v (%v), got %+v (%v)", id, wantOrder, wantOK, gotOrder, gotOK)
+		}
+	}
+
+	// The same aggressive order must produce identical trades on both books.
+	sweep := TradingOrder{OrderID: "sweep", Side: SideBuy, Type: OrderTypeMarket, Volume: 500}
+	wantTrades, _ := original.Add(sweep)
+	gotTrades, _ := rebuilt.Add(sweep)
+	if !reflect.DeepEqual(wantTrades, gotTrades) {
+		t.Errorf("Expected identical matching:\n want %+v\n got  %+v", wantTrades, gotTrades)
+	}
+}
+
+// TestRebuildDetectsTamperedLog verifies replay divergence is reported
+func TestRebuildDetectsTamperedLog(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log))
+	book.Add(TradingOrder{OrderID: "s1", Side: SideSell, Price: 75, Volume: 10})
+	book.Add(TradingOrder{OrderID: "b1", Side: SideBuy, Price: 75, Volume: 10})
+
+	events := log.Events()
+	tampered := NewMemoryEventLog()
+	for _, ev := range events {
+		if ev.Type == BookEventTrade {
+			trade := *ev.Trade
+			trade.Volume = 5
+			ev.Trade = &trade
+		}
+		tampered.Append(ev)
+	}
+	if _, err := Rebuild(tampered); err == nil {
+		t.Error("Expected rebuild to detect a diverging trade")
+	}
+}
+
+// TestSnapshotAtReconstructsHistory verifies depth at a mid-sequence instant, before the first event and after the last
+func TestSnapshotAtReconstructsHistory(t *testing.T) {
+	start := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	now := start
+	book := NewOrderBook("crude_oil", WithClock(func() time.Time { return now }), WithEventLog(NewMemoryEventLog()))
+
+	ops := []func() error{
+		func() error {
+			_, err := book.Add(TradingOrder{OrderID: "s1", Side: SideSell, Price: 75.60, Volume: 100})
+			return err
+		},
+		func() error {
+			_, err := book.Add(TradingOrder{OrderID: "b1", Side: SideBuy, Price: 75.40, Volume: 50})
+			return err
+		},
+		func() error {
+			_, err := book.Add(TradingOrder{OrderID: "b2", Side: SideBuy, Price: 75.60, Volume: 30})
+			return err
+		},
+		func() error { return book.Cancel("b1") },
+		func() error {
+			_, err := book.Amend("s1", 75.70, 40)
+			return err
+		},
+	}
+	for i, op := range ops {
+		now = start.Add(time.Duration(i) * time.Minute)
+		if err := op(); err != nil {
+			t.Fatalf("Op %d failed: %v", i, err)
+		}
+	}
+
+	// Just after b2 traded 30 against s1 and before b1 was cancelled
+	mid, err := book.SnapshotAt(start.Add(2*time.Minute + 30*time.Second))
+	if err != nil {
+		t.Fatalf("SnapshotAt failed: %v", err)
+	}
+	wantBids := []PriceLevel{{Price: 75.40, Volume: 50, Orders: 1}}
+	wantAsks := []PriceLevel{{Price: 75.60, Volume: 70, Orders: 1}}
+	if !reflect.DeepEqual(mid.Bids, wantBids) || !reflect.DeepEqual(mid.Asks, wantAsks) {
+		t.Errorf("Expected bids %v asks %v mid-sequence, got %v %v", wantBids, wantAsks, mid.Bids, mid.Asks)
+	}
+
+	// The instant of an event includes it
+	if at, _ := book.SnapshotAt(start); len(at.Asks) != 1 || len(at.Bids) != 0 {
+		t.Errorf("Expected only s1 at the first event, got %+v", at)
+	}
+
+	before, err := book.SnapshotAt(start.Add(-time.Second))
+	if err != nil || len(before.Bids) != 0 || len(before.Asks) != 0 || before.Commodity != "crude_oil" {
+		t.Errorf("Expected an empty book before the first event, got %+v, %v", before, err)
+	}
+
+	after, err := book.SnapshotAt(start.Add(time.Hour))
+	current := book.Snapshot()
+	if err != nil || !reflect.DeepEqual(after.Bids, current.Bids) || !reflect.DeepEqual(after.Asks, current.Asks) {
+		t.Errorf("Expected the current book after the last event, got %+v want %+v (%v)", after, current, err)
+	}
+
+	if _, err := NewOrderBook("crude_oil").SnapshotAt(start); err == nil {
+		t.Error("Expected an error for a book without an event log")
+	}
+}
+
+// TestRebuildReplaysUnderMatchingOptions verifies a book run with each
+// matching option rebuilds from its log when given the same option
+func TestRebuildReplaysUnderMatchingOptions(t *testing.T) {
+	for name, opt := range map[string]BookOption{
+		"lot_size":       WithLotSize(10, LotResidualCancel),
+		"pro_rata":       WithProRata(1),
+		"priority_boost": WithPriorityBoost(5 * time.Second),
+		"market_collar":  WithMarketCollar(0.3, CollarRemainderRest),
+		"iceberg_jitter": WithIcebergJitter(0.4, 7),
+		"amend_cross":    WithAmendCross(AmendCrossReject),
+	} {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+			clock := func() time.Time { return now }
+			log := NewMemoryEventLog()
+			original := NewOrderBook("crude_oil", WithClock(clock), WithEventLog(log), opt)
+
+			rng := rand.New(rand.NewSource(3))
+			var trades int
+			for i := 0; i < 300; i++ {
+				now = now.Add(time.Second)
+				side := SideBuy
+				if rng.Intn(2) == 0 {
+					side = SideSell
+				}
+				id := fmt.Sprintf("o%d", i)
+				price := 74.5 + float64(rng.Intn(11))*0.1
+				switch n := rng.Intn(10); {
+				case n < 6:
+					order := TradingOrder{OrderID: id, Side: side, Price: price, Volume: float64(10 * (1 + rng.Intn(5)))}
+					if rng.Intn(4) == 0 {
+						order.Volume *= 4
+						order.DisplayVolume = 20
+					}
+					fills, _ := original.Add(order)
+					trades += len(fills)
+				case n < 8:
+					fills, _ := original.Add(TradingOrder{OrderID: id, Side: side, Type: OrderTypeMarket, Volume: float64(5 + rng.Intn(60))})
+					trades += len(fills)
+				default:
+					fills, _ := original.Amend(fmt.Sprintf("o%d", rng.Intn(i+1)), price, float64(10*(1+rng.Intn(5))))
+					trades += len(fills)
+				}
+			}
+			if trades == 0 {
+				t.Fatal("Expected the session to trade")
+			}
+
+			rebuilt, err := Rebuild(log, WithClock(clock), opt)
+			if err != nil {
+				t.Fatalf("Expected rebuild under the same option to succeed, got %v", err)
+			}
+			if want, got := original.Snapshot(), rebuilt.Snapshot(); !reflect.DeepEqual(want.Bids, got.Bids) || !reflect.DeepEqual(want.Asks, got.Asks) {
+				t.Errorf("Expected rebuilt depth %+v, got %+v", want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"sync"
+)
+
+// ExchangeRoute lists the venues a commodity trades on and the preferred one
+type ExchangeRoute struct {
+	Default   string   `json:"default"`
+	Exchanges []string `json:"exchanges"`
+}
+
+// ExchangeMap maps commodities to their venues. It is safe to update at runtime.
+type ExchangeMap struct {
+	mu     sync.RWMutex
+	routes map[string]ExchangeRoute
+}
+
+// NewExchangeMap creates a map from routes, validating each one
+func NewExchangeMap(routes map[string]ExchangeRoute) (*ExchangeMap, error) {
+	m := &ExchangeMap{routes: make(map[string]ExchangeRoute, len(routes))}
+	for commodity, route := range routes {
+		if err := m.Set(commodity, route); err != nil {
+			return nil, err
+		}
+	}
+	return m, nil
+}
+
+// LoadExchangeMap reads a JSON exchange map file
+func LoadExchangeMap(path string) (*ExchangeMap, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open exchange map: %w", err)
+	}
+	defer f.Close()
+	return ParseExchangeMapJSON(f)
+}
+
+// ParseExchangeMapJSON parses {"crude_oil": {"default": "NYMEX", "exchanges": ["NYMEX", "ICE"]}}
+func ParseExchangeMapJSON(r io.Reader) (*ExchangeMap, error) {
+	var routes map[string]ExchangeRoute
+	if err := json.NewDecoder(r).Decode(&routes); err != nil {
+		return nil, fmt.Errorf("decode exchange map json: %w", err)
+	}
+	return NewExchangeMap(routes)
+}
+
+// Set adds or replaces a commodity's route. The default is added to the
+// supported exchanges if missing.
+func (m *ExchangeMap) Set(commodity string, route ExchangeRoute) error {
+	if commodity == "" || route.Default == "" {
+		return fmt.Errorf("exchange map: commodity and default exchange are required")
+	}
+	exchanges := []string{route.Default}
+	for _, ex := range route.Exchanges {
+		if ex != route.Default {
+			exchanges = append(exchanges, ex)
+		}
+	}
+	sort.Strings(exchanges[1:])
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.routes[commodity] = ExchangeRoute{Default: route.Default, Exchanges: exchanges}
+	return nil
+}
+
+// Remove drops a commodity's route
+func (m *ExchangeMap) Remove(commodity string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.routes, commodity)
+}
+
+// DefaultExchange returns the preferred venue for a commodity
+func (m *ExchangeMap) DefaultExchange(commodity string) (string, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	route, ok := m.routes[commodity]
+	if !ok {
+		return "", fmt.Errorf("%w: no exchange mapping for %s", ErrUnknownCommodity, commodity)
+	}
+	return route.Default, nil
+}
+
+// SupportedExchanges returns the venues for a commodity, default first;
+// unknown commodities have none
+func (m *ExchangeMap) SupportedExchanges(commodity string) []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return append([]string(nil), m.routes[commodity].Exchanges...)
+}
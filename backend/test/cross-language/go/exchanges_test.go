@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const exchangeMapJSON = `{
+	"crude_oil": {"default": "NYMEX", "exchanges": ["ICE", "NYMEX", "DME"]},
+	"natural_gas": {"default": "NYMEX"}
+}`
+
+// TestExchangeMapMappedCommodity verifies defaults, supported venues and runtime updates
+func TestExchangeMapMappedCommodity(t *testing.T) {
+	venues, err := ParseExchangeMapJSON(strings.NewReader(exchangeMapJSON))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if def, err := venues.DefaultExchange("crude_oil"); err != nil || def != "NYMEX" {
+		t.Errorf("Expected NYMEX default, got %q, %v", def, err)
+	}
+	if got := venues.SupportedExchanges("crude_oil"); !reflect.DeepEqual(got, []string{"NYMEX", "DME", "ICE"}) {
+		t.Errorf("Expected default first then sorted venues, got %v", got)
+	}
+
+	if err := venues.Set("natural_gas", ExchangeRoute{Default: "ICE", Exchanges: []string{"NYMEX"}}); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if def, _ := venues.DefaultExchange("natural_gas"); def != "ICE" {
+		t.Errorf("Expected the runtime update to take effect, got %q", def)
+	}
+
+	// The router sends unspecified orders to mapped venues and honours an explicit one
+	quotes := fakeConsolidatedQuotes{"crude_oil": {
+		{Venue: "NYMEX", Ask: 75.60, AskSize: 100},
+		{Venue: "CME_DARK", Ask: 75.50, AskSize: 100},
+		{Venue: "ICE", Ask: 75.65, AskSize: 100},
+	}}
+	router := NewLatencyRouter(quotes, LatencyRouterConfig{}, func() time.Time { return time.Time{} })
+	router.SetExchangeMap(venues)
+	if route, _ := router.Route(TradingOrder{Commodity: "crude_oil", Side: SideBuy}); route.Venue != "NYMEX" {
+		t.Errorf("Expected the best mapped venue NYMEX, got %+v", route)
+	}
+	if route, _ := router.Route(TradingOrder{Commodity: "crude_oil", Side: SideBuy, Exchange: "ICE"}); route.Venue != "ICE" {
+		t.Errorf("Expected the explicit venue ICE, got %+v", route)
+	}
+	if route, err := router.Route(TradingOrder{Commodity: "natural_gas", Side: SideBuy}); err != nil || route.Venue != "ICE" {
+		t.Errorf("Expected an unquoted commodity to fall back to its default, got %+v, %v", route, err)
+	}
+}
+
+// TestExchangeMapUnknownCommodity verifies unmapped commodities error
+func TestExchangeMapUnknownCommodity(t *testing.T) {
+	venues, err := NewExchangeMap(map[string]ExchangeRoute{"crude_oil": {Default: "NYMEX"}})
+	if err != nil {
+		t.Fatalf("NewExchangeMap failed: %v", err)
+	}
+	if _, err := venues.DefaultExchange("power"); !errors.Is(err, ErrUnknownCommodity) {
+		t.Errorf("Expected ErrUnknownCommodity, got %v", err)
+	}
+	if got := venues.SupportedExchanges("power"); len(got) != 0 {
+		t.Errorf("Expected no venues for an unknown commodity, got %v", got)
+	}
+
+	router := NewLatencyRouter(fakeConsolidatedQuotes{}, LatencyRouterConfig{}, nil)
+	router.SetExchangeMap(venues)
+	if _, err := router.Route(TradingOrder{Commodity: "power", Side: SideBuy}); !errors.Is(err, ErrUnknownCommodity) {
+		t.Errorf("Expected the router to reject an unmapped commodity, got %v", err)
+	}
+	if _, err := NewExchangeMap(map[string]ExchangeRoute{"gasoline": {}}); err == nil {
+		t.Error("Expected a route without a default to be rejected")
+	}
+}
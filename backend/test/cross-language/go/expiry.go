@@ -0,0 +1,150 @@
+package integration
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// FuturesContract is one listed delivery month of a commodity
+type FuturesContract struct {
+	Symbol    string    `json:"symbol"`
+	Commodity string    `json:"commodity"`
+	Expiry    time.Time `json:"expiry"`
+}
+
+// PhysicalSettlement flags a position left open in an expired contract
+type PhysicalSettlement struct {
+	ClientID string    `json:"client_id"`
+	Symbol   string    `json:"symbol"`
+	Volume   float64   `json:"volume"`
+	Expiry   time.Time `json:"expiry"`
+}
+
+// ExpiryReport is the outcome of one ExpiryManager tick
+type ExpiryReport struct {
+	Rolls    []TradingOrder       `json:"rolls"`
+	Physical []PhysicalSettlement `json:"physical"`
+}
+
+// ExpiryManager rolls positions out of contracts nearing expiry. Positions
+// are tracked per contract symbol in a PositionTracker. Within a commodity's
+// roll window each open position gets a market order closing the expiring
+// contract and one opening the same exposure in the next listed contract.
+// Positions still open once a contract expires are flagged for physical
+// settlement. Commodities without a roll window are never rolled.
+type ExpiryManager struct {
+	mu        sync.Mutex
+	positions *PositionTracker
+	rollDays  map[string]int // commodity -> days before expiry to roll
+	contracts map[string]FuturesContract
+	rolled    map[string]bool // symbol/client
+	flagged   map[string]bool // symbol/client
+}
+
+// NewExpiryManager creates a manager with per-commodity roll windows in days
+func NewExpiryManager(positions *PositionTracker, rollDays map[string]int) *ExpiryManager {
+	return &ExpiryManager{
+		positions: positions,
+		rollDays:  rollDays,
+		contracts: make(map[string]FuturesContract),
+		rolled:    make(map[string]bool),
+		flagged:   make(map[string]bool),
+	}
+}
+
+// AddContract lists a contract
+func (m *ExpiryManager) AddContract(contract FuturesContract) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.contracts[contract.Symbol] = contract
+}
+
+// Next returns the contract following symbol in the same commodity
+func (m *ExpiryManager) Next(symbol string) (FuturesContract, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.nextLocked(m.contracts[symbol])
+}
+
+// Tick generates roll orders and settlement flags due at now. Each client
+// position is rolled or flagged at most once per contract.
+func (m *ExpiryManager) Tick(now time.Time) ExpiryReport {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var report ExpiryReport
+	for _, contract := range m.sortedContractsLocked() {
+		for _, clientID := range m.positions.Clients() {
+			pos, ok := m.positions.Position(clientID, contract.Symbol)
+			if !ok || (pos.Volume > -volumeEpsilon && pos.Volume < volumeEpsilon) {
+				continue
+			}
+			key := contract.Symbol + "/" + clientID
+
+			if !now.Before(contract.Expiry) {
+				if !m.flagged[key] {
+					m.flagged[key] = true
+					report.Physical = append(report.Physical, PhysicalSettlement{
+						ClientID: clientID, Symbol: contract.Symbol, Volume: pos.Volume, Expiry: contract.Expiry,
+					})
+				}
+				continue
+			}
+
+			days, ok := m.rollDays[contract.Commodity]
+			if !ok || m.rolled[key] || now.Before(contract.Expiry.AddDate(0, 0, -days)) {
+				continue
+			}
+			next, ok := m.nextLocked(contract)
+			if !ok {
+				continue
+			}
+			m.rolled[key] = true
+			report.Rolls = append(report.Rolls, rollOrders(clientID, contract, next, pos.Volume, now)...)
+		}
+	}
+	return report
+}
+
+// rollOrders closes volume in from and opens it in to
+func rollOrders(clientID string, from, to FuturesContract, volume float64, now time.Time) []TradingOrder {
+	closeSide, openSide := SideSell, SideBuy
+	if volume < 0 {
+		closeSide, openSide = SideBuy, SideSell
+		volume = -volume
+	}
+	prefix := "roll-" + clientID + "-" + from.Symbol
+	return []TradingOrder{
+		{OrderID: prefix + "-close", ClientID: clientID, Commodity: from.Symbol, Volume: volume, Side: closeSide, Type: OrderTypeMarket, Timestamp: now},
+		{OrderID: prefix + "-open", ClientID: clientID, Commodity: to.Symbol, Volume: volume, Side: openSide, Type: OrderTypeMarket, Timestamp: now},
+	}
+}
+
+func (m *ExpiryManager) nextLocked(contract FuturesContract) (FuturesContract, bool) {
+	var next FuturesContract
+	found := false
+	for _, c := range m.contracts {
+		if c.Commodity != contract.Commodity || !c.Expiry.After(contract.Expiry) {
+			continue
+		}
+		if !found || c.Expiry.Before(next.Expiry) {
+			next, found = c, true
+		}
+	}
+	return next, found
+}
+
+func (m *ExpiryManager) sortedContractsLocked() []FuturesContract {
+	contracts := make([]FuturesContract, 0, len(m.contracts))
+	for _, c := range m.contracts {
+		contracts = append(contracts, c)
+	}
+	sort.Slice(contracts, func(i, j int) bool {
+		if !contracts[i].Expiry.Equal(contracts[j].Expiry) {
+			return contracts[i].Expiry.Before(contracts[j].Expiry)
+		}
+		return contracts[i].Symbol < contracts[j].Symbol
+	})
+	return contracts
+}
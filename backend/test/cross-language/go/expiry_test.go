@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// TestExpiryManagerRollsAndFlags verifies roll orders near expiry and physical settlement after it
+func TestExpiryManagerRollsAndFlags(t *testing.T) {
+	positions := NewPositionTracker()
+	positions.ApplyFill("acme", "CLF24", SideBuy, 50, 75)
+	positions.ApplyFill("gulf", "CLF24", SideSell, 20, 75)
+	positions.ApplyFill("acme", "NGF24", SideBuy, 10, 2.5)
+
+	manager := NewExpiryManager(positions, map[string]int{"crude_oil": 3})
+	manager.AddContract(FuturesContract{Symbol: "CLF24", Commodity: "crude_oil", Expiry: time.Date(2024, 1, 19, 19, 30, 0, 0, time.UTC)})
+	manager.AddContract(FuturesContract{Symbol: "CLH24", Commodity: "crude_oil", Expiry: time.Date(2024, 3, 19, 19, 30, 0, 0, time.UTC)})
+	manager.AddContract(FuturesContract{Symbol: "CLG24", Commodity: "crude_oil", Expiry: time.Date(2024, 2, 20, 19, 30, 0, 0, time.UTC)})
+	manager.AddContract(FuturesContract{Symbol: "NGF24", Commodity: "natural_gas", Expiry: time.Date(2024, 1, 26, 19, 30, 0, 0, time.UTC)})
+
+	now := time.Date(2024, 1, 15, 14, 0, 0, 0, time.UTC)
+	if report := manager.Tick(now); len(report.Rolls) != 0 {
+		t.Fatalf("Expected no rolls before the window, got %+v", report.Rolls)
+	}
+
+	now = time.Date(2024, 1, 16, 20, 0, 0, 0, time.UTC)
+	rolls := manager.Tick(now).Rolls
+	if len(rolls) != 4 {
+		t.Fatalf("Expected close/open pairs for acme and gulf, got %+v", rolls)
+	}
+	expected := []struct {
+		id, symbol, side string
+		volume           float64
+	}{
+		{"roll-acme-CLF24-close", "CLF24", SideSell, 50},
+		{"roll-acme-CLF24-open", "CLG24", SideBuy, 50},
+		{"roll-gulf-CLF24-close", "CLF24", SideBuy, 20},
+		{"roll-gulf-CLF24-open", "CLG24", SideSell, 20},
+	}
+	for i, e := range expected {
+		o := rolls[i]
+		if o.OrderID != e.id || o.Commodity != e.symbol || o.Side != e.side || o.Volume != e.volume || o.ClientID == "" {
+			t.Errorf("Roll %d: expected %+v, got %+v", i, e, o)
+		}
+	}
+	if again := manager.Tick(now.Add(time.Hour)); len(again.Rolls) != 0 {
+		t.Errorf("Expected rolls to be generated once, got %+v", again.Rolls)
+	}
+
+	// acme's roll fills; gulf's does not
+	positions.ApplyFill("acme", "CLF24", SideSell, 50, 76)
+	positions.ApplyFill("acme", "CLG24", SideBuy, 50, 76.2)
+
+	now = time.Date(2024, 1, 27, 0, 0, 0, 0, time.UTC)
+	physical := manager.Tick(now).Physical
+	if len(physical) != 2 {
+		t.Fatalf("Expected gulf CLF24 and acme NGF24 flagged, got %+v", physical)
+	}
+	if p := physical[0]; p.ClientID != "gulf" || p.Symbol != "CLF24" || p.Volume != -20 {
+		t.Errorf("Expected gulf's unrolled short flagged, got %+v", p)
+	}
+	if p := physical[1]; p.ClientID != "acme" || p.Symbol != "NGF24" {
+		t.Errorf("Expected acme's gas position without a roll window flagged, got %+v", p)
+	}
+}
@@ -0,0 +1,205 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// failoverBacklog caps how many ticks are held per standby source
+const failoverBacklog = 256
+
+// FailoverSource wraps a primary feed and ordered backups. When the active
+// feed has been silent longer than the staleness threshold, the first
+// backup that has delivered since the active feed went quiet is promoted; as soon as the primary
+// delivers again it is reinstated. Standby ticks are held briefly so the
+// promoted feed can cover the gap. Ticks older than the last one emitted
+// are skipped. At the latest timestamp a tick is a duplicate only if
+// another feed already delivered the same price and volume there, each
+// delivered tick covering one repeat per feed; a feed's own ticks are never
+// collapsed, so identical prints on one feed all pass. Consumers see no
+// duplicates during overlap and lose no volume to look-alike trades.
+type FailoverSource struct {
+	sources   []MarketDataSource
+	staleness time.Duration
+
+	mu     sync.Mutex
+	active int
+}
+
+// NewFailoverSource creates a failover over primary and backups in priority order
+func NewFailoverSource(staleness time.Duration, primary MarketDataSource, backups ...MarketDataSource) *FailoverSource {
+	return &FailoverSource{sources: append([]MarketDataSource{primary}, backups...), staleness: staleness}
+}
+
+// Name identifies the source by its primary
+func (f *FailoverSource) Name() string {
+	return "failover(" + f.sources[0].Name() + ")"
+}
+
+// Active returns the name of the source currently being passed through
+func (f *FailoverSource) Active() string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.sources[f.active].Name()
+}
+
+// Fetch serves history from the first source that answers
+func (f *FailoverSource) Fetch(ctx context.Context, commodity string, from, to time.Time) ([]MarketData, error) {
+	var lastErr error
+	for _, src := range f.sources {
+		ticks, err := src.Fetch(ctx, commodity, from, to)
+		if err == nil {
+			return ticks, nil
+		}
+		lastErr = fmt.Errorf("%s: %w", src.Name(), err)
+	}
+	return nil, lastErr
+}
+
+type sourcedTick struct {
+	source int
+	tick   MarketData
+}
+
+// deliveredTick is a tick emitted at the latest timestamp and the feeds
+// whose copy of it has been accounted for
+type deliveredTick struct {
+	tick    MarketData
+	covered map[int]bool
+}
+
+// Subscribe subscribes to every source and streams the active one. The
+// returned channel closes when ctx is done. Only the primary must subscribe
+// successfully; failed backups are skipped.
+func (f *FailoverSource) Subscribe(ctx context.Context, commodity string) (<-chan MarketData, error) {
+	merged := make(chan sourcedTick)
+	for i, src := range f.sources {
+		ch, err := src.Subscribe(ctx, commodity)
+		if err != nil {
+			if i == 0 {
+				return nil, fmt.Errorf("subscribe primary %s: %w", src.Name(), err)
+			}
+			continue
+		}
+		go func(i int, ch <-chan MarketData) {
+			for tick := range ch {
+				select {
+				case merged <- sourcedTick{source: i, tick: tick}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(i, ch)
+	}
+
+	out := make(chan MarketData)
+	go f.run(ctx, merged, out)
+	return out, nil
+}
+
+func (f *FailoverSource) run(ctx context.Context, merged <-chan sourcedTick, out chan<- MarketData) {
+	defer close(out)
+
+	now := time.Now()
+	lastSeen := make([]time.Time, len(f.sources))
+	for i := range lastSeen {
+		lastSeen[i] = now
+	}
+	standby := make([][]MarketData, len(f.sources))
+	var lastEmitted time.Time
+	var atLast []deliveredTick // ticks emitted at lastEmitted
+
+	// delivered reports whether source's tick was already emitted, marking
+	// the delivered copy it repeats as covered for source
+	delivered := func(source int, tick MarketData) bool {
+		if tick.Timestamp.Before(lastEmitted) {
+			return true
+		}
+		if tick.Timestamp.After(lastEmitted) {
+			return false
+		}
+		for _, prev := range atLast {
+			if !prev.covered[source] && prev.tick.Price == tick.Price && prev.tick.Volume == tick.Volume {
+				prev.covered[source] = true
+				return true
+			}
+		}
+		return false
+	}
+	emit := func(source int, tick MarketData) bool {
+		if delivered(source, tick) {
+			return true // already delivered by another source
+		}
+		select {
+		case out <- tick:
+		case <-ctx.Done():
+			return false
+		}
+		if tick.Timestamp.After(lastEmitted) {
+			lastEmitted = tick.Timestamp
+			atLast = atLast[:0]
+		}
+		atLast = append(atLast, deliveredTick{tick: tick, covered: map[int]bool{source: true}})
+		return true
+	}
+	activate := func(i int) bool {
+		f.mu.Lock()
+		f.active = i
+		f.mu.Unlock()
+		backlog := standby[i]
+		standby[i] = nil
+		for _, tick := range backlog {
+			if !emit(i, tick) {
+				return false
+			}
+		}
+		return true
+	}
+
+	ticker := time.NewTicker(f.staleness / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case st := <-merged:
+			lastSeen[st.source] = time.Now()
+			f.mu.Lock()
+			active := f.active
+			f.mu.Unlock()
+			if st.source == 0 && active != 0 {
+				if !activate(0) {
+					return
+				}
+				active = 0
+			}
+			if st.source != active {
+				if len(standby[st.source]) == failoverBacklog {
+					standby[st.source] = standby[st.source][1:]
+				}
+				standby[st.source] = append(standby[st.source], st.tick)
+				continue
+			}
+			if !emit(st.source, st.tick) {
+				return
+			}
+		case now := <-ticker.C:
+			f.mu.Lock()
+			active := f.active
+			f.mu.Unlock()
+			if now.Sub(lastSeen[active]) <= f.staleness {
+				continue
+			}
+			for i := range f.sources {
+				if i != active && lastSeen[i].After(lastSeen[active]) {
+					if !activate(i) {
+						return
+					}
+					break
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// chanMarketDataSource streams whatever the test pushes onto its channel
+type chanMarketDataSource struct {
+	name  string
+	ticks chan MarketData
+}
+
+func (c *chanMarketDataSource) Name() string { return c.name }
+
+func (c *chanMarketDataSource) Subscribe(ctx context.Context, commodity string) (<-chan MarketData, error) {
+	return c.ticks, nil
+}
+
+func (c *chanMarketDataSource) Fetch(ctx context.Context, commodity string, from, to time.Time) ([]MarketData, error) {
+	return nil, nil
+}
+
+func receiveTicks(t *testing.T, ch <-chan MarketData, n int) []MarketData {
+	t.Helper()
+	var got []MarketData
+	timeout := time.After(2 * time.Second)
+	for len(got) < n {
+		select {
+		case tick := <-ch:
+			got = append(got, tick)
+		case <-timeout:
+			t.Fatalf("Timed out after %d of %d ticks", len(got), n)
+		}
+	}
+	return got
+}
+
+// TestFailoverSourcePromotesBackupWithoutDuplicates verifies the backup covers a silent primary and hands back
+func TestFailoverSourcePromotesBackupWithoutDuplicates(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	primary := &chanMarketDataSource{name: "nymex", ticks: make(chan MarketData, 32)}
+	backup := &chanMarketDataSource{name: "ice", ticks: make(chan MarketData, 32)}
+	failover := NewFailoverSource(40*time.Millisecond, primary, backup)
+	out, err := failover.Subscribe(ctx, "crude_oil")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	ticks := recordedTicks(15, time.Second)
+	for _, tick := range ticks[:5] {
+		primary.ticks <- tick
+		backup.ticks <- tick
+	}
+	got := receiveTicks(t, out, 5)
+
+	// Primary goes silent; the backup keeps publishing
+	for _, tick := range ticks[5:10] {
+		backup.ticks <- tick
+	}
+	got = append(got, receiveTicks(t, out, 5)...)
+	if active := failover.Active(); active != "ice" {
+		t.Errorf("Expected backup to be active, got %s", active)
+	}
+
+	// Primary recovers from the last tick it missed, overlapping the backup
+	for i, tick := range ticks[9:15] {
+		primary.ticks <- tick
+		if i > 0 {
+			backup.ticks <- tick
+		}
+	}
+	got = append(got, receiveTicks(t, out, 5)...)
+	if active := failover.Active(); active != "nymex" {
+		t.Errorf("Expected primary reinstated, got %s", active)
+	}
+
+	for i, tick := range got {
+		if !tick.Timestamp.Equal(ticks[i].Timestamp) {
+			t.Fatalf("Tick %d: expected %v, got %v (duplicate or gap)", i, ticks[i].Timestamp, tick.Timestamp)
+		}
+	}
+	select {
+	case extra := <-out:
+		t.Errorf("Expected no duplicate ticks, got extra %+v", extra)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestFailoverSourcePassesSameTimestampTicks verifies distinct ticks sharing a timestamp all pass, and that after a
+// switch only the ones not already delivered are taken from the new feed
+func TestFailoverSourcePassesSameTimestampTicks(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	primary := &chanMarketDataSource{name: "nymex", ticks: make(chan MarketData, 32)}
+	backup := &chanMarketDataSource{name: "ice", ticks: make(chan MarketData, 32)}
+	failover := NewFailoverSource(40*time.Millisecond, primary, backup)
+	out, err := failover.Subscribe(ctx, "crude_oil")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	// Three trades print in the same millisecond
+	at := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	burst := []MarketData{
+		{Commodity: "crude_oil", Price: 75.00, Volume: 100, Timestamp: at},
+		{Commodity: "crude_oil", Price: 75.01, Volume: 50, Timestamp: at},
+		{Commodity: "crude_oil", Price: 75.02, Volume: 20, Timestamp: at},
+	}
+	for _, tick := range burst[:2] {
+		primary.ticks <- tick
+	}
+	got := receiveTicks(t, out, 2)
+	if got[0].Price != 75.00 || got[1].Price != 75.01 {
+		t.Fatalf("Expected both same-timestamp ticks from the active feed, got %+v", got)
+	}
+
+	// The primary dies before the third; the backup carries the whole burst
+	for _, tick := range burst {
+		backup.ticks <- tick
+	}
+	next := MarketData{Commodity: "crude_oil", Price: 75.03, Volume: 10, Timestamp: at.Add(time.Second)}
+	backup.ticks <- next
+	got = receiveTicks(t, out, 2)
+	if got[0].Price != 75.02 || got[1] != next {
+		t.Errorf("Expected only the undelivered 75.02 then the next tick from the backup, got %+v", got)
+	}
+	select {
+	case extra := <-out:
+		t.Errorf("Expected no duplicate ticks, got extra %+v", extra)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestFailoverSourceKeepsIdenticalPrints verifies two genuine identical
+// prints at one timestamp both pass, while a backup's copies of them are
+// still dropped after a switch
+func TestFailoverSourceKeepsIdenticalPrints(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	primary := &chanMarketDataSource{name: "nymex", ticks: make(chan MarketData, 32)}
+	backup := &chanMarketDataSource{name: "ice", ticks: make(chan MarketData, 32)}
+	failover := NewFailoverSource(40*time.Millisecond, primary, backup)
+	out, err := failover.Subscribe(ctx, "crude_oil")
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+
+	at := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	print := MarketData{Commodity: "crude_oil", Price: 75.00, Volume: 100, Timestamp: at}
+	primary.ticks <- print
+	primary.ticks <- print
+	if got := receiveTicks(t, out, 2); got[0] != print || got[1] != print {
+		t.Fatalf("Expected both identical prints, got %+v", got)
+	}
+
+	// The backup repeats both and a third the primary never sent
+	for i := 0; i < 3; i++ {
+		backup.ticks <- print
+	}
+	if got := receiveTicks(t, out, 1); got[0] != print {
+		t.Errorf("Expected only the third print from the backup, got %+v", got)
+	}
+	select {
+	case extra := <-out:
+		t.Errorf("Expected no duplicate ticks, got extra %+v", extra)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
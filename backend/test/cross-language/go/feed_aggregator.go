@@ -0,0 +1,225 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Feed aggregation policies
+const (
+	AggregateLatest         = "latest"
+	AggregateMedian         = "median"
+	AggregateVolumeWeighted = "volume_weighted"
+)
+
+// consolidatedExchange labels ticks produced by the aggregator
+const consolidatedExchange = "consolidated"
+
+// FeedAggregatorConfig configures how vendor feeds are combined
+type FeedAggregatorConfig struct {
+	Commodity      string
+	Policy         string
+	Staleness      time.Duration // sources quiet for longer are excluded
+	MaxDiscrepancy float64       // fractional spread between sources that raises an alert
+	MinDiscrepancy float64       // absolute spread sources may always differ by, for prices near zero
+	OnDiscrepancy  func(Alert)
+	Clock          func() time.Time
+}
+
+// FeedAggregator consolidates ticks for one commodity from several sources
+type FeedAggregator struct {
+	sources []MarketDataSource
+	config  FeedAggregatorConfig
+
+	mu     sync.Mutex
+	latest map[string]MarketData // by source name
+}
+
+// NewFeedAggregator creates an aggregator over the given sources
+func NewFeedAggregator(config FeedAggregatorConfig, sources ...MarketDataSource) (*FeedAggregator, error) {
+	switch config.Policy {
+	case AggregateLatest, AggregateMedian, AggregateVolumeWeighted:
+	default:
+		return nil, fmt.Errorf("unknown aggregation policy %q", config.Policy)
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+	return &FeedAggregator{
+		sources: sources,
+		config:  config,
+		latest:  make(map[string]MarketData),
+	}, nil
+}
+
+// Run subscribes to every source and sends a consolidated tick to out for
+// each incoming tick until ctx is done or all sources close
+func (a *FeedAggregator) Run(ctx context.Context, out chan<- MarketData) error {
+	type sourced struct {
+		source string
+		tick   MarketData
+	}
+	merged := make(chan sourced)
+	var wg sync.WaitGroup
+	for _, src := range a.sources {
+		ticks, err := src.Subscribe(ctx, a.config.Commodity)
+		if err != nil {
+			return fmt.Errorf("subscribe %s: %w", src.Name(), err)
+		}
+		wg.Add(1)
+		go func(name string, ticks <-chan MarketData) {
+			defer wg.Done()
+			for tick := range ticks {
+				select {
+				case merged <- sourced{source: name, tick: tick}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}(src.Name(), ticks)
+	}
+	go func() {
+		wg.Wait()
+		close(merged)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case in, ok := <-merged:
+			if !ok {
+				return nil
+			}
+			tick, ok := a.Update(in.source, in.tick)
+			if !ok {
+				continue
+			}
+			select {
+			case out <- tick:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+}
+
+// Update records a tick from a source and returns the consolidated tick
+// across all fresh sources. It returns false when no source is fresh.
+func (a *FeedAggregator) Update(source string, tick MarketData) (MarketData, bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if tick.Commodity != a.config.Commodity {
+		return MarketData{}, false
+	}
+	a.latest[source] = tick
+
+	now := a.config.Clock()
+	names := make([]string, 0, len(a.latest))
+	for name, t := range a.latest {
+		if a.config.Staleness <= 0 || now.Sub(t.Timestamp) <= a.config.Staleness {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return MarketData{}, false
+	}
+	sort.Strings(names)
+
+	fresh := make([]MarketData, len(names))
+	for i, name := range names {
+		fresh[i] = a.latest[name]
+	}
+	a.checkDiscrepancy(names, fresh, now)
+	return a.consolidate(fresh), true
+}
+
+// consolidate applies the configured policy to fresh ticks
+func (a *FeedAggregator) consolidate(fresh []MarketData) MarketData {
+	out := MarketData{Commodity: a.config.Commodity, Exchange: consolidatedExchange}
+	for _, t := range fresh {
+		if t.Timestamp.After(out.Timestamp) {
+			out.Timestamp = t.Timestamp
+		}
+	}
+
+	switch a.config.Policy {
+	case AggregateLatest:
+		latest := fresh[0]
+		for _, t := range fresh[1:] {
+			if t.Timestamp.After(latest.Timestamp) {
+				latest = t
+			}
+		}
+		out.Price, out.Volume = latest.Price, latest.Volume
+
+	case AggregateMedian:
+		prices := make([]float64, len(fresh))
+		for i, t := range fresh {
+			prices[i] = t.Price
+			out.Volume += t.Volume
+		}
+		sort.Float64s(prices)
+		mid := len(prices) / 2
+		out.Price = prices[mid]
+		if len(prices)%2 == 0 {
+			out.Price = (prices[mid-1] + prices[mid]) / 2
+		}
+
+	case AggregateVolumeWeighted:
+		weighted := 0.0
+		for _, t := range fresh {
+			weighted += t.Price * float64(t.Volume)
+			out.Volume += t.Volume
+		}
+		if out.Volume > 0 {
+			out.Price = weighted / float64(out.Volume)
+		} else {
+			out.Price = fresh[len(fresh)-1].Price
+		}
+	}
+	return out
+}
+
+// checkDiscrepancy alerts when fresh sources disagree by more than the
+// threshold. Sources within MinDiscrepancy of each other always agree, so a
+// price at or near zero does not turn a tiny gap into a huge fraction.
+func (a *FeedAggregator) checkDiscrepancy(names []string, fresh []MarketData, now time.Time) {
+	if a.config.MaxDiscrepancy <= 0 || a.config.OnDiscrepancy == nil || len(fresh) < 2 {
+		return
+	}
+	lo, hi := 0, 0
+	for i, t := range fresh {
+		if t.Price < fresh[lo].Price {
+			lo = i
+		}
+		if t.Price > fresh[hi].Price {
+			hi = i
+		}
+	}
+	gap := fresh[hi].Price - fresh[lo].Price
+	if gap <= a.config.MinDiscrepancy {
+		return
+	}
+	apart := fmt.Sprintf("%g apart", gap)
+	if base := math.Abs(fresh[lo].Price); base > 0 {
+		spread := gap / base
+		if spread <= a.config.MaxDiscrepancy {
+			return
+		}
+		apart = fmt.Sprintf("%.2f%% apart", spread*100)
+	}
+	a.config.OnDiscrepancy(Alert{
+		Severity:  SeverityWarning,
+		Commodity: a.config.Commodity,
+		Title:     "Price feed discrepancy",
+		Detail: fmt.Sprintf("%s at %g vs %s at %g (%s)",
+			names[lo], fresh[lo].Price, names[hi], fresh[hi].Price, apart),
+		Timestamp: now,
+	})
+}
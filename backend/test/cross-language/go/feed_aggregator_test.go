@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func vendorTick(price float64, volume int64, at time.Time) MarketData {
+	return MarketData{Commodity: "crude_oil", Price: price, Volume: volume, Timestamp: at}
+}
+
+// TestFeedAggregatorPolicies verifies each consolidation policy over three vendors
+func TestFeedAggregatorPolicies(t *testing.T) {
+	now := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	ticks := map[string]MarketData{
+		"vendor_a": vendorTick(75.00, 100, now.Add(-3*time.Second)),
+		"vendor_b": vendorTick(75.20, 300, now.Add(-1*time.Second)),
+		"vendor_c": vendorTick(75.10, 100, now.Add(-2*time.Second)),
+	}
+
+	testCases := []struct {
+		policy string
+		want   float64
+	}{
+		{AggregateLatest, 75.20},
+		{AggregateMedian, 75.10},
+		{AggregateVolumeWeighted, (75.00*100 + 75.20*300 + 75.10*100) / 500},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.policy, func(t *testing.T) {
+			agg, err := NewFeedAggregator(FeedAggregatorConfig{
+				Commodity: "crude_oil",
+				Policy:    tc.policy,
+				Staleness: 10 * time.Second,
+				Clock:     func() time.Time { return now },
+			})
+			if err != nil {
+				t.Fatalf("Failed to create aggregator: %v", err)
+			}
+			var got MarketData
+			for _, name := range []string{"vendor_a", "vendor_b", "vendor_c"} {
+				got, _ = agg.Update(name, ticks[name])
+			}
+			if math.Abs(got.Price-tc.want) > 1e-9 {
+				t.Errorf("Expected %f, got %f", tc.want, got.Price)
+			}
+			if got.Exchange != "consolidated" {
+				t.Errorf("Expected consolidated exchange, got %s", got.Exchange)
+			}
+		})
+	}
+}
+
+// TestFeedAggregatorExcludesStaleSource verifies a quiet vendor drops out of the median
+func TestFeedAggregatorExcludesStaleSource(t *testing.T) {
+	now := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	var alerts []Alert
+	agg, _ := NewFeedAggregator(FeedAggregatorConfig{
+		Commodity:      "crude_oil",
+		Policy:         AggregateMedian,
+		Staleness:      5 * time.Second,
+		MaxDiscrepancy: 0.01,
+		OnDiscrepancy:  func(a Alert) { alerts = append(alerts, a) },
+		Clock:          func() time.Time { return now },
+	})
+
+	agg.Update("vendor_a", vendorTick(60.00, 100, now.Add(-time.Minute))) // stale bad print
+	agg.Update("vendor_b", vendorTick(75.20, 100, now))
+	got, ok := agg.Update("vendor_c", vendorTick(75.00, 100, now))
+	if !ok {
+		t.Fatal("Expected a consolidated tick")
+	}
+	if math.Abs(got.Price-75.10) > 1e-9 {
+		t.Errorf("Expected stale source excluded giving 75.10, got %f", got.Price)
+	}
+	if len(alerts) != 0 {
+		t.Errorf("Expected no discrepancy alert from fresh sources, got %+v", alerts)
+	}
+
+	agg.Update("vendor_a", vendorTick(77.00, 100, now))
+	if len(alerts) != 1 || !strings.Contains(alerts[0].Detail, "vendor_a") {
+		t.Errorf("Expected a discrepancy alert naming vendor_a, got %+v", alerts)
+	}
+}
+
+// TestFeedAggregatorDiscrepancyNearZero verifies prices at or near zero alert only past the absolute tolerance
+func TestFeedAggregatorDiscrepancyNearZero(t *testing.T) {
+	now := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+	var alerts []Alert
+	agg, _ := NewFeedAggregator(FeedAggregatorConfig{
+		Commodity:      "crude_oil",
+		Policy:         AggregateMedian,
+		MaxDiscrepancy: 0.01,
+		MinDiscrepancy: 0.05,
+		OnDiscrepancy:  func(a Alert) { alerts = append(alerts, a) },
+		Clock:          func() time.Time { return now },
+	})
+
+	agg.Update("vendor_a", vendorTick(0, 100, now))
+	agg.Update("vendor_b", vendorTick(0.01, 100, now))
+	agg.Update("vendor_c", vendorTick(-0.02, 100, now))
+	if len(alerts) != 0 {
+		t.Errorf("Expected prices within the absolute tolerance to agree, got %+v", alerts)
+	}
+
+	agg.Update("vendor_b", vendorTick(0.5, 100, now))
+	if len(alerts) != 1 || !strings.Contains(alerts[0].Detail, "vendor_b") {
+		t.Errorf("Expected a discrepancy alert naming vendor_b, got %+v", alerts)
+	}
+	agg.Update("vendor_c", vendorTick(0, 100, now))
+	if len(alerts) != 2 || !strings.Contains(alerts[1].Detail, "0.5 apart") {
+		t.Errorf("Expected an absolute gap reported against a zero price, got %+v", alerts)
+	}
+}
+
+// TestFeedAggregatorRun verifies ticks from subscribed sources are consolidated
+func TestFeedAggregatorRun(t *testing.T) {
+	now := time.Now()
+	agg, _ := NewFeedAggregator(FeedAggregatorConfig{Commodity: "crude_oil", Policy: AggregateLatest},
+		&fakeMarketDataSource{name: "vendor_a", ticks: []MarketData{vendorTick(75, 1, now)}},
+		&fakeMarketDataSource{name: "vendor_b", ticks: []MarketData{vendorTick(76, 1, now.Add(time.Second))}},
+	)
+
+	out := make(chan MarketData, 4)
+	if err := agg.Run(context.Background(), out); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if len(out) != 2 {
+		t.Errorf("Expected 2 consolidated ticks, got %d", len(out))
+	}
+}
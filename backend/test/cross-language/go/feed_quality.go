@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// FeedQualityWeights sets how much each component counts towards a feed's
+// score. Weights are relative; all zero weighs the components equally.
+type FeedQualityWeights struct {
+	Staleness float64 `json:"staleness"`
+	Gaps      float64 `json:"gaps"`
+	Outliers  float64 `json:"outliers"`
+}
+
+// FeedQualityConfig configures feed quality scoring
+type FeedQualityConfig struct {
+	// Window is the number of recent ticks the gap and outlier rates cover
+	Window int
+	// MaxInterval is the longest expected time between ticks; a longer
+	// interval counts as a gap
+	MaxInterval time.Duration
+	// Staleness is how long since the last tick before the staleness
+	// component falls to zero; it falls linearly from the last tick
+	Staleness time.Duration
+	// Outliers configures the per-feed outlier check
+	Outliers OutlierConfig
+	Weights  FeedQualityWeights
+	// Threshold is the score below which a feed is degraded
+	Threshold float64
+	// OnDegraded receives an alert when a feed's score falls below
+	// Threshold; it fires again only after the feed has recovered
+	OnDegraded func(Alert)
+	Clock      func() time.Time
+}
+
+// FeedQuality is a feed's score and the components it was computed from
+type FeedQuality struct {
+	Source      string        `json:"source"`
+	Score       float64       `json:"score"`
+	Age         time.Duration `json:"age"`
+	GapRate     float64       `json:"gap_rate"`
+	OutlierRate float64       `json:"outlier_rate"`
+	Degraded    bool          `json:"degraded"`
+}
+
+// Alert converts a degraded feed's quality into a notifier alert
+func (q FeedQuality) Alert(commodity string, at time.Time) Alert {
+	return Alert{
+		Severity:  SeverityWarning,
+		Commodity: commodity,
+		Title:     "degraded feed: " + q.Source,
+		Detail: fmt.Sprintf("score %.2f: last tick %s ago, %.0f%% gaps, %.0f%% outliers",
+			q.Score, q.Age.Round(time.Millisecond), q.GapRate*100, q.OutlierRate*100),
+		Timestamp: at,
+	}
+}
+
+type feedTick struct {
+	gap     bool
+	outlier bool
+}
+
+type feedState struct {
+	ticks     []feedTick
+	next      int
+	lastTick  time.Time // exchange time of the previous tick
+	lastSeen  time.Time // when the previous tick arrived
+	commodity string
+	outliers  *OutlierFilter
+	degraded  bool
+}
+
+// FeedQualityScorer keeps a rolling 0-1 health score for each market data
+// source from how stale it is, how often ticks arrive late and how often
+// they are outliers. Gaps are measured on exchange timestamps and staleness
+// on the scorer's clock, so a feed that stops entirely degrades even though
+// it sends no ticks that could show a gap.
+type FeedQualityScorer struct {
+	mu      sync.Mutex
+	config  FeedQualityConfig
+	sources map[string]*feedState
+}
+
+// NewFeedQualityScorer creates a scorer
+func NewFeedQualityScorer(config FeedQualityConfig) *FeedQualityScorer {
+	if config.Window <= 0 {
+		config.Window = 100
+	}
+	w := config.Weights
+	if w.Staleness < 0 || w.Gaps < 0 || w.Outliers < 0 || w.Staleness+w.Gaps+w.Outliers <= 0 {
+		config.Weights = FeedQualityWeights{Staleness: 1, Gaps: 1, Outliers: 1}
+	}
+	if config.Clock == nil {
+		config.Clock = time.Now
+	}
+	return &FeedQualityScorer{config: config, sources: make(map[string]*feedState)}
+}
+
+// Observe records a tick from source and returns the source's quality
+// after it
+func (s *FeedQualityScorer) Observe(source string, tick MarketData) FeedQuality {
+	s.mu.Lock()
+	now := s.config.Clock()
+	state, ok := s.sources[source]
+	if !ok {
+		state = &feedState{outliers: NewOutlierFilter(s.config.Outliers, nil)}
+		s.sources[source] = state
+	}
+	at := tick.Timestamp
+	if at.IsZero() {
+		at = now
+	}
+
+	var entry feedTick
+	if !state.lastTick.IsZero() && s.config.MaxInterval > 0 && at.Sub(state.lastTick) > s.config.MaxInterval {
+		entry.gap = true
+	}
+	entry.outlier = !state.outliers.Accept(tick)
+	if len(state.ticks) < s.config.Window {
+		state.ticks = append(state.ticks, entry)
+	} else {
+		state.ticks[state.next] = entry
+		state.next = (state.next + 1) % s.config.Window
+	}
+	if at.After(state.lastTick) {
+		state.lastTick = at
+	}
+	state.lastSeen = now
+	state.commodity = tick.Commodity
+
+	quality, alert := s.evaluateLocked(source, state, now)
+	s.mu.Unlock()
+	s.emit(alert)
+	return quality
+}
+
+// Score returns the source's current score between 0 and 1. A source that
+// has never delivered scores 0.
+func (s *FeedQualityScorer) Score(source string) float64 {
+	return s.Quality(source).Score
+}
+
+// Quality returns the source's current score and its components
+func (s *FeedQualityScorer) Quality(source string) FeedQuality {
+	s.mu.Lock()
+	state, ok := s.sources[source]
+	if !ok {
+		s.mu.Unlock()
+		return FeedQuality{Source: source, Degraded: true}
+	}
+	quality, alert := s.evaluateLocked(source, state, s.config.Clock())
+	s.mu.Unlock()
+	s.emit(alert)
+	return quality
+}
+
+// evaluateLocked scores the source and returns an alert if it has just
+// become degraded
+func (s *FeedQualityScorer) evaluateLocked(source string, state *feedState, now time.Time) (FeedQuality, *Alert) {
+	quality := FeedQuality{Source: source, Age: now.Sub(state.lastSeen)}
+	for _, t := range state.ticks {
+		if t.gap {
+			quality.GapRate++
+		}
+		if t.outlier {
+			quality.OutlierRate++
+		}
+	}
+	quality.GapRate /= float64(len(state.ticks))
+	quality.OutlierRate /= float64(len(state.ticks))
+
+	fresh := 1.0
+	if s.config.Staleness > 0 {
+		fresh = math.Max(0, 1-float64(quality.Age)/float64(s.config.Staleness))
+	}
+	w := s.config.Weights
+	quality.Score = (w.Staleness*fresh + w.Gaps*(1-quality.GapRate) + w.Outliers*(1-quality.OutlierRate)) /
+		(w.Staleness + w.Gaps + w.Outliers)
+	quality.Degraded = quality.Score < s.config.Threshold
+
+	var alert *Alert
+	if quality.Degraded && !state.degraded {
+		a := quality.Alert(state.commodity, now)
+		alert = &a
+	}
+	state.degraded = quality.Degraded
+	return quality, alert
+}
+
+func (s *FeedQualityScorer) emit(alert *Alert) {
+	if alert != nil && s.config.OnDegraded != nil {
+		s.config.OnDegraded(*alert)
+	}
+}
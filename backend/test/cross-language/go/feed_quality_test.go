@@ -0,0 +1,106 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestFeedQualityScorer(now *time.Time, alerts *[]Alert) *FeedQualityScorer {
+	return NewFeedQualityScorer(FeedQualityConfig{
+		Window:      20,
+		MaxInterval: 2 * time.Second,
+		Staleness:   time.Minute,
+		Outliers:    OutlierConfig{Window: 20, MaxStdDevs: 4, MinSamples: 5, MinDeviation: 0.5},
+		Threshold:   0.9,
+		OnDegraded:  func(a Alert) { *alerts = append(*alerts, a) },
+		Clock:       func() time.Time { return *now },
+	})
+}
+
+// TestFeedQualityGapsAndOutliersDegradeScore verifies injected gaps and
+// outliers lower the score and raise a single degraded-feed alert
+func TestFeedQualityGapsAndOutliersDegradeScore(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var alerts []Alert
+	scorer := newTestFeedQualityScorer(&now, &alerts)
+
+	send := func(price float64, step time.Duration) FeedQuality {
+		now = now.Add(step)
+		return scorer.Observe("vendor_a", MarketData{Commodity: "crude_oil", Price: price, Timestamp: now})
+	}
+	for i := 0; i < 10; i++ {
+		send(75+0.01*float64(i%3), time.Second)
+	}
+	if score := scorer.Score("vendor_a"); score != 1 {
+		t.Fatalf("Expected a clean feed to score 1, got %g", score)
+	}
+
+	// Every other tick arrives after a gap and every other is a bad print
+	var q FeedQuality
+	for i := 0; i < 10; i++ {
+		step, price := time.Second, 75.01
+		if i%2 == 0 {
+			step = 5 * time.Second
+		} else {
+			price = 150
+		}
+		q = send(price, step)
+	}
+	if q.GapRate != 0.25 || q.OutlierRate != 0.25 {
+		t.Errorf("Expected 25%% gaps and outliers over the window, got %+v", q)
+	}
+	if want := (1 + 0.75 + 0.75) / 3; math.Abs(q.Score-want) > 1e-9 || !q.Degraded {
+		t.Errorf("Expected degraded score %g, got %+v", want, q)
+	}
+	if len(alerts) != 1 || alerts[0].Commodity != "crude_oil" || alerts[0].Title != "degraded feed: vendor_a" {
+		t.Fatalf("Expected one degraded-feed alert, got %+v", alerts)
+	}
+
+	// Clean ticks push the bad ones out of the window and re-arm the alert
+	for i := 0; i < 20; i++ {
+		send(75, time.Second)
+	}
+	if score := scorer.Score("vendor_a"); score != 1 {
+		t.Errorf("Expected the feed to recover to 1, got %g", score)
+	}
+	for i := 0; i < 4; i++ {
+		send(75, 10*time.Second)
+		send(200, time.Second)
+	}
+	if len(alerts) != 2 {
+		t.Errorf("Expected a second alert after recovery, got %d", len(alerts))
+	}
+}
+
+// TestFeedQualityStalenessAndWeights verifies a silent feed decays with
+// time and that weights change what the score emphasizes
+func TestFeedQualityStalenessAndWeights(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	var alerts []Alert
+	scorer := newTestFeedQualityScorer(&now, &alerts)
+	scorer.Observe("vendor_a", MarketData{Commodity: "crude_oil", Price: 75, Timestamp: now})
+
+	now = now.Add(30 * time.Second)
+	if want := (0.5 + 1 + 1) / 3; math.Abs(scorer.Score("vendor_a")-want) > 1e-9 {
+		t.Errorf("Expected half-stale score %g, got %g", want, scorer.Score("vendor_a"))
+	}
+	now = now.Add(time.Minute)
+	if score := scorer.Score("vendor_a"); math.Abs(score-2.0/3) > 1e-9 || len(alerts) != 1 {
+		t.Errorf("Expected a fully stale feed to score 2/3 and alert, got %g with %d alerts", score, len(alerts))
+	}
+	if score := scorer.Score("vendor_b"); score != 0 {
+		t.Errorf("Expected an unknown feed to score 0, got %g", score)
+	}
+
+	weighted := NewFeedQualityScorer(FeedQualityConfig{
+		Staleness: time.Minute,
+		Weights:   FeedQualityWeights{Staleness: 3, Gaps: 1},
+		Clock:     func() time.Time { return now },
+	})
+	weighted.Observe("vendor_a", MarketData{Commodity: "crude_oil", Price: 75, Timestamp: now})
+	now = now.Add(30 * time.Second)
+	if want := (3*0.5 + 1) / 4; math.Abs(weighted.Score("vendor_a")-want) > 1e-9 {
+		t.Errorf("Expected staleness-weighted score %g, got %g", want, weighted.Score("vendor_a"))
+	}
+}
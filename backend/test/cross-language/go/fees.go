@@ -0,0 +1,192 @@
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultFeeWindowDays is the trailing volume window used for tiering
+const defaultFeeWindowDays = 30
+
+// FeeTier is a fee schedule applying from MinVolume of trailing volume
+// upwards. A negative MakerRate pays makers a rebate.
+type FeeTier struct {
+	Name      string  `json:"name"`
+	MinVolume float64 `json:"min_volume"`
+	MakerRate float64 `json:"maker_rate"` // fraction of notional; negative for a rebate
+	TakerRate float64 `json:"taker_rate"` // fraction of notional
+}
+
+// FeeTierResolver tracks per-client daily volume and resolves fee tiers
+type FeeTierResolver struct {
+	mu         sync.Mutex
+	tiers      []FeeTier // ascending by MinVolume
+	windowDays int
+	volumes    map[string]map[string]float64 // client -> UTC day -> volume
+}
+
+// NewFeeTierResolver creates a resolver over the given tiers. The lowest
+// tier must start at zero volume so every client has a rate. Taker rates
+// cannot be negative, and a maker rebate may not exceed its tier's taker
+// fee, so every trade nets a non-negative fee.
+func NewFeeTierResolver(tiers []FeeTier) (*FeeTierResolver, error) {
+	if len(tiers) == 0 {
+		return nil, fmt.Errorf("at least one fee tier is required")
+	}
+	sorted := make([]FeeTier, len(tiers))
+	copy(sorted, tiers)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].MinVolume < sorted[j].MinVolume })
+	if sorted[0].MinVolume != 0 {
+		return nil, fmt.Errorf("lowest fee tier must start at zero volume, got %g", sorted[0].MinVolume)
+	}
+	for i, tier := range sorted {
+		if tier.TakerRate < 0 {
+			return nil, fmt.Errorf("fee tier %s has negative taker rate %g", tier.Name, tier.TakerRate)
+		}
+		if tier.MakerRate+tier.TakerRate < 0 {
+			return nil, fmt.Errorf("fee tier %s maker rebate %g exceeds taker rate %g", tier.Name, -tier.MakerRate, tier.TakerRate)
+		}
+		if i > 0 && tier.MinVolume == sorted[i-1].MinVolume {
+			return nil, fmt.Errorf("fee tiers %s and %s share boundary %g", sorted[i-1].Name, tier.Name, tier.MinVolume)
+		}
+	}
+
+	return &FeeTierResolver{
+		tiers:      sorted,
+		windowDays: defaultFeeWindowDays,
+		volumes:    make(map[string]map[string]float64),
+	}, nil
+}
+
+// RecordVolume adds traded volume for a client on the day of at, dropping
+// days that have rolled out of the window
+func (r *FeeTierResolver) RecordVolume(clientID string, volume float64, at time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	days, ok := r.volumes[clientID]
+	if !ok {
+		days = make(map[string]float64)
+		r.volumes[clientID] = days
+	}
+	days[dayKey(at)] += volume
+
+	oldest := dayKey(r.windowStart(at))
+	for day := range days {
+		if day < oldest {
+			delete(days, day)
+		}
+	}
+}
+
+// TrailingVolume returns the client's volume over the window ending on the day of at
+func (r *FeeTierResolver) TrailingVolume(clientID string, at time.Time) float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	oldest, today := dayKey(r.windowStart(at)), dayKey(at)
+	total := 0.0
+	for day, volume := range r.volumes[clientID] {
+		if day >= oldest && day <= today {
+			total += volume
+		}
+	}
+	return total
+}
+
+// Resolve returns the tier for a trailing volume. A volume exactly on a
+// boundary qualifies for the higher tier.
+func (r *FeeTierResolver) Resolve(trailingVolume float64) FeeTier {
+	i := sort.Search(len(r.tiers), func(i int) bool { return r.tiers[i].MinVolume > trailingVolume })
+	return r.tiers[i-1]
+}
+
+// TierFor resolves the tier for a client's trailing volume at a point in time
+func (r *FeeTierResolver) TierFor(clientID string, at time.Time) FeeTier {
+	return r.Resolve(r.TrailingVolume(clientID, at))
+}
+
+// windowStart is the first day included in the trailing window
+func (r *FeeTierResolver) windowStart(at time.Time) time.Time {
+	return at.UTC().AddDate(0, 0, -(r.windowDays - 1))
+}
+
+// dayKey formats a UTC calendar day so keys sort chronologically
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
+
+// FeeModel charges maker and taker fees on trades using volume tiers
+type FeeModel struct {
+	resolver *FeeTierResolver
+}
+
+// NewFeeModel creates a fee model backed by a tier resolver
+func NewFeeModel(resolver *FeeTierResolver) *FeeModel {
+	return &FeeModel{resolver: resolver}
+}
+
+// Charge returns the trade with buy and sell fees populated. The aggressor
+// pays the taker rate and the resting side the maker rate, each at the tier
+// earned before this trade; the trade's volume then counts towards both
+// clients' trailing volume. A maker rebate is a negative fee.
+func (m *FeeModel) Charge(trade Trade) Trade {
+	notional := trade.Price * trade.Volume
+	buyTier := m.resolver.TierFor(trade.BuyClientID, trade.Timestamp)
+	sellTier := m.resolver.TierFor(trade.SellClientID, trade.Timestamp)
+
+	if trade.Aggressor == SideBuy {
+		trade.BuyFee = notional * buyTier.TakerRate
+		trade.SellFee = notional * sellTier.MakerRate
+	} else {
+		trade.BuyFee = notional * buyTier.MakerRate
+		trade.SellFee = notional * sellTier.TakerRate
+	}
+
+	if trade.BuyClientID != "" {
+		m.resolver.RecordVolume(trade.BuyClientID, trade.Volume, trade.Timestamp)
+	}
+	if trade.SellClientID != "" {
+		m.resolver.RecordVolume(trade.SellClientID, trade.Volume, trade.Timestamp)
+	}
+	return trade
+}
+
+// FeeSummary splits a client's fees into what it paid and what it earned
+type FeeSummary struct {
+	TakerFees    float64 `json:"taker_fees"`    // gross fees paid on aggressing fills
+	MakerFees    float64 `json:"maker_fees"`    // fees paid on resting fills
+	MakerRebates float64 `json:"maker_rebates"` // rebates earned on resting fills, as a positive amount
+	Net          float64 `json:"net"`           // fees less rebates; negative when rebates exceed fees
+}
+
+// SummarizeFees totals the client's fees over trades, keeping taker fees
+// and maker rebates apart
+func SummarizeFees(clientID string, trades []Trade) FeeSummary {
+	var s FeeSummary
+	for _, t := range trades {
+		for _, leg := range []struct {
+			client, side string
+			fee          float64
+		}{
+			{t.BuyClientID, SideBuy, t.BuyFee},
+			{t.SellClientID, SideSell, t.SellFee},
+		} {
+			if leg.client != clientID {
+				continue
+			}
+			switch {
+			case leg.side == t.Aggressor:
+				s.TakerFees += leg.fee
+			case leg.fee < 0:
+				s.MakerRebates -= leg.fee
+			default:
+				s.MakerFees += leg.fee
+			}
+			s.Net += leg.fee
+		}
+	}
+	return s
+}
@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func newTestFeeResolver(t *testing.T) *FeeTierResolver {
+	t.Helper()
+	resolver, err := NewFeeTierResolver([]FeeTier{
+		{Name: "standard", MinVolume: 0, MakerRate: 0.0002, TakerRate: 0.0005},
+		{Name: "silver", MinVolume: 10000, MakerRate: 0.0001, TakerRate: 0.0004},
+		{Name: "gold", MinVolume: 50000, MakerRate: 0, TakerRate: 0.0003},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create resolver: %v", err)
+	}
+	return resolver
+}
+
+// TestFeeTierBoundaryCrossing verifies a client moves tier exactly at the boundary
+func TestFeeTierBoundaryCrossing(t *testing.T) {
+	resolver := newTestFeeResolver(t)
+	day := time.Date(2024, 3, 1, 15, 0, 0, 0, time.UTC)
+
+	resolver.RecordVolume("client_1", 9999, day)
+	if tier := resolver.TierFor("client_1", day); tier.Name != "standard" {
+		t.Errorf("Expected standard tier below boundary, got %s", tier.Name)
+	}
+
+	resolver.RecordVolume("client_1", 1, day)
+	if tier := resolver.TierFor("client_1", day); tier.Name != "silver" {
+		t.Errorf("Expected silver tier at boundary, got %s", tier.Name)
+	}
+	if tier := resolver.Resolve(50000); tier.Name != "gold" {
+		t.Errorf("Expected gold tier at its boundary, got %s", tier.Name)
+	}
+}
+
+// TestFeeTierVolumeRollsOff verifies old days leave the trailing window
+func TestFeeTierVolumeRollsOff(t *testing.T) {
+	resolver := newTestFeeResolver(t)
+	start := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	resolver.RecordVolume("client_1", 12000, start)
+	if tier := resolver.TierFor("client_1", start.AddDate(0, 0, 29)); tier.Name != "silver" {
+		t.Errorf("Expected silver on day 30 of window, got %s", tier.Name)
+	}
+	if tier := resolver.TierFor("client_1", start.AddDate(0, 0, 30)); tier.Name != "standard" {
+		t.Errorf("Expected volume to roll off after 30 days, got %s", tier.Name)
+	}
+}
+
+// TestFeeModelChargesMakerAndTaker verifies the aggressor pays taker rates at the client's tier
+func TestFeeModelChargesMakerAndTaker(t *testing.T) {
+	resolver := newTestFeeResolver(t)
+	model := NewFeeModel(resolver)
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	first := model.Charge(Trade{Price: 100, Volume: 10000, BuyClientID: "taker", SellClientID: "maker", Aggressor: SideBuy, Timestamp: at})
+	if math.Abs(first.BuyFee-500) > 1e-9 || math.Abs(first.SellFee-200) > 1e-9 {
+		t.Errorf("Expected standard fees 500/200, got %f/%f", first.BuyFee, first.SellFee)
+	}
+
+	// Both clients now sit at the silver boundary.
+	second := model.Charge(Trade{Price: 100, Volume: 100, BuyClientID: "maker", SellClientID: "taker", Aggressor: SideSell, Timestamp: at})
+	if math.Abs(second.BuyFee-1) > 1e-9 || math.Abs(second.SellFee-4) > 1e-9 {
+		t.Errorf("Expected silver fees 1/4, got %f/%f", second.BuyFee, second.SellFee)
+	}
+}
+
+// TestFeeModelMakerRebateIncreasesPnL verifies a negative maker rate pays the maker and lifts its PnL
+func TestFeeModelMakerRebateIncreasesPnL(t *testing.T) {
+	resolver, err := NewFeeTierResolver([]FeeTier{{Name: "rebate", MakerRate: -0.0002, TakerRate: 0.0005}})
+	if err != nil {
+		t.Fatalf("Failed to create resolver: %v", err)
+	}
+	at := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	trade := NewFeeModel(resolver).Charge(Trade{Commodity: "crude_oil", Price: 100, Volume: 1000,
+		BuyClientID: "taker", SellClientID: "maker", Aggressor: SideBuy, Timestamp: at})
+	if math.Abs(trade.BuyFee-50) > 1e-9 || math.Abs(trade.SellFee+20) > 1e-9 {
+		t.Fatalf("Expected taker fee 50 and maker rebate -20, got %g/%g", trade.BuyFee, trade.SellFee)
+	}
+
+	maker := PnLAttributor{ClientID: "maker"}
+	prices := map[string]float64{"crude_oil": 100}
+	withRebate := maker.AttributePnL([]Trade{trade}, prices, prices)
+	noFees := trade
+	noFees.BuyFee, noFees.SellFee = 0, 0
+	without := maker.AttributePnL([]Trade{noFees}, prices, prices)
+	if math.Abs(withRebate.Fees-20) > 1e-9 || math.Abs(withRebate.Total-without.Total-20) > 1e-9 {
+		t.Errorf("Expected the rebate to add 20 to PnL, got fees %g and total %g vs %g", withRebate.Fees, withRebate.Total, without.Total)
+	}
+
+	makerFees := SummarizeFees("maker", []Trade{trade})
+	if makerFees.MakerRebates != 20 || makerFees.TakerFees != 0 || makerFees.Net != -20 {
+		t.Errorf("Unexpected maker fee summary %+v", makerFees)
+	}
+	takerFees := SummarizeFees("taker", []Trade{trade})
+	if takerFees.TakerFees != 50 || takerFees.MakerRebates != 0 || takerFees.Net != 50 {
+		t.Errorf("Unexpected taker fee summary %+v", takerFees)
+	}
+
+	if _, err := NewFeeTierResolver([]FeeTier{{Name: "loss", MakerRate: -0.001, TakerRate: 0.0005}}); err == nil {
+		t.Error("Expected a rebate above the taker fee to be rejected")
+	}
+}
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"context"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FirmRiskConfig configures firmwide risk aggregation
+type FirmRiskConfig struct {
+	Interval     time.Duration      // recompute period for Run; defaults to one second
+	Confidence   float64            // VaR confidence level; defaults to 0.99
+	Volatility   map[string]float64 // daily return volatility per commodity
+	Correlations *CorrelationMatrix // nil treats every pair as perfectly correlated
+}
+
+// CommodityRisk is the firm's net position in one commodity
+type CommodityRisk struct {
+	Commodity     string  `json:"commodity"`
+	NetVolume     float64 `json:"net_volume"`
+	NetExposure   float64 `json:"net_exposure"`   // signed notional at mark
+	GrossNotional float64 `json:"gross_notional"` // sum of absolute client notionals
+}
+
+// FirmRiskReport is a firmwide risk snapshot
+type FirmRiskReport struct {
+	At            time.Time       `json:"at"`
+	Clients       int             `json:"clients"`
+	TotalNotional float64         `json:"total_notional"`
+	Commodities   []CommodityRisk `json:"commodities"`
+	VaR           float64         `json:"var"`
+}
+
+// FirmRisk aggregates client positions from one or more trackers into a
+// firmwide report. Each tracker is copied under one read lock and the
+// aggregation runs on the copies, so trading is held up only for the copy.
+type FirmRisk struct {
+	config   FirmRiskConfig
+	trackers []*PositionTracker
+	clock    func() time.Time
+
+	mu     sync.RWMutex
+	latest FirmRiskReport
+}
+
+// NewFirmRisk creates an aggregator; a nil clock uses time.Now
+func NewFirmRisk(config FirmRiskConfig, clock func() time.Time, trackers ...*PositionTracker) *FirmRisk {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.Confidence <= 0 || config.Confidence >= 1 {
+		config.Confidence = 0.99
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return &FirmRisk{config: config, trackers: trackers, clock: clock}
+}
+
+// Compute builds a fresh report and makes it the latest
+func (f *FirmRisk) Compute() FirmRiskReport {
+	report := FirmRiskReport{At: f.clock()}
+	byCommodity := make(map[string]*CommodityRisk)
+	clients := make(map[string]bool)
+	for _, tracker := range f.trackers {
+		for clientID, positions := range tracker.Snapshot() {
+			for _, pos := range positions {
+				if pos.Volume == 0 {
+					continue
+				}
+				clients[clientID] = true
+				c, ok := byCommodity[pos.Commodity]
+				if !ok {
+					c = &CommodityRisk{Commodity: pos.Commodity}
+					byCommodity[pos.Commodity] = c
+				}
+				c.NetVolume += pos.Volume
+				c.NetExposure += pos.Volume * pos.MarkPrice
+				c.GrossNotional += math.Abs(pos.Volume * pos.MarkPrice)
+			}
+		}
+	}
+
+	for _, c := range byCommodity {
+		report.TotalNotional += c.GrossNotional
+		report.Commodities = append(report.Commodities, *c)
+	}
+	sort.Slice(report.Commodities, func(i, j int) bool {
+		return report.Commodities[i].Commodity < report.Commodities[j].Commodity
+	})
+	report.Clients = len(clients)
+	report.VaR = f.valueAtRisk(report.Commodities)
+
+	f.mu.Lock()
+	f.latest = report
+	f.mu.Unlock()
+	return report
+}
+
+// Latest returns the most recent report
+func (f *FirmRisk) Latest() FirmRiskReport {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.latest
+}
+
+// Run recomputes the report every interval until ctx is cancelled
+func (f *FirmRisk) Run(ctx context.Context) error {
+	ticker := time.NewTicker(f.config.Interval)
+	defer ticker.Stop()
+	for {
+		f.Compute()
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// valueAtRisk is the parametric one-day VaR of the net exposures:
+// z * sqrt(e' * Sigma * e) with Sigma built from volatilities and correlations
+func (f *FirmRisk) valueAtRisk(commodities []CommodityRisk) float64 {
+	variance := 0.0
+	for _, a := range commodities {
+		for _, b := range commodities {
+			variance += a.NetExposure * b.NetExposure *
+				f.config.Volatility[a.Commodity] * f.config.Volatility[b.Commodity] *
+				f.correlation(a.Commodity, b.Commodity)
+		}
+	}
+	if variance <= 0 {
+		return 0
+	}
+	z := math.Sqrt2 * math.Erfinv(2*f.config.Confidence-1)
+	return z * math.Sqrt(variance)
+}
+
+func (f *FirmRisk) correlation(a, b string) float64 {
+	if a == b || f.config.Correlations == nil {
+		return 1
+	}
+	if corr, ok := f.config.Correlations.Get(a, b); ok {
+		return corr
+	}
+	return 1
+}
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"math"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestFirmRiskAggregatesClients verifies net exposure, gross notional and VaR across clients and trackers
+func TestFirmRiskAggregatesClients(t *testing.T) {
+	desk := NewPositionTracker()
+	desk.ApplyFill("acme", "crude_oil", SideBuy, 100, 75)
+	desk.ApplyFill("gulf", "crude_oil", SideSell, 40, 75)
+	desk.ApplyFill("gulf", "natural_gas", SideBuy, 1000, 2.5)
+	otc := NewPositionTracker()
+	otc.ApplyFill("delta", "natural_gas", SideSell, 3000, 2.5)
+	otc.ApplyFill("flat", "crude_oil", SideBuy, 10, 75)
+	otc.ApplyFill("flat", "crude_oil", SideSell, 10, 76)
+
+	correlations, err := ParseCorrelationJSON(strings.NewReader(`{
+		"crude_oil":   {"crude_oil": 1, "natural_gas": 0.5},
+		"natural_gas": {"crude_oil": 0.5, "natural_gas": 1}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to parse correlations: %v", err)
+	}
+	at := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	firm := NewFirmRisk(FirmRiskConfig{
+		Confidence:   0.99,
+		Volatility:   map[string]float64{"crude_oil": 0.02, "natural_gas": 0.04},
+		Correlations: correlations,
+	}, func() time.Time { return at }, desk, otc)
+
+	report := firm.Compute()
+	if report.Clients != 3 || !report.At.Equal(at) {
+		t.Errorf("Expected 3 clients with open positions at %v, got %d at %v", at, report.Clients, report.At)
+	}
+	if len(report.Commodities) != 2 {
+		t.Fatalf("Expected crude and gas lines, got %+v", report.Commodities)
+	}
+	crude, gas := report.Commodities[0], report.Commodities[1]
+	if crude.NetVolume != 60 || crude.NetExposure != 4500 || crude.GrossNotional != 10500 {
+		t.Errorf("Unexpected crude risk %+v", crude)
+	}
+	if gas.NetVolume != -2000 || gas.NetExposure != -5000 || gas.GrossNotional != 10000 {
+		t.Errorf("Unexpected gas risk %+v", gas)
+	}
+	if report.TotalNotional != 20500 {
+		t.Errorf("Expected total gross notional 20500, got %g", report.TotalNotional)
+	}
+
+	// sigma_crude = 90, sigma_gas = -200; variance = 90^2 + 200^2 + 2*0.5*90*(-200)
+	variance := 90.0*90 + 200*200 - 2*0.5*90*200
+	if want := 2.3263478740 * math.Sqrt(variance); math.Abs(report.VaR-want) > 1e-4 {
+		t.Errorf("Expected VaR %.4f, got %.4f", want, report.VaR)
+	}
+	if firm.Latest().VaR != report.VaR {
+		t.Error("Expected the latest report to be kept")
+	}
+}
+
+// TestFirmRiskConsistentUnderTrading verifies each snapshot sees whole trades while fills keep arriving
+func TestFirmRiskConsistentUnderTrading(t *testing.T) {
+	tracker := NewPositionTracker()
+	firm := NewFirmRisk(FirmRiskConfig{}, nil, tracker)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 2000; i++ {
+			tracker.ApplyTrade(Trade{Commodity: "crude_oil", Price: 75, Volume: 1, BuyClientID: "acme", SellClientID: "gulf"})
+		}
+	}()
+	for i := 0; i < 200; i++ {
+		for _, c := range firm.Compute().Commodities {
+			if math.Abs(c.NetVolume) > volumeEpsilon {
+				t.Fatalf("Snapshot split a trade: net %g", c.NetVolume)
+			}
+		}
+	}
+	wg.Wait()
+}
@@ -0,0 +1,83 @@
+package integration
+
+import (
+	"fmt"
+	"time"
+)
+
+// FXRateSource quotes how many units of to one unit of from buys
+type FXRateSource interface {
+	Rate(from, to string) (rate float64, at time.Time, err error)
+}
+
+// FXDifference is the PnL impact of settling at a different rate than the
+// one locked at trade time
+type FXDifference struct {
+	TradeID        string    `json:"trade_id"`
+	Currency       string    `json:"currency"`
+	TradeRate      float64   `json:"trade_rate"`
+	TradeRateAt    time.Time `json:"trade_rate_at"`
+	SettlementRate float64   `json:"settlement_rate"`
+	// Difference is what the trade would have settled for at the current
+	// rate minus what it settles for at the locked rate, in Currency
+	Difference float64 `json:"difference"`
+}
+
+// FXStamper locks the FX rate on trades that settle in a currency other
+// than the one they are priced in
+type FXStamper struct {
+	source        FXRateSource
+	priceCurrency string
+	currencies    map[string]string // commodity -> settlement currency
+}
+
+// NewFXStamper creates a stamper for trades priced in priceCurrency
+func NewFXStamper(source FXRateSource, priceCurrency string, currencies map[string]string) *FXStamper {
+	return &FXStamper{source: source, priceCurrency: priceCurrency, currencies: currencies}
+}
+
+// Stamp records the current rate into the trade's settlement fields
+func (s *FXStamper) Stamp(trade Trade) (Trade, error) {
+	currency, ok := s.currencies[trade.Commodity]
+	if !ok {
+		return trade, fmt.Errorf("no settlement currency configured for %s", trade.Commodity)
+	}
+	trade.SettlementCurrency = currency
+	if currency == s.priceCurrency {
+		trade.FXRate, trade.FXRateAt = 1, trade.Timestamp
+		return trade, nil
+	}
+	rate, at, err := s.source.Rate(s.priceCurrency, currency)
+	if err != nil {
+		return trade, fmt.Errorf("fx rate %s/%s for trade %s: %w", s.priceCurrency, currency, trade.TradeID, err)
+	}
+	trade.FXRate, trade.FXRateAt = rate, at
+	return trade, nil
+}
+
+// Reconcile compares each stamped trade's locked rate with the current
+// rate and reports trades where they differ
+func (s *FXStamper) Reconcile(trades []Trade) ([]FXDifference, error) {
+	var diffs []FXDifference
+	for _, trade := range trades {
+		if trade.FXRate <= 0 || trade.SettlementCurrency == s.priceCurrency {
+			continue
+		}
+		current, _, err := s.source.Rate(s.priceCurrency, trade.SettlementCurrency)
+		if err != nil {
+			return nil, fmt.Errorf("fx rate %s/%s: %w", s.priceCurrency, trade.SettlementCurrency, err)
+		}
+		if current == trade.FXRate {
+			continue
+		}
+		diffs = append(diffs, FXDifference{
+			TradeID:        trade.TradeID,
+			Currency:       trade.SettlementCurrency,
+			TradeRate:      trade.FXRate,
+			TradeRateAt:    trade.FXRateAt,
+			SettlementRate: current,
+			Difference:     trade.Price * trade.Volume * (current - trade.FXRate),
+		})
+	}
+	return diffs, nil
+}
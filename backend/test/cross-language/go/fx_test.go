@@ -0,0 +1,61 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"testing"
+	"time"
+)
+
+// fakeFXRates serves a mutable rate table
+type fakeFXRates struct {
+	rates map[string]float64
+	at    time.Time
+}
+
+func (f *fakeFXRates) Rate(from, to string) (float64, time.Time, error) {
+	rate, ok := f.rates[from+to]
+	if !ok {
+		return 0, time.Time{}, fmt.Errorf("no rate for %s/%s", from, to)
+	}
+	return rate, f.at, nil
+}
+
+// TestSettlementUsesTradeTimeFXRate verifies the locked rate survives a later rate move
+func TestSettlementUsesTradeTimeFXRate(t *testing.T) {
+	tradeTime := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	rates := &fakeFXRates{rates: map[string]float64{"USDEUR": 0.90}, at: tradeTime}
+	currencies := map[string]string{"brent": "EUR", "crude_oil": "USD"}
+	stamper := NewFXStamper(rates, "USD", currencies)
+
+	trade, err := stamper.Stamp(Trade{TradeID: "t1", Commodity: "brent", Price: 80, Volume: 100, Timestamp: tradeTime})
+	if err != nil {
+		t.Fatalf("Stamp failed: %v", err)
+	}
+	if trade.FXRate != 0.90 || trade.SettlementCurrency != "EUR" || !trade.FXRateAt.Equal(tradeTime) {
+		t.Fatalf("Expected EUR rate 0.90 stamped at trade time, got %+v", trade)
+	}
+
+	rates.rates["USDEUR"] = 0.95
+	rates.at = tradeTime.Add(48 * time.Hour)
+
+	batcher := NewSettlementBatcher(currencies)
+	if err := batcher.Add(trade); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	instructions, err := batcher.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if instructions[0].Amount != 7200 {
+		t.Errorf("Expected settlement of 7200 EUR at the locked rate, got %.2f", instructions[0].Amount)
+	}
+
+	diffs, err := stamper.Reconcile([]Trade{trade})
+	if err != nil {
+		t.Fatalf("Reconcile failed: %v", err)
+	}
+	if len(diffs) != 1 || diffs[0].SettlementRate != 0.95 || math.Abs(diffs[0].Difference-400) > 1e-9 {
+		t.Errorf("Expected a 400 EUR rate difference to be reported, got %+v", diffs)
+	}
+}
@@ -0,0 +1,231 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+)
+
+// RiskCheck approves orders before they reach the book
+type RiskCheck interface {
+	// Check reports whether the order would pass without recording anything
+	Check(order TradingOrder) error
+	// Submit checks the order and records its usage of the limit
+	Submit(order TradingOrder) error
+	// Refund undoes Submit for an order rejected after it passed
+	Refund(order TradingOrder)
+}
+
+// SubmitOptions controls a gateway submission
+type SubmitOptions struct {
+	// DryRun validates, risk-checks and simulates matching without
+	// changing the book or any risk state
+	DryRun bool
+}
+
+// SubmitResult is the outcome of an accepted submission
+type SubmitResult struct {
+	Order   TradingOrder `json:"order"`
+	Trades  []Trade      `json:"trades"`
+	Resting float64      `json:"resting"` // volume left on the book, hidden reserve included
+	DryRun  bool         `json:"dry_run"`
+}
+
+// OrderGateway runs orders through validation, risk checks and the book
+type OrderGateway struct {
+	book      *OrderBook
+	validator *OrderValidator
+	risk      []RiskCheck
+	acks      *AckHub
+	wal       *OrderWAL
+}
+
+// NewOrderGateway creates a gateway; validator may be nil
+func NewOrderGateway(book *OrderBook, validator *OrderValidator, risk ...RiskCheck) *OrderGateway {
+	return &OrderGateway{book: book, validator: validator, risk: risk}
+}
+
+// SetAcks publishes an ack for every live submission to the client's stream
+func (g *OrderGateway) SetAcks(acks *AckHub) {
+	g.acks = acks
+}
+
+// SetWAL logs every live submission to wal before it is processed and
+// marks it once the client has been acknowledged
+func (g *OrderGateway) SetWAL(wal *OrderWAL) {
+	g.wal = wal
+}
+
+// Submit validates and risk-checks the order, then matches it on the book,
+// or simulates the match when opts.DryRun is set. Rejections are returned
+// as errors from the stage that refused the order, and risk checks the
+// order already passed are refunded. With a WAL, a live order that cannot
+// be logged is rejected without being processed.
+func (g *OrderGateway) Submit(order TradingOrder, opts SubmitOptions) (SubmitResult, error) {
+	if g.wal == nil || opts.DryRun {
+		return g.process(order, opts)
+	}
+	seq, err := g.wal.Append(order)
+	if err != nil {
+		err = fmt.Errorf("log order %s: %w", order.OrderID, err)
+		g.ack(order, SubmitResult{}, err)
+		return SubmitResult{}, err
+	}
+	result, err := g.process(order, opts)
+	// A failed ack record only means the order is offered again on recovery
+	g.wal.Ack(seq)
+	return result, err
+}
+
+// RecoverWAL processes the orders the WAL holds without an ack, as left by
+// a crash between logging an order and acknowledging it, in the order they
+// were logged. An unacked order may still have reached the book before the
+// crash, and may since have filled, so orders the book's event log shows it
+// already accepted or paused are marked Processed and acknowledged in the
+// WAL without being submitted again. The book must therefore have an event
+// log, restored to its state before the crash.
+func (g *OrderGateway) RecoverWAL() ([]RecoveredOrder, error) {
+	if g.wal == nil {
+		return nil, fmt.Errorf("gateway has no wal")
+	}
+	processed, err := g.book.ProcessedOrders()
+	if err != nil {
+		return nil, fmt.Errorf("recover wal: %w", err)
+	}
+	var recovered []RecoveredOrder
+	for _, entry := range g.wal.Unacked() {
+		if processed[entry.Order.OrderID] {
+			recovered = append(recovered, RecoveredOrder{Seq: entry.Seq, Processed: true})
+		} else {
+			result, err := g.process(entry.Order, SubmitOptions{})
+			recovered = append(recovered, RecoveredOrder{Seq: entry.Seq, Result: result, Err: err})
+		}
+		if err := g.wal.Ack(entry.Seq); err != nil {
+			return recovered, err
+		}
+	}
+	return recovered, nil
+}
+
+// process runs a submission and publishes its ack
+func (g *OrderGateway) process(order TradingOrder, opts SubmitOptions) (SubmitResult, error) {
+	result, err := g.submit(order, opts)
+	if !opts.DryRun {
+		g.ack(order, result, err)
+	}
+	return result, err
+}
+
+func (g *OrderGateway) ack(order TradingOrder, result SubmitResult, err error) {
+	if g.acks != nil {
+		g.acks.Publish(order.ClientID, order.OrderID, ackStatus(order, result, err), errorReason(err))
+	}
+}
+
+func (g *OrderGateway) submit(order TradingOrder, opts SubmitOptions) (SubmitResult, error) {
+	if g.validator != nil {
+		if err := g.validator.Validate(order); err != nil {
+			return SubmitResult{}, err
+		}
+	}
+	for i, check := range g.risk {
+		var err error
+		if opts.DryRun {
+			err = check.Check(order)
+		} else {
+			err = check.Submit(order)
+		}
+		if err != nil {
+			if !opts.DryRun {
+				g.refund(order, g.risk[:i])
+			}
+			return SubmitResult{}, err
+		}
+	}
+
+	var trades []Trade
+	var resting float64
+	var err error
+	if opts.DryRun {
+		trades, resting, err = g.book.simulate(order)
+	} else {
+		trades, err = g.book.Add(order)
+	}
+	if err != nil {
+		// A paused order is held for review and may still trade, so it
+		// keeps its charge until Cancel rejects it
+		if !opts.DryRun && !errors.Is(err, ErrPriceBandPaused) {
+			g.refund(order, g.risk)
+		}
+		return SubmitResult{}, err
+	}
+
+	// IOC and collared remainders are cancelled rather than rested, so
+	// the book is asked what is actually left
+	if !opts.DryRun {
+		if rested, ok := g.book.Order(order.OrderID); ok {
+			resting = rested.Volume
+		}
+	}
+	return SubmitResult{Order: order, Trades: trades, Resting: resting, DryRun: opts.DryRun}, nil
+}
+
+// Cancel removes a live order from the book. Rejecting an order paused
+// for review also refunds the risk charge it was holding, as it never
+// traded.
+func (g *OrderGateway) Cancel(orderID string) error {
+	return g.cancel(orderID, "", false)
+}
+
+// CancelForClient cancels a live order on behalf of clientID, returning
+// ErrNotOrderOwner without touching the order if it belongs to another
+// client
+func (g *OrderGateway) CancelForClient(clientID, orderID string) error {
+	return g.cancel(orderID, clientID, true)
+}
+
+func (g *OrderGateway) cancel(orderID, clientID string, checkOwner bool) error {
+	paused, err := g.book.cancel(orderID, clientID, checkOwner)
+	if err != nil {
+		return err
+	}
+	if paused != nil {
+		g.refund(*paused, g.risk)
+	}
+	return nil
+}
+
+// refund undoes the risk checks' charges for an order that was rejected
+func (g *OrderGateway) refund(order TradingOrder, checks []RiskCheck) {
+	for _, check := range checks {
+		check.Refund(order)
+	}
+}
+
+// ackStatus summarises a submission outcome
+func ackStatus(order TradingOrder, result SubmitResult, err error) string {
+	if errors.Is(err, ErrPriceBandPaused) {
+		return AckPaused
+	}
+	if err != nil {
+		return AckRejected
+	}
+	var filled float64
+	for _, t := range result.Trades {
+		filled += t.Volume
+	}
+	switch {
+	case filled <= volumeEpsilon:
+		return AckAccepted
+	case filled >= order.Volume-volumeEpsilon:
+		return AckFilled
+	default:
+		return AckPartiallyFilled
+	}
+}
+
+func errorReason(err error) string {
+	if err == nil {
+		return ""
+	}
+	return err.Error()
+}
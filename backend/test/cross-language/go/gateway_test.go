@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestOrderGatewayDryRunLeavesBookUnchanged verifies simulated fills without side effects
+func TestOrderGatewayDryRunLeavesBookUnchanged(t *testing.T) {
+	book := newQuotedBook(t)
+	book.Add(TradingOrder{OrderID: "ask2", Side: SideSell, Price: 75.70, Volume: 50})
+	budget := NewNotionalBudget(20000, time.Hour, nil)
+	gateway := NewOrderGateway(book, NewOrderValidator(BasicOrderRule(nil)), budget)
+	before := book.Snapshot()
+
+	order := TradingOrder{OrderID: "dry1", ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Type: OrderTypeLimit, Price: 75.70, Volume: 130}
+	result, err := gateway.Submit(order, SubmitOptions{DryRun: true})
+	if err != nil {
+		t.Fatalf("Dry run rejected: %v", err)
+	}
+	if len(result.Trades) != 2 || result.Trades[0].Volume != 100 || result.Trades[1].Price != 75.70 || result.Resting != 0 {
+		t.Errorf("Expected fills of 100@75.60 and 30@75.70, got %+v", result)
+	}
+
+	if after := book.Snapshot(); !reflect.DeepEqual(before, after) {
+		t.Errorf("Expected book unchanged after dry run:\nbefore %+v\nafter  %+v", before, after)
+	}
+	if _, ok := book.Order("dry1"); ok {
+		t.Error("Expected no residual dry-run order on the book")
+	}
+	if used := budget.Used("acme"); used != 0 {
+		t.Errorf("Expected dry run not to charge the budget, used %.2f", used)
+	}
+
+	live, err := gateway.Submit(order, SubmitOptions{})
+	for i := range live.Trades {
+		live.Trades[i].Timestamp = result.Trades[i].Timestamp
+	}
+	if err != nil || !reflect.DeepEqual(live.Trades, result.Trades) {
+		t.Errorf("Expected the live submission to match the dry run, got %+v, %v", live.Trades, err)
+	}
+}
+
+// TestOrderGatewayDryRunReportsRejections verifies risk rejections surface in dry runs
+func TestOrderGatewayDryRunReportsRejections(t *testing.T) {
+	gateway := NewOrderGateway(newQuotedBook(t), nil, NewNotionalBudget(1000, time.Hour, nil))
+
+	_, err := gateway.Submit(TradingOrder{OrderID: "big", ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Price: 75.60, Volume: 100}, SubmitOptions{DryRun: true})
+	if !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected ErrBudgetExceeded, got %v", err)
+	}
+}
+
+// TestOrderGatewayRefundsRejectedOrders verifies an order the book or a later check rejects leaves the budget unchanged
+func TestOrderGatewayRefundsRejectedOrders(t *testing.T) {
+	book := newQuotedBook(t)
+	budget := NewNotionalBudget(100000, time.Hour, nil)
+	kill := NewCommodityKillSwitch(nil)
+	gateway := NewOrderGateway(book, nil, budget, kill)
+
+	order := TradingOrder{OrderID: "b1", ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Price: 75.50, Volume: 100}
+	if _, err := gateway.Submit(order, SubmitOptions{}); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	if used := budget.Used("acme"); used != 7550 {
+		t.Fatalf("Expected 7550 charged, used %.2f", used)
+	}
+
+	// The book refuses a reused order id after the budget has charged it
+	if _, err := gateway.Submit(order, SubmitOptions{}); !errors.Is(err, ErrDuplicateOrder) {
+		t.Fatalf("Expected the book to reject a duplicate, got %v", err)
+	}
+	if used := budget.Used("acme"); used != 7550 {
+		t.Errorf("Expected the rejected order refunded, used %.2f", used)
+	}
+
+	// A check after the budget refuses the order
+	kill.Engage("crude_oil", KillSwitchOptions{})
+	order.OrderID = "b2"
+	if _, err := gateway.Submit(order, SubmitOptions{}); !errors.Is(err, ErrCommodityFrozen) {
+		t.Fatalf("Expected the kill switch to reject, got %v", err)
+	}
+	if used := budget.Used("acme"); used != 7550 {
+		t.Errorf("Expected the frozen order refunded, used %.2f", used)
+	}
+}
+
+// TestOrderGatewayAcksAndRefundsPausedOrders verifies a paused order is
+// acked as live and keeps its charge until a reviewer rejects it
+func TestOrderGatewayAcksAndRefundsPausedOrders(t *testing.T) {
+	book := NewOrderBook("crude_oil",
+		WithReferenceBand(func() (float64, bool) { return 75.0, true }, 0.02, nil))
+	if _, err := book.Add(TradingOrder{OrderID: "s1", Side: SideSell, Price: 78.0, Volume: 10}); err != nil {
+		t.Fatalf("Failed to seed book: %v", err)
+	}
+	budget := NewNotionalBudget(100000, time.Hour, nil)
+	gateway := NewOrderGateway(book, nil, budget)
+	acks := NewAckHub(4, 4, nil)
+	gateway.SetAcks(acks)
+
+	order := TradingOrder{OrderID: "b1", ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Price: 78.0, Volume: 10}
+	if _, err := gateway.Submit(order, SubmitOptions{}); !errors.Is(err, ErrPriceBandPaused) {
+		t.Fatalf("Expected the order to pause, got %v", err)
+	}
+	if ack := <-acks.Stream("acme").C(); ack.Status != AckPaused || ack.Reason == "" {
+		t.Errorf("Expected a paused ack with the reason, got %+v", ack)
+	}
+	if used := budget.Used("acme"); used != 780 {
+		t.Errorf("Expected the paused order to keep its charge, used %.2f", used)
+	}
+
+	if err := gateway.Cancel("b1"); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if used := budget.Used("acme"); used != 0 {
+		t.Errorf("Expected the rejected order refunded, used %.2f", used)
+	}
+	if err := gateway.Cancel("b1"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("Expected a second cancel to find nothing, got %v", err)
+	}
+}
+
+// TestOrderGatewayReportsActualResting verifies cancelled remainders are not reported as resting
+func TestOrderGatewayReportsActualResting(t *testing.T) {
+	book := NewOrderBook("crude_oil", WithMarketCollar(0.10, CollarRemainderCancel))
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", Side: SideSell, Price: 75.60, Volume: 30},
+		{OrderID: "ask2", Side: SideSell, Price: 76.00, Volume: 100},
+	} {
+		book.Add(o)
+	}
+	gateway := NewOrderGateway(book, nil)
+
+	testCases := []struct {
+		name  string
+		order TradingOrder
+		want  float64
+	}{
+		{"ioc remainder", TradingOrder{OrderID: "ioc", Side: SideBuy, Price: 75.60, Volume: 40, TimeInForce: TimeInForceIOC}, 0},
+		{"collared market", TradingOrder{OrderID: "mkt", Side: SideBuy, Type: OrderTypeMarket, Volume: 200}, 0},
+		{"limit remainder", TradingOrder{OrderID: "lim", Side: SideBuy, Price: 75.50, Volume: 25}, 25},
+		{"iceberg", TradingOrder{OrderID: "ice", Side: SideBuy, Price: 75.40, Volume: 50, DisplayVolume: 10}, 50},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dry, err := gateway.Submit(tc.order, SubmitOptions{DryRun: true})
+			if err != nil || dry.Resting != tc.want {
+				t.Errorf("Expected a dry run to report %g resting, got %g (%v)", tc.want, dry.Resting, err)
+			}
+			live, err := gateway.Submit(tc.order, SubmitOptions{})
+			if err != nil || live.Resting != tc.want {
+				t.Errorf("Expected %g resting, got %g (%v)", tc.want, live.Resting, err)
+			}
+		})
+	}
+}
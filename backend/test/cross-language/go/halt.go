@@ -0,0 +1,125 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// HaltTier halts trading for Duration once the price has moved Move (a
+// fraction, e.g. 0.07 for 7%) away from the reference price
+type HaltTier struct {
+	Move     float64
+	Duration time.Duration
+}
+
+// HaltConfig configures tiered circuit breakers for one commodity
+type HaltConfig struct {
+	Reference float64
+	Tiers     []HaltTier
+	// CloseAfterFinalTier closes the market for the rest of the session when
+	// the deepest tier trips instead of resuming after its duration
+	CloseAfterFinalTier bool
+}
+
+// HaltState is a commodity's current halt status
+type HaltState struct {
+	Commodity string
+	Tier      int // deepest tier tripped this session, 1-based; 0 if none
+	Until     time.Time
+	Closed    bool
+}
+
+type haltBook struct {
+	config HaltConfig
+	state  HaltState
+}
+
+// HaltController trips progressively deeper halts as a commodity's move
+// from its reference price widens. Each tier trips at most once per session,
+// and a deeper tier replaces any shallower halt in progress.
+type HaltController struct {
+	mu    sync.Mutex
+	clock func() time.Time
+	books map[string]*haltBook
+}
+
+// NewHaltController creates a controller with no configured commodities
+func NewHaltController(clock func() time.Time) *HaltController {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &HaltController{clock: clock, books: make(map[string]*haltBook)}
+}
+
+// Configure sets a commodity's tiers and resets its session state
+func (h *HaltController) Configure(commodity string, config HaltConfig) error {
+	if config.Reference <= 0 {
+		return fmt.Errorf("halt config for %s: reference price must be positive", commodity)
+	}
+	tiers := append([]HaltTier(nil), config.Tiers...)
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].Move < tiers[j].Move })
+	for _, tier := range tiers {
+		if tier.Move <= 0 {
+			return fmt.Errorf("halt config for %s: tier move must be positive", commodity)
+		}
+	}
+	config.Tiers = tiers
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.books[commodity] = &haltBook{config: config, state: HaltState{Commodity: commodity}}
+	return nil
+}
+
+// OnPrice evaluates a traded price and reports whether it tripped a new tier
+func (h *HaltController) OnPrice(commodity string, price float64) (HaltState, bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	book, ok := h.books[commodity]
+	if !ok {
+		return HaltState{Commodity: commodity}, false
+	}
+	move := math.Abs(price-book.config.Reference) / book.config.Reference
+	tier := 0
+	for i, t := range book.config.Tiers {
+		if move+volumeEpsilon >= t.Move {
+			tier = i + 1
+		}
+	}
+	if tier <= book.state.Tier {
+		return book.state, false
+	}
+
+	book.state.Tier = tier
+	book.state.Until = h.clock().Add(book.config.Tiers[tier-1].Duration)
+	if tier == len(book.config.Tiers) && book.config.CloseAfterFinalTier {
+		book.state.Closed = true
+	}
+	return book.state, true
+}
+
+// Halted reports whether trading in a commodity is currently stopped
+func (h *HaltController) Halted(commodity string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	book, ok := h.books[commodity]
+	if !ok {
+		return false
+	}
+	return book.state.Closed || h.clock().Before(book.state.Until)
+}
+
+// State returns a commodity's halt state
+func (h *HaltController) State(commodity string) HaltState {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if book, ok := h.books[commodity]; ok {
+		return book.state
+	}
+	return HaltState{Commodity: commodity}
+}
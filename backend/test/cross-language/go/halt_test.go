@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// TestHaltControllerTiers verifies successive tiers trip, override, and close the session
+func TestHaltControllerTiers(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	halts := NewHaltController(func() time.Time { return now })
+	err := halts.Configure("crude_oil", HaltConfig{
+		Reference: 100,
+		Tiers: []HaltTier{
+			{Move: 0.07, Duration: 15 * time.Minute},
+			{Move: 0.13, Duration: 15 * time.Minute},
+			{Move: 0.20},
+		},
+		CloseAfterFinalTier: true,
+	})
+	if err != nil {
+		t.Fatalf("Configure failed: %v", err)
+	}
+
+	if _, tripped := halts.OnPrice("crude_oil", 95); tripped || halts.Halted("crude_oil") {
+		t.Fatal("Expected a 5% move not to halt")
+	}
+
+	state, tripped := halts.OnPrice("crude_oil", 93)
+	if !tripped || state.Tier != 1 || !halts.Halted("crude_oil") {
+		t.Fatalf("Expected tier 1 halt at -7%%, got %+v", state)
+	}
+
+	now = now.Add(10 * time.Minute)
+	state, tripped = halts.OnPrice("crude_oil", 87)
+	if !tripped || state.Tier != 2 || !state.Until.Equal(now.Add(15*time.Minute)) {
+		t.Fatalf("Expected tier 2 to override with a fresh 15 minute halt, got %+v", state)
+	}
+
+	now = now.Add(16 * time.Minute)
+	if halts.Halted("crude_oil") {
+		t.Fatal("Expected trading to resume after the tier 2 halt")
+	}
+	if _, tripped := halts.OnPrice("crude_oil", 92); tripped {
+		t.Error("Expected shallower tiers not to re-trip in the same session")
+	}
+
+	state, tripped = halts.OnPrice("crude_oil", 80)
+	if !tripped || state.Tier != 3 || !state.Closed {
+		t.Fatalf("Expected the final tier to close the session, got %+v", state)
+	}
+	now = now.Add(6 * time.Hour)
+	if !halts.Halted("crude_oil") {
+		t.Error("Expected the market to stay closed for the session")
+	}
+}
@@ -0,0 +1,115 @@
+package integration
+
+import (
+	"math"
+	"sort"
+)
+
+// HedgeLeg is a proposed offsetting position in a correlated commodity
+type HedgeLeg struct {
+	Commodity   string  `json:"commodity"`
+	Side        string  `json:"side"`
+	Volume      float64 `json:"volume"`
+	Correlation float64 `json:"correlation"`
+	HedgeRatio  float64 `json:"hedge_ratio"`
+	// Effectiveness is the share of variance the leg removes (correlation squared)
+	Effectiveness float64 `json:"effectiveness"`
+}
+
+// HedgeEngine sizes minimum-variance hedges from correlations and volatilities
+type HedgeEngine struct {
+	// Volatility per commodity; when empty all commodities are treated as equally volatile
+	Volatility map[string]float64
+	// MinCorrelation is the minimum absolute correlation for a usable hedge
+	MinCorrelation float64
+	// MaxLegs caps the number of legs; zero means a single leg
+	MaxLegs int
+}
+
+// SuggestHedge proposes offsetting positions for a signed position in commodity.
+// Each candidate is sized by the minimum-variance ratio rho * vol(position) /
+// vol(hedge); when several legs are used the position is split between them
+// in proportion to their absolute correlation. Returns an empty slice when no
+// commodity qualifies.
+func (e *HedgeEngine) SuggestHedge(position float64, commodity string, correl *CorrelationMatrix) []HedgeLeg {
+	legs := []HedgeLeg{}
+	if correl == nil || math.Abs(position) < volumeEpsilon {
+		return legs
+	}
+
+	type candidate struct {
+		commodity string
+		rho       float64
+		ratio     float64
+	}
+	var candidates []candidate
+	for _, other := range correl.Commodities() {
+		if other == commodity {
+			continue
+		}
+		rho, ok := correl.Get(commodity, other)
+		if !ok || math.Abs(rho) < volumeEpsilon || math.Abs(rho) < e.MinCorrelation {
+			continue
+		}
+		volRatio, ok := e.volatilityRatio(commodity, other)
+		if !ok {
+			continue
+		}
+		candidates = append(candidates, candidate{commodity: other, rho: rho, ratio: rho * volRatio})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if math.Abs(candidates[i].rho) != math.Abs(candidates[j].rho) {
+			return math.Abs(candidates[i].rho) > math.Abs(candidates[j].rho)
+		}
+		return candidates[i].commodity < candidates[j].commodity
+	})
+
+	maxLegs := e.MaxLegs
+	if maxLegs <= 0 {
+		maxLegs = 1
+	}
+	if len(candidates) > maxLegs {
+		candidates = candidates[:maxLegs]
+	}
+
+	totalRho := 0.0
+	for _, c := range candidates {
+		totalRho += math.Abs(c.rho)
+	}
+	for _, c := range candidates {
+		weight := math.Abs(c.rho) / totalRho
+		volume := -position * c.ratio * weight
+		if math.Abs(volume) < volumeEpsilon {
+			continue
+		}
+		side := SideBuy
+		if volume < 0 {
+			side = SideSell
+		}
+		legs = append(legs, HedgeLeg{
+			Commodity:     c.commodity,
+			Side:          side,
+			Volume:        math.Abs(volume),
+			Correlation:   c.rho,
+			HedgeRatio:    c.ratio,
+			Effectiveness: c.rho * c.rho,
+		})
+	}
+	return legs
+}
+
+// volatilityRatio returns vol(position) / vol(hedge)
+func (e *HedgeEngine) volatilityRatio(commodity, hedge string) (float64, bool) {
+	if len(e.Volatility) == 0 {
+		return 1, true
+	}
+	own, ok := e.Volatility[commodity]
+	if !ok || own <= 0 {
+		return 0, false
+	}
+	other, ok := e.Volatility[hedge]
+	if !ok || other <= 0 {
+		return 0, false
+	}
+	return own / other, true
+}
@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"math"
+	"testing"
+)
+
+// TestSuggestHedgeUsesMostCorrelatedCommodity verifies a long crude position is hedged by selling heating oil
+func TestSuggestHedgeUsesMostCorrelatedCommodity(t *testing.T) {
+	correl, err := LoadCorrelationMatrix("testdata/correlations.csv")
+	if err != nil {
+		t.Fatalf("Failed to load correlations: %v", err)
+	}
+	engine := &HedgeEngine{
+		Volatility:     map[string]float64{"crude_oil": 0.30, "heating_oil": 0.25, "natural_gas": 0.60},
+		MinCorrelation: 0.5,
+	}
+
+	legs := engine.SuggestHedge(1000, "crude_oil", correl)
+	if len(legs) != 1 {
+		t.Fatalf("Expected 1 hedge leg, got %+v", legs)
+	}
+	leg := legs[0]
+	if leg.Commodity != "heating_oil" || leg.Side != SideSell {
+		t.Errorf("Expected to sell heating_oil, got %+v", leg)
+	}
+	// 1000 * 0.82 * 0.30 / 0.25
+	if math.Abs(leg.Volume-984) > 1e-6 {
+		t.Errorf("Expected hedge volume 984, got %g", leg.Volume)
+	}
+
+	again := engine.SuggestHedge(1000, "crude_oil", correl)
+	if len(again) != 1 || again[0] != leg {
+		t.Errorf("Expected deterministic recommendation, got %+v", again)
+	}
+
+	short := engine.SuggestHedge(-1000, "crude_oil", correl)
+	if len(short) != 1 || short[0].Side != SideBuy {
+		t.Errorf("Expected a short position to be hedged with a buy, got %+v", short)
+	}
+}
+
+// TestSuggestHedgeNoSuitableCommodity verifies an empty result when nothing is correlated enough
+func TestSuggestHedgeNoSuitableCommodity(t *testing.T) {
+	correl, err := LoadCorrelationMatrix("testdata/correlations.csv")
+	if err != nil {
+		t.Fatalf("Failed to load correlations: %v", err)
+	}
+	engine := &HedgeEngine{MinCorrelation: 0.5}
+
+	legs := engine.SuggestHedge(500, "natural_gas", correl)
+	if legs == nil || len(legs) != 0 {
+		t.Errorf("Expected an empty slice, got %#v", legs)
+	}
+}
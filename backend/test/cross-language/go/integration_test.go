@@ -18,26 +18,6 @@ import (
 // - Microservices communication
 // - System-level integrations
 
-// TradingOrder represents a trading order structure for testing
-type TradingOrder struct {
-	OrderID   string    `json:"order_id"`
-	Commodity string    `json:"commodity"`
-	Volume    float64   `json:"volume"`
-	Price     float64   `json:"price"`
-	Side      string    `json:"side"`
-	Type      string    `json:"type"`
-	Timestamp time.Time `json:"timestamp"`
-}
-
-// MarketData represents market data point structure for testing
-type MarketData struct {
-	Commodity string    `json:"commodity"`
-	Price     float64   `json:"price"`
-	Volume    int64     `json:"volume"`
-	Exchange  string    `json:"exchange"`
-	Timestamp time.Time `json:"timestamp"`
-}
-
 // TestGoEnvironmentSetup verifies Go testing environment is properly configured
 func TestGoEnvironmentSetup(t *testing.T) {
 	if testing.Short() {
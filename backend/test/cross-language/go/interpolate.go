@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Gap fill modes for Interpolator
+const (
+	InterpolateLastValue = "last_value" // gaps repeat the last traded price
+	InterpolateLinear    = "linear"     // gaps follow a line between the ticks either side
+	InterpolateNone      = "none"       // gaps are left out of the series
+)
+
+// SeriesPoint is one point of a regular series. Synthetic points were
+// filled in for a slot without any tick and carry no volume or exchange.
+type SeriesPoint struct {
+	MarketData
+	Synthetic bool `json:"synthetic"`
+}
+
+// Interpolator turns an irregular tick series into one point per cadence
+type Interpolator struct {
+	cadence time.Duration
+	mode    string
+}
+
+// NewInterpolator creates an interpolator for the cadence and fill mode
+func NewInterpolator(cadence time.Duration, mode string) (*Interpolator, error) {
+	if cadence <= 0 {
+		return nil, fmt.Errorf("interpolation cadence must be positive")
+	}
+	switch mode {
+	case InterpolateLastValue, InterpolateLinear, InterpolateNone:
+	default:
+		return nil, fmt.Errorf("unknown interpolation mode %q", mode)
+	}
+	return &Interpolator{cadence: cadence, mode: mode}, nil
+}
+
+// Regularize returns a point at every multiple of the cadence from the
+// first tick to the last. A point stands for the slot ending at its
+// timestamp: with ticks in the slot it has the last one's price and their
+// total volume, otherwise it is synthetic or, in InterpolateNone mode,
+// omitted. Ticks are taken to be one commodity and need not be sorted.
+func (i *Interpolator) Regularize(ticks []MarketData) []SeriesPoint {
+	if len(ticks) == 0 {
+		return nil
+	}
+	sorted := append([]MarketData(nil), ticks...)
+	sort.SliceStable(sorted, func(a, b int) bool { return sorted[a].Timestamp.Before(sorted[b].Timestamp) })
+
+	end := i.slotEnd(sorted[len(sorted)-1].Timestamp)
+	var out []SeriesPoint
+	next := 0 // first tick not yet placed in a slot
+	for slot := i.slotEnd(sorted[0].Timestamp); !slot.After(end); slot = slot.Add(i.cadence) {
+		if next < len(sorted) && !sorted[next].Timestamp.After(slot) {
+			point := SeriesPoint{MarketData: sorted[next]}
+			point.Volume = 0
+			for ; next < len(sorted) && !sorted[next].Timestamp.After(slot); next++ {
+				point.Price = sorted[next].Price
+				point.Exchange = sorted[next].Exchange
+				point.Volume += sorted[next].Volume
+			}
+			point.Timestamp, point.OriginalTimestamp = slot, time.Time{}
+			out = append(out, point)
+			continue
+		}
+		if i.mode == InterpolateNone {
+			continue
+		}
+		// A gap always has a tick before it, the last one placed, and one
+		// after it, as the series ends on a slot holding the last tick
+		prev, following := sorted[next-1], sorted[next]
+		price := prev.Price
+		if i.mode == InterpolateLinear {
+			span := following.Timestamp.Sub(prev.Timestamp)
+			price += (following.Price - prev.Price) * float64(slot.Sub(prev.Timestamp)) / float64(span)
+		}
+		out = append(out, SeriesPoint{
+			MarketData: MarketData{Commodity: prev.Commodity, Price: price, Timestamp: slot},
+			Synthetic:  true,
+		})
+	}
+	return out
+}
+
+// slotEnd rounds t up to the cadence grid
+func (i *Interpolator) slotEnd(t time.Time) time.Time {
+	end := t.Truncate(i.cadence)
+	if end.Before(t) {
+		end = end.Add(i.cadence)
+	}
+	return end
+}
@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestInterpolatorFillModes verifies each mode over a series with a three-slot gap
+func TestInterpolatorFillModes(t *testing.T) {
+	base := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	at := func(seconds float64) time.Time { return base.Add(time.Duration(seconds * float64(time.Second))) }
+	ticks := []MarketData{
+		{Commodity: "crude_oil", Price: 75.10, Volume: 2, Exchange: "NYMEX", Timestamp: at(1.5)},
+		{Commodity: "crude_oil", Price: 75.00, Volume: 5, Exchange: "NYMEX", Timestamp: at(0.4)},
+		{Commodity: "crude_oil", Price: 75.20, Volume: 1, Exchange: "NYMEX", Timestamp: at(1.8)},
+		// nothing in the slots ending at 3s, 4s and 5s
+		{Commodity: "crude_oil", Price: 76.00, Volume: 3, Exchange: "NYMEX", Timestamp: at(6)},
+	}
+
+	tests := []struct {
+		mode   string
+		prices []float64 // slots ending 1s..6s; NaN marks an omitted slot
+	}{
+		{InterpolateLastValue, []float64{75.00, 75.20, 75.20, 75.20, 75.20, 76.00}},
+		// 75.20 at 1.8s to 76.00 at 6s is 0.8 over 4.2s
+		{InterpolateLinear, []float64{75.00, 75.20, 75.20 + 0.8*1.2/4.2, 75.20 + 0.8*2.2/4.2, 75.20 + 0.8*3.2/4.2, 76.00}},
+		{InterpolateNone, []float64{75.00, 75.20, math.NaN(), math.NaN(), math.NaN(), 76.00}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.mode, func(t *testing.T) {
+			interp, err := NewInterpolator(time.Second, tt.mode)
+			if err != nil {
+				t.Fatalf("Failed to create interpolator: %v", err)
+			}
+			points := interp.Regularize(ticks)
+
+			var want []SeriesPoint
+			for i, price := range tt.prices {
+				if !math.IsNaN(price) {
+					synthetic := i >= 2 && i <= 4
+					want = append(want, SeriesPoint{MarketData: MarketData{Price: price, Timestamp: at(float64(i + 1))}, Synthetic: synthetic})
+				}
+			}
+			if len(points) != len(want) {
+				t.Fatalf("Expected %d points, got %+v", len(want), points)
+			}
+			for i, p := range points {
+				w := want[i]
+				if !p.Timestamp.Equal(w.Timestamp) || math.Abs(p.Price-w.Price) > 1e-9 || p.Synthetic != w.Synthetic {
+					t.Errorf("Point %d: expected %v %.4f synthetic=%v, got %v %.4f synthetic=%v",
+						i, w.Timestamp.Format("15:04:05"), w.Price, w.Synthetic, p.Timestamp.Format("15:04:05"), p.Price, p.Synthetic)
+				}
+				if p.Synthetic && (p.Volume != 0 || p.Exchange != "") {
+					t.Errorf("Point %d: expected a synthetic point without volume or exchange, got %+v", i, p)
+				}
+			}
+			if real := points[1]; real.Volume != 3 || real.Exchange != "NYMEX" {
+				t.Errorf("Expected the 2s slot to sum both ticks' volume, got %+v", real)
+			}
+		})
+	}
+}
@@ -0,0 +1,313 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TopicPartition identifies a Kafka partition
+type TopicPartition struct {
+	Topic     string `json:"topic"`
+	Partition int32  `json:"partition"`
+}
+
+func (tp TopicPartition) String() string {
+	return fmt.Sprintf("%s/%d", tp.Topic, tp.Partition)
+}
+
+// KafkaMessage is a record consumed from a partition
+type KafkaMessage struct {
+	Topic     string
+	Partition int32
+	Offset    int64
+	Key       []byte
+	Value     []byte
+}
+
+// TopicPartition returns the partition the message came from
+func (m KafkaMessage) TopicPartition() TopicPartition {
+	return TopicPartition{Topic: m.Topic, Partition: m.Partition}
+}
+
+// KafkaRebalance announces partitions revoked from and assigned to this member
+type KafkaRebalance struct {
+	Revoked  []TopicPartition
+	Assigned []TopicPartition
+}
+
+// KafkaBroker joins consumer groups. It is implemented by the client
+// library adapter and faked in tests.
+type KafkaBroker interface {
+	JoinGroup(ctx context.Context, group string, topics []string) (KafkaGroupSession, error)
+}
+
+// KafkaGroupSession is one membership of a consumer group
+type KafkaGroupSession interface {
+	Rebalances() <-chan KafkaRebalance
+	Messages() <-chan KafkaMessage
+	Done() <-chan struct{} // closed when the broker connection is lost
+	Committed(tp TopicPartition) (int64, error)
+	Commit(offsets map[TopicPartition]int64) error
+	Seek(tp TopicPartition, offset int64) error
+	Close() error
+}
+
+// KafkaConsumerConfig configures a group consumer
+type KafkaConsumerConfig struct {
+	Group          string
+	Topics         []string
+	CommitInterval time.Duration
+	MinBackoff     time.Duration
+	MaxBackoff     time.Duration
+
+	// Checkpoints optionally mirrors processed offsets outside the broker.
+	// On assignment a checkpoint ahead of the broker's committed offset wins.
+	Checkpoints OffsetStore
+	Logger      *log.Logger
+}
+
+// KafkaConsumer consumes a group's partitions, committing offsets cleanly
+// across rebalances and rejoining the group after broker disconnects
+type KafkaConsumer struct {
+	broker  KafkaBroker
+	config  KafkaConsumerConfig
+	handler func(ctx context.Context, msg KafkaMessage) error
+
+	mu       sync.Mutex
+	assigned map[TopicPartition]int64 // next offset to process
+	dirty    bool
+	joins    int
+}
+
+// NewKafkaConsumer creates a consumer; handler is invoked for each message in
+// partition order and must be idempotent
+func NewKafkaConsumer(broker KafkaBroker, config KafkaConsumerConfig, handler func(ctx context.Context, msg KafkaMessage) error) *KafkaConsumer {
+	if config.CommitInterval <= 0 {
+		config.CommitInterval = time.Second
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 100 * time.Millisecond
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = 30 * time.Second
+	}
+	if config.Logger == nil {
+		config.Logger = log.Default()
+	}
+	return &KafkaConsumer{
+		broker:   broker,
+		config:   config,
+		handler:  handler,
+		assigned: make(map[TopicPartition]int64),
+	}
+}
+
+// Assigned returns the partitions currently owned by this consumer
+func (c *KafkaConsumer) Assigned() []TopicPartition {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	out := make([]TopicPartition, 0, len(c.assigned))
+	for tp := range c.assigned {
+		out = append(out, tp)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Topic != out[j].Topic {
+			return out[i].Topic < out[j].Topic
+		}
+		return out[i].Partition < out[j].Partition
+	})
+	return out
+}
+
+// Joins returns how many times the consumer has joined the group
+func (c *KafkaConsumer) Joins() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.joins
+}
+
+// Run consumes until ctx is cancelled or the handler fails. Lost broker
+// connections are retried with exponential backoff.
+func (c *KafkaConsumer) Run(ctx context.Context) error {
+	backoff := c.config.MinBackoff
+	for {
+		session, err := c.broker.JoinGroup(ctx, c.config.Group, c.config.Topics)
+		if err == nil {
+			backoff = c.config.MinBackoff
+			c.mu.Lock()
+			c.joins++
+			c.mu.Unlock()
+
+			err = c.consume(ctx, session)
+			session.Close()
+			if err == nil || ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != errSessionLost {
+				return err
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > c.config.MaxBackoff {
+			backoff = c.config.MaxBackoff
+		}
+	}
+}
+
+// errSessionLost signals that the group session ended and should be rejoined
+var errSessionLost = fmt.Errorf("kafka session lost")
+
+// consume processes one group session
+func (c *KafkaConsumer) consume(ctx context.Context, session KafkaGroupSession) error {
+	ticker := time.NewTicker(c.config.CommitInterval)
+	defer ticker.Stop()
+
+	// Partitions from a previous session are no longer ours; the group
+	// will hand them out again through a rebalance.
+	c.mu.Lock()
+	c.assigned = make(map[TopicPartition]int64)
+	c.dirty = false
+	c.mu.Unlock()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return c.commit(session, nil)
+
+		case <-session.Done():
+			return errSessionLost
+
+		case rb := <-session.Rebalances():
+			if err := c.rebalance(session, rb); err != nil {
+				return err
+			}
+
+		case msg := <-session.Messages():
+			if err := c.process(ctx, msg); err != nil {
+				c.commit(session, nil)
+				return err
+			}
+
+		case <-ticker.C:
+			if err := c.commit(session, nil); err != nil {
+				return errSessionLost
+			}
+		}
+	}
+}
+
+// rebalance commits offsets for revoked partitions before releasing them,
+// then positions newly assigned partitions at their committed offsets
+func (c *KafkaConsumer) rebalance(session KafkaGroupSession, rb KafkaRebalance) error {
+	if len(rb.Revoked) > 0 {
+		if err := c.commit(session, rb.Revoked); err != nil {
+			return fmt.Errorf("commit revoked partitions: %w", err)
+		}
+		c.mu.Lock()
+		for _, tp := range rb.Revoked {
+			delete(c.assigned, tp)
+		}
+		c.mu.Unlock()
+	}
+
+	for _, tp := range rb.Assigned {
+		offset, err := session.Committed(tp)
+		if err != nil {
+			return fmt.Errorf("read committed offset for %s: %w", tp, err)
+		}
+		offset = c.checkpointedOffset(tp, offset)
+		if err := session.Seek(tp, offset); err != nil {
+			return fmt.Errorf("seek %s to %d: %w", tp, offset, err)
+		}
+		c.mu.Lock()
+		c.assigned[tp] = offset
+		c.mu.Unlock()
+	}
+	return nil
+}
+
+// process hands a message to the handler unless it belongs to a partition
+// we no longer own or has already been processed
+func (c *KafkaConsumer) process(ctx context.Context, msg KafkaMessage) error {
+	tp := msg.TopicPartition()
+	c.mu.Lock()
+	next, owned := c.assigned[tp]
+	c.mu.Unlock()
+	if !owned || msg.Offset < next {
+		return nil
+	}
+
+	if err := c.handler(ctx, msg); err != nil {
+		return fmt.Errorf("handle %s@%d: %w", tp, msg.Offset, err)
+	}
+
+	c.mu.Lock()
+	if _, still := c.assigned[tp]; still {
+		c.assigned[tp] = msg.Offset + 1
+		c.dirty = true
+	}
+	c.mu.Unlock()
+	return nil
+}
+
+// commit stores processed offsets for the given partitions, or for every
+// owned partition when partitions is nil
+func (c *KafkaConsumer) commit(session KafkaGroupSession, partitions []TopicPartition) error {
+	c.mu.Lock()
+	offsets := make(map[TopicPartition]int64)
+	if partitions == nil {
+		if !c.dirty {
+			c.mu.Unlock()
+			return nil
+		}
+		for tp, off := range c.assigned {
+			offsets[tp] = off
+		}
+		c.dirty = false
+	} else {
+		for _, tp := range partitions {
+			if off, ok := c.assigned[tp]; ok {
+				offsets[tp] = off
+			}
+		}
+	}
+	c.mu.Unlock()
+
+	if len(offsets) == 0 {
+		return nil
+	}
+	if c.config.Checkpoints != nil {
+		if err := c.config.Checkpoints.Save(context.Background(), c.config.Group, offsets); err != nil {
+			c.config.Logger.Printf("kafka consumer: checkpoint save failed, relying on broker offsets: %v", err)
+		}
+	}
+	return session.Commit(offsets)
+}
+
+// checkpointedOffset prefers the external checkpoint when it is ahead of
+// the broker. If the store is unavailable the broker offset is used.
+func (c *KafkaConsumer) checkpointedOffset(tp TopicPartition, brokerOffset int64) int64 {
+	if c.config.Checkpoints == nil {
+		return brokerOffset
+	}
+	offset, found, err := c.config.Checkpoints.Load(context.Background(), c.config.Group, tp)
+	if err != nil {
+		c.config.Logger.Printf("kafka consumer: checkpoint unavailable for %s, using broker offset %d: %v", tp, brokerOffset, err)
+		return brokerOffset
+	}
+	if found && offset > brokerOffset {
+		return offset
+	}
+	return brokerOffset
+}
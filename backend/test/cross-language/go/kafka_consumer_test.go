@@ -0,0 +1,197 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeKafkaBroker keeps committed offsets and hands the test control of each session
+type fakeKafkaBroker struct {
+	mu        sync.Mutex
+	committed map[TopicPartition]int64
+	sessions  chan *fakeKafkaSession
+	failJoins int
+}
+
+type fakeKafkaSession struct {
+	broker     *fakeKafkaBroker
+	rebalances chan KafkaRebalance
+	messages   chan KafkaMessage
+	done       chan struct{}
+	seeks      map[TopicPartition]int64
+	mu         sync.Mutex
+}
+
+func newFakeKafkaBroker() *fakeKafkaBroker {
+	return &fakeKafkaBroker{
+		committed: make(map[TopicPartition]int64),
+		sessions:  make(chan *fakeKafkaSession, 4),
+	}
+}
+
+func (b *fakeKafkaBroker) JoinGroup(ctx context.Context, group string, topics []string) (KafkaGroupSession, error) {
+	b.mu.Lock()
+	if b.failJoins > 0 {
+		b.failJoins--
+		b.mu.Unlock()
+		return nil, errors.New("broker unreachable")
+	}
+	b.mu.Unlock()
+
+	s := &fakeKafkaSession{
+		broker:     b,
+		rebalances: make(chan KafkaRebalance),
+		messages:   make(chan KafkaMessage),
+		done:       make(chan struct{}),
+		seeks:      make(map[TopicPartition]int64),
+	}
+	b.sessions <- s
+	return s, nil
+}
+
+func (b *fakeKafkaBroker) committedOffset(tp TopicPartition) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.committed[tp]
+}
+
+func (s *fakeKafkaSession) Rebalances() <-chan KafkaRebalance { return s.rebalances }
+func (s *fakeKafkaSession) Messages() <-chan KafkaMessage     { return s.messages }
+func (s *fakeKafkaSession) Done() <-chan struct{}             { return s.done }
+func (s *fakeKafkaSession) Close() error                      { return nil }
+
+func (s *fakeKafkaSession) Committed(tp TopicPartition) (int64, error) {
+	return s.broker.committedOffset(tp), nil
+}
+
+func (s *fakeKafkaSession) Commit(offsets map[TopicPartition]int64) error {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	for tp, off := range offsets {
+		s.broker.committed[tp] = off
+	}
+	return nil
+}
+
+func (s *fakeKafkaSession) Seek(tp TopicPartition, offset int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.seeks[tp] = offset
+	return nil
+}
+
+func (s *fakeKafkaSession) seekOffset(tp TopicPartition) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.seeks[tp]
+}
+
+// deliver sends offsets [from, to) of a partition to the consumer
+func (s *fakeKafkaSession) deliver(tp TopicPartition, from, to int64) {
+	for off := from; off < to; off++ {
+		s.messages <- KafkaMessage{Topic: tp.Topic, Partition: tp.Partition, Offset: off, Value: []byte(fmt.Sprint(off))}
+	}
+}
+
+// barrier returns once the consumer has finished handling everything sent
+// before it; the consumer ignores messages for partitions it does not own
+func (s *fakeKafkaSession) barrier() {
+	s.messages <- KafkaMessage{Topic: "__barrier"}
+}
+
+// TestKafkaConsumerRebalanceAndReconnect verifies clean commits on rebalance and resumption after a disconnect
+func TestKafkaConsumerRebalanceAndReconnect(t *testing.T) {
+	broker := newFakeKafkaBroker()
+	p0 := TopicPartition{Topic: "market-data", Partition: 0}
+	p1 := TopicPartition{Topic: "market-data", Partition: 1}
+
+	var mu sync.Mutex
+	processed := make(map[TopicPartition][]int64)
+	consumer := NewKafkaConsumer(broker, KafkaConsumerConfig{
+		Group:          "risk-engine",
+		Topics:         []string{"market-data"},
+		CommitInterval: time.Hour,
+		MinBackoff:     time.Millisecond,
+		MaxBackoff:     5 * time.Millisecond,
+	}, func(ctx context.Context, msg KafkaMessage) error {
+		mu.Lock()
+		processed[msg.TopicPartition()] = append(processed[msg.TopicPartition()], msg.Offset)
+		mu.Unlock()
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	runErr := make(chan error, 1)
+	go func() { runErr <- consumer.Run(ctx) }()
+
+	first := <-broker.sessions
+	first.rebalances <- KafkaRebalance{Assigned: []TopicPartition{p0, p1}}
+	first.deliver(p0, 0, 5)
+	first.deliver(p1, 0, 3)
+	first.barrier()
+
+	if got := consumer.Assigned(); len(got) != 2 {
+		t.Fatalf("Expected 2 assigned partitions, got %v", got)
+	}
+
+	// The group moves p0 to another member; its progress must be committed first.
+	first.rebalances <- KafkaRebalance{Revoked: []TopicPartition{p0}}
+	first.barrier()
+	if off := broker.committedOffset(p0); off != 5 {
+		t.Errorf("Expected p0 committed at 5 on revoke, got %d", off)
+	}
+	if got := consumer.Assigned(); len(got) != 1 || got[0] != p1 {
+		t.Errorf("Expected only p1 assigned after revoke, got %v", got)
+	}
+
+	// A late message for the revoked partition must be ignored.
+	first.deliver(p0, 5, 6)
+
+	// Broker drops; the consumer rejoins after a failed attempt.
+	broker.mu.Lock()
+	broker.failJoins = 1
+	broker.mu.Unlock()
+	close(first.done)
+
+	second := <-broker.sessions
+	second.rebalances <- KafkaRebalance{Assigned: []TopicPartition{p0, p1}}
+	second.barrier()
+	if off := second.seekOffset(p0); off != 5 {
+		t.Errorf("Expected p0 to resume at 5, got %d", off)
+	}
+	second.deliver(p0, 5, 8)
+	second.deliver(p1, 3, 4)
+
+	cancel()
+	if err := <-runErr; !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context cancellation, got %v", err)
+	}
+	if consumer.Joins() != 2 {
+		t.Errorf("Expected 2 successful joins, got %d", consumer.Joins())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	assertOffsets(t, processed[p0], 0, 8)
+	assertOffsets(t, processed[p1], 0, 4)
+	if off := broker.committedOffset(p0); off != 8 {
+		t.Errorf("Expected final p0 commit at 8, got %d", off)
+	}
+}
+
+// assertOffsets checks offsets are exactly from..to-1 with no gaps or repeats
+func assertOffsets(t *testing.T, got []int64, from, to int64) {
+	t.Helper()
+	if int64(len(got)) != to-from {
+		t.Fatalf("Expected offsets %d..%d, got %v", from, to-1, got)
+	}
+	for i, off := range got {
+		if off != from+int64(i) {
+			t.Fatalf("Expected offsets %d..%d, got %v", from, to-1, got)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrCommodityFrozen is returned for orders in a commodity whose kill
+// switch is engaged
+var ErrCommodityFrozen = errors.New("commodity frozen")
+
+// KillSwitchOptions controls one engagement of a commodity kill switch
+type KillSwitchOptions struct {
+	// Duration releases the switch automatically; zero holds it until
+	// Release is called
+	Duration time.Duration
+	// CancelResting cancels every resting order in the commodity's books,
+	// along with any paused for review
+	CancelResting bool
+	Reason        string
+}
+
+// KillSwitchState is a commodity's kill switch status
+type KillSwitchState struct {
+	Commodity string    `json:"commodity"`
+	Engaged   bool      `json:"engaged"`
+	Reason    string    `json:"reason,omitempty"`
+	EngagedAt time.Time `json:"engaged_at,omitempty"`
+	Until     time.Time `json:"until,omitempty"` // zero when held until released
+}
+
+// CommodityKillSwitch freezes trading in single commodities. It is a
+// RiskCheck, so a gateway using it rejects orders in a frozen commodity
+// with ErrCommodityFrozen. Timed engagements release themselves once the
+// clock passes their expiry; nothing needs to run for that to happen.
+type CommodityKillSwitch struct {
+	mu     sync.Mutex
+	clock  func() time.Time
+	books  map[string][]*OrderBook
+	frozen map[string]KillSwitchState
+}
+
+// NewCommodityKillSwitch creates a switch over books, whose resting orders
+// it can cancel on engagement; a nil clock uses time.Now
+func NewCommodityKillSwitch(clock func() time.Time, books ...*OrderBook) *CommodityKillSwitch {
+	if clock == nil {
+		clock = time.Now
+	}
+	k := &CommodityKillSwitch{
+		clock:  clock,
+		books:  make(map[string][]*OrderBook),
+		frozen: make(map[string]KillSwitchState),
+	}
+	for _, book := range books {
+		k.books[book.Commodity()] = append(k.books[book.Commodity()], book)
+	}
+	return k
+}
+
+// Engage freezes a commodity, replacing any engagement already in place,
+// and returns the orders cancelled. The freeze takes effect before any
+// order is cancelled, so nothing new can rest behind the cancellation.
+func (k *CommodityKillSwitch) Engage(commodity string, opts KillSwitchOptions) []TradingOrder {
+	now := k.clock()
+	state := KillSwitchState{Commodity: commodity, Engaged: true, Reason: opts.Reason, EngagedAt: now}
+	if opts.Duration > 0 {
+		state.Until = now.Add(opts.Duration)
+	}
+	k.mu.Lock()
+	k.frozen[commodity] = state
+	books := k.books[commodity]
+	k.mu.Unlock()
+
+	if !opts.CancelResting {
+		return nil
+	}
+	var cancelled []TradingOrder
+	for _, book := range books {
+		cancelled = append(cancelled, book.CancelAll()...)
+	}
+	return cancelled
+}
+
+// Release lifts a commodity's freeze and reports whether one was in place
+func (k *CommodityKillSwitch) Release(commodity string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	_, engaged := k.stateLocked(commodity)
+	delete(k.frozen, commodity)
+	return engaged
+}
+
+// Frozen reports whether a commodity's kill switch is engaged
+func (k *CommodityKillSwitch) Frozen(commodity string) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	_, engaged := k.stateLocked(commodity)
+	return engaged
+}
+
+// State returns a commodity's kill switch status
+func (k *CommodityKillSwitch) State(commodity string) KillSwitchState {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	state, _ := k.stateLocked(commodity)
+	return state
+}
+
+// Engaged returns the state of every engaged switch
+func (k *CommodityKillSwitch) Engaged() []KillSwitchState {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	var states []KillSwitchState
+	for commodity := range k.frozen {
+		if state, ok := k.stateLocked(commodity); ok {
+			states = append(states, state)
+		}
+	}
+	return states
+}
+
+// stateLocked returns the commodity's state, releasing it first if its
+// duration has run out
+func (k *CommodityKillSwitch) stateLocked(commodity string) (KillSwitchState, bool) {
+	state, ok := k.frozen[commodity]
+	if ok && !state.Until.IsZero() && !k.clock().Before(state.Until) {
+		delete(k.frozen, commodity)
+		ok = false
+	}
+	if !ok {
+		return KillSwitchState{Commodity: commodity}, false
+	}
+	return state, true
+}
+
+// Check implements RiskCheck
+func (k *CommodityKillSwitch) Check(order TradingOrder) error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	state, engaged := k.stateLocked(order.Commodity)
+	if !engaged {
+		return nil
+	}
+	if state.Reason != "" {
+		return fmt.Errorf("%w: %s: %s", ErrCommodityFrozen, order.Commodity, state.Reason)
+	}
+	return fmt.Errorf("%w: %s", ErrCommodityFrozen, order.Commodity)
+}
+
+// Submit implements RiskCheck; the switch records nothing per order
+func (k *CommodityKillSwitch) Submit(order TradingOrder) error {
+	return k.Check(order)
+}
+
+// Refund implements RiskCheck; there is nothing to undo
+func (k *CommodityKillSwitch) Refund(order TradingOrder) {}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCommodityKillSwitchAutoRelease verifies an engaged commodity rejects orders until its duration runs out
+func TestCommodityKillSwitchAutoRelease(t *testing.T) {
+	var mu sync.Mutex
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	clock := func() time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return now
+	}
+	crude, gas := NewOrderBook("crude_oil"), NewOrderBook("natural_gas")
+	ks := NewCommodityKillSwitch(clock, crude, gas)
+	crudeGateway := NewOrderGateway(crude, nil, ks)
+	gasGateway := NewOrderGateway(gas, nil, ks)
+
+	for _, o := range []TradingOrder{
+		{OrderID: "bid", Commodity: "crude_oil", Side: SideBuy, Price: 75, Volume: 10},
+		{OrderID: "ask", Commodity: "crude_oil", Side: SideSell, Price: 76, Volume: 10},
+	} {
+		if _, err := crudeGateway.Submit(o, SubmitOptions{}); err != nil {
+			t.Fatalf("Submit %s failed: %v", o.OrderID, err)
+		}
+	}
+
+	cancelled := ks.Engage("crude_oil", KillSwitchOptions{Duration: 5 * time.Minute, CancelResting: true, Reason: "bad print"})
+	if len(cancelled) != 2 || cancelled[0].OrderID != "bid" {
+		t.Errorf("Expected both resting orders cancelled in arrival order, got %+v", cancelled)
+	}
+	if state := ks.State("crude_oil"); !state.Engaged || state.Reason != "bad print" || !state.Until.Equal(now.Add(5*time.Minute)) {
+		t.Errorf("Unexpected state %+v", state)
+	}
+	_, err := crudeGateway.Submit(TradingOrder{OrderID: "blocked", Commodity: "crude_oil", Side: SideBuy, Price: 75, Volume: 1}, SubmitOptions{})
+	if !errors.Is(err, ErrCommodityFrozen) {
+		t.Errorf("Expected ErrCommodityFrozen, got %v", err)
+	}
+	if _, err := gasGateway.Submit(TradingOrder{OrderID: "gas", Commodity: "natural_gas", Side: SideBuy, Price: 3, Volume: 1}, SubmitOptions{}); err != nil {
+		t.Errorf("Expected other commodities to trade, got %v", err)
+	}
+
+	mu.Lock()
+	now = now.Add(5 * time.Minute)
+	mu.Unlock()
+	if ks.Frozen("crude_oil") || len(ks.Engaged()) != 0 {
+		t.Errorf("Expected the switch to release after its duration, got %+v", ks.Engaged())
+	}
+	if _, err := crudeGateway.Submit(TradingOrder{OrderID: "after", Commodity: "crude_oil", Side: SideBuy, Price: 75, Volume: 1}, SubmitOptions{}); err != nil {
+		t.Errorf("Expected normal flow after release, got %v", err)
+	}
+
+	ks.Engage("natural_gas", KillSwitchOptions{})
+	if _, ok := gas.Order("gas"); !ok {
+		t.Error("Expected resting orders kept without CancelResting")
+	}
+	if !ks.Release("natural_gas") || ks.Frozen("natural_gas") {
+		t.Error("Expected a manual release to lift an untimed freeze")
+	}
+}
@@ -0,0 +1,254 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// L2 feed message types
+const (
+	L2Snapshot = "snapshot"
+	L2Diff     = "diff"
+)
+
+// L2Message is one frame on the level-2 feed. Seq is the book sequence
+// the subscriber holds after applying the frame.
+type L2Message struct {
+	Type      string        `json:"type"`
+	Commodity string        `json:"commodity"`
+	Seq       uint64        `json:"seq"`
+	Snapshot  *BookSnapshot `json:"snapshot,omitempty"`
+	Diff      *BookDiff     `json:"diff,omitempty"`
+}
+
+// FeedConn is the outbound half of a client connection. A WebSocket
+// connection's WriteJSON satisfies it.
+type FeedConn interface {
+	WriteJSON(v interface{}) error
+}
+
+// L2Feed publishes an order book as a full snapshot followed by diffs.
+// Each subscriber has its own writer so a slow client never delays others;
+// a subscriber whose backlog reaches the limit has it discarded and is sent
+// a fresh snapshot instead.
+type L2Feed struct {
+	mu         sync.Mutex
+	book       *OrderBook
+	maxBacklog int
+	last       BookSnapshot
+	subs       map[*L2Subscription]struct{}
+}
+
+// L2Subscription is one client's place on the feed
+type L2Subscription struct {
+	feed    *L2Feed
+	conn    FeedConn
+	mu      sync.Mutex
+	pending []L2Message
+	resync  bool
+	wake    chan struct{}
+	done    chan struct{}
+	closed  sync.Once
+	err     error
+}
+
+// NewL2Feed creates a feed for book; maxBacklog <= 0 allows 256 queued diffs
+func NewL2Feed(book *OrderBook, maxBacklog int) *L2Feed {
+	if maxBacklog <= 0 {
+		maxBacklog = 256
+	}
+	return &L2Feed{
+		book:       book,
+		maxBacklog: maxBacklog,
+		last:       book.Snapshot(),
+		subs:       make(map[*L2Subscription]struct{}),
+	}
+}
+
+// Subscribe starts streaming to conn, beginning with a snapshot
+func (f *L2Feed) Subscribe(conn FeedConn) *L2Subscription {
+	sub := &L2Subscription{
+		feed:   f,
+		conn:   conn,
+		resync: true,
+		wake:   make(chan struct{}, 1),
+		done:   make(chan struct{}),
+	}
+	f.mu.Lock()
+	f.subs[sub] = struct{}{}
+	f.mu.Unlock()
+
+	sub.notify()
+	go sub.run()
+	return sub
+}
+
+// Publish diffs the book against the last published state and queues the
+// diff for every subscriber. It does nothing if the book has not changed.
+func (f *L2Feed) Publish() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	curr := f.book.Snapshot()
+	if curr.Seq == f.last.Seq {
+		return
+	}
+	diff := Diff(f.last, curr)
+	f.last = curr
+	msg := L2Message{Type: L2Diff, Commodity: curr.Commodity, Seq: curr.Seq, Diff: &diff}
+	for sub := range f.subs {
+		sub.enqueue(msg, f.maxBacklog)
+	}
+}
+
+// Run publishes every interval until ctx is cancelled
+func (f *L2Feed) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.Publish()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Resync discards queued diffs and sends a fresh snapshot, as when the
+// client reports a sequence gap
+func (s *L2Subscription) Resync() {
+	s.mu.Lock()
+	s.pending = nil
+	s.resync = true
+	s.mu.Unlock()
+	s.notify()
+}
+
+// Close unsubscribes and stops the writer
+func (s *L2Subscription) Close() {
+	s.closed.Do(func() {
+		s.feed.mu.Lock()
+		delete(s.feed.subs, s)
+		s.feed.mu.Unlock()
+		close(s.done)
+	})
+}
+
+// Err returns the write error that ended the subscription, if any
+func (s *L2Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// enqueue queues a diff; it is called with the feed lock held
+func (s *L2Subscription) enqueue(msg L2Message, maxBacklog int) {
+	s.mu.Lock()
+	if !s.resync {
+		if len(s.pending) >= maxBacklog {
+			s.pending = nil
+			s.resync = true
+		} else {
+			s.pending = append(s.pending, msg)
+		}
+	}
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *L2Subscription) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *L2Subscription) run() {
+	for {
+		select {
+		case <-s.wake:
+		case <-s.done:
+			return
+		}
+		for {
+			msg, ok := s.next()
+			if !ok {
+				break
+			}
+			if err := s.conn.WriteJSON(msg); err != nil {
+				s.mu.Lock()
+				s.err = fmt.Errorf("write l2 %s at seq %d: %w", msg.Type, msg.Seq, err)
+				s.mu.Unlock()
+				s.Close()
+				return
+			}
+		}
+	}
+}
+
+// next takes the next frame to send: a snapshot when resyncing, otherwise the oldest diff
+func (s *L2Subscription) next() (L2Message, bool) {
+	s.mu.Lock()
+	resync := s.resync
+	s.mu.Unlock()
+
+	if resync {
+		// Lock order is feed then subscription, as in Publish, so no diff
+		// can be queued between taking the snapshot and clearing the backlog
+		s.feed.mu.Lock()
+		defer s.feed.mu.Unlock()
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		snap := s.feed.last
+		s.pending = nil
+		s.resync = false
+		return L2Message{Type: L2Snapshot, Commodity: snap.Commodity, Seq: snap.Seq, Snapshot: &snap}, true
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.pending) == 0 {
+		return L2Message{}, false
+	}
+	msg := s.pending[0]
+	s.pending = s.pending[1:]
+	return msg, true
+}
+
+// L2Replica rebuilds a book from feed frames on the client side
+type L2Replica struct {
+	book   BookSnapshot
+	synced bool
+}
+
+// Handle applies a frame. It returns ErrSequenceGap when a diff does not
+// follow the replica's state; the client should then request a resync and
+// ignore diffs until the next snapshot.
+func (r *L2Replica) Handle(msg L2Message) error {
+	switch msg.Type {
+	case L2Snapshot:
+		r.book = *msg.Snapshot
+		r.synced = true
+		return nil
+	case L2Diff:
+		if !r.synced {
+			return nil
+		}
+		next, err := Apply(r.book, *msg.Diff)
+		if err != nil {
+			r.synced = false
+			return err
+		}
+		r.book = next
+		return nil
+	}
+	return fmt.Errorf("unknown l2 message type %q", msg.Type)
+}
+
+// Book returns the replica's current state and whether it is in sync
+func (r *L2Replica) Book() (BookSnapshot, bool) {
+	return r.book, r.synced
+}
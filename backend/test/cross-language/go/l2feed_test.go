@@ -0,0 +1,134 @@
+package integration
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// chanFeedConn hands written frames to the test, optionally holding each write until released
+type chanFeedConn struct {
+	frames chan L2Message
+	gate   chan struct{}
+}
+
+func (c *chanFeedConn) WriteJSON(v interface{}) error {
+	if c.gate != nil {
+		<-c.gate
+	}
+	c.frames <- v.(L2Message)
+	return nil
+}
+
+func nextFrame(t *testing.T, conn *chanFeedConn) L2Message {
+	t.Helper()
+	select {
+	case msg := <-conn.frames:
+		return msg
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for an l2 frame")
+		return L2Message{}
+	}
+}
+
+func l2Book(t *testing.T) *OrderBook {
+	book := NewOrderBook("crude_oil")
+	for _, o := range []TradingOrder{
+		{OrderID: "bid1", Side: SideBuy, Price: 75.40, Volume: 100},
+		{OrderID: "ask1", Side: SideSell, Price: 75.60, Volume: 100},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+	return book
+}
+
+// TestL2FeedSnapshotDiffsAndResync verifies snapshot then diffs, and a resync after a dropped diff
+func TestL2FeedSnapshotDiffsAndResync(t *testing.T) {
+	book := l2Book(t)
+	feed := NewL2Feed(book, 0)
+	conn := &chanFeedConn{frames: make(chan L2Message, 16)}
+	sub := feed.Subscribe(conn)
+	defer sub.Close()
+
+	var replica L2Replica
+	first := nextFrame(t, conn)
+	if first.Type != L2Snapshot || first.Seq != 2 {
+		t.Fatalf("Expected an initial snapshot at seq 2, got %+v", first)
+	}
+	replica.Handle(first)
+
+	book.Add(TradingOrder{OrderID: "bid2", Side: SideBuy, Price: 75.50, Volume: 20})
+	feed.Publish()
+	diff := nextFrame(t, conn)
+	if diff.Type != L2Diff || diff.Diff.FromSeq != 2 || diff.Seq != 3 {
+		t.Fatalf("Expected a diff from 2 to 3, got %+v", diff)
+	}
+	if err := replica.Handle(diff); err != nil {
+		t.Fatalf("Applying diff failed: %v", err)
+	}
+
+	// The next diff is lost in transit
+	book.Cancel("bid1")
+	feed.Publish()
+	nextFrame(t, conn)
+
+	book.Add(TradingOrder{OrderID: "ask2", Side: SideSell, Price: 75.55, Volume: 10})
+	feed.Publish()
+	if err := replica.Handle(nextFrame(t, conn)); !errors.Is(err, ErrSequenceGap) {
+		t.Fatalf("Expected the replica to detect a gap, got %v", err)
+	}
+	if _, synced := replica.Book(); synced {
+		t.Fatal("Expected the replica to be out of sync after a gap")
+	}
+
+	sub.Resync()
+	resync := nextFrame(t, conn)
+	if resync.Type != L2Snapshot {
+		t.Fatalf("Expected a snapshot after resync, got %+v", resync)
+	}
+	replica.Handle(resync)
+	got, synced := replica.Book()
+	if !synced || !reflect.DeepEqual(got, book.Snapshot()) {
+		t.Errorf("Expected the resynced replica to match the book\n got  %+v\n want %+v", got, book.Snapshot())
+	}
+}
+
+// TestL2FeedSlowClientGetsSnapshot verifies a client over its backlog limit is resent a snapshot instead of diffs
+func TestL2FeedSlowClientGetsSnapshot(t *testing.T) {
+	book := l2Book(t)
+	feed := NewL2Feed(book, 2)
+	conn := &chanFeedConn{frames: make(chan L2Message, 16), gate: make(chan struct{})}
+	sub := feed.Subscribe(conn)
+	defer sub.Close()
+
+	for i := 0; i < 5; i++ {
+		book.Amend("bid1", 75.40, float64(90-i))
+		feed.Publish()
+	}
+	close(conn.gate)
+
+	var replica L2Replica
+	for {
+		msg := nextFrame(t, conn)
+		if err := replica.Handle(msg); err != nil {
+			t.Fatalf("Unexpected error applying %+v: %v", msg, err)
+		}
+		if msg.Seq == book.Snapshot().Seq {
+			if msg.Type != L2Snapshot {
+				t.Errorf("Expected the backlog to be replaced by a snapshot, got %s", msg.Type)
+			}
+			break
+		}
+	}
+	if got, _ := replica.Book(); !reflect.DeepEqual(got, book.Snapshot()) {
+		t.Errorf("Expected replica to match the book, got %+v", got)
+	}
+	select {
+	case extra := <-conn.frames:
+		t.Errorf("Expected no stale diffs after the snapshot, got %+v", extra)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
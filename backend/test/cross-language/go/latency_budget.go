@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrBudgetExhausted is returned when an order runs out of processing time
+var ErrBudgetExhausted = errors.New("latency budget exhausted")
+
+// PipelineStage is one named step of order processing. Stages should
+// return promptly once ctx is done, which is how a stage overrunning the
+// budget is cut short.
+type PipelineStage struct {
+	Name string
+	Run  func(ctx context.Context, order TradingOrder) error
+}
+
+// StageTiming is the time one stage took
+type StageTiming struct {
+	Stage    string        `json:"stage"`
+	Duration time.Duration `json:"duration"`
+}
+
+// BudgetReport describes how one order spent its budget
+type BudgetReport struct {
+	OrderID   string        `json:"order_id"`
+	Stages    []StageTiming `json:"stages"`
+	Elapsed   time.Duration `json:"elapsed"`
+	Exhausted bool          `json:"exhausted"`
+	// Stage is where the budget ran out: the stage that overran it, or
+	// the first stage not started because nothing was left
+	Stage string `json:"stage,omitempty"`
+}
+
+// LatencyBudgetConfig controls budget enforcement
+type LatencyBudgetConfig struct {
+	Budget time.Duration
+	// Shed aborts an order with ErrBudgetExhausted once its budget is
+	// spent. Without it the order completes and the report is flagged.
+	Shed bool
+	// OnExhausted is called with the report of every order over budget
+	OnExhausted func(BudgetReport)
+}
+
+// LatencyBudget runs orders through a pipeline of stages against a
+// per-order processing budget, timing each stage
+type LatencyBudget struct {
+	config LatencyBudgetConfig
+	stages []PipelineStage
+
+	mu        sync.Mutex
+	processed uint64
+	exhausted uint64
+}
+
+// NewLatencyBudget creates a budget around the stages, run in order
+func NewLatencyBudget(config LatencyBudgetConfig, stages ...PipelineStage) *LatencyBudget {
+	return &LatencyBudget{config: config, stages: stages}
+}
+
+// Process runs the order through every stage. When shedding, the stages
+// share a context that expires with the budget, and a stage is not started
+// once the accumulated time has used the budget up. The returned report
+// covers the stages that ran.
+func (l *LatencyBudget) Process(ctx context.Context, order TradingOrder) (BudgetReport, error) {
+	start := time.Now()
+	if l.config.Shed {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, start.Add(l.config.Budget))
+		defer cancel()
+	}
+
+	report := BudgetReport{OrderID: order.OrderID}
+	var err error
+	for _, stage := range l.stages {
+		if l.config.Shed && time.Since(start) >= l.config.Budget {
+			if !report.Exhausted {
+				report.Exhausted, report.Stage = true, stage.Name
+			}
+			err = fmt.Errorf("%w: %s not started after %v", ErrBudgetExhausted, stage.Name, time.Since(start))
+			break
+		}
+		stageStart := time.Now()
+		stageErr := stage.Run(ctx, order)
+		report.Stages = append(report.Stages, StageTiming{Stage: stage.Name, Duration: time.Since(stageStart)})
+
+		if time.Since(start) > l.config.Budget && !report.Exhausted {
+			report.Exhausted, report.Stage = true, stage.Name
+		}
+		if stageErr != nil {
+			if l.config.Shed && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				stageErr = fmt.Errorf("%w: %s overran: %v", ErrBudgetExhausted, stage.Name, stageErr)
+			}
+			err = stageErr
+			break
+		}
+	}
+	report.Elapsed = time.Since(start)
+
+	l.mu.Lock()
+	l.processed++
+	if report.Exhausted {
+		l.exhausted++
+	}
+	l.mu.Unlock()
+	if report.Exhausted && l.config.OnExhausted != nil {
+		l.config.OnExhausted(report)
+	}
+	return report, err
+}
+
+// Stats returns how many orders were processed and how many ran over budget
+func (l *LatencyBudget) Stats() (processed, exhausted uint64) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.processed, l.exhausted
+}
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestLatencyBudgetShedsSlowStage verifies a slow stage exhausts the budget and later stages never run
+func TestLatencyBudgetShedsSlowStage(t *testing.T) {
+	var reached []string
+	stage := func(name string, work time.Duration) PipelineStage {
+		return PipelineStage{Name: name, Run: func(ctx context.Context, order TradingOrder) error {
+			reached = append(reached, name)
+			select {
+			case <-time.After(work):
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}}
+	}
+	var observed []BudgetReport
+	budget := NewLatencyBudget(LatencyBudgetConfig{
+		Budget:      50 * time.Millisecond,
+		Shed:        true,
+		OnExhausted: func(r BudgetReport) { observed = append(observed, r) },
+	}, stage("validate", 0), stage("risk", 5*time.Second), stage("book", 0))
+
+	started := time.Now()
+	report, err := budget.Process(context.Background(), TradingOrder{OrderID: "o1"})
+	if !errors.Is(err, ErrBudgetExhausted) {
+		t.Fatalf("Expected ErrBudgetExhausted, got %v", err)
+	}
+	if elapsed := time.Since(started); elapsed > time.Second {
+		t.Errorf("Expected the slow stage to be cut short at the budget, took %v", elapsed)
+	}
+	if len(reached) != 2 || reached[1] != "risk" {
+		t.Errorf("Expected the book stage never to run, reached %v", reached)
+	}
+	if !report.Exhausted || report.Stage != "risk" || len(report.Stages) != 2 {
+		t.Errorf("Expected the report to blame the risk stage, got %+v", report)
+	}
+	if len(observed) != 1 || observed[0].OrderID != "o1" {
+		t.Errorf("Expected exhaustion to be observed once, got %+v", observed)
+	}
+
+	// A stage ignoring its context still stops the next stage starting
+	reached = nil
+	stubborn := NewLatencyBudget(LatencyBudgetConfig{Budget: 10 * time.Millisecond, Shed: true},
+		PipelineStage{Name: "slow", Run: func(ctx context.Context, order TradingOrder) error {
+			reached = append(reached, "slow")
+			time.Sleep(20 * time.Millisecond)
+			return nil
+		}}, stage("book", 0))
+	if _, err := stubborn.Process(context.Background(), TradingOrder{OrderID: "o2"}); !errors.Is(err, ErrBudgetExhausted) {
+		t.Errorf("Expected ErrBudgetExhausted before the book stage, got %v", err)
+	}
+	if len(reached) != 1 {
+		t.Errorf("Expected only the slow stage to run, reached %v", reached)
+	}
+	if processed, exhausted := stubborn.Stats(); processed != 1 || exhausted != 1 {
+		t.Errorf("Expected 1/1 processed/exhausted, got %d/%d", processed, exhausted)
+	}
+}
+
+// TestLatencyBudgetFlagsWithoutShedding verifies orders complete but are flagged when shedding is off
+func TestLatencyBudgetFlagsWithoutShedding(t *testing.T) {
+	ran := 0
+	budget := NewLatencyBudget(LatencyBudgetConfig{Budget: time.Millisecond},
+		PipelineStage{Name: "slow", Run: func(ctx context.Context, order TradingOrder) error {
+			ran++
+			time.Sleep(5 * time.Millisecond)
+			return ctx.Err()
+		}},
+		PipelineStage{Name: "book", Run: func(ctx context.Context, order TradingOrder) error { ran++; return nil }})
+
+	report, err := budget.Process(context.Background(), TradingOrder{OrderID: "o1"})
+	if err != nil || ran != 2 {
+		t.Fatalf("Expected every stage to run, ran %d: %v", ran, err)
+	}
+	if !report.Exhausted || report.Stage != "slow" {
+		t.Errorf("Expected the report flagged at the slow stage, got %+v", report)
+	}
+}
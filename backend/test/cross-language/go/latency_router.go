@@ -0,0 +1,198 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// ErrNoRoute is returned when no venue quotes the side an order needs
+var ErrNoRoute = errors.New("no venue quoting")
+
+// LatencyRouterConfig tunes latency tracking for a LatencyRouter
+type LatencyRouterConfig struct {
+	// Alpha is the EWMA weight given to each new round trip
+	Alpha float64
+	// StaleAfter is how long a venue may go without a round trip before
+	// its estimate is no longer trusted; default 1m
+	StaleAfter time.Duration
+	// MaxLatency excludes venues slower than this unless no other venue
+	// quotes the price; zero disables the limit
+	MaxLatency time.Duration
+}
+
+// venueLatency is the round-trip estimate for one venue
+type venueLatency struct {
+	ewma    float64   // nanoseconds
+	at      time.Time // time of the latest sample
+	samples *PercentileEstimator
+}
+
+// LatencyRouter routes orders to the venue with the best price and breaks
+// price ties by measured round-trip latency. An estimate goes stale once a
+// venue has seen no samples for StaleAfter: it keeps its last value rather
+// than drifting, but the venue is no longer excluded as slow and ranks
+// behind every venue with a fresh estimate at its price, unmeasured venues
+// included. A venue excluded for being slow can therefore win on price
+// again once it goes quiet, and the sample that order brings back replaces
+// the stale estimate outright.
+type LatencyRouter struct {
+	mu      sync.Mutex
+	quotes  ConsolidatedQuoteSource
+	config  LatencyRouterConfig
+	clock   func() time.Time
+	latency map[string]*venueLatency
+	venues  *ExchangeMap
+}
+
+// NewLatencyRouter creates a router; a nil clock uses time.Now
+func NewLatencyRouter(quotes ConsolidatedQuoteSource, config LatencyRouterConfig, clock func() time.Time) *LatencyRouter {
+	if config.Alpha <= 0 || config.Alpha > 1 {
+		config.Alpha = 0.2
+	}
+	if config.StaleAfter <= 0 {
+		config.StaleAfter = time.Minute
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	return &LatencyRouter{
+		quotes:  quotes,
+		config:  config,
+		clock:   clock,
+		latency: make(map[string]*venueLatency),
+	}
+}
+
+// ObserveRoundTrip records a measured round trip to venue. The first
+// sample after the estimate went stale replaces it.
+func (r *LatencyRouter) ObserveRoundTrip(venue string, rtt time.Duration) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock()
+	v, ok := r.latency[venue]
+	switch {
+	case !ok:
+		v = &venueLatency{ewma: float64(rtt), samples: NewPercentileEstimator(0)}
+		r.latency[venue] = v
+	case r.staleLocked(v, now):
+		v.ewma = float64(rtt)
+	default:
+		v.ewma += r.config.Alpha * (float64(rtt) - v.ewma)
+	}
+	v.at = now
+	v.samples.Add(float64(rtt))
+}
+
+// Latency returns the round-trip estimate for venue and whether it is
+// fresh; unmeasured venues report zero and false
+func (r *LatencyRouter) Latency(venue string) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.latencyLocked(venue, r.clock())
+}
+
+// SetExchangeMap restricts routing to each commodity's supported exchanges.
+// Orders naming an Exchange always go there.
+func (r *LatencyRouter) SetExchangeMap(venues *ExchangeMap) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.venues = venues
+}
+
+// Percentile returns the q quantile of every round trip measured for venue
+func (r *LatencyRouter) Percentile(venue string, q float64) (time.Duration, bool) {
+	r.mu.Lock()
+	v, ok := r.latency[venue]
+	r.mu.Unlock()
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(v.samples.Quantile(q)), true
+}
+
+// Route picks the venue for order. Buys go to the lowest ask and sells to
+// the highest bid; venues at the same price are ranked by latency, with
+// stale and unmeasured venues after those with fresh estimates. Orders
+// naming an Exchange are held to it. With an exchange map, other orders are
+// held to the commodity's supported exchanges, and go to its default
+// exchange when none of them is quoting.
+func (r *LatencyRouter) Route(order TradingOrder) (Route, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var allowed map[string]bool
+	fallback := order.Exchange
+	switch {
+	case order.Exchange != "":
+		allowed = map[string]bool{order.Exchange: true}
+	case r.venues != nil:
+		def, err := r.venues.DefaultExchange(order.Commodity)
+		if err != nil {
+			return Route{}, err
+		}
+		fallback = def
+		allowed = make(map[string]bool)
+		for _, venue := range r.venues.SupportedExchanges(order.Commodity) {
+			allowed[venue] = true
+		}
+	}
+
+	now := r.clock()
+	var best Route
+	var bestLatency time.Duration
+	bestEligible, bestFresh := false, false
+	found := false
+	for _, q := range r.quotes.VenueQuotes(order.Commodity) {
+		price, size := q.Ask, q.AskSize
+		if order.Side == SideSell {
+			price, size = q.Bid, q.BidSize
+		}
+		if size <= 0 || (allowed != nil && !allowed[q.Venue]) {
+			continue
+		}
+		latency, fresh := r.latencyLocked(q.Venue, now)
+		eligible := !fresh || r.config.MaxLatency <= 0 || latency <= r.config.MaxLatency
+
+		better := !found
+		if found {
+			switch {
+			case eligible != bestEligible:
+				better = eligible
+			case math.Abs(price-best.Price) > volumeEpsilon:
+				better = (order.Side == SideBuy) == (price < best.Price)
+			case fresh != bestFresh:
+				better = fresh
+			default:
+				better = latency < bestLatency
+			}
+		}
+		if better {
+			best = Route{Venue: q.Venue, Price: price}
+			bestLatency, bestEligible, bestFresh, found = latency, eligible, fresh, true
+		}
+	}
+	if !found && fallback != "" {
+		return Route{Venue: fallback}, nil
+	}
+	if !found {
+		return Route{}, fmt.Errorf("%w: %s %s", ErrNoRoute, order.Side, order.Commodity)
+	}
+	return best, nil
+}
+
+func (r *LatencyRouter) latencyLocked(venue string, now time.Time) (time.Duration, bool) {
+	v, ok := r.latency[venue]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(v.ewma), !r.staleLocked(v, now)
+}
+
+// staleLocked reports whether v has gone without samples for StaleAfter
+func (r *LatencyRouter) staleLocked(v *venueLatency, now time.Time) bool {
+	return now.Sub(v.at) > r.config.StaleAfter
+}
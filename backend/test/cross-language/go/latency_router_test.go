@@ -0,0 +1,74 @@
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// TestLatencyRouterPrefersFasterVenueOnTie verifies equal prices route to the lower-latency venue
+func TestLatencyRouterPrefersFasterVenueOnTie(t *testing.T) {
+	quotes := fakeConsolidatedQuotes{"crude_oil": {
+		{Venue: "nymex", Bid: 75.40, BidSize: 100, Ask: 75.60, AskSize: 100},
+		{Venue: "ice", Bid: 75.40, BidSize: 100, Ask: 75.60, AskSize: 100},
+		{Venue: "dme", Bid: 75.30, BidSize: 100, Ask: 75.70, AskSize: 100},
+	}}
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	router := NewLatencyRouter(quotes, LatencyRouterConfig{StaleAfter: time.Minute, MaxLatency: 20 * time.Millisecond}, func() time.Time { return now })
+
+	for i := 0; i < 10; i++ {
+		router.ObserveRoundTrip("nymex", 8*time.Millisecond)
+		router.ObserveRoundTrip("ice", 3*time.Millisecond)
+		router.ObserveRoundTrip("dme", time.Millisecond)
+	}
+
+	buy := TradingOrder{OrderID: "o1", Commodity: "crude_oil", Side: SideBuy, Volume: 10}
+	route, err := router.Route(buy)
+	if err != nil {
+		t.Fatalf("Route failed: %v", err)
+	}
+	if route.Venue != "ice" || route.Price != 75.60 {
+		t.Errorf("Expected the faster of the tied venues (ice at 75.60), got %+v", route)
+	}
+	if p50, _ := router.Percentile("ice", 0.5); p50 != 3*time.Millisecond {
+		t.Errorf("Expected ice p50 of 3ms, got %v", p50)
+	}
+
+	// ice degrades past the latency limit and loses the tie
+	now = now.Add(time.Second)
+	for i := 0; i < 20; i++ {
+		router.ObserveRoundTrip("ice", 200*time.Millisecond)
+	}
+	if route, _ := router.Route(buy); route.Venue != "nymex" {
+		t.Errorf("Expected nymex once ice is slow, got %+v", route)
+	}
+
+	// A slow venue stays excluded even when it improves its price
+	quotes["crude_oil"][1].Ask = 75.55
+	if route, _ := router.Route(buy); route.Venue != "nymex" {
+		t.Errorf("Expected the slow ice excluded despite its price, got %+v", route)
+	}
+
+	// Once quiet its estimate goes stale rather than decaying: it can win on
+	// price again, but ranks behind fresh venues on a tie
+	now = now.Add(10 * time.Minute)
+	router.ObserveRoundTrip("nymex", 8*time.Millisecond)
+	if latency, fresh := router.Latency("ice"); fresh || latency < 100*time.Millisecond {
+		t.Errorf("Expected ice's slow estimate kept but stale, got %v (fresh %v)", latency, fresh)
+	}
+	if route, _ := router.Route(buy); route.Venue != "ice" {
+		t.Errorf("Expected stale ice to win on price, got %+v", route)
+	}
+	quotes["crude_oil"][1].Ask = 75.60
+	if route, _ := router.Route(buy); route.Venue != "nymex" {
+		t.Errorf("Expected stale ice ranked behind fresh nymex on a tie, got %+v", route)
+	}
+
+	// The first sample after the gap replaces the stale estimate
+	router.ObserveRoundTrip("ice", 3*time.Millisecond)
+	if latency, fresh := router.Latency("ice"); !fresh || latency != 3*time.Millisecond {
+		t.Errorf("Expected a fresh 3ms estimate for ice, got %v (fresh %v)", latency, fresh)
+	}
+	if route, _ := router.Route(buy); route.Venue != "ice" {
+		t.Errorf("Expected recovered ice to win the tie again, got %+v", route)
+	}
+}
@@ -0,0 +1,81 @@
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// RiskLimits bounds a desk's trading in one commodity
+type RiskLimits struct {
+	MaxPosition    float64 `json:"max_position"`
+	MaxOrderVolume float64 `json:"max_order_volume"`
+	MaxNotional    float64 `json:"max_notional"`
+}
+
+// LimitWindow applies limits over part of the trading day. Start is
+// inclusive and End exclusive, both as wall-clock times of day. When
+// RampTo is set and the schedule interpolates, limits move linearly from
+// Limits at Start to RampTo at End.
+type LimitWindow struct {
+	Start  time.Duration `json:"start"`
+	End    time.Duration `json:"end"`
+	Limits RiskLimits    `json:"limits"`
+	RampTo *RiskLimits   `json:"ramp_to,omitempty"`
+}
+
+// LimitSchedule resolves the active risk limits for a commodity by time of day
+type LimitSchedule struct {
+	location    *time.Location
+	windows     map[string][]LimitWindow
+	fallback    RiskLimits
+	interpolate bool
+}
+
+// NewLimitSchedule creates a schedule evaluated in loc. Times outside every
+// window get the conservative fallback limits.
+func NewLimitSchedule(loc *time.Location, fallback RiskLimits, interpolate bool, windows map[string][]LimitWindow) (*LimitSchedule, error) {
+	if loc == nil {
+		loc = time.UTC
+	}
+	sorted := make(map[string][]LimitWindow, len(windows))
+	for commodity, ws := range windows {
+		ws = append([]LimitWindow(nil), ws...)
+		sort.Slice(ws, func(i, j int) bool { return ws[i].Start < ws[j].Start })
+		for i, w := range ws {
+			if w.End <= w.Start || w.End > 24*time.Hour {
+				return nil, fmt.Errorf("%s window %d: invalid span %v-%v", commodity, i, w.Start, w.End)
+			}
+			if i > 0 && w.Start < ws[i-1].End {
+				return nil, fmt.Errorf("%s windows %d and %d overlap", commodity, i-1, i)
+			}
+		}
+		sorted[commodity] = ws
+	}
+	return &LimitSchedule{location: loc, windows: sorted, fallback: fallback, interpolate: interpolate}, nil
+}
+
+// Active returns the limits in force for a commodity at t
+func (s *LimitSchedule) Active(commodity string, t time.Time) RiskLimits {
+	offset := timeOfDay(t.In(s.location))
+
+	for _, w := range s.windows[commodity] {
+		if offset < w.Start || offset >= w.End {
+			continue
+		}
+		if !s.interpolate || w.RampTo == nil {
+			return w.Limits
+		}
+		frac := float64(offset-w.Start) / float64(w.End-w.Start)
+		return RiskLimits{
+			MaxPosition:    lerp(w.Limits.MaxPosition, w.RampTo.MaxPosition, frac),
+			MaxOrderVolume: lerp(w.Limits.MaxOrderVolume, w.RampTo.MaxOrderVolume, frac),
+			MaxNotional:    lerp(w.Limits.MaxNotional, w.RampTo.MaxNotional, frac),
+		}
+	}
+	return s.fallback
+}
+
+func lerp(a, b, frac float64) float64 {
+	return a + (b-a)*frac
+}
@@ -0,0 +1,96 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestLimitSchedule verifies mid-window, boundary, and outside-window limits in the desk's timezone
+func TestLimitSchedule(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	fallback := RiskLimits{MaxPosition: 1000, MaxOrderVolume: 100, MaxNotional: 75000}
+	liquid := RiskLimits{MaxPosition: 5000, MaxOrderVolume: 500, MaxNotional: 375000}
+	windows := map[string][]LimitWindow{
+		"brent": {
+			{Start: 8 * time.Hour, End: 12 * time.Hour, Limits: fallback, RampTo: &liquid},
+			{Start: 12 * time.Hour, End: 16*time.Hour + 30*time.Minute, Limits: liquid},
+		},
+	}
+
+	stepped, err := NewLimitSchedule(london, fallback, false, windows)
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+	ramped, _ := NewLimitSchedule(london, fallback, true, windows)
+
+	// Summer time: 13:00 UTC is 14:00 in London.
+	midWindow := time.Date(2024, 7, 1, 13, 0, 0, 0, time.UTC)
+	if got := stepped.Active("brent", midWindow); got != liquid {
+		t.Errorf("Expected liquid limits mid-window, got %+v", got)
+	}
+
+	boundary := time.Date(2024, 7, 1, 16, 30, 0, 0, london)
+	if got := stepped.Active("brent", boundary); got != fallback {
+		t.Errorf("Expected fallback at the exclusive window end, got %+v", got)
+	}
+	if got := stepped.Active("brent", time.Date(2024, 7, 1, 12, 0, 0, 0, london)); got != liquid {
+		t.Errorf("Expected liquid limits at the inclusive window start, got %+v", got)
+	}
+
+	outside := time.Date(2024, 7, 1, 5, 0, 0, 0, london)
+	if got := stepped.Active("brent", outside); got != fallback {
+		t.Errorf("Expected fallback outside windows, got %+v", got)
+	}
+	if got := stepped.Active("wti", midWindow); got != fallback {
+		t.Errorf("Expected fallback for unscheduled commodity, got %+v", got)
+	}
+
+	rampMid := time.Date(2024, 7, 1, 10, 0, 0, 0, london)
+	if got := ramped.Active("brent", rampMid); math.Abs(got.MaxPosition-3000) > 1e-9 {
+		t.Errorf("Expected interpolated max position 3000, got %f", got.MaxPosition)
+	}
+	if got := stepped.Active("brent", rampMid); got != fallback {
+		t.Errorf("Expected stepped schedule to hold window limits, got %+v", got)
+	}
+}
+
+// TestLimitScheduleOnDSTDay verifies windows follow the local clock on the day clocks go forward
+func TestLimitScheduleOnDSTDay(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	fallback := RiskLimits{MaxPosition: 1000, MaxOrderVolume: 100, MaxNotional: 75000}
+	liquid := RiskLimits{MaxPosition: 5000, MaxOrderVolume: 500, MaxNotional: 375000}
+	schedule, err := NewLimitSchedule(newYork, fallback, false, map[string][]LimitWindow{
+		"wti": {{Start: 9*time.Hour + 30*time.Minute, End: 16*time.Hour + 30*time.Minute, Limits: liquid}},
+	})
+	if err != nil {
+		t.Fatalf("Failed to create schedule: %v", err)
+	}
+
+	// 2024-03-10 skips 02:00-03:00, so only 15.5 hours have passed since midnight at 16:30
+	if got := schedule.Active("wti", time.Date(2024, 3, 10, 16, 30, 0, 0, newYork)); got != fallback {
+		t.Errorf("Expected fallback at 16:30 local on the DST day, got %+v", got)
+	}
+	if got := schedule.Active("wti", time.Date(2024, 3, 10, 9, 30, 0, 0, newYork)); got != liquid {
+		t.Errorf("Expected liquid limits from 09:30 local on the DST day, got %+v", got)
+	}
+}
+
+// TestLimitScheduleRejectsOverlap verifies overlapping windows are invalid
+func TestLimitScheduleRejectsOverlap(t *testing.T) {
+	_, err := NewLimitSchedule(time.UTC, RiskLimits{}, false, map[string][]LimitWindow{
+		"brent": {
+			{Start: 8 * time.Hour, End: 12 * time.Hour},
+			{Start: 11 * time.Hour, End: 14 * time.Hour},
+		},
+	})
+	if err == nil {
+		t.Error("Expected overlapping windows to be rejected")
+	}
+}
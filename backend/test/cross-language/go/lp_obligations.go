@@ -0,0 +1,182 @@
+package integration
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LPObligation is the quoting standard a liquidity provider must keep in
+// one commodity to earn its rebate
+type LPObligation struct {
+	// MinSize is the displayed volume each side must show
+	MinSize float64 `json:"min_size"`
+	// MaxSpread is the widest the LP's own bid-ask may be; zero is no limit
+	MaxSpread float64 `json:"max_spread"`
+	// RequireTouch counts only time with both sides at the best price
+	RequireTouch bool `json:"require_touch"`
+	// MinCompliance is the percentage of the session the standard must be
+	// met for the LP to be eligible
+	MinCompliance float64 `json:"min_compliance"`
+}
+
+// shows reports whether a side's displayed volume meets the minimum size
+func (o LPObligation) shows(volume float64) bool {
+	return volume > volumeEpsilon && volume >= o.MinSize-volumeEpsilon
+}
+
+// LPCompliance is how much of its session an LP met its obligation
+type LPCompliance struct {
+	ClientID  string        `json:"client_id"`
+	Commodity string        `json:"commodity"`
+	Session   time.Duration `json:"session"`
+	// TwoSided is time quoting both sides at MinSize within MaxSpread
+	TwoSided time.Duration `json:"two_sided"`
+	// AtTouch is time two-sided with both sides at the best price
+	AtTouch time.Duration `json:"at_touch"`
+	// CompliancePct is the percentage of Session the obligation was met
+	CompliancePct float64 `json:"compliance_pct"`
+	Eligible      bool    `json:"eligible"`
+}
+
+type lpRef struct {
+	clientID  string
+	commodity string
+}
+
+type lpState struct {
+	compliance LPCompliance
+	sampled    time.Time
+	twoSided   bool // as of the last sample
+	atTouch    bool
+}
+
+type lpMarket struct {
+	book       *OrderBook
+	obligation LPObligation
+}
+
+// LPObligationTracker measures each liquidity provider's quoting against
+// its obligation in every commodity it is registered for. Quoting state is
+// sampled from the books, and the time from one sample to the next is
+// credited to the state seen at the first, so accuracy is bounded by how
+// often Sample runs; sampling after each book change measures it exactly.
+type LPObligationTracker struct {
+	mu      sync.Mutex
+	clock   func() time.Time
+	markets map[string]lpMarket
+	lps     map[lpRef]*lpState
+}
+
+// NewLPObligationTracker creates a tracker; a nil clock uses time.Now
+func NewLPObligationTracker(clock func() time.Time) *LPObligationTracker {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &LPObligationTracker{
+		clock:   clock,
+		markets: make(map[string]lpMarket),
+		lps:     make(map[lpRef]*lpState),
+	}
+}
+
+// Register sets the obligation for the book's commodity and tracks the
+// given LPs against it. An LP's session starts at its first sample;
+// re-registering an LP keeps the time it has accrued.
+func (t *LPObligationTracker) Register(book *OrderBook, obligation LPObligation, clientIDs ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	commodity := book.Commodity()
+	t.markets[commodity] = lpMarket{book: book, obligation: obligation}
+	for _, id := range clientIDs {
+		ref := lpRef{clientID: id, commodity: commodity}
+		if _, ok := t.lps[ref]; !ok {
+			t.lps[ref] = &lpState{compliance: LPCompliance{ClientID: id, Commodity: commodity}}
+		}
+	}
+}
+
+// Sample credits the time since each LP's previous sample and reads its
+// current quoting state
+func (t *LPObligationTracker) Sample() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.clock()
+	for ref, state := range t.lps {
+		market := t.markets[ref.commodity]
+		if !state.sampled.IsZero() && now.After(state.sampled) {
+			elapsed := now.Sub(state.sampled)
+			state.compliance.Session += elapsed
+			if state.twoSided {
+				state.compliance.TwoSided += elapsed
+			}
+			if state.atTouch {
+				state.compliance.AtTouch += elapsed
+			}
+		}
+		state.sampled = now
+
+		quote := market.book.ClientQuote(ref.clientID)
+		ob := market.obligation
+		state.twoSided = ob.shows(quote.BidVolume) && ob.shows(quote.AskVolume) &&
+			(ob.MaxSpread <= 0 || quote.Ask-quote.Bid <= ob.MaxSpread+volumeEpsilon)
+		state.atTouch = state.twoSided && quote.BidAtTouch && quote.AskAtTouch
+	}
+}
+
+// Run samples every interval until ctx is done
+func (t *LPObligationTracker) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.Sample()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Compliance returns an LP's compliance in a commodity up to the last sample
+func (t *LPObligationTracker) Compliance(clientID, commodity string) (LPCompliance, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	state, ok := t.lps[lpRef{clientID: clientID, commodity: commodity}]
+	if !ok {
+		return LPCompliance{}, false
+	}
+	return t.complianceLocked(state), true
+}
+
+// Report returns every tracked LP's compliance, by commodity then client
+func (t *LPObligationTracker) Report() []LPCompliance {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	report := make([]LPCompliance, 0, len(t.lps))
+	for _, state := range t.lps {
+		report = append(report, t.complianceLocked(state))
+	}
+	sort.Slice(report, func(i, j int) bool {
+		if report[i].Commodity != report[j].Commodity {
+			return report[i].Commodity < report[j].Commodity
+		}
+		return report[i].ClientID < report[j].ClientID
+	})
+	return report
+}
+
+func (t *LPObligationTracker) complianceLocked(state *lpState) LPCompliance {
+	c := state.compliance
+	obligation := t.markets[c.Commodity].obligation
+	met := c.TwoSided
+	if obligation.RequireTouch {
+		met = c.AtTouch
+	}
+	if c.Session > 0 {
+		c.CompliancePct = 100 * float64(met) / float64(c.Session)
+		c.Eligible = c.CompliancePct >= obligation.MinCompliance
+	}
+	return c
+}
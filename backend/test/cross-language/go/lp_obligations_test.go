@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestLPObligationTrackerMeasuresPartialCompliance verifies an LP that
+// meets its obligation for part of the session gets that share as its
+// compliance percentage
+func TestLPObligationTrackerMeasuresPartialCompliance(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	book := NewOrderBook("crude_oil", WithClock(clock))
+	tracker := NewLPObligationTracker(clock)
+	tracker.Register(book, LPObligation{MinSize: 10, MaxSpread: 0.5, RequireTouch: true, MinCompliance: 70}, "lp1", "lp2")
+
+	add := func(o TradingOrder) {
+		t.Helper()
+		o.Commodity, o.Type = "crude_oil", OrderTypeLimit
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Expected %s to be accepted, got %v", o.OrderID, err)
+		}
+	}
+	// Sample every second for d, as a scheduled sampler would
+	run := func(d time.Duration) {
+		for end := now.Add(d); now.Before(end); {
+			now = now.Add(time.Second)
+			tracker.Sample()
+		}
+	}
+
+	tracker.Sample()
+	add(TradingOrder{OrderID: "lp1-b", ClientID: "lp1", Side: SideBuy, Price: 74.9, Volume: 20})
+	add(TradingOrder{OrderID: "lp1-a", ClientID: "lp1", Side: SideSell, Price: 75.1, Volume: 20})
+	tracker.Sample()
+	run(50 * time.Second) // lp1 two-sided at the touch
+
+	add(TradingOrder{OrderID: "lp2-b", ClientID: "lp2", Side: SideBuy, Price: 75.0, Volume: 5})
+	tracker.Sample()
+	run(20 * time.Second) // lp1 two-sided but outbid
+
+	if err := book.Cancel("lp2-b"); err != nil {
+		t.Fatalf("Failed to cancel: %v", err)
+	}
+	if err := book.ReduceQuantity("lp1-a", 15); err != nil {
+		t.Fatalf("Failed to reduce: %v", err)
+	}
+	tracker.Sample()
+	run(30 * time.Second) // lp1 ask below minimum size
+
+	c, ok := tracker.Compliance("lp1", "crude_oil")
+	if !ok {
+		t.Fatal("Expected lp1 to be tracked")
+	}
+	if c.Session != 100*time.Second || c.TwoSided != 70*time.Second || c.AtTouch != 50*time.Second {
+		t.Errorf("Expected 100s session, 70s two-sided and 50s at touch, got %+v", c)
+	}
+	if math.Abs(c.CompliancePct-50) > 1e-9 || c.Eligible {
+		t.Errorf("Expected 50%% compliance and no rebate, got %+v", c)
+	}
+
+	// lp2 quoted one side only, below size, so never complied
+	report := tracker.Report()
+	if len(report) != 2 || report[1].ClientID != "lp2" || report[1].CompliancePct != 0 || report[1].TwoSided != 0 {
+		t.Errorf("Expected lp2 at 0%% compliance, got %+v", report)
+	}
+
+	// Relaxing the touch requirement counts presence instead
+	tracker.Register(book, LPObligation{MinSize: 10, MaxSpread: 0.5, MinCompliance: 70})
+	if c, _ := tracker.Compliance("lp1", "crude_oil"); math.Abs(c.CompliancePct-70) > 1e-9 || !c.Eligible {
+		t.Errorf("Expected 70%% two-sided compliance to be eligible, got %+v", c)
+	}
+}
@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// MarginRate holds a commodity's margin requirements as fractions of
+// contract notional
+type MarginRate struct {
+	Commodity   string  `json:"commodity"`
+	Initial     float64 `json:"initial"`
+	Maintenance float64 `json:"maintenance"`
+	Multiplier  float64 `json:"multiplier"` // units per contract; zero means 1
+}
+
+// MarginCalculator computes margin from per-commodity rates
+type MarginCalculator struct {
+	mu    sync.RWMutex
+	rates map[string]MarginRate
+}
+
+// NewMarginCalculator creates a calculator from the given rates
+func NewMarginCalculator(rates ...MarginRate) *MarginCalculator {
+	c := &MarginCalculator{rates: make(map[string]MarginRate, len(rates))}
+	for _, rate := range rates {
+		c.rates[rate.Commodity] = rate
+	}
+	return c
+}
+
+// SetRate adds or replaces a commodity's rate
+func (c *MarginCalculator) SetRate(rate MarginRate) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rates[rate.Commodity] = rate
+}
+
+// Rate returns the rate for a commodity
+func (c *MarginCalculator) Rate(commodity string) (MarginRate, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	rate, ok := c.rates[commodity]
+	return rate, ok
+}
+
+// InitialMargin returns the margin required to open position contracts at
+// price; commodities without a rate require none
+func (c *MarginCalculator) InitialMargin(position float64, commodity string, price float64) float64 {
+	rate, _ := c.Rate(commodity)
+	return c.notional(position, rate, price) * rate.Initial
+}
+
+// MaintenanceMargin returns the margin required to keep position open at price
+func (c *MarginCalculator) MaintenanceMargin(position float64, commodity string, price float64) float64 {
+	rate, _ := c.Rate(commodity)
+	return c.notional(position, rate, price) * rate.Maintenance
+}
+
+func (c *MarginCalculator) notional(position float64, rate MarginRate, price float64) float64 {
+	return math.Abs(position) * multiplierOf(rate) * price
+}
+
+func multiplierOf(rate MarginRate) float64 {
+	if rate.Multiplier == 0 {
+		return 1
+	}
+	return rate.Multiplier
+}
+
+// MarginCall is raised when a client's equity falls below maintenance margin
+type MarginCall struct {
+	ClientID    string    `json:"client_id"`
+	Equity      float64   `json:"equity"`
+	Maintenance float64   `json:"maintenance"`
+	Initial     float64   `json:"initial"`
+	Shortfall   float64   `json:"shortfall"` // deposit needed to restore initial margin
+	At          time.Time `json:"at"`
+}
+
+// Alert converts the call into a notifier alert
+func (m MarginCall) Alert() Alert {
+	return Alert{
+		Severity:  SeverityCritical,
+		Title:     "margin call: " + m.ClientID,
+		Detail:    fmt.Sprintf("equity %.2f below maintenance %.2f; deposit %.2f to restore initial margin", m.Equity, m.Maintenance, m.Shortfall),
+		Timestamp: m.At,
+	}
+}
+
+// MarginMonitor marks client positions to market and raises a margin call
+// when equity drops below the maintenance requirement. Equity is posted
+// collateral plus unrealized P&L on open positions; realized P&L is expected
+// to reach collateral through settlement. A client is called once until equity recovers
+// above maintenance.
+type MarginMonitor struct {
+	mu         sync.Mutex
+	calc       *MarginCalculator
+	positions  *PositionTracker
+	onCall     func(MarginCall)
+	clock      func() time.Time
+	collateral map[string]float64
+	marks      map[string]float64
+	called     map[string]bool
+}
+
+// NewMarginMonitor creates a monitor; a nil clock uses time.Now
+func NewMarginMonitor(calc *MarginCalculator, positions *PositionTracker, onCall func(MarginCall), clock func() time.Time) *MarginMonitor {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &MarginMonitor{
+		calc:       calc,
+		positions:  positions,
+		onCall:     onCall,
+		clock:      clock,
+		collateral: make(map[string]float64),
+		marks:      make(map[string]float64),
+		called:     make(map[string]bool),
+	}
+}
+
+// SetCollateral sets a client's posted collateral
+func (m *MarginMonitor) SetCollateral(clientID string, amount float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.collateral[clientID] = amount
+}
+
+// OnPrice marks commodity at price and evaluates every client, returning
+// the margin calls raised
+func (m *MarginMonitor) OnPrice(commodity string, price float64) []MarginCall {
+	m.mu.Lock()
+	m.marks[commodity] = price
+	m.mu.Unlock()
+
+	var calls []MarginCall
+	for _, clientID := range m.positions.Clients() {
+		if call, ok := m.Evaluate(clientID); ok {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// Evaluate checks one client and raises a call if it is newly under
+// maintenance. The callback runs after the monitor is unlocked, so it may
+// call back into the monitor, for example to post collateral.
+func (m *MarginMonitor) Evaluate(clientID string) (MarginCall, bool) {
+	m.mu.Lock()
+	call, ok := m.evaluateLocked(clientID)
+	m.mu.Unlock()
+
+	if ok && m.onCall != nil {
+		m.onCall(call)
+	}
+	return call, ok
+}
+
+// evaluateLocked marks a client's positions and returns a call if it is
+// newly under maintenance
+func (m *MarginMonitor) evaluateLocked(clientID string) (MarginCall, bool) {
+	call := MarginCall{ClientID: clientID, Equity: m.collateral[clientID], At: m.clock()}
+	for _, commodity := range m.positionCommodities(clientID) {
+		pos, ok := m.positions.Position(clientID, commodity)
+		if !ok {
+			continue
+		}
+		mark, marked := m.marks[commodity]
+		if !marked {
+			mark = pos.AvgPrice
+		}
+		rate, _ := m.calc.Rate(commodity)
+		call.Equity += (mark - pos.AvgPrice) * pos.Volume * multiplierOf(rate)
+		call.Maintenance += m.calc.MaintenanceMargin(pos.Volume, commodity, mark)
+		call.Initial += m.calc.InitialMargin(pos.Volume, commodity, mark)
+	}
+
+	if call.Equity >= call.Maintenance {
+		delete(m.called, clientID)
+		return MarginCall{}, false
+	}
+	if m.called[clientID] {
+		return MarginCall{}, false
+	}
+	m.called[clientID] = true
+	call.Shortfall = call.Initial - call.Equity
+	return call, true
+}
+
+// positionCommodities lists the commodities a client has positions in
+func (m *MarginMonitor) positionCommodities(clientID string) []string {
+	report := m.positions.ClientExposure(clientID)
+	commodities := make([]string, 0, len(report.Commodities))
+	for _, c := range report.Commodities {
+		commodities = append(commodities, c.Commodity)
+	}
+	return commodities
+}
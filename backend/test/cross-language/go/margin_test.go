@@ -0,0 +1,91 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestMarginCalculatorRates verifies margin scales with multiplier and price for longs and shorts
+func TestMarginCalculatorRates(t *testing.T) {
+	calc := NewMarginCalculator(MarginRate{Commodity: "crude_oil", Initial: 0.10, Maintenance: 0.08, Multiplier: 1000})
+
+	if got := calc.InitialMargin(2, "crude_oil", 75); math.Abs(got-15000) > 1e-6 {
+		t.Errorf("Expected initial margin 15000, got %g", got)
+	}
+	if got := calc.MaintenanceMargin(-2, "crude_oil", 75); math.Abs(got-12000) > 1e-6 {
+		t.Errorf("Expected short maintenance margin 12000, got %g", got)
+	}
+	if got := calc.InitialMargin(2, "unlisted", 75); got != 0 {
+		t.Errorf("Expected no margin without a rate, got %g", got)
+	}
+}
+
+// TestMarginMonitorCallsAfterPriceMove verifies a long breaching maintenance after a drop raises one call
+func TestMarginMonitorCallsAfterPriceMove(t *testing.T) {
+	calc := NewMarginCalculator(MarginRate{Commodity: "crude_oil", Initial: 0.10, Maintenance: 0.08, Multiplier: 1000})
+	positions := NewPositionTracker()
+	positions.ApplyFill("acme", "crude_oil", SideBuy, 2, 75)
+
+	at := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	var events []MarginCall
+	monitor := NewMarginMonitor(calc, positions, func(c MarginCall) { events = append(events, c) }, func() time.Time { return at })
+	monitor.SetCollateral("acme", 15000)
+
+	// 75 -> 74: equity 13000 against maintenance 11840
+	if calls := monitor.OnPrice("crude_oil", 74); len(calls) != 0 {
+		t.Fatalf("Expected no call above maintenance, got %+v", calls)
+	}
+
+	// 74 -> 73: equity 11000 against maintenance 11680
+	calls := monitor.OnPrice("crude_oil", 73)
+	if len(calls) != 1 || len(events) != 1 {
+		t.Fatalf("Expected one margin call, got %+v", calls)
+	}
+	call := calls[0]
+	if call.ClientID != "acme" || math.Abs(call.Equity-11000) > 1e-6 || math.Abs(call.Maintenance-11680) > 1e-6 {
+		t.Errorf("Unexpected margin call %+v", call)
+	}
+	if math.Abs(call.Shortfall-3600) > 1e-6 {
+		t.Errorf("Expected shortfall to initial margin of 3600, got %g", call.Shortfall)
+	}
+	if alert := call.Alert(); alert.Severity != SeverityCritical || !alert.Timestamp.Equal(at) {
+		t.Errorf("Unexpected alert %+v", alert)
+	}
+
+	if again := monitor.OnPrice("crude_oil", 72.5); len(again) != 0 {
+		t.Errorf("Expected no repeat call while still under maintenance, got %+v", again)
+	}
+	monitor.SetCollateral("acme", 20000)
+	monitor.OnPrice("crude_oil", 73)
+	if again := monitor.OnPrice("crude_oil", 70); len(again) != 1 {
+		t.Errorf("Expected a fresh call after recovering and breaching again, got %+v", again)
+	}
+}
+
+// TestMarginMonitorCallbackMayPostCollateral verifies the call callback can call back into the monitor
+func TestMarginMonitorCallbackMayPostCollateral(t *testing.T) {
+	calc := NewMarginCalculator(MarginRate{Commodity: "crude_oil", Initial: 0.10, Maintenance: 0.08, Multiplier: 1000})
+	positions := NewPositionTracker()
+	positions.ApplyFill("acme", "crude_oil", SideBuy, 2, 75)
+
+	var monitor *MarginMonitor
+	monitor = NewMarginMonitor(calc, positions, func(c MarginCall) {
+		monitor.SetCollateral(c.ClientID, 15000+c.Shortfall) // auto top-up
+	}, nil)
+	monitor.SetCollateral("acme", 15000)
+
+	done := make(chan []MarginCall, 1)
+	go func() { done <- monitor.OnPrice("crude_oil", 73) }()
+	select {
+	case calls := <-done:
+		if len(calls) != 1 {
+			t.Fatalf("Expected one margin call, got %+v", calls)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Expected the callback to run without the monitor locked")
+	}
+	if _, called := monitor.Evaluate("acme"); called {
+		t.Error("Expected the top-up to clear the call")
+	}
+}
@@ -0,0 +1,270 @@
+package integration
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// MarketDataServiceName is the gRPC service defined in
+// backend/src/grpc/proto/marketdata.proto
+const MarketDataServiceName = "quantenergx.marketdata.MarketDataService"
+
+// MarketDataServiceServer is the server API for MarketDataService
+type MarketDataServiceServer interface {
+	SubscribeMarketData(req *MarketDataRequest, stream MarketDataStream) error
+}
+
+// MarketDataServiceDesc describes MarketDataService for registration with a
+// grpc.Server
+var MarketDataServiceDesc = grpc.ServiceDesc{
+	ServiceName: MarketDataServiceName,
+	HandlerType: (*MarketDataServiceServer)(nil),
+	Streams: []grpc.StreamDesc{{
+		StreamName:    "SubscribeMarketData",
+		Handler:       subscribeMarketDataHandler,
+		ServerStreams: true,
+	}},
+	Metadata: "marketdata.proto",
+}
+
+// RegisterMarketDataServer registers srv on s. The server must be created
+// with grpc.ForceServerCodec(MarketDataCodec{}) so the package's plain Go
+// messages go out in the proto wire format.
+func RegisterMarketDataServer(s grpc.ServiceRegistrar, srv MarketDataServiceServer) {
+	s.RegisterService(&MarketDataServiceDesc, srv)
+}
+
+// NewMarketDataGRPCServer creates a grpc.Server with the market data codec
+// and srv registered on it
+func NewMarketDataGRPCServer(srv MarketDataServiceServer, opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(append([]grpc.ServerOption{grpc.ForceServerCodec(MarketDataCodec{})}, opts...)...)
+	RegisterMarketDataServer(s, srv)
+	return s
+}
+
+func subscribeMarketDataHandler(srv interface{}, stream grpc.ServerStream) error {
+	req := new(MarketDataRequest)
+	if err := stream.RecvMsg(req); err != nil {
+		return err
+	}
+	return srv.(MarketDataServiceServer).SubscribeMarketData(req, marketDataServerStream{stream})
+}
+
+// marketDataServerStream adapts a grpc.ServerStream to MarketDataStream
+type marketDataServerStream struct {
+	grpc.ServerStream
+}
+
+func (s marketDataServerStream) Send(tick *MarketData) error {
+	return s.ServerStream.SendMsg(tick)
+}
+
+// MarketDataClient calls MarketDataService over a client connection
+type MarketDataClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewMarketDataClient creates a client over cc
+func NewMarketDataClient(cc grpc.ClientConnInterface) *MarketDataClient {
+	return &MarketDataClient{cc: cc}
+}
+
+// MarketDataSubscription receives the ticks of one SubscribeMarketData call
+type MarketDataSubscription struct {
+	stream grpc.ClientStream
+}
+
+// SubscribeMarketData opens a stream of ticks for req's commodities. The
+// stream ends when ctx is cancelled.
+func (c *MarketDataClient) SubscribeMarketData(ctx context.Context, req *MarketDataRequest, opts ...grpc.CallOption) (*MarketDataSubscription, error) {
+	opts = append([]grpc.CallOption{grpc.ForceCodec(MarketDataCodec{})}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MarketDataServiceDesc.Streams[0], "/"+MarketDataServiceName+"/SubscribeMarketData", opts...)
+	if err != nil {
+		return nil, err
+	}
+	if err := stream.SendMsg(req); err != nil {
+		return nil, err
+	}
+	if err := stream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return &MarketDataSubscription{stream: stream}, nil
+}
+
+// Recv returns the next tick, or the status the stream ended with
+func (s *MarketDataSubscription) Recv() (*MarketData, error) {
+	tick := new(MarketData)
+	if err := s.stream.RecvMsg(tick); err != nil {
+		return nil, err
+	}
+	return tick, nil
+}
+
+// MarketDataCodec encodes MarketDataRequest and MarketData in the proto
+// wire format of marketdata.proto, so clients generated from it interoperate,
+// and hands every other message to the standard proto codec. The package
+// keeps its plain Go types, so there are no generated messages to marshal.
+type MarketDataCodec struct{}
+
+// Name implements encoding.Codec; the content subtype stays proto
+func (MarketDataCodec) Name() string {
+	return "proto"
+}
+
+// Marshal implements encoding.Codec
+func (MarketDataCodec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case *MarketDataRequest:
+		var b []byte
+		for _, commodity := range m.Commodities {
+			b = protowire.AppendTag(b, 1, protowire.BytesType)
+			b = protowire.AppendString(b, commodity)
+		}
+		return b, nil
+	case *MarketData:
+		var b []byte
+		b = appendProtoString(b, 1, m.Commodity)
+		if m.Price != 0 {
+			b = protowire.AppendTag(b, 2, protowire.Fixed64Type)
+			b = protowire.AppendFixed64(b, math.Float64bits(m.Price))
+		}
+		if m.Volume != 0 {
+			b = protowire.AppendTag(b, 3, protowire.VarintType)
+			b = protowire.AppendVarint(b, uint64(m.Volume))
+		}
+		b = appendProtoString(b, 4, m.Exchange)
+		b = appendProtoTimestamp(b, 5, m.Timestamp)
+		b = appendProtoTimestamp(b, 6, m.OriginalTimestamp)
+		return b, nil
+	default:
+		return encoding.GetCodec("proto").Marshal(v)
+	}
+}
+
+// Unmarshal implements encoding.Codec
+func (MarketDataCodec) Unmarshal(data []byte, v interface{}) error {
+	switch m := v.(type) {
+	case *MarketDataRequest:
+		*m = MarketDataRequest{}
+		return consumeProtoFields(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, bool, error) {
+			if num != 1 || typ != protowire.BytesType {
+				return 0, false, nil
+			}
+			s, n := protowire.ConsumeString(b)
+			m.Commodities = append(m.Commodities, s)
+			return n, true, nil
+		})
+	case *MarketData:
+		*m = MarketData{}
+		return consumeProtoFields(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, bool, error) {
+			switch {
+			case num == 1 && typ == protowire.BytesType:
+				s, n := protowire.ConsumeString(b)
+				m.Commodity = s
+				return n, true, nil
+			case num == 2 && typ == protowire.Fixed64Type:
+				bits, n := protowire.ConsumeFixed64(b)
+				m.Price = math.Float64frombits(bits)
+				return n, true, nil
+			case num == 3 && typ == protowire.VarintType:
+				volume, n := protowire.ConsumeVarint(b)
+				m.Volume = int64(volume)
+				return n, true, nil
+			case num == 4 && typ == protowire.BytesType:
+				s, n := protowire.ConsumeString(b)
+				m.Exchange = s
+				return n, true, nil
+			case (num == 5 || num == 6) && typ == protowire.BytesType:
+				raw, n := protowire.ConsumeBytes(b)
+				if n < 0 {
+					return n, true, nil
+				}
+				t, err := decodeProtoTimestamp(raw)
+				if num == 5 {
+					m.Timestamp = t
+				} else {
+					m.OriginalTimestamp = t
+				}
+				return n, true, err
+			}
+			return 0, false, nil
+		})
+	default:
+		return encoding.GetCodec("proto").Unmarshal(data, v)
+	}
+}
+
+func appendProtoString(b []byte, num protowire.Number, s string) []byte {
+	if s == "" {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendString(b, s)
+}
+
+// appendProtoTimestamp appends t as a google.protobuf.Timestamp, leaving
+// the zero time unset
+func appendProtoTimestamp(b []byte, num protowire.Number, t time.Time) []byte {
+	if t.IsZero() {
+		return b
+	}
+	var ts []byte
+	if secs := t.Unix(); secs != 0 {
+		ts = protowire.AppendTag(ts, 1, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(secs))
+	}
+	if nanos := t.Nanosecond(); nanos != 0 {
+		ts = protowire.AppendTag(ts, 2, protowire.VarintType)
+		ts = protowire.AppendVarint(ts, uint64(nanos))
+	}
+	b = protowire.AppendTag(b, num, protowire.BytesType)
+	return protowire.AppendBytes(b, ts)
+}
+
+func decodeProtoTimestamp(data []byte) (time.Time, error) {
+	var secs, nanos int64
+	err := consumeProtoFields(data, func(num protowire.Number, typ protowire.Type, b []byte) (int, bool, error) {
+		if typ != protowire.VarintType || (num != 1 && num != 2) {
+			return 0, false, nil
+		}
+		v, n := protowire.ConsumeVarint(b)
+		if num == 1 {
+			secs = int64(v)
+		} else {
+			nanos = int64(int32(v))
+		}
+		return n, true, nil
+	})
+	return time.Unix(secs, nanos).UTC(), err
+}
+
+// consumeProtoFields walks the fields in data, passing each value to field.
+// field returns the length it consumed, negative for a parse error, and
+// false for a field it does not know, which is skipped.
+func consumeProtoFields(data []byte, field func(num protowire.Number, typ protowire.Type, b []byte) (int, bool, error)) error {
+	for len(data) > 0 {
+		num, typ, n := protowire.ConsumeTag(data)
+		if n < 0 {
+			return fmt.Errorf("decode market data: %w", protowire.ParseError(n))
+		}
+		data = data[n:]
+		n, known, err := field(num, typ, data)
+		if err != nil {
+			return err
+		}
+		if !known {
+			n = protowire.ConsumeFieldValue(num, typ, data)
+		}
+		if n < 0 {
+			return fmt.Errorf("decode market data field %d: %w", num, protowire.ParseError(n))
+		}
+		data = data[n:]
+	}
+	return nil
+}
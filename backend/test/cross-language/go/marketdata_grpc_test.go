@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"context"
+	"net"
+	"runtime"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// TestMarketDataGRPCStreamsUntilCancel verifies ticks stream to a client
+// over an in-memory gRPC connection and cancelling the call ends the
+// server handler without leaking goroutines
+func TestMarketDataGRPCStreamsUntilCancel(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	source := &chanMarketDataSource{name: "nymex", ticks: make(chan MarketData, 16)}
+	handlerDone := make(chan error, 1)
+	server := NewMarketDataGRPCServer(handlerFunc(func(req *MarketDataRequest, stream MarketDataStream) error {
+		err := NewMarketDataServer(source, MarketDataStreamConfig{SendTimeout: time.Second}).SubscribeMarketData(req, stream)
+		handlerDone <- err
+		return err
+	}))
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+
+	callCtx, cancelCall := context.WithCancel(ctx)
+	sub, err := NewMarketDataClient(conn).SubscribeMarketData(callCtx, &MarketDataRequest{Commodities: []string{"crude_oil"}})
+	if err != nil {
+		t.Fatalf("Subscribe failed: %v", err)
+	}
+	ticks := recordedTicks(3, time.Second)
+	ticks[1].OriginalTimestamp = ticks[1].Timestamp.Add(-250 * time.Millisecond)
+	source.ticks <- MarketData{Commodity: "natural_gas", Price: 2.5}
+	for _, tick := range ticks {
+		source.ticks <- tick
+	}
+	for i, want := range ticks {
+		got, err := sub.Recv()
+		if err != nil {
+			t.Fatalf("Recv %d failed: %v", i, err)
+		}
+		if got.Commodity != want.Commodity || got.Price != want.Price || got.Volume != want.Volume || got.Exchange != want.Exchange ||
+			!got.Timestamp.Equal(want.Timestamp) || !got.OriginalTimestamp.Equal(want.OriginalTimestamp) {
+			t.Errorf("Tick %d: expected %+v, got %+v", i, want, *got)
+		}
+	}
+
+	cancelCall()
+	if _, err := sub.Recv(); status.Code(err) != codes.Canceled {
+		t.Errorf("Expected the stream to end as cancelled, got %v", err)
+	}
+	select {
+	case err := <-handlerDone:
+		if status.Code(err) != codes.Canceled && err != context.Canceled {
+			t.Errorf("Expected the handler to stop on cancellation, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Handler did not stop after the client cancelled")
+	}
+
+	conn.Close()
+	server.Stop()
+	waitForGoroutines(t, baseline)
+}
+
+// handlerFunc adapts a function to MarketDataServiceServer
+type handlerFunc func(req *MarketDataRequest, stream MarketDataStream) error
+
+func (f handlerFunc) SubscribeMarketData(req *MarketDataRequest, stream MarketDataStream) error {
+	return f(req, stream)
+}
@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrSlowConsumer is returned when a client does not accept a tick within
+// the send deadline
+var ErrSlowConsumer = errors.New("market data consumer too slow")
+
+// MarketDataRequest selects the commodities a subscriber wants
+type MarketDataRequest struct {
+	Commodities []string `json:"commodities"`
+}
+
+// MarketDataStream is the server side of a SubscribeMarketData call,
+// shaped after a gRPC server stream so a grpc.ServerStream adapts to it
+// directly
+type MarketDataStream interface {
+	Context() context.Context
+	Send(*MarketData) error
+}
+
+// MarketDataStreamConfig tunes the streaming endpoint
+type MarketDataStreamConfig struct {
+	// SendTimeout is how long one Send may block before the subscriber is
+	// dropped as a slow consumer; default 5s
+	SendTimeout time.Duration
+}
+
+// MarketDataServer implements the server-streaming SubscribeMarketData
+// RPC over a MarketDataSource. NewMarketDataGRPCServer serves it over gRPC
+type MarketDataServer struct {
+	source MarketDataSource
+	config MarketDataStreamConfig
+}
+
+// NewMarketDataServer creates a streaming endpoint over source
+func NewMarketDataServer(source MarketDataSource, config MarketDataStreamConfig) *MarketDataServer {
+	if config.SendTimeout <= 0 {
+		config.SendTimeout = 5 * time.Second
+	}
+	return &MarketDataServer{source: source, config: config}
+}
+
+// SubscribeMarketData streams ticks for the requested commodities until
+// the client goes away, the source ends or a send misses its deadline.
+// Every goroutine it starts is tied to a context it cancels on return; a
+// Send still blocked on a stalled client finishes once the transport tears
+// the stream down after the handler returns.
+func (s *MarketDataServer) SubscribeMarketData(req *MarketDataRequest, stream MarketDataStream) error {
+	if req == nil || len(req.Commodities) == 0 {
+		return errors.New("no commodities requested")
+	}
+	ctx, cancel := context.WithCancel(stream.Context())
+	var wg sync.WaitGroup
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	wanted := make(map[string]bool, len(req.Commodities))
+	ticks := make(chan MarketData)
+	for _, commodity := range req.Commodities {
+		if wanted[commodity] {
+			continue
+		}
+		wanted[commodity] = true
+		ch, err := s.source.Subscribe(ctx, commodity)
+		if err != nil {
+			return fmt.Errorf("subscribe %s: %w", commodity, err)
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case tick, ok := <-ch:
+					if !ok {
+						return
+					}
+					select {
+					case ticks <- tick:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}
+		}()
+	}
+	sourcesDone := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(sourcesDone)
+	}()
+
+	// A single sender goroutine lets Send be abandoned at its deadline
+	sends := make(chan MarketData)
+	results := make(chan error, 1)
+	defer close(sends)
+	go func() {
+		for tick := range sends {
+			tick := tick
+			results <- stream.Send(&tick)
+		}
+	}()
+
+	timer := time.NewTimer(s.config.SendTimeout)
+	defer timer.Stop()
+	for {
+		var tick MarketData
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-sourcesDone:
+			return nil
+		case tick = <-ticks:
+		}
+		if !wanted[tick.Commodity] {
+			continue
+		}
+
+		sends <- tick
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(s.config.SendTimeout)
+		select {
+		case err := <-results:
+			if err != nil {
+				return err
+			}
+		case <-timer.C:
+			return fmt.Errorf("%w: send blocked for %v", ErrSlowConsumer, s.config.SendTimeout)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// chanMarketDataStream is an in-memory server stream delivering sends to
+// the test, so the handler can be driven without a gRPC transport
+type chanMarketDataStream struct {
+	ctx  context.Context
+	sent chan MarketData
+}
+
+func (s *chanMarketDataStream) Context() context.Context { return s.ctx }
+
+func (s *chanMarketDataStream) Send(tick *MarketData) error {
+	select {
+	case s.sent <- *tick:
+		return nil
+	case <-s.ctx.Done():
+		return s.ctx.Err()
+	}
+}
+
+// waitForGoroutines waits for the goroutine count to fall back to baseline
+func waitForGoroutines(t *testing.T, baseline int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for runtime.NumGoroutine() > baseline {
+		if time.Now().After(deadline) {
+			t.Fatalf("Goroutines leaked: %d running, baseline %d", runtime.NumGoroutine(), baseline)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// TestSubscribeMarketDataStreamsUntilCancel verifies requested ticks stream and cancellation ends the call cleanly
+func TestSubscribeMarketDataStreamsUntilCancel(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	source := &chanMarketDataSource{name: "nymex", ticks: make(chan MarketData, 16)}
+	server := NewMarketDataServer(source, MarketDataStreamConfig{SendTimeout: time.Second})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &chanMarketDataStream{ctx: ctx, sent: make(chan MarketData)}
+	done := make(chan error, 1)
+	go func() {
+		done <- server.SubscribeMarketData(&MarketDataRequest{Commodities: []string{"crude_oil"}}, stream)
+	}()
+
+	ticks := recordedTicks(3, time.Second)
+	source.ticks <- MarketData{Commodity: "natural_gas", Price: 2.5}
+	for _, tick := range ticks {
+		source.ticks <- tick
+	}
+	got := receiveTicks(t, stream.sent, 3)
+	for i, tick := range got {
+		if tick.Commodity != "crude_oil" || !tick.Timestamp.Equal(ticks[i].Timestamp) {
+			t.Errorf("Tick %d: expected %+v, got %+v", i, ticks[i], tick)
+		}
+	}
+
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected the stream to end with context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Stream did not stop after cancellation")
+	}
+	waitForGoroutines(t, baseline)
+}
+
+// TestSubscribeMarketDataDropsSlowConsumer verifies a client that stops reading is cut off at the send deadline
+func TestSubscribeMarketDataDropsSlowConsumer(t *testing.T) {
+	baseline := runtime.NumGoroutine()
+	source := &chanMarketDataSource{name: "nymex", ticks: make(chan MarketData, 16)}
+	server := NewMarketDataServer(source, MarketDataStreamConfig{SendTimeout: 30 * time.Millisecond})
+
+	// The transport cancels the stream context once the handler returns
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := &chanMarketDataStream{ctx: ctx, sent: make(chan MarketData)}
+	source.ticks <- recordedTicks(1, time.Second)[0]
+
+	err := server.SubscribeMarketData(&MarketDataRequest{Commodities: []string{"crude_oil"}}, stream)
+	cancel()
+	if !errors.Is(err, ErrSlowConsumer) {
+		t.Fatalf("Expected ErrSlowConsumer, got %v", err)
+	}
+	waitForGoroutines(t, baseline)
+}
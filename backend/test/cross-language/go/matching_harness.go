@@ -0,0 +1,212 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"time"
+)
+
+// Scenario operation kinds
+const (
+	ScenarioOpAdd    = "add"
+	ScenarioOpCancel = "cancel"
+	ScenarioOpAmend  = "amend"
+
+	// Book control operations, run on engines implementing BookControls
+	ScenarioOpReduce       = "reduce"
+	ScenarioOpRelease      = "release"
+	ScenarioOpTickSize     = "tick_size"
+	ScenarioOpAuctionStart = "auction_start"
+	ScenarioOpUncross      = "uncross"
+	ScenarioOpCloseUncross = "close_uncross"
+	ScenarioOpPegMarket    = "peg_market"
+)
+
+// scenarioEpoch is the fixed clock used when replaying scenarios
+var scenarioEpoch = time.Date(2024, 1, 2, 9, 0, 0, 0, time.UTC)
+
+// MatchingScenario is a golden test case for the order book
+type MatchingScenario struct {
+	Name           string              `json:"name"`
+	Commodity      string              `json:"commodity"`
+	Operations     []ScenarioOperation `json:"operations"`
+	ExpectedTrades []ExpectedTrade     `json:"expected_trades"`
+}
+
+// ScenarioOperation is a single step applied to the book
+type ScenarioOperation struct {
+	Op          string        `json:"op"`
+	Order       *TradingOrder `json:"order,omitempty"`
+	OrderID     string        `json:"order_id,omitempty"`
+	Price       float64       `json:"price,omitempty"`  // for amends and tick sizes
+	Volume      float64       `json:"volume,omitempty"` // for amends and reductions
+	Bid         float64       `json:"bid,omitempty"`    // peg reference market
+	Ask         float64       `json:"ask,omitempty"`
+	ExpectError bool          `json:"expect_error,omitempty"`
+}
+
+// ExpectedTrade is the subset of trade fields asserted by a scenario
+type ExpectedTrade struct {
+	BuyOrderID  string  `json:"buy_order_id"`
+	SellOrderID string  `json:"sell_order_id"`
+	Price       float64 `json:"price"`
+	Volume      float64 `json:"volume"`
+}
+
+// ScenarioResult holds the trades produced and any differences from expectations
+type ScenarioResult struct {
+	Trades      []Trade
+	Differences []string
+}
+
+// Passed reports whether the scenario matched its expectations exactly
+func (r ScenarioResult) Passed() bool {
+	return len(r.Differences) == 0
+}
+
+// LoadMatchingScenario reads a scenario from a JSON file
+func LoadMatchingScenario(path string) (MatchingScenario, error) {
+	var scenario MatchingScenario
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return scenario, fmt.Errorf("read scenario: %w", err)
+	}
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		return scenario, fmt.Errorf("decode scenario %s: %w", path, err)
+	}
+	if scenario.Commodity == "" {
+		return scenario, fmt.Errorf("scenario %s: commodity is required", path)
+	}
+	return scenario, nil
+}
+
+// RunMatchingScenario replays the operations against a fresh book with a
+// fixed clock and diffs the resulting trades against the expected ones
+func RunMatchingScenario(scenario MatchingScenario) ScenarioResult {
+	book := NewOrderBook(scenario.Commodity, WithClock(func() time.Time { return scenarioEpoch }))
+	var result ScenarioResult
+
+	for i, op := range scenario.Operations {
+		trades, err := applyScenarioOperation(book, op)
+		switch {
+		case err != nil && !op.ExpectError:
+			result.Differences = append(result.Differences, fmt.Sprintf("operation %d (%s): unexpected error: %v", i, op.Op, err))
+		case err == nil && op.ExpectError:
+			result.Differences = append(result.Differences, fmt.Sprintf("operation %d (%s): expected an error, got none", i, op.Op))
+		}
+		result.Trades = append(result.Trades, trades...)
+	}
+
+	result.Differences = append(result.Differences, DiffTrades(scenario.ExpectedTrades, result.Trades)...)
+	return result
+}
+
+// MatchingEngine is the order entry surface of a matching engine, which
+// OrderBook implements. Alternative engines implement it to be run against
+// scenarios or shadowed against production.
+type MatchingEngine interface {
+	Add(order TradingOrder) ([]Trade, error)
+	Cancel(orderID string) error
+	Amend(orderID string, price, volume float64) ([]Trade, error)
+}
+
+// BookControls is the rest of OrderBook's mutating surface, for engines
+// that shadow a book through auctions, pegs and reviews
+type BookControls interface {
+	ReduceQuantity(orderID string, reduceBy float64) error
+	ReleasePaused(orderID string) ([]Trade, error)
+	SetTickSize(tick float64) ([]TradingOrder, error)
+	StartAuction()
+	Uncross() (clearingPrice float64, trades []Trade)
+	UncrossClose() (clearingPrice float64, trades []Trade, cancelled []TradingOrder)
+	UpdatePegReference(bid, ask float64) []Trade
+}
+
+// applyScenarioOperation dispatches one operation to the engine
+func applyScenarioOperation(book MatchingEngine, op ScenarioOperation) ([]Trade, error) {
+	if controls, ok := book.(BookControls); ok {
+		if trades, handled, err := applyBookControl(controls, op); handled {
+			return trades, err
+		}
+	}
+	switch op.Op {
+	case ScenarioOpAdd:
+		if op.Order == nil {
+			return nil, fmt.Errorf("add operation missing order")
+		}
+		return book.Add(*op.Order)
+	case ScenarioOpCancel:
+		return nil, book.Cancel(op.OrderID)
+	case ScenarioOpAmend:
+		return book.Amend(op.OrderID, op.Price, op.Volume)
+	default:
+		return nil, fmt.Errorf("unknown operation %q", op.Op)
+	}
+}
+
+// applyBookControl runs a book control operation, reporting false for any
+// other kind
+func applyBookControl(book BookControls, op ScenarioOperation) ([]Trade, bool, error) {
+	switch op.Op {
+	case ScenarioOpReduce:
+		return nil, true, book.ReduceQuantity(op.OrderID, op.Volume)
+	case ScenarioOpRelease:
+		trades, err := book.ReleasePaused(op.OrderID)
+		return trades, true, err
+	case ScenarioOpTickSize:
+		_, err := book.SetTickSize(op.Price)
+		return nil, true, err
+	case ScenarioOpAuctionStart:
+		book.StartAuction()
+		return nil, true, nil
+	case ScenarioOpUncross:
+		_, trades := book.Uncross()
+		return trades, true, nil
+	case ScenarioOpCloseUncross:
+		_, trades, _ := book.UncrossClose()
+		return trades, true, nil
+	case ScenarioOpPegMarket:
+		return book.UpdatePegReference(op.Bid, op.Ask), true, nil
+	default:
+		return nil, false, nil
+	}
+}
+
+// DiffTrades compares expected and actual trades position by position and
+// describes every mismatch, missing trade, and unexpected trade
+func DiffTrades(expected []ExpectedTrade, actual []Trade) []string {
+	var diffs []string
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			diffs = append(diffs, fmt.Sprintf("trade %d: missing, expected %s", i, formatExpectedTrade(expected[i])))
+		case i >= len(expected):
+			diffs = append(diffs, fmt.Sprintf("trade %d: unexpected %s", i, formatTrade(actual[i])))
+		default:
+			want, got := expected[i], actual[i]
+			if want.BuyOrderID != got.BuyOrderID {
+				diffs = append(diffs, fmt.Sprintf("trade %d: buy order expected %s, got %s", i, want.BuyOrderID, got.BuyOrderID))
+			}
+			if want.SellOrderID != got.SellOrderID {
+				diffs = append(diffs, fmt.Sprintf("trade %d: sell order expected %s, got %s", i, want.SellOrderID, got.SellOrderID))
+			}
+			if math.Abs(want.Price-got.Price) > volumeEpsilon {
+				diffs = append(diffs, fmt.Sprintf("trade %d: price expected %g, got %g", i, want.Price, got.Price))
+			}
+			if math.Abs(want.Volume-got.Volume) > volumeEpsilon {
+				diffs = append(diffs, fmt.Sprintf("trade %d: volume expected %g, got %g", i, want.Volume, got.Volume))
+			}
+		}
+	}
+	return diffs
+}
+
+func formatExpectedTrade(t ExpectedTrade) string {
+	return fmt.Sprintf("%s/%s %g@%g", t.BuyOrderID, t.SellOrderID, t.Volume, t.Price)
+}
+
+func formatTrade(t Trade) string {
+	return fmt.Sprintf("%s/%s %g@%g", t.BuyOrderID, t.SellOrderID, t.Volume, t.Price)
+}
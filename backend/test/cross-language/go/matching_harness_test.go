@@ -0,0 +1,64 @@
+package integration
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMatchingScenarios replays every golden scenario under testdata/scenarios
+func TestMatchingScenarios(t *testing.T) {
+	paths, err := filepath.Glob("testdata/scenarios/*.json")
+	if err != nil {
+		t.Fatalf("Failed to list scenarios: %v", err)
+	}
+	if len(paths) == 0 {
+		t.Fatal("Expected at least one scenario file")
+	}
+
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			scenario, err := LoadMatchingScenario(path)
+			if err != nil {
+				t.Fatalf("Failed to load scenario: %v", err)
+			}
+			result := RunMatchingScenario(scenario)
+			for _, diff := range result.Differences {
+				t.Error(diff)
+			}
+		})
+	}
+}
+
+// TestMatchingScenarioReportsDifferences verifies mismatches are described precisely
+func TestMatchingScenarioReportsDifferences(t *testing.T) {
+	scenario := MatchingScenario{
+		Commodity: "natural_gas",
+		Operations: []ScenarioOperation{
+			{Op: ScenarioOpAdd, Order: &TradingOrder{OrderID: "s1", Side: SideSell, Price: 3.25, Volume: 10}},
+			{Op: ScenarioOpAdd, Order: &TradingOrder{OrderID: "b1", Side: SideBuy, Price: 3.30, Volume: 4}},
+		},
+		ExpectedTrades: []ExpectedTrade{
+			{BuyOrderID: "b1", SellOrderID: "s1", Price: 3.30, Volume: 4},
+			{BuyOrderID: "b2", SellOrderID: "s1", Price: 3.25, Volume: 6},
+		},
+	}
+
+	result := RunMatchingScenario(scenario)
+	if result.Passed() {
+		t.Fatal("Expected scenario to report differences")
+	}
+
+	expected := []string{
+		"trade 0: price expected 3.3, got 3.25",
+		"trade 1: missing, expected b2/s1 6@3.25",
+	}
+	if len(result.Differences) != len(expected) {
+		t.Fatalf("Expected %d differences, got %d: %v", len(expected), len(result.Differences), result.Differences)
+	}
+	for i, want := range expected {
+		if !strings.Contains(result.Differences[i], want) {
+			t.Errorf("Expected difference %q, got %q", want, result.Differences[i])
+		}
+	}
+}
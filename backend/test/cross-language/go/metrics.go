@@ -0,0 +1,145 @@
+package integration
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// MetricsRecorder receives matching engine activity from an OrderBook
+type MetricsRecorder interface {
+	OrderAdded(commodity string)
+	OrderRejected(commodity string)
+	OrdersCanceled(commodity string, n int)
+	TradeExecuted(commodity string, volume float64)
+	RestingOrders(commodity string, n int)
+}
+
+// NoopMetrics discards all metrics
+type NoopMetrics struct{}
+
+// OrderAdded implements MetricsRecorder
+func (NoopMetrics) OrderAdded(string) {}
+
+// OrderRejected implements MetricsRecorder
+func (NoopMetrics) OrderRejected(string) {}
+
+// OrdersCanceled implements MetricsRecorder
+func (NoopMetrics) OrdersCanceled(string, int) {}
+
+// TradeExecuted implements MetricsRecorder
+func (NoopMetrics) TradeExecuted(string, float64) {}
+
+// RestingOrders implements MetricsRecorder
+func (NoopMetrics) RestingOrders(string, int) {}
+
+// BookMetrics is one commodity's matching engine metrics
+type BookMetrics struct {
+	OrdersAdded    uint64  `json:"orders_added"`
+	OrdersRejected uint64  `json:"orders_rejected"`
+	OrdersCanceled uint64  `json:"orders_canceled"`
+	Trades         uint64  `json:"trades"`
+	MatchedVolume  float64 `json:"matched_volume"`
+	RestingOrders  int     `json:"resting_orders"`
+}
+
+// PrometheusMetrics is a MetricsRecorder served in the Prometheus text
+// exposition format. One instance can be shared by many books.
+type PrometheusMetrics struct {
+	mu     sync.Mutex
+	prefix string
+	byBook map[string]*BookMetrics
+}
+
+// NewPrometheusMetrics creates a recorder whose metric names start with prefix
+func NewPrometheusMetrics(prefix string) *PrometheusMetrics {
+	if prefix == "" {
+		prefix = "quantenergx_orderbook"
+	}
+	return &PrometheusMetrics{prefix: prefix, byBook: make(map[string]*BookMetrics)}
+}
+
+func (p *PrometheusMetrics) update(commodity string, fn func(*BookMetrics)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	m, ok := p.byBook[commodity]
+	if !ok {
+		m = &BookMetrics{}
+		p.byBook[commodity] = m
+	}
+	fn(m)
+}
+
+// OrderAdded implements MetricsRecorder
+func (p *PrometheusMetrics) OrderAdded(commodity string) {
+	p.update(commodity, func(m *BookMetrics) { m.OrdersAdded++ })
+}
+
+// OrderRejected implements MetricsRecorder
+func (p *PrometheusMetrics) OrderRejected(commodity string) {
+	p.update(commodity, func(m *BookMetrics) { m.OrdersRejected++ })
+}
+
+// OrdersCanceled implements MetricsRecorder
+func (p *PrometheusMetrics) OrdersCanceled(commodity string, n int) {
+	p.update(commodity, func(m *BookMetrics) { m.OrdersCanceled += uint64(n) })
+}
+
+// TradeExecuted implements MetricsRecorder
+func (p *PrometheusMetrics) TradeExecuted(commodity string, volume float64) {
+	p.update(commodity, func(m *BookMetrics) {
+		m.Trades++
+		m.MatchedVolume += volume
+	})
+}
+
+// RestingOrders implements MetricsRecorder
+func (p *PrometheusMetrics) RestingOrders(commodity string, n int) {
+	p.update(commodity, func(m *BookMetrics) { m.RestingOrders = n })
+}
+
+// Metrics returns a copy of one commodity's metrics
+func (p *PrometheusMetrics) Metrics(commodity string) BookMetrics {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if m, ok := p.byBook[commodity]; ok {
+		return *m
+	}
+	return BookMetrics{}
+}
+
+// ServeHTTP writes every metric in the Prometheus text format
+func (p *PrometheusMetrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	fmt.Fprint(w, p.Exposition())
+}
+
+// Exposition renders every metric in the Prometheus text format
+func (p *PrometheusMetrics) Exposition() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	commodities := make([]string, 0, len(p.byBook))
+	for commodity := range p.byBook {
+		commodities = append(commodities, commodity)
+	}
+	sort.Strings(commodities)
+
+	var sb strings.Builder
+	family := func(name, kind, help string, value func(*BookMetrics) string) {
+		fmt.Fprintf(&sb, "# HELP %s_%s %s\n# TYPE %s_%s %s\n", p.prefix, name, help, p.prefix, name, kind)
+		for _, commodity := range commodities {
+			fmt.Fprintf(&sb, "%s_%s{commodity=%q} %s\n", p.prefix, name, commodity, value(p.byBook[commodity]))
+		}
+	}
+	count := func(v uint64) string { return fmt.Sprintf("%d", v) }
+	family("orders_added_total", "counter", "Orders accepted by the book.", func(m *BookMetrics) string { return count(m.OrdersAdded) })
+	family("orders_rejected_total", "counter", "Orders rejected by validation.", func(m *BookMetrics) string { return count(m.OrdersRejected) })
+	family("orders_canceled_total", "counter", "Resting orders canceled or expired.", func(m *BookMetrics) string { return count(m.OrdersCanceled) })
+	family("trades_total", "counter", "Trades executed.", func(m *BookMetrics) string { return count(m.Trades) })
+	family("matched_volume_total", "counter", "Volume executed.", func(m *BookMetrics) string { return fmt.Sprintf("%g", m.MatchedVolume) })
+	family("resting_orders", "gauge", "Orders resting on the book.", func(m *BookMetrics) string { return fmt.Sprintf("%d", m.RestingOrders) })
+	return sb.String()
+}
@@ -0,0 +1,51 @@
+package integration
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// TestBookMetricsCountOperations verifies counters and the resting gauge across adds, trades, cancels and rejects
+func TestBookMetricsCountOperations(t *testing.T) {
+	metrics := NewPrometheusMetrics("")
+	book := NewOrderBook("crude_oil", WithMetrics(metrics))
+
+	for _, o := range []TradingOrder{
+		{OrderID: "bid1", ClientID: "acme", Side: SideBuy, Price: 75.40, Volume: 50},
+		{OrderID: "bid2", ClientID: "acme", Side: SideBuy, Price: 75.30, Volume: 50},
+		{OrderID: "ask1", ClientID: "gulf", Side: SideSell, Price: 75.60, Volume: 30},
+		{OrderID: "sell1", ClientID: "gulf", Side: SideSell, Price: 75.30, Volume: 70},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Add %s failed: %v", o.OrderID, err)
+		}
+	}
+	book.Add(TradingOrder{OrderID: "bid2", Side: SideBuy, Price: 75, Volume: 1}) // duplicate
+	book.Add(TradingOrder{OrderID: "bad", Side: SideBuy, Price: 75})             // no volume
+	book.Simulate(TradingOrder{OrderID: "probe", Side: SideBuy, Price: 75.60, Volume: 30})
+	book.Cancel("ask1")
+	book.Add(TradingOrder{OrderID: "ask2", ClientID: "gulf", Side: SideSell, Price: 75.80, Volume: 10})
+	book.CancelAllForClient("acme")
+
+	got := metrics.Metrics("crude_oil")
+	want := BookMetrics{OrdersAdded: 5, OrdersRejected: 2, OrdersCanceled: 2, Trades: 2, MatchedVolume: 70, RestingOrders: 1}
+	if got != want {
+		t.Errorf("Expected %+v, got %+v", want, got)
+	}
+
+	rec := httptest.NewRecorder()
+	metrics.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+	for _, line := range []string{
+		"# TYPE quantenergx_orderbook_orders_added_total counter",
+		`quantenergx_orderbook_orders_added_total{commodity="crude_oil"} 5`,
+		`quantenergx_orderbook_matched_volume_total{commodity="crude_oil"} 70`,
+		"# TYPE quantenergx_orderbook_resting_orders gauge",
+		`quantenergx_orderbook_resting_orders{commodity="crude_oil"} 1`,
+	} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("Expected exposition to contain %q, got:\n%s", line, body)
+		}
+	}
+}
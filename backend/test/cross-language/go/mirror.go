@@ -0,0 +1,350 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// MirrorDivergence is a mirrored operation on which the shadow engine
+// disagreed with production
+type MirrorDivergence struct {
+	Seq         uint64            `json:"seq"` // position in the mirrored flow, from 1
+	Operation   ScenarioOperation `json:"operation"`
+	Differences []string          `json:"differences"`
+}
+
+// MirrorConfig tunes a MirrorSink
+type MirrorConfig struct {
+	// Buffer is how many operations may wait for the shadow; default 1024
+	Buffer int
+	// OnDivergence is called off the production path for every divergence
+	OnDivergence func(MirrorDivergence)
+}
+
+// MirrorStats counts a sink's activity
+type MirrorStats struct {
+	Mirrored    uint64 `json:"mirrored"`
+	Compared    uint64 `json:"compared"`
+	Dropped     uint64 `json:"dropped"`
+	Divergences uint64 `json:"divergences"`
+}
+
+type mirroredOp struct {
+	seq    uint64
+	op     ScenarioOperation
+	trades []Trade
+	err    error
+}
+
+// MirrorSink replays production order flow on a shadow engine and compares
+// the outcomes. Mirror only queues, so production never waits on the
+// shadow; when the queue is full the operation is dropped instead. A drop
+// leaves the shadow's book different from production, so comparison stops
+// at the first drop, which is itself reported as a divergence.
+type MirrorSink struct {
+	shadow MatchingEngine
+	config MirrorConfig
+	queue  chan mirroredOp
+	done   chan struct{}
+
+	mu       sync.Mutex
+	closed   bool
+	seq      uint64
+	desynced bool
+	stats    MirrorStats
+}
+
+// NewMirrorSink starts a sink feeding shadow. Close it to stop the mirror
+// goroutine.
+func NewMirrorSink(shadow MatchingEngine, config MirrorConfig) *MirrorSink {
+	if config.Buffer <= 0 {
+		config.Buffer = 1024
+	}
+	s := &MirrorSink{
+		shadow: shadow,
+		config: config,
+		queue:  make(chan mirroredOp, config.Buffer),
+		done:   make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
+
+// Mirror queues an operation with the trades and error production returned
+// for it. It never blocks.
+func (s *MirrorSink) Mirror(op ScenarioOperation, trades []Trade, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.seq++
+	s.stats.Mirrored++
+	if s.desynced {
+		s.stats.Dropped++
+		return
+	}
+	select {
+	case s.queue <- mirroredOp{seq: s.seq, op: op, trades: trades, err: err}:
+	default:
+		s.stats.Dropped++
+		s.desynced = true
+		// The queue is full, so the report goes out from a goroutine
+		s.stats.Divergences++
+		go s.report(MirrorDivergence{Seq: s.seq, Operation: op, Differences: []string{
+			"mirror queue full: operation dropped and shadow no longer in sync",
+		}})
+	}
+}
+
+// Close stops accepting operations and waits for queued ones to be compared
+func (s *MirrorSink) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		<-s.done
+		return
+	}
+	s.closed = true
+	close(s.queue)
+	s.mu.Unlock()
+	<-s.done
+}
+
+// Stats returns the sink's counters
+func (s *MirrorSink) Stats() MirrorStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.stats
+}
+
+func (s *MirrorSink) run() {
+	defer close(s.done)
+	for m := range s.queue {
+		trades, err := applyScenarioOperation(s.shadow, m.op)
+		diffs := compareMirrored(m, trades, err)
+
+		s.mu.Lock()
+		s.stats.Compared++
+		if len(diffs) > 0 {
+			s.stats.Divergences++
+		}
+		s.mu.Unlock()
+		if len(diffs) > 0 {
+			s.report(MirrorDivergence{Seq: m.seq, Operation: m.op, Differences: diffs})
+		}
+	}
+}
+
+func (s *MirrorSink) report(d MirrorDivergence) {
+	if s.config.OnDivergence != nil {
+		s.config.OnDivergence(d)
+	}
+}
+
+// compareMirrored diffs the shadow's outcome against production's. Trades
+// are compared on counterparties, price and volume; IDs and timestamps are
+// engine-local.
+func compareMirrored(m mirroredOp, trades []Trade, err error) []string {
+	switch {
+	case m.err != nil && err == nil:
+		return []string{fmt.Sprintf("production rejected (%v), shadow accepted", m.err)}
+	case m.err == nil && err != nil:
+		return []string{fmt.Sprintf("shadow rejected (%v), production accepted", err)}
+	}
+	expected := make([]ExpectedTrade, len(m.trades))
+	for i, t := range m.trades {
+		expected[i] = ExpectedTrade{BuyOrderID: t.BuyOrderID, SellOrderID: t.SellOrderID, Price: t.Price, Volume: t.Volume}
+	}
+	return DiffTrades(expected, trades)
+}
+
+// MirroredBook sends its production book's order flow to a MirrorSink.
+// The book is held privately and every mutator is forwarded explicitly, so
+// nothing can change it without the shadow seeing the same change. Bulk
+// cancellations and expiries are mirrored as one cancel per order removed,
+// as the book records them; the shadow's clock and client state are its
+// own. Other control operations need a shadow implementing BookControls,
+// and any other shadow rejects them as a divergence. Each change is
+// queued for the shadow before the next one reaches the book, so
+// concurrent callers are mirrored in the order production applied them.
+type MirroredBook struct {
+	mu   sync.Mutex // held from the book call until the mirror is queued
+	book *OrderBook
+	sink *MirrorSink
+}
+
+// NewMirroredBook wraps book so every change to it is mirrored. The book
+// must not be changed other than through the wrapper.
+func NewMirroredBook(book *OrderBook, sink *MirrorSink) *MirroredBook {
+	return &MirroredBook{book: book, sink: sink}
+}
+
+// Add submits to the production book, then mirrors the order
+func (m *MirroredBook) Add(order TradingOrder) ([]Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	trades, err := m.book.Add(order)
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpAdd, Order: &order}, trades, err)
+	return trades, err
+}
+
+// Cancel cancels on the production book, then mirrors the cancel
+func (m *MirroredBook) Cancel(orderID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.book.Cancel(orderID)
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpCancel, OrderID: orderID}, nil, err)
+	return err
+}
+
+// Amend amends on the production book, then mirrors the amendment
+func (m *MirroredBook) Amend(orderID string, price, volume float64) ([]Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	trades, err := m.book.Amend(orderID, price, volume)
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpAmend, OrderID: orderID, Price: price, Volume: volume}, trades, err)
+	return trades, err
+}
+
+// ReduceQuantity reduces on the production book, then mirrors the reduction
+func (m *MirroredBook) ReduceQuantity(orderID string, reduceBy float64) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	err := m.book.ReduceQuantity(orderID, reduceBy)
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpReduce, OrderID: orderID, Volume: reduceBy}, nil, err)
+	return err
+}
+
+// CancelAll cancels every order on the production book and mirrors each
+// cancellation
+func (m *MirroredBook) CancelAll() []TradingOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mirrorCancels(m.book.CancelAll())
+}
+
+// CancelAllForClient cancels the client's orders on the production book and
+// mirrors each cancellation
+func (m *MirroredBook) CancelAllForClient(clientID string) int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.mirrorCancels(m.book.cancelAllForClient(clientID)))
+}
+
+// ExpireOrders expires orders on the production book and mirrors each
+// expiry as a cancel
+func (m *MirroredBook) ExpireOrders(timeInForce string) []TradingOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mirrorCancels(m.book.ExpireOrders(timeInForce))
+}
+
+// ExpireGTD expires GTD orders on the production book and mirrors each
+// expiry as a cancel
+func (m *MirroredBook) ExpireGTD(now time.Time) []TradingOrder {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mirrorCancels(m.book.ExpireGTD(now))
+}
+
+// SetTickSize changes the production book's tick size, then mirrors the
+// change
+func (m *MirroredBook) SetTickSize(tick float64) ([]TradingOrder, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	removed, err := m.book.SetTickSize(tick)
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpTickSize, Price: tick}, nil, err)
+	return removed, err
+}
+
+// ReleasePaused releases a paused order on the production book, then
+// mirrors the release
+func (m *MirroredBook) ReleasePaused(orderID string) ([]Trade, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	trades, err := m.book.ReleasePaused(orderID)
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpRelease, OrderID: orderID}, trades, err)
+	return trades, err
+}
+
+// StartAuction starts an auction on the production book, then mirrors it
+func (m *MirroredBook) StartAuction() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.book.StartAuction()
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpAuctionStart}, nil, nil)
+}
+
+// Uncross uncrosses the production book, then mirrors the uncross
+func (m *MirroredBook) Uncross() (float64, []Trade) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	price, trades := m.book.Uncross()
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpUncross}, trades, nil)
+	return price, trades
+}
+
+// UncrossClose runs the closing uncross on the production book, then
+// mirrors it
+func (m *MirroredBook) UncrossClose() (float64, []Trade, []TradingOrder) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	price, trades, cancelled := m.book.UncrossClose()
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpCloseUncross}, trades, nil)
+	return price, trades, cancelled
+}
+
+// UpdatePegReference reprices the production book's pegs, then mirrors the
+// market move
+func (m *MirroredBook) UpdatePegReference(bid, ask float64) []Trade {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	trades := m.book.UpdatePegReference(bid, ask)
+	m.sink.Mirror(ScenarioOperation{Op: ScenarioOpPegMarket, Bid: bid, Ask: ask}, trades, nil)
+	return trades
+}
+
+// mirrorCancels mirrors one cancel per order removed in bulk
+func (m *MirroredBook) mirrorCancels(removed []TradingOrder) []TradingOrder {
+	for _, o := range removed {
+		m.sink.Mirror(ScenarioOperation{Op: ScenarioOpCancel, OrderID: o.OrderID}, nil, nil)
+	}
+	return removed
+}
+
+// Commodity returns the production book's commodity
+func (m *MirroredBook) Commodity() string {
+	return m.book.Commodity()
+}
+
+// Order returns a resting order on the production book
+func (m *MirroredBook) Order(orderID string) (TradingOrder, bool) {
+	return m.book.Order(orderID)
+}
+
+// Simulate simulates an order against the production book
+func (m *MirroredBook) Simulate(order TradingOrder) ([]Trade, error) {
+	return m.book.Simulate(order)
+}
+
+// BestBid returns the production book's best bid
+func (m *MirroredBook) BestBid() (price, volume float64, ok bool) {
+	return m.book.BestBid()
+}
+
+// BestAsk returns the production book's best ask
+func (m *MirroredBook) BestAsk() (price, volume float64, ok bool) {
+	return m.book.BestAsk()
+}
+
+// Snapshot returns the production book's depth
+func (m *MirroredBook) Snapshot() BookSnapshot {
+	return m.book.Snapshot()
+}
+
+// PausedOrders returns the production book's orders awaiting review
+func (m *MirroredBook) PausedOrders() []PausedOrder {
+	return m.book.PausedOrders()
+}
@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestMirrorSinkReportsDivergence verifies a shadow filling differently from production is reported
+func TestMirrorSinkReportsDivergence(t *testing.T) {
+	var mu sync.Mutex
+	var divergences []MirrorDivergence
+	shadow := NewOrderBook("crude_oil", WithLotSize(10, LotResidualRest))
+	sink := NewMirrorSink(shadow, MirrorConfig{OnDivergence: func(d MirrorDivergence) {
+		mu.Lock()
+		divergences = append(divergences, d)
+		mu.Unlock()
+	}})
+	book := NewMirroredBook(NewOrderBook("crude_oil"), sink)
+
+	book.Add(TradingOrder{OrderID: "ask", Side: SideSell, Price: 75.50, Volume: 30})
+	trades, err := book.Add(TradingOrder{OrderID: "buy", Side: SideBuy, Price: 75.50, Volume: 15})
+	if err != nil || len(trades) != 1 || trades[0].Volume != 15 {
+		t.Fatalf("Expected production to fill 15, got %+v, %v", trades, err)
+	}
+	book.Cancel("buy")
+	sink.Close()
+
+	if stats := sink.Stats(); stats.Mirrored != 3 || stats.Compared != 3 || stats.Divergences != 2 || stats.Dropped != 0 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(divergences) != 2 {
+		t.Fatalf("Expected two divergences, got %+v", divergences)
+	}
+	// The shadow fills 10 of 15 and rests the sub-lot 5, so the cancel that fails in production succeeds there
+	if d := divergences[0]; d.Seq != 2 || len(d.Differences) != 1 || !strings.Contains(d.Differences[0], "volume expected 15, got 10") {
+		t.Errorf("Expected a volume divergence on the buy, got %+v", d)
+	}
+	if d := divergences[1]; d.Seq != 3 || !strings.Contains(d.Differences[0], "production rejected") {
+		t.Errorf("Expected a rejection divergence on the cancel, got %+v", d)
+	}
+}
+
+// TestMirroredBookMirrorsEveryMutation verifies reductions, auctions and
+// bulk cancels reach the shadow so it stays in step with production
+func TestMirroredBookMirrorsEveryMutation(t *testing.T) {
+	var divergences []MirrorDivergence
+	shadow := NewOrderBook("crude_oil")
+	sink := NewMirrorSink(shadow, MirrorConfig{OnDivergence: func(d MirrorDivergence) { divergences = append(divergences, d) }})
+	production := NewOrderBook("crude_oil")
+	book := NewMirroredBook(production, sink)
+
+	book.Add(TradingOrder{OrderID: "ask1", ClientID: "gulf", Side: SideSell, Price: 75.50, Volume: 30})
+	book.Add(TradingOrder{OrderID: "ask2", ClientID: "delta", Side: SideSell, Price: 75.60, Volume: 20})
+	if err := book.ReduceQuantity("ask1", 20); err != nil {
+		t.Fatalf("Reduce failed: %v", err)
+	}
+	book.StartAuction()
+	book.Add(TradingOrder{OrderID: "bid1", ClientID: "acme", Side: SideBuy, Price: 75.60, Volume: 25})
+	if _, trades := book.Uncross(); len(trades) != 2 {
+		t.Fatalf("Expected the uncross to fill both offers, got %+v", trades)
+	}
+	book.Add(TradingOrder{OrderID: "bid2", ClientID: "acme", Side: SideBuy, Price: 75.00, Volume: 5})
+	if n := book.CancelAllForClient("acme"); n != 1 {
+		t.Errorf("Expected acme's bid cancelled, got %d", n)
+	}
+	sink.Close()
+
+	if stats := sink.Stats(); stats.Mirrored != 8 || stats.Compared != 8 || stats.Divergences != 0 {
+		t.Errorf("Expected every mutation compared without divergence, got %+v (%+v)", stats, divergences)
+	}
+	if got, want := shadow.Snapshot(), production.Snapshot(); !reflect.DeepEqual(got.Bids, want.Bids) || !reflect.DeepEqual(got.Asks, want.Asks) {
+		t.Errorf("Expected the shadow to match production, got %+v, want %+v", got, want)
+	}
+}
+
+// TestMirroredBookKeepsConcurrentOrder verifies concurrent callers are
+// mirrored in the order production matched them
+func TestMirroredBookKeepsConcurrentOrder(t *testing.T) {
+	shadow := NewOrderBook("crude_oil")
+	sink := NewMirrorSink(shadow, MirrorConfig{Buffer: 4096})
+	production := NewOrderBook("crude_oil")
+	book := NewMirroredBook(production, sink)
+
+	var wg sync.WaitGroup
+	for c := 0; c < 8; c++ {
+		wg.Add(1)
+		go func(c int) {
+			defer wg.Done()
+			side := SideBuy
+			if c%2 == 1 {
+				side = SideSell
+			}
+			for i := 0; i < 200; i++ {
+				id := fmt.Sprintf("c%d-%d", c, i)
+				book.Add(TradingOrder{OrderID: id, Side: side, Price: 75 + float64(i%3)/10, Volume: float64(1 + i%4)})
+				if i%5 == 0 {
+					book.Cancel(id)
+				}
+			}
+		}(c)
+	}
+	wg.Wait()
+	sink.Close()
+
+	if stats := sink.Stats(); stats.Dropped != 0 || stats.Divergences != 0 {
+		t.Errorf("Expected the shadow to see production's order, got %+v", stats)
+	}
+	if got, want := shadow.Snapshot(), production.Snapshot(); !reflect.DeepEqual(got.Bids, want.Bids) || !reflect.DeepEqual(got.Asks, want.Asks) {
+		t.Errorf("Expected the shadow to match production, got %+v, want %+v", got, want)
+	}
+}
+
+// blockingEngine is a shadow that stalls until released
+type blockingEngine struct {
+	*OrderBook
+	release chan struct{}
+}
+
+func (e blockingEngine) Add(order TradingOrder) ([]Trade, error) {
+	<-e.release
+	return e.OrderBook.Add(order)
+}
+
+// TestMirrorSinkNeverBlocksProduction verifies a stalled shadow drops flow instead of slowing production
+func TestMirrorSinkNeverBlocksProduction(t *testing.T) {
+	shadow := blockingEngine{OrderBook: NewOrderBook("crude_oil"), release: make(chan struct{})}
+	reported := make(chan MirrorDivergence, 1)
+	sink := NewMirrorSink(shadow, MirrorConfig{Buffer: 2, OnDivergence: func(d MirrorDivergence) { reported <- d }})
+	book := NewMirroredBook(NewOrderBook("crude_oil"), sink)
+
+	start := time.Now()
+	for i := 0; i < 50; i++ {
+		book.Add(TradingOrder{OrderID: string(rune('a' + i)), Side: SideBuy, Price: 75, Volume: 1})
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Expected production to run unimpeded, took %v", elapsed)
+	}
+	select {
+	case d := <-reported:
+		if !strings.Contains(d.Differences[0], "queue full") {
+			t.Errorf("Expected a dropped-flow divergence, got %+v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Expected the overflow to be reported")
+	}
+
+	close(shadow.release)
+	sink.Close()
+	if stats := sink.Stats(); stats.Mirrored != 50 || stats.Dropped == 0 || stats.Compared+stats.Dropped != 50 {
+		t.Errorf("Unexpected stats %+v", stats)
+	}
+}
@@ -0,0 +1,171 @@
+package integration
+
+import (
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// defaultPriceCurrency is the currency trades are assumed to be priced in
+const defaultPriceCurrency = "USD"
+
+// NetObligation is what one counterparty owes or is owed in one currency
+// on one settlement date, after netting every trade that settles then.
+// Amount is positive when the counterparty receives cash and negative
+// when it pays.
+type NetObligation struct {
+	Counterparty   string    `json:"counterparty"`
+	Currency       string    `json:"currency"`
+	SettlementDate time.Time `json:"settlement_date"`
+	Amount         float64   `json:"amount"`
+	// Exact is Amount as an exact decimal; Amount is the nearest float
+	Exact      string   `json:"exact"`
+	TradeCount int      `json:"trade_count"`
+	TradeIDs   []string `json:"trade_ids"`
+}
+
+// NettingConfig configures settlement netting
+type NettingConfig struct {
+	// Calendar dates each trade's settlement; nil settles T+2 on weekdays
+	Calendar *SettlementCalendar
+	// PriceCurrency is the currency of trades without an FX stamp; "" is USD
+	PriceCurrency string
+	// Counterparties maps client IDs to counterparty IDs; clients it does
+	// not know, or a nil source, net under their client ID
+	Counterparties ClientReferenceSource
+}
+
+type nettingKey struct {
+	counterparty string
+	currency     string
+	date         string
+}
+
+type nettingTotal struct {
+	obligation NetObligation
+	amount     *big.Rat
+	places     int
+}
+
+// SettlementNetter nets trades into one cash obligation per counterparty,
+// currency and settlement date
+type SettlementNetter struct {
+	config NettingConfig
+}
+
+// NewSettlementNetter creates a netter
+func NewSettlementNetter(config NettingConfig) *SettlementNetter {
+	if config.Calendar == nil {
+		config.Calendar = NewSettlementCalendar(nil)
+	}
+	if config.PriceCurrency == "" {
+		config.PriceCurrency = defaultPriceCurrency
+	}
+	return &SettlementNetter{config: config}
+}
+
+// NetByCounterpartyDate nets trades with the default configuration
+func NetByCounterpartyDate(trades []Trade) []NetObligation {
+	return NewSettlementNetter(NettingConfig{}).NetByCounterpartyDate(trades)
+}
+
+// NetByCounterpartyDate returns one obligation per counterparty, currency
+// and settlement date, ordered by date, currency and counterparty. Each
+// trade's buyer pays its notional and its seller receives it, converted at
+// the trade's stamped FX rate when it has one. Notionals are summed as
+// exact decimals, so netting loses nothing to float rounding, and each
+// currency and date nets to exactly zero across counterparties.
+func (n *SettlementNetter) NetByCounterpartyDate(trades []Trade) []NetObligation {
+	totals := make(map[nettingKey]*nettingTotal)
+	for _, trade := range trades {
+		currency, rate := n.config.PriceCurrency, 1.0
+		if trade.FXRate > 0 {
+			currency, rate = trade.SettlementCurrency, trade.FXRate
+		}
+		price, pricePlaces := exactDecimal(trade.Price)
+		volume, volumePlaces := exactDecimal(trade.Volume)
+		fx, ratePlaces := exactDecimal(rate)
+		notional := new(big.Rat).Mul(price, volume)
+		notional.Mul(notional, fx)
+		places := pricePlaces + volumePlaces + ratePlaces
+
+		date := n.config.Calendar.SettlementDate(trade.Commodity, trade.Timestamp)
+		n.add(totals, trade, n.counterparty(trade.BuyClientID), currency, date, new(big.Rat).Neg(notional), places)
+		n.add(totals, trade, n.counterparty(trade.SellClientID), currency, date, notional, places)
+	}
+
+	out := make([]NetObligation, 0, len(totals))
+	for _, total := range totals {
+		ob := total.obligation
+		ob.Amount, _ = total.amount.Float64()
+		ob.Exact = trimDecimal(total.amount.FloatString(total.places))
+		out = append(out, ob)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		a, b := out[i], out[j]
+		if !a.SettlementDate.Equal(b.SettlementDate) {
+			return a.SettlementDate.Before(b.SettlementDate)
+		}
+		if a.Currency != b.Currency {
+			return a.Currency < b.Currency
+		}
+		return a.Counterparty < b.Counterparty
+	})
+	return out
+}
+
+func (n *SettlementNetter) add(totals map[nettingKey]*nettingTotal, trade Trade, counterparty, currency string, date time.Time, amount *big.Rat, places int) {
+	key := nettingKey{counterparty: counterparty, currency: currency, date: date.Format("2006-01-02")}
+	total, ok := totals[key]
+	if !ok {
+		total = &nettingTotal{
+			obligation: NetObligation{Counterparty: counterparty, Currency: currency, SettlementDate: date},
+			amount:     new(big.Rat),
+		}
+		totals[key] = total
+	}
+	total.amount.Add(total.amount, amount)
+	if places > total.places {
+		total.places = places
+	}
+	total.obligation.TradeCount++
+	total.obligation.TradeIDs = append(total.obligation.TradeIDs, trade.TradeID)
+}
+
+// counterparty resolves a client to the counterparty it settles as
+func (n *SettlementNetter) counterparty(clientID string) string {
+	if n.config.Counterparties != nil {
+		if ref, ok := n.config.Counterparties.Lookup(clientID); ok && ref.CounterpartyID != "" {
+			return ref.CounterpartyID
+		}
+	}
+	return clientID
+}
+
+// exactDecimal returns v's shortest decimal representation as an exact
+// rational with its number of decimal places; non-finite values are zero
+func exactDecimal(v float64) (*big.Rat, int) {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return new(big.Rat), 0
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		return r, len(s) - i - 1
+	}
+	return r, 0
+}
+
+// trimDecimal drops trailing fractional zeros and a bare decimal point
+func trimDecimal(s string) string {
+	if strings.IndexByte(s, '.') < 0 {
+		return s
+	}
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,65 @@
+package integration
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestNetByCounterpartyDateKeepsDatesApart verifies trades net into one
+// obligation per counterparty, currency and settlement date, exactly
+func TestNetByCounterpartyDateKeepsDatesApart(t *testing.T) {
+	monday := time.Date(2024, 3, 4, 15, 0, 0, 0, time.UTC)
+	tuesday := monday.AddDate(0, 0, 1)
+	netter := NewSettlementNetter(NettingConfig{
+		Counterparties: NewClientReferenceTable(
+			ClientReference{ClientID: "desk_a1", CounterpartyID: "CP_A"},
+			ClientReference{ClientID: "desk_a2", CounterpartyID: "CP_A"},
+			ClientReference{ClientID: "desk_b", CounterpartyID: "CP_B"},
+		),
+	})
+
+	trades := []Trade{
+		// Monday: A buys 10@75.1 and sells back 4@75.3 across two desks
+		{TradeID: "t1", Commodity: "crude_oil", Price: 75.1, Volume: 10, BuyClientID: "desk_a1", SellClientID: "desk_b", Timestamp: monday},
+		{TradeID: "t2", Commodity: "crude_oil", Price: 75.3, Volume: 4, BuyClientID: "desk_b", SellClientID: "desk_a2", Timestamp: monday},
+		// Tuesday: B buys 2@75 and ten 0.1 notionals of gas, which floats do not sum exactly
+		{TradeID: "t3", Commodity: "crude_oil", Price: 75, Volume: 2, BuyClientID: "desk_b", SellClientID: "desk_a1", Timestamp: tuesday},
+	}
+	for i := 0; i < 10; i++ {
+		trades = append(trades, Trade{TradeID: "n", Commodity: "natural_gas", Price: 0.1, Volume: 1,
+			BuyClientID: "desk_b", SellClientID: "desk_a2", Timestamp: tuesday})
+	}
+	// A EUR-stamped trade settles separately from the USD ones
+	trades = append(trades, Trade{TradeID: "t4", Commodity: "crude_oil", Price: 75, Volume: 1,
+		BuyClientID: "desk_a1", SellClientID: "desk_b", Timestamp: tuesday, SettlementCurrency: "EUR", FXRate: 0.92})
+
+	got := netter.NetByCounterpartyDate(trades)
+	type row struct {
+		cp, ccy, date, exact string
+		amount               float64
+		count                int
+	}
+	var rows []row
+	for _, ob := range got {
+		rows = append(rows, row{ob.Counterparty, ob.Currency, ob.SettlementDate.Format("2006-01-02"), ob.Exact, ob.Amount, ob.TradeCount})
+	}
+	want := []row{
+		// Monday trades settle Wednesday: A pays 751 and receives 301.2
+		{"CP_A", "USD", "2024-03-06", "-449.8", -449.8, 2},
+		{"CP_B", "USD", "2024-03-06", "449.8", 449.8, 2},
+		// Tuesday trades settle Thursday, never netted with Wednesday's
+		{"CP_A", "EUR", "2024-03-07", "-69", -69, 1},
+		{"CP_B", "EUR", "2024-03-07", "69", 69, 1},
+		{"CP_A", "USD", "2024-03-07", "151", 151, 11},
+		{"CP_B", "USD", "2024-03-07", "-151", -151, 11},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("Expected obligations\n%+v\ngot\n%+v", want, rows)
+	}
+
+	// Without a counterparty source each client nets on its own
+	if obs := NetByCounterpartyDate(trades[:2]); len(obs) != 3 {
+		t.Errorf("Expected desk_a1, desk_a2 and desk_b obligations, got %+v", obs)
+	}
+}
@@ -0,0 +1,178 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Normalization errors
+var (
+	ErrUnmappableTick = errors.New("tick cannot be mapped to the canonical schema")
+	ErrUnknownSymbol  = errors.New("unknown symbol")
+)
+
+// SymbolResolver maps vendor symbols and aliases to canonical commodity names
+type SymbolResolver struct {
+	mu      sync.RWMutex
+	aliases map[string]string
+}
+
+// NewSymbolResolver creates a resolver from alias -> canonical commodity.
+// Canonical names always resolve to themselves.
+func NewSymbolResolver(aliases map[string]string) *SymbolResolver {
+	r := &SymbolResolver{aliases: make(map[string]string, len(aliases))}
+	for alias, commodity := range aliases {
+		r.Alias(alias, commodity)
+	}
+	return r
+}
+
+// Alias registers an alias for a commodity; matching ignores case and surrounding space
+func (r *SymbolResolver) Alias(alias, commodity string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.aliases[normalizeSymbol(alias)] = commodity
+	r.aliases[normalizeSymbol(commodity)] = commodity
+}
+
+// Resolve returns the canonical commodity for a symbol
+func (r *SymbolResolver) Resolve(symbol string) (string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	commodity, ok := r.aliases[normalizeSymbol(symbol)]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnknownSymbol, symbol)
+	}
+	return commodity, nil
+}
+
+func normalizeSymbol(symbol string) string {
+	return strings.ToLower(strings.TrimSpace(symbol))
+}
+
+// TickAdapter maps one vendor's payload format onto MarketData. Adapters
+// only translate fields; symbols are resolved and defaults filled by the
+// normalizer.
+type TickAdapter interface {
+	Adapt(payload []byte) (MarketData, error)
+}
+
+// TickAdapterFunc adapts a plain function to TickAdapter
+type TickAdapterFunc func(payload []byte) (MarketData, error)
+
+// Adapt implements TickAdapter
+func (f TickAdapterFunc) Adapt(payload []byte) (MarketData, error) {
+	return f(payload)
+}
+
+// TickNormalizer turns vendor payloads into canonical MarketData using the
+// adapter registered for each source. Missing exchange and timestamp fields
+// default to the source name and the receive time. Payloads that cannot be
+// mapped are passed to the error handler as well as returned. Prices must
+// be positive unless the commodity is configured to trade through zero.
+type TickNormalizer struct {
+	mu       sync.RWMutex
+	adapters map[string]TickAdapter
+	resolver *SymbolResolver
+	onError  func(source string, payload []byte, err error)
+	clock    func() time.Time
+	aligner  *TimestampAligner
+	signed   map[string]bool // commodities whose prices may be zero or negative
+}
+
+// NewTickNormalizer creates a normalizer; onError may be nil
+func NewTickNormalizer(resolver *SymbolResolver, onError func(source string, payload []byte, err error), clock func() time.Time) *TickNormalizer {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &TickNormalizer{
+		adapters: make(map[string]TickAdapter),
+		resolver: resolver,
+		onError:  onError,
+		clock:    clock,
+		signed:   make(map[string]bool),
+	}
+}
+
+// Register sets the adapter for a source
+func (n *TickNormalizer) Register(source string, adapter TickAdapter) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.adapters[source] = adapter
+}
+
+// AllowNegativePrices sets whether a canonical commodity may print zero or
+// negative prices, as futures such as crude have. Commodities default to
+// rejecting them, which suits spot markets that cannot go below zero.
+func (n *TickNormalizer) AllowNegativePrices(commodity string, allow bool) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if allow {
+		n.signed[commodity] = true
+	} else {
+		delete(n.signed, commodity)
+	}
+}
+
+// SetAligner aligns every normalized tick to exchange trade time
+func (n *TickNormalizer) SetAligner(aligner *TimestampAligner) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.aligner = aligner
+}
+
+// Normalize maps a payload from source to canonical MarketData
+func (n *TickNormalizer) Normalize(source string, payload []byte) (MarketData, error) {
+	tick, err := n.normalize(source, payload)
+	if err != nil {
+		err = fmt.Errorf("%w: %s: %v", ErrUnmappableTick, source, err)
+		if n.onError != nil {
+			n.onError(source, payload, err)
+		}
+		return MarketData{}, err
+	}
+	return tick, nil
+}
+
+func (n *TickNormalizer) normalize(source string, payload []byte) (MarketData, error) {
+	n.mu.RLock()
+	adapter, ok := n.adapters[source]
+	aligner := n.aligner
+	n.mu.RUnlock()
+	if !ok {
+		return MarketData{}, errors.New("no adapter registered")
+	}
+
+	tick, err := adapter.Adapt(payload)
+	if err != nil {
+		return MarketData{}, err
+	}
+	commodity, err := n.resolver.Resolve(tick.Commodity)
+	if err != nil {
+		return MarketData{}, err
+	}
+	tick.Commodity = commodity
+	if tick.Price <= 0 && !n.allowsNegative(commodity) {
+		return MarketData{}, fmt.Errorf("non-positive price %g for %s", tick.Price, commodity)
+	}
+	if tick.Exchange == "" {
+		tick.Exchange = source
+	}
+	if tick.Timestamp.IsZero() {
+		tick.Timestamp = n.clock()
+	}
+	tick.Timestamp = tick.Timestamp.UTC()
+	if aligner != nil {
+		return aligner.Align(tick)
+	}
+	return tick, nil
+}
+
+func (n *TickNormalizer) allowsNegative(commodity string) bool {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+	return n.signed[commodity]
+}
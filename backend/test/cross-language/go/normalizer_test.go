@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// vendorJSONAdapter handles {"sym":"CL","px":75.5,"qty":100,"ts":1704204000000}
+var vendorJSONAdapter = TickAdapterFunc(func(payload []byte) (MarketData, error) {
+	var msg struct {
+		Sym string  `json:"sym"`
+		Px  float64 `json:"px"`
+		Qty int64   `json:"qty"`
+		Ts  int64   `json:"ts"`
+	}
+	if err := json.Unmarshal(payload, &msg); err != nil {
+		return MarketData{}, err
+	}
+	return MarketData{Commodity: msg.Sym, Price: msg.Px, Volume: msg.Qty, Exchange: "NYMEX", Timestamp: time.UnixMilli(msg.Ts)}, nil
+})
+
+// vendorPipeAdapter handles WTI|75.50|100|2024-01-02T09:00:00-05:00
+var vendorPipeAdapter = TickAdapterFunc(func(payload []byte) (MarketData, error) {
+	fields := strings.Split(string(payload), "|")
+	if len(fields) != 4 {
+		return MarketData{}, fmt.Errorf("expected 4 fields, got %d", len(fields))
+	}
+	price, err := strconv.ParseFloat(fields[1], 64)
+	if err != nil {
+		return MarketData{}, err
+	}
+	volume, err := strconv.ParseInt(fields[2], 10, 64)
+	if err != nil {
+		return MarketData{}, err
+	}
+	ts, err := time.Parse(time.RFC3339, fields[3])
+	if err != nil {
+		return MarketData{}, err
+	}
+	return MarketData{Commodity: fields[0], Price: price, Volume: volume, Exchange: "NYMEX", Timestamp: ts}, nil
+})
+
+func newTestNormalizer(onError func(string, []byte, error)) *TickNormalizer {
+	resolver := NewSymbolResolver(map[string]string{"CL": "crude_oil", "WTI": "crude_oil", "NG": "natural_gas"})
+	normalizer := NewTickNormalizer(resolver, onError, nil)
+	normalizer.Register("vendor-a", vendorJSONAdapter)
+	normalizer.Register("vendor-b", vendorPipeAdapter)
+	return normalizer
+}
+
+// TestTickNormalizerVendorsAgree verifies two vendor formats normalize to the same MarketData
+func TestTickNormalizerVendorsAgree(t *testing.T) {
+	normalizer := newTestNormalizer(nil)
+
+	a, err := normalizer.Normalize("vendor-a", []byte(`{"sym":"CL","px":75.5,"qty":100,"ts":1704204000000}`))
+	if err != nil {
+		t.Fatalf("vendor-a: %v", err)
+	}
+	b, err := normalizer.Normalize("vendor-b", []byte("wti|75.50|100|2024-01-02T09:00:00-05:00"))
+	if err != nil {
+		t.Fatalf("vendor-b: %v", err)
+	}
+
+	expected := MarketData{Commodity: "crude_oil", Price: 75.5, Volume: 100, Exchange: "NYMEX", Timestamp: time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)}
+	if a != expected || b != expected {
+		t.Errorf("Expected both vendors to produce %+v, got\n%+v\n%+v", expected, a, b)
+	}
+}
+
+// TestTickNormalizerRoutesErrors verifies unmappable payloads reach the error handler
+func TestTickNormalizerRoutesErrors(t *testing.T) {
+	var handled []string
+	normalizer := newTestNormalizer(func(source string, payload []byte, err error) {
+		handled = append(handled, source)
+	})
+
+	payloads := []struct{ source, payload string }{
+		{"vendor-a", `{"sym":"BRENT","px":80,"qty":1,"ts":1704204000000}`}, // unknown symbol
+		{"vendor-b", "CL|abc|1|2024-01-02T14:00:00Z"},                      // bad price
+		{"vendor-c", "anything"},                                           // no adapter
+	}
+	for _, p := range payloads {
+		if _, err := normalizer.Normalize(p.source, []byte(p.payload)); !errors.Is(err, ErrUnmappableTick) {
+			t.Errorf("%s: expected ErrUnmappableTick, got %v", p.source, err)
+		}
+	}
+	if len(handled) != 3 {
+		t.Errorf("Expected 3 errors routed to the handler, got %v", handled)
+	}
+}
+
+// TestTickNormalizerNegativePricesByCommodity verifies negative prices pass only for commodities configured for them
+func TestTickNormalizerNegativePricesByCommodity(t *testing.T) {
+	normalizer := newTestNormalizer(nil)
+	normalizer.AllowNegativePrices("crude_oil", true)
+
+	tick, err := normalizer.Normalize("vendor-b", []byte("CL|-37.63|100|2020-04-20T14:30:00-04:00"))
+	if err != nil {
+		t.Fatalf("Expected negative crude futures price to pass, got %v", err)
+	}
+	if tick.Price != -37.63 || tick.Commodity != "crude_oil" {
+		t.Errorf("Unexpected tick %+v", tick)
+	}
+	if _, err := normalizer.Normalize("vendor-b", []byte("NG|-0.5|100|2020-04-20T14:30:00-04:00")); !errors.Is(err, ErrUnmappableTick) {
+		t.Errorf("Expected negative spot gas price to be rejected, got %v", err)
+	}
+	if _, err := normalizer.Normalize("vendor-b", []byte("NG|0|100|2020-04-20T14:30:00-04:00")); err == nil {
+		t.Error("Expected zero spot gas price to be rejected")
+	}
+
+	normalizer.AllowNegativePrices("crude_oil", false)
+	if _, err := normalizer.Normalize("vendor-b", []byte("CL|-37.63|100|2020-04-20T14:30:00-04:00")); err == nil {
+		t.Error("Expected negative crude to be rejected once disallowed")
+	}
+}
@@ -0,0 +1,160 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Alert severities
+const (
+	SeverityInfo     = "info"
+	SeverityWarning  = "warning"
+	SeverityCritical = "critical"
+)
+
+// Alert describes a risk event that people need to act on
+type Alert struct {
+	Severity  string    `json:"severity"`
+	Commodity string    `json:"commodity"`
+	Title     string    `json:"title"`
+	Detail    string    `json:"detail"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// Notifier delivers alerts to a destination
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+// NoopNotifier discards alerts
+type NoopNotifier struct{}
+
+// Notify implements Notifier
+func (NoopNotifier) Notify(ctx context.Context, alert Alert) error {
+	return nil
+}
+
+// WebhookNotifier posts alerts as JSON to an HTTP endpoint
+type WebhookNotifier struct {
+	URL    string
+	Client *http.Client
+}
+
+// WebhookError is returned for non-2xx webhook responses
+type WebhookError struct {
+	StatusCode int
+}
+
+func (e *WebhookError) Error() string {
+	return fmt.Sprintf("webhook returned status %d", e.StatusCode)
+}
+
+// Temporary reports whether the response is worth retrying
+func (e *WebhookError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// Notify implements Notifier
+func (w *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return fmt.Errorf("encode alert: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := w.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return &WebhookError{StatusCode: resp.StatusCode}
+	}
+	return nil
+}
+
+// isTransient reports whether an error should be retried. Errors that do
+// not say otherwise, such as network failures, are treated as transient.
+func isTransient(err error) bool {
+	if t, ok := err.(interface{ Temporary() bool }); ok {
+		return t.Temporary()
+	}
+	return true
+}
+
+// NotificationDispatcher fans alerts out to several notifiers in parallel,
+// retrying transient failures with linear backoff
+type NotificationDispatcher struct {
+	notifiers []Notifier
+	retries   int
+	backoff   time.Duration
+}
+
+// NewNotificationDispatcher creates a dispatcher over the given notifiers
+func NewNotificationDispatcher(retries int, backoff time.Duration, notifiers ...Notifier) *NotificationDispatcher {
+	return &NotificationDispatcher{notifiers: notifiers, retries: retries, backoff: backoff}
+}
+
+// Dispatch delivers the alert to every notifier concurrently so one slow or
+// failing destination never holds up the others. It returns an error
+// describing every notifier that ultimately failed.
+func (d *NotificationDispatcher) Dispatch(ctx context.Context, alert Alert) error {
+	if alert.Timestamp.IsZero() {
+		alert.Timestamp = time.Now()
+	}
+
+	errs := make([]error, len(d.notifiers))
+	var wg sync.WaitGroup
+	for i, n := range d.notifiers {
+		wg.Add(1)
+		go func(i int, n Notifier) {
+			defer wg.Done()
+			errs[i] = d.deliver(ctx, n, alert)
+		}(i, n)
+	}
+	wg.Wait()
+
+	var failures []string
+	for i, err := range errs {
+		if err != nil {
+			failures = append(failures, fmt.Sprintf("notifier %d (%T): %v", i, d.notifiers[i], err))
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("alert delivery failed: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// deliver sends to a single notifier with retries
+func (d *NotificationDispatcher) deliver(ctx context.Context, n Notifier, alert Alert) error {
+	var err error
+	for attempt := 0; attempt <= d.retries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(time.Duration(attempt) * d.backoff):
+			}
+		}
+		if err = n.Notify(ctx, alert); err == nil || !isTransient(err) {
+			return err
+		}
+	}
+	return err
+}
@@ -0,0 +1,97 @@
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// failingNotifier always fails, optionally after a delay
+type failingNotifier struct {
+	delay time.Duration
+	calls int32
+}
+
+func (f *failingNotifier) Notify(ctx context.Context, alert Alert) error {
+	atomic.AddInt32(&f.calls, 1)
+	time.Sleep(f.delay)
+	return errors.New("pager unavailable")
+}
+
+// TestNotificationDispatcherFanOut verifies delivery to a webhook despite a failing notifier
+func TestNotificationDispatcherFanOut(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []Alert
+		requests int32
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable) // first attempt fails transiently
+			return
+		}
+		var alert Alert
+		if err := json.NewDecoder(r.Body).Decode(&alert); err != nil {
+			t.Errorf("Failed to decode alert: %v", err)
+		}
+		mu.Lock()
+		received = append(received, alert)
+		mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	failing := &failingNotifier{delay: 20 * time.Millisecond}
+	dispatcher := NewNotificationDispatcher(2, time.Millisecond,
+		&WebhookNotifier{URL: server.URL},
+		failing,
+		NoopNotifier{},
+	)
+
+	err := dispatcher.Dispatch(context.Background(), Alert{
+		Severity:  SeverityCritical,
+		Commodity: "crude_oil",
+		Title:     "Position limit breached",
+		Detail:    "client_a long 12000 vs limit 10000",
+	})
+	if err == nil || !strings.Contains(err.Error(), "pager unavailable") {
+		t.Errorf("Expected failing notifier to be reported, got %v", err)
+	}
+	if calls := atomic.LoadInt32(&failing.calls); calls != 3 {
+		t.Errorf("Expected failing notifier to be tried 3 times, got %d", calls)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 {
+		t.Fatalf("Expected webhook to receive 1 alert, got %d", len(received))
+	}
+	if received[0].Severity != SeverityCritical || received[0].Commodity != "crude_oil" {
+		t.Errorf("Unexpected alert payload: %+v", received[0])
+	}
+}
+
+// TestWebhookPermanentFailureNotRetried verifies client errors are not retried
+func TestWebhookPermanentFailureNotRetried(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	dispatcher := NewNotificationDispatcher(3, time.Millisecond, &WebhookNotifier{URL: server.URL})
+	if err := dispatcher.Dispatch(context.Background(), Alert{Severity: SeverityWarning}); err == nil {
+		t.Error("Expected dispatch to fail")
+	}
+	if n := atomic.LoadInt32(&requests); n != 1 {
+		t.Errorf("Expected a single attempt for a 400 response, got %d", n)
+	}
+}
@@ -0,0 +1,184 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Notional budget errors
+var (
+	// ErrBudgetExceeded is returned when an order would exceed a client's notional budget
+	ErrBudgetExceeded = errors.New("notional budget exceeded")
+	// ErrNoMarketPrice is returned for a market order when there is no
+	// reference price to charge it at
+	ErrNoMarketPrice = errors.New("no reference price for market order")
+)
+
+// MarketPriceFunc prices a market order for risk checks, from the far
+// touch, last trade or mark; ok is false when there is no reference
+type MarketPriceFunc func(order TradingOrder) (price float64, ok bool)
+
+// FarTouch prices market orders at the opposite side of book: the best
+// ask for a buy and the best bid for a sell
+func FarTouch(book *OrderBook) MarketPriceFunc {
+	return func(order TradingOrder) (float64, bool) {
+		var price float64
+		var ok bool
+		if order.Side == SideBuy {
+			price, _, ok = book.BestAsk()
+		} else {
+			price, _, ok = book.BestBid()
+		}
+		return price, ok
+	}
+}
+
+type notionalEntry struct {
+	orderID  string
+	at       time.Time
+	notional float64
+}
+
+// NotionalBudget caps the total notional each client may submit within a
+// rolling window. Submissions older than the window no longer count.
+// Market orders carry no price and are charged at the MarketPriceFunc's
+// reference; without one they are rejected.
+type NotionalBudget struct {
+	mu          sync.Mutex
+	budget      float64
+	window      time.Duration
+	clock       func() time.Time
+	marketPrice MarketPriceFunc
+	entries     map[string][]notionalEntry
+	totals      map[string]float64
+}
+
+// NewNotionalBudget creates a budget of limit notional per client per window
+func NewNotionalBudget(limit float64, window time.Duration, clock func() time.Time) *NotionalBudget {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &NotionalBudget{
+		budget:  limit,
+		window:  window,
+		clock:   clock,
+		entries: make(map[string][]notionalEntry),
+		totals:  make(map[string]float64),
+	}
+}
+
+// SetMarketPrice sets the reference market orders are charged at
+func (n *NotionalBudget) SetMarketPrice(fn MarketPriceFunc) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.marketPrice = fn
+}
+
+// Submit charges the order's notional to its client, or returns
+// ErrBudgetExceeded without charging if it would go over the budget
+func (n *NotionalBudget) Submit(order TradingOrder) error {
+	notional, err := n.notional(order)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := n.clock()
+	n.expireLocked(order.ClientID, now)
+	if used := n.totals[order.ClientID]; used+notional > n.budget+volumeEpsilon {
+		return fmt.Errorf("%w: %s has used %.2f of %.2f, order needs %.2f",
+			ErrBudgetExceeded, order.ClientID, used, n.budget, notional)
+	}
+	n.entries[order.ClientID] = append(n.entries[order.ClientID], notionalEntry{orderID: order.OrderID, at: now, notional: notional})
+	n.totals[order.ClientID] += notional
+	return nil
+}
+
+// Refund returns the notional charged for an order that was rejected
+// after Submit. Orders no longer in the window have nothing left to refund.
+func (n *NotionalBudget) Refund(order TradingOrder) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	entries := n.entries[order.ClientID]
+	for i := len(entries) - 1; i >= 0; i-- {
+		if entries[i].orderID != order.OrderID {
+			continue
+		}
+		n.totals[order.ClientID] -= entries[i].notional
+		if len(entries) == 1 {
+			delete(n.entries, order.ClientID)
+			delete(n.totals, order.ClientID)
+		} else {
+			n.entries[order.ClientID] = append(entries[:i:i], entries[i+1:]...)
+		}
+		return
+	}
+}
+
+// Used returns the notional a client has submitted within the current window
+func (n *NotionalBudget) Used(clientID string) float64 {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.expireLocked(clientID, n.clock())
+	return n.totals[clientID]
+}
+
+func (n *NotionalBudget) expireLocked(clientID string, now time.Time) {
+	entries := n.entries[clientID]
+	cutoff := now.Add(-n.window)
+	i := 0
+	for i < len(entries) && !entries[i].at.After(cutoff) {
+		n.totals[clientID] -= entries[i].notional
+		i++
+	}
+	if i == 0 {
+		return
+	}
+	if i == len(entries) {
+		delete(n.entries, clientID)
+		delete(n.totals, clientID)
+		return
+	}
+	n.entries[clientID] = entries[i:]
+}
+
+// Check reports whether the order would fit the budget without charging it
+func (n *NotionalBudget) Check(order TradingOrder) error {
+	notional, err := n.notional(order)
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	n.expireLocked(order.ClientID, n.clock())
+	if used := n.totals[order.ClientID]; used+notional > n.budget+volumeEpsilon {
+		return fmt.Errorf("%w: %s has used %.2f of %.2f, order needs %.2f",
+			ErrBudgetExceeded, order.ClientID, used, n.budget, notional)
+	}
+	return nil
+}
+
+// notional values the order at its limit price, or a market order at the
+// reference price. The reference is read outside the budget's lock since
+// it may lock a book.
+func (n *NotionalBudget) notional(order TradingOrder) (float64, error) {
+	if order.Type != OrderTypeMarket {
+		return order.Volume * order.Price, nil
+	}
+	n.mu.Lock()
+	marketPrice := n.marketPrice
+	n.mu.Unlock()
+	if marketPrice != nil {
+		if price, ok := marketPrice(order); ok {
+			return order.Volume * price, nil
+		}
+	}
+	return 0, fmt.Errorf("%w: %s", ErrNoMarketPrice, order.OrderID)
+}
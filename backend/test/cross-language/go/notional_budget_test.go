@@ -0,0 +1,89 @@
+package integration
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestNotionalBudgetRejectionPoint verifies the order that would cross the budget is rejected
+func TestNotionalBudgetRejectionPoint(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	budget := NewNotionalBudget(100000, time.Minute, func() time.Time { return now })
+	order := TradingOrder{ClientID: "acme", Commodity: "crude_oil", Price: 80, Volume: 300} // 24,000
+
+	for i := 0; i < 4; i++ {
+		if err := budget.Submit(order); err != nil {
+			t.Fatalf("Order %d: expected acceptance, got %v", i+1, err)
+		}
+		now = now.Add(10 * time.Second)
+	}
+	if err := budget.Submit(order); !errors.Is(err, ErrBudgetExceeded) {
+		t.Fatalf("Expected the fifth order to exceed the budget, got %v", err)
+	}
+	if used := budget.Used("acme"); used != 96000 {
+		t.Errorf("Expected rejected order not to be charged, used %.0f", used)
+	}
+	if err := budget.Submit(TradingOrder{ClientID: "gulf", Price: 80, Volume: 300}); err != nil {
+		t.Errorf("Expected other clients to be unaffected, got %v", err)
+	}
+
+	// The first order (t=0) leaves the window at t=60s
+	now = now.Add(20 * time.Second)
+	if err := budget.Submit(order); err != nil {
+		t.Errorf("Expected capacity after the oldest order expired, got %v", err)
+	}
+}
+
+// TestNotionalBudgetChargesMarketOrders verifies market orders are charged at the far touch and rejected without one
+func TestNotionalBudgetChargesMarketOrders(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	budget := NewNotionalBudget(10000, time.Hour, nil)
+	order := TradingOrder{OrderID: "m1", ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Type: OrderTypeMarket, Volume: 100}
+
+	if err := budget.Submit(order); !errors.Is(err, ErrNoMarketPrice) {
+		t.Fatalf("Expected a market order without a reference to be rejected, got %v", err)
+	}
+	budget.SetMarketPrice(FarTouch(book))
+	if err := budget.Check(order); !errors.Is(err, ErrNoMarketPrice) {
+		t.Errorf("Expected an empty far side to leave no reference, got %v", err)
+	}
+
+	book.Add(TradingOrder{OrderID: "ask1", Side: SideSell, Price: 75.60, Volume: 500})
+	if err := budget.Submit(order); err != nil {
+		t.Fatalf("Expected the market order to fit, got %v", err)
+	}
+	if used := budget.Used("acme"); math.Abs(used-7560) > 1e-6 {
+		t.Errorf("Expected the order charged at the 75.60 offer, used %.2f", used)
+	}
+	order.OrderID = "m2"
+	if err := budget.Submit(order); !errors.Is(err, ErrBudgetExceeded) {
+		t.Errorf("Expected a second market order to exceed the budget, got %v", err)
+	}
+}
+
+// TestNotionalBudgetConcurrentSubmits verifies concurrent workers never overspend
+func TestNotionalBudgetConcurrentSubmits(t *testing.T) {
+	budget := NewNotionalBudget(10000, time.Hour, nil)
+	var accepted int64
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 50; i++ {
+				if budget.Submit(TradingOrder{ClientID: "acme", Price: 10, Volume: 10}) == nil {
+					atomic.AddInt64(&accepted, 1)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if accepted != 100 {
+		t.Errorf("Expected exactly 100 orders of 100 notional to fit a 10000 budget, got %d", accepted)
+	}
+}
@@ -0,0 +1,67 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+// ErrRedisNil is returned by RedisClient.Get when a key does not exist
+var ErrRedisNil = errors.New("redis: nil")
+
+// RedisClient is the subset of a Redis client used for checkpointing
+type RedisClient interface {
+	Get(ctx context.Context, key string) (string, error)
+	Set(ctx context.Context, key, value string) error
+}
+
+// OffsetStore persists processed offsets outside the broker
+type OffsetStore interface {
+	Load(ctx context.Context, group string, tp TopicPartition) (offset int64, found bool, err error)
+	Save(ctx context.Context, group string, offsets map[TopicPartition]int64) error
+}
+
+// RedisOffsetStore checkpoints consumer offsets in Redis
+type RedisOffsetStore struct {
+	client RedisClient
+	prefix string
+}
+
+// NewRedisOffsetStore creates a store writing keys under prefix
+func NewRedisOffsetStore(client RedisClient, prefix string) *RedisOffsetStore {
+	if prefix == "" {
+		prefix = "kafka:offsets"
+	}
+	return &RedisOffsetStore{client: client, prefix: prefix}
+}
+
+// Load implements OffsetStore
+func (s *RedisOffsetStore) Load(ctx context.Context, group string, tp TopicPartition) (int64, bool, error) {
+	value, err := s.client.Get(ctx, s.key(group, tp))
+	if errors.Is(err, ErrRedisNil) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("load checkpoint %s: %w", tp, err)
+	}
+	offset, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, false, fmt.Errorf("parse checkpoint %s: %w", tp, err)
+	}
+	return offset, true, nil
+}
+
+// Save implements OffsetStore
+func (s *RedisOffsetStore) Save(ctx context.Context, group string, offsets map[TopicPartition]int64) error {
+	for tp, offset := range offsets {
+		if err := s.client.Set(ctx, s.key(group, tp), strconv.FormatInt(offset, 10)); err != nil {
+			return fmt.Errorf("save checkpoint %s: %w", tp, err)
+		}
+	}
+	return nil
+}
+
+func (s *RedisOffsetStore) key(group string, tp TopicPartition) string {
+	return fmt.Sprintf("%s:%s:%s:%d", s.prefix, group, tp.Topic, tp.Partition)
+}
@@ -0,0 +1,118 @@
+package integration
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-memory RedisClient that can be switched off
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+	down bool
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{data: make(map[string]string)}
+}
+
+func (r *fakeRedis) Get(ctx context.Context, key string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return "", errors.New("dial tcp: connection refused")
+	}
+	value, ok := r.data[key]
+	if !ok {
+		return "", ErrRedisNil
+	}
+	return value, nil
+}
+
+func (r *fakeRedis) Set(ctx context.Context, key, value string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.down {
+		return errors.New("dial tcp: connection refused")
+	}
+	r.data[key] = value
+	return nil
+}
+
+// runCheckpointedConsumer consumes one session where p0 is assigned and returns the seek offset
+func runCheckpointedConsumer(t *testing.T, broker *fakeKafkaBroker, store OffsetStore, logger *log.Logger, deliver func(s *fakeKafkaSession, from int64)) int64 {
+	t.Helper()
+	p0 := TopicPartition{Topic: "trades", Partition: 0}
+	consumer := NewKafkaConsumer(broker, KafkaConsumerConfig{
+		Group:          "settlement",
+		Topics:         []string{"trades"},
+		CommitInterval: time.Hour,
+		Checkpoints:    store,
+		Logger:         logger,
+	}, func(ctx context.Context, msg KafkaMessage) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- consumer.Run(ctx) }()
+
+	session := <-broker.sessions
+	session.rebalances <- KafkaRebalance{Assigned: []TopicPartition{p0}}
+	session.barrier()
+	from := session.seekOffset(p0)
+	if deliver != nil {
+		deliver(session, from)
+		session.barrier()
+	}
+
+	cancel()
+	<-done
+	return from
+}
+
+// TestKafkaResumesFromRedisCheckpoint verifies a restart resumes from a Redis checkpoint ahead of the broker
+func TestKafkaResumesFromRedisCheckpoint(t *testing.T) {
+	p0 := TopicPartition{Topic: "trades", Partition: 0}
+	broker := newFakeKafkaBroker()
+	redis := newFakeRedis()
+	store := NewRedisOffsetStore(redis, "")
+
+	runCheckpointedConsumer(t, broker, store, nil, func(s *fakeKafkaSession, from int64) {
+		s.deliver(p0, from, 10)
+	})
+	if off, found, _ := store.Load(context.Background(), "settlement", p0); !found || off != 10 {
+		t.Fatalf("Expected checkpoint at 10, got %d (found=%v)", off, found)
+	}
+
+	// Simulate the broker commit being lost in the crash.
+	broker.mu.Lock()
+	broker.committed[p0] = 3
+	broker.mu.Unlock()
+
+	if from := runCheckpointedConsumer(t, broker, store, nil, nil); from != 10 {
+		t.Errorf("Expected restart to resume from Redis checkpoint 10, got %d", from)
+	}
+}
+
+// TestKafkaCheckpointFallsBackWhenRedisDown verifies broker offsets are used with a warning
+func TestKafkaCheckpointFallsBackWhenRedisDown(t *testing.T) {
+	p0 := TopicPartition{Topic: "trades", Partition: 0}
+	broker := newFakeKafkaBroker()
+	broker.committed[p0] = 7
+	redis := newFakeRedis()
+	redis.down = true
+
+	var logs bytes.Buffer
+	from := runCheckpointedConsumer(t, broker, NewRedisOffsetStore(redis, ""), log.New(&logs, "", 0), nil)
+	if from != 7 {
+		t.Errorf("Expected fallback to broker offset 7, got %d", from)
+	}
+	if !strings.Contains(logs.String(), "checkpoint unavailable") {
+		t.Errorf("Expected a warning to be logged, got %q", logs.String())
+	}
+}
@@ -0,0 +1,347 @@
+package integration
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// WAL durability modes
+const (
+	// WALSync fsyncs every order before Append returns, so an order is on
+	// disk before it can be acknowledged
+	WALSync = "sync"
+	// WALAsync writes orders straight away but fsyncs them in the
+	// background; a machine crash can lose the last SyncInterval of orders
+	WALAsync = "async"
+)
+
+// ErrWALClosed is returned by a closed write-ahead log
+var ErrWALClosed = errors.New("order wal closed")
+
+const (
+	walRecordOrder = "order"
+	walRecordAck   = "ack"
+
+	walHeaderSize = 8 // payload length and CRC-32, both uint32
+)
+
+// OrderWALConfig tunes an OrderWAL
+type OrderWALConfig struct {
+	// Durability is WALSync (the default) or WALAsync
+	Durability string
+	// SyncInterval is how often WALAsync fsyncs; default 10ms
+	SyncInterval time.Duration
+}
+
+// WALEntry is an order in the log with its sequence number
+type WALEntry struct {
+	Seq   uint64       `json:"seq"`
+	Order TradingOrder `json:"order"`
+}
+
+// RecoveredOrder is the outcome of replaying an unacked order. Processed
+// means the book had already taken the order before the crash, so it was
+// not submitted again and Result is empty.
+type RecoveredOrder struct {
+	Seq       uint64
+	Result    SubmitResult
+	Err       error
+	Processed bool
+}
+
+type walRecord struct {
+	Seq   uint64        `json:"seq"`
+	Type  string        `json:"type"`
+	Order *TradingOrder `json:"order,omitempty"`
+}
+
+// OrderWAL is a write-ahead log of accepted orders. Each order is appended
+// before it is processed and marked with an ack record once the client has
+// been answered; on startup the orders without an ack are the ones to
+// replay. Records are length-prefixed and checksummed, and a torn record
+// at the tail left by a crash is truncated on open.
+//
+// Ack records are not fsynced, and in WALAsync mode neither are orders, so
+// the log alone does not give exactly-once processing: an order whose ack
+// was lost is offered for replay again even if it reached the book and
+// filled. OrderGateway.RecoverWAL skips such orders by checking them
+// against the book's event log.
+type OrderWAL struct {
+	path   string
+	config OrderWALConfig
+
+	mu      sync.Mutex
+	file    *os.File
+	size    int64 // bytes of intact records
+	seq     uint64
+	pending map[uint64]TradingOrder
+	dirty   bool
+	closed  bool
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// OpenOrderWAL opens or creates the log at path and loads the orders it
+// holds without an ack
+func OpenOrderWAL(path string, config OrderWALConfig) (*OrderWAL, error) {
+	if config.Durability == "" {
+		config.Durability = WALSync
+	}
+	if config.Durability != WALSync && config.Durability != WALAsync {
+		return nil, fmt.Errorf("unknown wal durability %q", config.Durability)
+	}
+	if config.SyncInterval <= 0 {
+		config.SyncInterval = 10 * time.Millisecond
+	}
+
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open order wal: %w", err)
+	}
+	w := &OrderWAL{path: path, config: config, file: file, pending: make(map[uint64]TradingOrder)}
+	valid, err := w.load()
+	if err == nil {
+		err = w.truncate(valid)
+	}
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	if config.Durability == WALAsync {
+		w.done = make(chan struct{})
+		w.stopped = make(chan struct{})
+		go w.syncLoop()
+	}
+	return w, nil
+}
+
+// load reads every intact record and returns the offset just past the
+// last one
+func (w *OrderWAL) load() (int64, error) {
+	if _, err := w.file.Seek(0, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("read order wal: %w", err)
+	}
+	r := bufio.NewReader(w.file)
+	var offset int64
+	header := make([]byte, walHeaderSize)
+	for {
+		if _, err := io.ReadFull(r, header); err != nil {
+			return offset, nil // clean end or torn header
+		}
+		size := binary.BigEndian.Uint32(header[:4])
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return offset, nil // torn payload
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+			return offset, nil // partially written record
+		}
+		var rec walRecord
+		if err := json.Unmarshal(payload, &rec); err != nil {
+			return offset, fmt.Errorf("decode wal record at %d: %w", offset, err)
+		}
+		switch {
+		case rec.Type == walRecordOrder && rec.Order != nil:
+			w.pending[rec.Seq] = *rec.Order
+		case rec.Type == walRecordAck:
+			delete(w.pending, rec.Seq)
+		default:
+			return offset, fmt.Errorf("unknown wal record %q at %d", rec.Type, offset)
+		}
+		if rec.Seq > w.seq {
+			w.seq = rec.Seq
+		}
+		offset += walHeaderSize + int64(size)
+	}
+}
+
+// truncate drops anything after the last intact record and positions the
+// file for appending
+func (w *OrderWAL) truncate(size int64) error {
+	if err := w.file.Truncate(size); err != nil {
+		return fmt.Errorf("truncate order wal: %w", err)
+	}
+	if _, err := w.file.Seek(size, io.SeekStart); err != nil {
+		return fmt.Errorf("seek order wal: %w", err)
+	}
+	w.size = size
+	return nil
+}
+
+// Append logs an accepted order and returns its sequence number. Under
+// WALSync the order is on disk when Append returns.
+func (w *OrderWAL) Append(order TradingOrder) (uint64, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, ErrWALClosed
+	}
+
+	seq := w.seq + 1
+	if err := w.writeLocked(walRecord{Seq: seq, Type: walRecordOrder, Order: &order}); err != nil {
+		return 0, err
+	}
+	if w.config.Durability == WALSync {
+		if err := w.file.Sync(); err != nil {
+			return 0, fmt.Errorf("sync order wal: %w", err)
+		}
+	} else {
+		w.dirty = true
+	}
+	w.seq = seq
+	w.pending[seq] = order
+	return seq, nil
+}
+
+// Ack marks an order as acknowledged, so it is not replayed on recovery
+func (w *OrderWAL) Ack(seq uint64) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrWALClosed
+	}
+	if _, ok := w.pending[seq]; !ok {
+		return nil
+	}
+	if err := w.writeLocked(walRecord{Seq: seq, Type: walRecordAck}); err != nil {
+		return err
+	}
+	w.dirty = true
+	delete(w.pending, seq)
+	return nil
+}
+
+func (w *OrderWAL) writeLocked(rec walRecord) error {
+	payload, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("encode wal record: %w", err)
+	}
+	buf := make([]byte, walHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buf[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(payload))
+	copy(buf[walHeaderSize:], payload)
+	if _, err := w.file.Write(buf); err != nil {
+		// Cut off any partial record so later appends stay readable
+		w.truncate(w.size)
+		return fmt.Errorf("write order wal: %w", err)
+	}
+	w.size += int64(len(buf))
+	return nil
+}
+
+// Unacked returns the orders logged without an ack, in sequence order
+func (w *OrderWAL) Unacked() []WALEntry {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	entries := make([]WALEntry, 0, len(w.pending))
+	for seq, order := range w.pending {
+		entries = append(entries, WALEntry{Seq: seq, Order: order})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Seq < entries[j].Seq })
+	return entries
+}
+
+// Compact rewrites the log to hold only unacked orders. The new log is
+// written and synced beside the old one and renamed over it, so a crash
+// during compaction leaves one or the other intact.
+func (w *OrderWAL) Compact() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return ErrWALClosed
+	}
+
+	tmp := w.path + ".compact"
+	file, err := os.OpenFile(tmp, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0o644)
+	if err != nil {
+		return fmt.Errorf("compact order wal: %w", err)
+	}
+	old, oldSize := w.file, w.size
+	w.file, w.size = file, 0
+	seqs := make([]uint64, 0, len(w.pending))
+	for seq := range w.pending {
+		seqs = append(seqs, seq)
+	}
+	sort.Slice(seqs, func(i, j int) bool { return seqs[i] < seqs[j] })
+	for _, seq := range seqs {
+		order := w.pending[seq]
+		if err = w.writeLocked(walRecord{Seq: seq, Type: walRecordOrder, Order: &order}); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if err == nil {
+		err = os.Rename(tmp, w.path)
+	}
+	if err != nil {
+		w.file, w.size = old, oldSize
+		file.Close()
+		os.Remove(tmp)
+		return fmt.Errorf("compact order wal: %w", err)
+	}
+	old.Close()
+	w.dirty = false
+	if dir, err := os.Open(filepath.Dir(w.path)); err == nil {
+		dir.Sync()
+		dir.Close()
+	}
+	return nil
+}
+
+// Close syncs and closes the log
+func (w *OrderWAL) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.mu.Unlock()
+	if w.done != nil {
+		close(w.done)
+		<-w.stopped
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	err := w.file.Sync()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// syncLoop fsyncs WALAsync writes every SyncInterval
+func (w *OrderWAL) syncLoop() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(w.config.SyncInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-w.done:
+			return
+		case <-ticker.C:
+			w.mu.Lock()
+			if w.dirty {
+				// A failed sync leaves dirty set for the next tick
+				if w.file.Sync() == nil {
+					w.dirty = false
+				}
+			}
+			w.mu.Unlock()
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package integration
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// TestOrderWALRecoversUnackedAfterCrash verifies orders logged but never acknowledged are replayed after a crash
+func TestOrderWALRecoversUnackedAfterCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.wal")
+	wal, err := OpenOrderWAL(path, OrderWALConfig{Durability: WALSync})
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	gateway := NewOrderGateway(NewOrderBook("crude_oil"), nil)
+	gateway.SetWAL(wal)
+	for _, o := range []TradingOrder{
+		{OrderID: "acked1", Side: SideBuy, Price: 75.00, Volume: 10},
+		{OrderID: "acked2", Side: SideSell, Price: 76.00, Volume: 10},
+	} {
+		if _, err := gateway.Submit(o, SubmitOptions{}); err != nil {
+			t.Fatalf("Submit %s failed: %v", o.OrderID, err)
+		}
+	}
+	// Two orders reach the log but the process dies before answering, and
+	// a third is torn mid-write
+	for _, id := range []string{"inflight1", "inflight2"} {
+		if _, err := wal.Append(TradingOrder{OrderID: id, Side: SideBuy, Price: 74.50, Volume: 5}); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+	}
+	if _, err := wal.file.Write([]byte{0, 0, 1, 0, 0xde, 0xad, '{', '"'}); err != nil {
+		t.Fatalf("Failed to tear the tail: %v", err)
+	}
+	wal.file.Close() // no clean shutdown
+
+	recovered, err := OpenOrderWAL(path, OrderWALConfig{Durability: WALSync})
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer recovered.Close()
+	unacked := recovered.Unacked()
+	if len(unacked) != 2 || unacked[0].Order.OrderID != "inflight1" || unacked[1].Order.OrderID != "inflight2" {
+		t.Fatalf("Expected the two in-flight orders, got %+v", unacked)
+	}
+
+	book := NewOrderBook("crude_oil", WithEventLog(NewMemoryEventLog()))
+	restarted := NewOrderGateway(book, nil)
+	restarted.SetWAL(recovered)
+	results, err := restarted.RecoverWAL()
+	if err != nil || len(results) != 2 || results[0].Err != nil || results[1].Err != nil {
+		t.Fatalf("Expected both orders recovered, got %+v, %v", results, err)
+	}
+	if order, ok := book.Order("inflight2"); !ok || order.Volume != 5 {
+		t.Errorf("Expected inflight2 resting after recovery, got %+v", order)
+	}
+	if _, ok := book.Order("acked1"); ok {
+		t.Error("Expected acknowledged orders not to be replayed")
+	}
+	if len(recovered.Unacked()) != 0 {
+		t.Errorf("Expected nothing left to recover, got %+v", recovered.Unacked())
+	}
+	if seq, err := recovered.Append(TradingOrder{OrderID: "next", Side: SideBuy, Price: 74, Volume: 1}); err != nil || seq != 5 {
+		t.Errorf("Expected numbering to continue at 5 after the torn record, got %d, %v", seq, err)
+	}
+}
+
+// TestOrderWALRecoverySkipsOrdersFilledBeforeCrash verifies orders that
+// reached the book before a crash lost their ack are not submitted again
+func TestOrderWALRecoverySkipsOrdersFilledBeforeCrash(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.wal")
+	wal, err := OpenOrderWAL(path, OrderWALConfig{Durability: WALAsync})
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log))
+	gateway := NewOrderGateway(book, nil)
+	gateway.SetWAL(wal)
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", Side: SideSell, Price: 75.00, Volume: 10},
+		{OrderID: "ask2", Side: SideSell, Price: 75.10, Volume: 10},
+		{OrderID: "ask3", Side: SideSell, Price: 75.20, Volume: 10},
+	} {
+		if _, err := gateway.Submit(o, SubmitOptions{}); err != nil {
+			t.Fatalf("Submit %s failed: %v", o.OrderID, err)
+		}
+	}
+	// A limit and a market order are logged and fill, then the process dies
+	// before acking them; a third is logged but never reaches the book
+	for _, o := range []TradingOrder{
+		{OrderID: "buy1", Side: SideBuy, Price: 75.00, Volume: 10},
+		{OrderID: "mkt1", Side: SideBuy, Type: OrderTypeMarket, Volume: 10},
+	} {
+		if _, err := wal.Append(o); err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Add %s failed: %v", o.OrderID, err)
+		}
+	}
+	if _, err := wal.Append(TradingOrder{OrderID: "buy2", Side: SideBuy, Price: 74.50, Volume: 5}); err != nil {
+		t.Fatalf("Append failed: %v", err)
+	}
+	wal.Close()
+
+	recovered, err := OpenOrderWAL(path, OrderWALConfig{Durability: WALAsync})
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer recovered.Close()
+	rebuilt, err := Rebuild(log)
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	restarted := NewOrderGateway(rebuilt, nil)
+	restarted.SetWAL(recovered)
+	results, err := restarted.RecoverWAL()
+	if err != nil || len(results) != 3 {
+		t.Fatalf("Expected three orders recovered, got %+v, %v", results, err)
+	}
+	if !results[0].Processed || !results[1].Processed || results[2].Processed || results[2].Err != nil {
+		t.Errorf("Expected buy1 and mkt1 skipped and buy2 submitted, got %+v", results)
+	}
+	if order, ok := rebuilt.Order("ask3"); !ok || order.Volume != 10 {
+		t.Errorf("Expected ask3 untouched by recovery, got %+v", order)
+	}
+	if order, ok := rebuilt.Order("buy2"); !ok || order.Volume != 5 {
+		t.Errorf("Expected buy2 resting after recovery, got %+v", order)
+	}
+	if len(recovered.Unacked()) != 0 {
+		t.Errorf("Expected nothing left to recover, got %+v", recovered.Unacked())
+	}
+}
+
+// TestOrderWALAsyncCompact verifies async writes survive a clean close and compaction keeps only unacked orders
+func TestOrderWALAsyncCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "orders.wal")
+	wal, err := OpenOrderWAL(path, OrderWALConfig{Durability: WALAsync})
+	if err != nil {
+		t.Fatalf("Failed to open wal: %v", err)
+	}
+	for i := 1; i <= 20; i++ {
+		seq, err := wal.Append(TradingOrder{OrderID: "o" + strconv.Itoa(i), Side: SideBuy, Price: 75, Volume: 1})
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		if i != 7 {
+			wal.Ack(seq)
+		}
+	}
+	before, _ := os.Stat(path)
+	if err := wal.Compact(); err != nil {
+		t.Fatalf("Compact failed: %v", err)
+	}
+	after, _ := os.Stat(path)
+	if after.Size() >= before.Size() {
+		t.Errorf("Expected compaction to shrink the log from %d bytes, got %d", before.Size(), after.Size())
+	}
+	if err := wal.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	reopened, err := OpenOrderWAL(path, OrderWALConfig{})
+	if err != nil {
+		t.Fatalf("Failed to reopen wal: %v", err)
+	}
+	defer reopened.Close()
+	if unacked := reopened.Unacked(); len(unacked) != 1 || unacked[0].Seq != 7 || unacked[0].Order.OrderID != "o7" {
+		t.Errorf("Expected only o7 left, got %+v", unacked)
+	}
+}
+
+// BenchmarkOrderWALAppend measures logged orders per second in each durability mode
+func BenchmarkOrderWALAppend(b *testing.B) {
+	for _, mode := range []string{WALSync, WALAsync} {
+		b.Run(mode, func(b *testing.B) {
+			wal, err := OpenOrderWAL(filepath.Join(b.TempDir(), "orders.wal"), OrderWALConfig{Durability: mode})
+			if err != nil {
+				b.Fatalf("Failed to open wal: %v", err)
+			}
+			defer wal.Close()
+			order := TradingOrder{OrderID: "bench", Commodity: "crude_oil", Side: SideBuy, Price: 75, Volume: 10}
+
+			b.ReportAllocs()
+			start := time.Now()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				seq, err := wal.Append(order)
+				if err != nil {
+					b.Fatal(err)
+				}
+				wal.Ack(seq)
+			}
+			b.StopTimer()
+			if secs := time.Since(start).Seconds(); secs > 0 {
+				b.ReportMetric(float64(b.N)/secs, "orders/s")
+			}
+		})
+	}
+}
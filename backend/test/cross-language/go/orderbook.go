@@ -0,0 +1,1246 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// volumeEpsilon absorbs floating point residue when volumes are subtracted
+const volumeEpsilon = 1e-9
+
+// Order book errors
+var (
+	ErrInvalidOrder   = errors.New("invalid order")
+	ErrDuplicateOrder = errors.New("duplicate order id")
+	ErrOrderNotFound  = errors.New("order not found")
+	ErrWouldCross     = errors.New("amendment would cross the book")
+	ErrWouldTake      = errors.New("post-only order would take liquidity")
+	ErrNotOrderOwner  = errors.New("order belongs to another client")
+)
+
+// Amendment cross policies
+const (
+	AmendCrossTrade  = "trade"  // a crossing amendment trades immediately
+	AmendCrossReject = "reject" // a crossing amendment is rejected with ErrWouldCross
+)
+
+// Lot residual policies
+const (
+	LotResidualRest   = "rest"   // a sub-lot remainder rests unmatched
+	LotResidualCancel = "cancel" // a sub-lot remainder is cancelled on entry
+)
+
+// Tick size change policies for resting orders off the new tick grid
+const (
+	TickChangeGrandfather = "grandfather" // off-grid resting orders keep their price
+	TickChangeCancel      = "cancel"      // off-grid resting orders are cancelled
+)
+
+// Market order collar remainder policies
+const (
+	CollarRemainderCancel = "cancel" // volume the collar stops is cancelled
+	CollarRemainderRest   = "rest"   // volume the collar stops rests as a limit at the collar
+)
+
+// Market-on-close remainder policies
+const (
+	MOCRemainderCancel = "cancel" // unfilled MOC volume is cancelled at the close
+	MOCRemainderCarry  = "carry"  // unfilled MOC volume waits for the next close
+)
+
+// Trade represents an execution between a buy and a sell order
+type Trade struct {
+	TradeID      string    `json:"trade_id"`
+	Commodity    string    `json:"commodity"`
+	Price        float64   `json:"price"`
+	Volume       float64   `json:"volume"`
+	BuyOrderID   string    `json:"buy_order_id"`
+	SellOrderID  string    `json:"sell_order_id"`
+	BuyClientID  string    `json:"buy_client_id,omitempty"`
+	SellClientID string    `json:"sell_client_id,omitempty"`
+	BuyFee       float64   `json:"buy_fee,omitempty"`  // negative for a maker rebate
+	SellFee      float64   `json:"sell_fee,omitempty"` // negative for a maker rebate
+	Aggressor    string    `json:"aggressor"`
+	Timestamp    time.Time `json:"timestamp"`
+	// FX rate locked at trade time for settlement in another currency
+	SettlementCurrency string    `json:"settlement_currency,omitempty"`
+	FXRate             float64   `json:"fx_rate,omitempty"`
+	FXRateAt           time.Time `json:"fx_rate_at,omitempty"`
+}
+
+// PriceLevel is the aggregated resting interest at one price
+type PriceLevel struct {
+	Price  float64 `json:"price"`
+	Volume float64 `json:"volume"`
+	Orders int     `json:"orders"`
+}
+
+// BookSnapshot is a point-in-time view of aggregated book depth
+type BookSnapshot struct {
+	Commodity string       `json:"commodity"`
+	Seq       uint64       `json:"seq"`
+	Bids      []PriceLevel `json:"bids"`
+	Asks      []PriceLevel `json:"asks"`
+}
+
+// BookOption configures an OrderBook
+type BookOption func(*OrderBook)
+
+// WithClock overrides the clock used to timestamp trades
+func WithClock(clock func() time.Time) BookOption {
+	return func(b *OrderBook) {
+		b.clock = clock
+	}
+}
+
+// WithEventLog appends every book mutation to log
+func WithEventLog(log EventLog) BookOption {
+	return func(b *OrderBook) {
+		b.events = log
+	}
+}
+
+// WithMetrics reports book activity to recorder. Calls are made under the
+// book lock, so metrics move in step with the operations they describe.
+func WithMetrics(recorder MetricsRecorder) BookOption {
+	return func(b *OrderBook) {
+		b.metrics = recorder
+	}
+}
+
+// WithMOCRemainder sets what happens to market-on-close volume the closing
+// uncross cannot fill; the default is MOCRemainderCancel
+func WithMOCRemainder(policy string) BookOption {
+	return func(b *OrderBook) {
+		b.mocRemainder = policy
+	}
+}
+
+// WithAmendCross sets how amendments that would immediately cross the
+// opposite side are handled; the default is AmendCrossTrade
+func WithAmendCross(policy string) BookOption {
+	return func(b *OrderBook) {
+		b.amendCross = policy
+	}
+}
+
+// WithPriorityBoost changes queue priority within a price level. Orders
+// that have rested for at least after are seasoned and trade before any
+// unseasoned order at the same price. Seasoned orders are ranked by larger
+// remaining volume first, then by arrival; unseasoned orders keep pure time
+// priority behind them. Resting time is measured with the book clock, so
+// replays with the same clock allocate identically. A zero duration keeps
+// plain price-time priority.
+func WithPriorityBoost(after time.Duration) BookOption {
+	return func(b *OrderBook) {
+		b.boostAfter = after
+	}
+}
+
+// WithLotSize makes continuous matching fill only whole multiples of lot.
+// Sub-lot volume is never traded: an incoming order's sub-lot remainder
+// rests or is cancelled according to residual, and a resting order worn
+// down below one lot is passed over by matching until cancelled. The
+// default residual policy is LotResidualRest.
+func WithLotSize(lot float64, residual string) BookOption {
+	return func(b *OrderBook) {
+		b.lotSize = lot
+		b.lotResidual = residual
+	}
+}
+
+// WithIcebergJitter randomises iceberg slice sizes so refreshes are harder
+// to spot. Each slice is the order's DisplayVolume scaled by a factor drawn
+// uniformly from [1-spread, 1+spread], rounded to the lot size when one is
+// set. Draws are derived from seed, the order ID and the slice number, so
+// a seed reproduces the same slices, including on replay.
+func WithIcebergJitter(spread float64, seed int64) BookOption {
+	return func(b *OrderBook) {
+		b.jitter = spread
+		b.jitterSeed = seed
+	}
+}
+
+// WithTickSize rejects limit orders priced off the tick grid with
+// ErrInvalidTick. Resting orders left off the grid by a later SetTickSize
+// are handled according to policy; the default is TickChangeGrandfather.
+func WithTickSize(tick float64, policy string) BookOption {
+	return func(b *OrderBook) {
+		b.tickSize = tick
+		b.tickChange = policy
+	}
+}
+
+// WithMarketCollar stops market orders walking the book more than width
+// from the best opposite price when they arrive. Fills stop at the collar
+// and the rest of the order is cancelled or rests as a limit order at the
+// collar price according to remainder; the default is
+// CollarRemainderCancel. Each book trades one commodity, so the width is
+// set per commodity.
+func WithMarketCollar(width float64, remainder string) BookOption {
+	return func(b *OrderBook) {
+		b.collarWidth = width
+		b.collarRemainder = remainder
+	}
+}
+
+// WithProRata allocates fills within a price level in proportion to
+// resting volume instead of by time. Allocations are whole multiples of
+// unit, or of the lot size when unit is zero, or of 1 without either, and
+// are rounded by the largest-remainder method so they add up exactly; any
+// sub-unit residue of the incoming order fills by time priority.
+func WithProRata(unit float64) BookOption {
+	return func(b *OrderBook) {
+		b.proRata = true
+		b.proRataUnit = unit
+	}
+}
+
+// WithSignedPrices accepts zero and negative limit prices, as quoted on
+// spread books where the net price between two legs can fall below zero
+func WithSignedPrices() BookOption {
+	return func(b *OrderBook) {
+		b.signedPrices = true
+	}
+}
+
+// OrderBook is a price-time priority limit order book for a single commodity
+type OrderBook struct {
+	mu        sync.Mutex
+	commodity string
+	bids      []*bookLevel // highest price first
+	asks      []*bookLevel // lowest price first
+	orders    map[string]*restingOrder
+	clock     func() time.Time
+	seq       uint64 // incremented on every mutation
+	tradeSeq  uint64
+	arrivals  uint64
+	events    EventLog
+	eventSeq  uint64
+
+	metrics         MetricsRecorder
+	amendCross      string
+	boostAfter      time.Duration
+	auction         bool
+	moc             []*restingOrder // market-on-close orders in arrival order
+	mocRemainder    string
+	pegStep         float64
+	pegRetain       bool
+	pegBid          float64 // peg reference market
+	pegAsk          float64
+	signedPrices    bool
+	tickSize        float64
+	tickChange      string
+	collarWidth     float64
+	collarRemainder string
+	proRata         bool
+	proRataUnit     float64
+	refPrice        ReferencePriceFunc
+	refBand         float64
+	onPause         func(PausedOrder)
+	paused          []PausedOrder // held for review in the order they paused
+	lotSize         float64
+	lotResidual     string
+	jitter          float64
+	jitterSeed      int64
+	nextExpiry      time.Time // earliest resting GTD expiry, zero when none
+	opTime          time.Time // clock reading for the operation in progress
+}
+
+type bookLevel struct {
+	price  float64
+	orders []*restingOrder
+}
+
+type restingOrder struct {
+	TradingOrder // Volume is the displayed volume
+	arrival      uint64
+	restedAt     time.Time
+	hidden       float64 // iceberg reserve behind the display
+	slices       int     // iceberg slices displayed so far
+}
+
+// NewOrderBook creates an empty order book for a commodity
+func NewOrderBook(commodity string, opts ...BookOption) *OrderBook {
+	b := &OrderBook{
+		commodity: commodity,
+		orders:    make(map[string]*restingOrder),
+		clock:     time.Now,
+		metrics:   NoopMetrics{},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// Commodity returns the commodity traded on this book
+func (b *OrderBook) Commodity() string {
+	return b.commodity
+}
+
+// Add submits an order, matching it against resting interest. Any unfilled
+// limit volume rests on the book; unfilled market volume is discarded.
+func (b *OrderBook) Add(order TradingOrder) ([]Trade, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	err := b.validate(order)
+	if err == nil {
+		err = b.pegLocked(&order)
+	}
+	if err == nil {
+		err = b.postOnlyLocked(order)
+	}
+	if err != nil {
+		b.metrics.OrderRejected(b.commodity)
+		return nil, err
+	}
+	if order.Commodity == "" {
+		order.Commodity = b.commodity
+	}
+	if order.Type == "" {
+		order.Type = OrderTypeLimit
+	}
+	if !order.ExpiresAt.IsZero() {
+		order.ExpiresAt = order.ExpiresAt.UTC().Truncate(time.Second)
+	}
+	if paused, ok := b.bandLocked(order); ok {
+		b.pauseLocked(paused)
+		return nil, fmt.Errorf("%w: %s at %g against %g", ErrPriceBandPaused, order.OrderID, paused.Price, paused.Reference)
+	}
+	b.record(BookEvent{Type: BookEventAdd, OrderID: order.OrderID, Order: &order})
+	b.metrics.OrderAdded(b.commodity)
+	return b.addLocked(order), nil
+}
+
+// Simulate returns the trades order would produce without changing the
+// book. The order is validated as Add would validate it, and one that Add
+// would pause outside the reference band returns ErrPriceBandPaused.
+func (b *OrderBook) Simulate(order TradingOrder) ([]Trade, error) {
+	trades, _, err := b.simulate(order)
+	return trades, err
+}
+
+// simulate is Simulate that also returns the volume the order would leave
+// resting, hidden reserve included
+func (b *OrderBook) simulate(order TradingOrder) ([]Trade, float64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err := b.validate(order); err != nil {
+		return nil, 0, err
+	}
+	if err := b.pegLocked(&order); err != nil {
+		return nil, 0, err
+	}
+	if err := b.postOnlyLocked(order); err != nil {
+		return nil, 0, err
+	}
+	if order.Commodity == "" {
+		order.Commodity = b.commodity
+	}
+	if order.Type == "" {
+		order.Type = OrderTypeLimit
+	}
+	if !order.ExpiresAt.IsZero() {
+		order.ExpiresAt = order.ExpiresAt.UTC().Truncate(time.Second)
+	}
+	if paused, ok := b.bandLocked(order); ok {
+		return nil, 0, fmt.Errorf("%w: %s at %g against %g", ErrPriceBandPaused, order.OrderID, paused.Price, paused.Reference)
+	}
+	clone := b.cloneLocked()
+	trades := clone.addLocked(order)
+	var resting float64
+	if ro, ok := clone.orders[order.OrderID]; ok {
+		resting = ro.Volume + ro.hidden
+	}
+	return trades, resting, nil
+}
+
+// Cancel removes a resting order from the book, or rejects an order paused
+// for review
+func (b *OrderBook) Cancel(orderID string) error {
+	_, err := b.cancel(orderID, "", false)
+	return err
+}
+
+// cancel removes an order as Cancel does, returning the paused order it
+// rejected, if it was one. With checkOwner set, an order not belonging to
+// clientID is left alone and ErrNotOrderOwner returned.
+func (b *OrderBook) cancel(orderID, clientID string, checkOwner bool) (*TradingOrder, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ro, ok := b.orders[orderID]
+	if !ok {
+		if i := b.pausedIndex(orderID); i >= 0 {
+			paused := b.paused[i].Order
+			if checkOwner && paused.ClientID != clientID {
+				return nil, fmt.Errorf("%w: %s", ErrNotOrderOwner, orderID)
+			}
+			b.record(BookEvent{Type: BookEventCancel, OrderID: orderID})
+			b.paused = append(b.paused[:i], b.paused[i+1:]...)
+			b.metrics.OrdersCanceled(b.commodity, 1)
+			b.changedLocked()
+			return &paused, nil
+		}
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	if checkOwner && ro.ClientID != clientID {
+		return nil, fmt.Errorf("%w: %s", ErrNotOrderOwner, orderID)
+	}
+	b.record(BookEvent{Type: BookEventCancel, OrderID: orderID})
+	b.removeLocked(ro)
+	b.metrics.OrdersCanceled(b.commodity, 1)
+	b.changedLocked()
+	return nil, nil
+}
+
+// Amend changes the price and volume of a resting order. Reducing volume at
+// the same price keeps time priority; any other change re-enters the order
+// at the back of the queue and may trade immediately. An amendment that
+// would trade outside the reference band takes the order off the book and
+// pauses it for review, as Add would pause a new order.
+func (b *OrderBook) Amend(orderID string, price, volume float64) ([]Trade, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ro, ok := b.orders[orderID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	if !finite(price, volume) || volume <= 0 || (price <= 0 && !b.signedPrices) {
+		return nil, fmt.Errorf("%w: amend requires positive price and volume", ErrInvalidOrder)
+	}
+	if price != ro.Price && !b.onTick(price) {
+		return nil, fmt.Errorf("%w: %g with tick %g", ErrInvalidTick, price, b.tickSize)
+	}
+	if (b.amendCross == AmendCrossReject || ro.PostOnly) && price != ro.Price {
+		probe := ro.TradingOrder
+		probe.Price = price
+		opposite := b.bids
+		if probe.Side == SideBuy {
+			opposite = b.asks
+		}
+		if len(opposite) > 0 && crosses(&probe, opposite[0].price) {
+			err := ErrWouldCross
+			if ro.PostOnly {
+				err = ErrWouldTake
+			}
+			return nil, fmt.Errorf("%w: %s at %g against %g", err, orderID, price, opposite[0].price)
+		}
+	}
+	amended := ro.TradingOrder
+	amended.Price = price
+	amended.Volume = volume
+	if paused, ok := b.bandLocked(amended); ok {
+		b.removeLocked(ro)
+		b.pauseLocked(paused)
+		return nil, fmt.Errorf("%w: %s at %g against %g", ErrPriceBandPaused, orderID, paused.Price, paused.Reference)
+	}
+	b.record(BookEvent{Type: BookEventAmend, OrderID: orderID, Price: price, Volume: volume})
+
+	if price == ro.Price && volume <= ro.Volume && ro.DisplayVolume == 0 {
+		ro.Volume = volume
+		b.changedLocked()
+		return nil, nil
+	}
+
+	b.removeLocked(ro)
+	return b.addLocked(amended), nil
+}
+
+// SetTickSize changes the tick size, zero for none. New prices must be on
+// the new grid at once. Under TickChangeCancel, resting limit orders off it
+// are cancelled in the same step and returned in arrival order; otherwise
+// they keep their price and priority until they fill or are cancelled.
+func (b *OrderBook) SetTickSize(tick float64) ([]TradingOrder, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.setTickSizeLocked(tick, b.tickChange == TickChangeCancel)
+}
+
+// setTickSizeLocked records the change so replay reapplies it; replay
+// passes cancel false because the cancellations are in the log
+func (b *OrderBook) setTickSizeLocked(tick float64, cancel bool) ([]TradingOrder, error) {
+	if tick < 0 || math.IsNaN(tick) {
+		return nil, fmt.Errorf("%w: tick size %g", ErrInvalidOrder, tick)
+	}
+	b.record(BookEvent{Type: BookEventTickSize, Price: tick})
+	b.tickSize = tick
+	if !cancel || tick == 0 {
+		b.changedLocked()
+		return nil, nil
+	}
+	removed := b.removeWhereLocked(func(ro *restingOrder) bool {
+		return ro.Type == OrderTypeLimit && !b.onTick(ro.Price)
+	})
+	if removed == nil {
+		b.changedLocked()
+	}
+	return removed, nil
+}
+
+// TickSize returns the current tick size, zero when prices are unrestricted
+func (b *OrderBook) TickSize() float64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.tickSize
+}
+
+// onTick reports whether price is on the tick grid
+func (b *OrderBook) onTick(price float64) bool {
+	return b.tickSize <= 0 || isMultiple(price, b.tickSize)
+}
+
+// ReduceQuantity takes reduceBy off a resting order's remaining volume
+// without touching its time priority. An iceberg gives up hidden reserve
+// before displayed volume. Reducing to zero or below is rejected; use
+// Cancel to remove an order.
+func (b *OrderBook) ReduceQuantity(orderID string, reduceBy float64) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ro, ok := b.orders[orderID]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	if !finite(reduceBy) || reduceBy <= 0 {
+		return fmt.Errorf("%w: reduction must be positive", ErrInvalidOrder)
+	}
+	if remaining := ro.Volume + ro.hidden; reduceBy >= remaining-volumeEpsilon {
+		return fmt.Errorf("%w: reducing %s by %g leaves nothing of %g; cancel it instead", ErrInvalidOrder, orderID, reduceBy, remaining)
+	}
+	b.record(BookEvent{Type: BookEventReduce, OrderID: orderID, Volume: reduceBy})
+
+	fromHidden := math.Min(reduceBy, ro.hidden)
+	ro.hidden -= fromHidden
+	ro.Volume -= reduceBy - fromHidden
+	b.changedLocked()
+	return nil
+}
+
+// Order returns a copy of a resting order with its remaining volume,
+// including any hidden iceberg reserve
+func (b *OrderBook) Order(orderID string) (TradingOrder, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	ro, ok := b.orders[orderID]
+	if !ok {
+		return TradingOrder{}, false
+	}
+	order := ro.TradingOrder
+	order.Volume += ro.hidden
+	return order, true
+}
+
+// ClientQuote is one client's best resting price on each side with the
+// volume it displays there, and whether that price is the book's best
+type ClientQuote struct {
+	ClientID   string  `json:"client_id"`
+	Bid        float64 `json:"bid"`
+	BidVolume  float64 `json:"bid_volume"`
+	Ask        float64 `json:"ask"`
+	AskVolume  float64 `json:"ask_volume"`
+	BidAtTouch bool    `json:"bid_at_touch"`
+	AskAtTouch bool    `json:"ask_at_touch"`
+}
+
+// ClientQuote returns clientID's best displayed bid and ask, read together
+// with the book's best prices. A side the client is not quoting has zero
+// volume.
+func (b *OrderBook) ClientQuote(clientID string) ClientQuote {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	quote := ClientQuote{ClientID: clientID}
+	quote.Bid, quote.BidVolume, quote.BidAtTouch = clientBest(b.bids, clientID)
+	quote.Ask, quote.AskVolume, quote.AskAtTouch = clientBest(b.asks, clientID)
+	return quote
+}
+
+// clientBest finds the best level holding an order of clientID
+func clientBest(levels []*bookLevel, clientID string) (price, volume float64, atTouch bool) {
+	for i, level := range levels {
+		for _, o := range level.orders {
+			if o.ClientID == clientID {
+				volume += o.Volume
+			}
+		}
+		if volume > volumeEpsilon {
+			return level.price, volume, i == 0
+		}
+	}
+	return 0, 0, false
+}
+
+// ExpireOrders removes every resting order with the given time in force and
+// returns them in arrival order
+func (b *OrderBook) ExpireOrders(timeInForce string) []TradingOrder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.removeWhereLocked(func(ro *restingOrder) bool {
+		return ro.TimeInForce == timeInForce
+	})
+}
+
+// ExpireGTD removes every resting GTD order whose expiry is at or before
+// now and returns them in arrival order. The book tracks its earliest
+// expiry, so calls before then cost nothing.
+func (b *OrderBook) ExpireGTD(now time.Time) []TradingOrder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.nextExpiry.IsZero() || now.Before(b.nextExpiry) {
+		return nil
+	}
+	expired := b.removeWhereLocked(func(ro *restingOrder) bool {
+		return ro.TimeInForce == TimeInForceGTD && !now.Before(ro.ExpiresAt)
+	})
+	b.nextExpiry = time.Time{}
+	for _, ro := range b.orders {
+		b.trackExpiryLocked(ro)
+	}
+	return expired
+}
+
+// CancelAllForClient cancels every resting or paused order belonging to
+// clientID in one operation and returns how many were cancelled. Each
+// cancellation is recorded as its own event, in arrival order.
+func (b *OrderBook) CancelAllForClient(clientID string) int {
+	return len(b.cancelAllForClient(clientID))
+}
+
+// cancelAllForClient cancels as CancelAllForClient does, returning the
+// orders it cancelled
+func (b *OrderBook) cancelAllForClient(clientID string) []TradingOrder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if clientID == "" {
+		return nil
+	}
+	return b.removeWhereLocked(func(ro *restingOrder) bool {
+		return ro.ClientID == clientID
+	})
+}
+
+// CancelAll cancels every resting order, including market-on-close orders,
+// and every order paused for review, and returns them in arrival order
+func (b *OrderBook) CancelAll() []TradingOrder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.removeWhereLocked(func(*restingOrder) bool { return true })
+}
+
+// BestBid returns the highest bid price and its aggregated volume
+func (b *OrderBook) BestBid() (price, volume float64, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bestOf(b.bids)
+}
+
+// BestAsk returns the lowest ask price and its aggregated volume
+func (b *OrderBook) BestAsk() (price, volume float64, ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return bestOf(b.asks)
+}
+
+// Snapshot returns the aggregated depth on both sides of the book
+func (b *OrderBook) Snapshot() BookSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return BookSnapshot{
+		Commodity: b.commodity,
+		Seq:       b.seq,
+		Bids:      aggregateLevels(b.bids),
+		Asks:      aggregateLevels(b.asks),
+	}
+}
+
+// validate checks an incoming order before it touches the book
+func (b *OrderBook) validate(order TradingOrder) error {
+	switch {
+	case order.OrderID == "":
+		return fmt.Errorf("%w: missing order id", ErrInvalidOrder)
+	case order.Commodity != "" && order.Commodity != b.commodity:
+		return fmt.Errorf("%w: commodity %s does not match book %s", ErrInvalidOrder, order.Commodity, b.commodity)
+	case order.Side != SideBuy && order.Side != SideSell:
+		return fmt.Errorf("%w: unknown side %q", ErrInvalidOrder, order.Side)
+	case order.Type != "" && order.Type != OrderTypeLimit && order.Type != OrderTypeMarket &&
+		order.Type != OrderTypeMarketOnClose && order.Type != OrderTypePegged:
+		return fmt.Errorf("%w: unknown type %q", ErrInvalidOrder, order.Type)
+	case order.Type == OrderTypePegged && order.PegReference != PegBid && order.PegReference != PegAsk && order.PegReference != PegMid:
+		return fmt.Errorf("%w: unknown peg reference %q", ErrInvalidOrder, order.PegReference)
+	case !finite(order.Volume, order.Price, order.MinQty, order.DisplayVolume, order.PegOffset):
+		return fmt.Errorf("%w: quantities and prices must be finite", ErrInvalidOrder)
+	case order.Volume <= 0:
+		return fmt.Errorf("%w: volume must be positive", ErrInvalidOrder)
+	case order.MinQty < 0 || order.MinQty > order.Volume+volumeEpsilon:
+		return fmt.Errorf("%w: minimum quantity must be between zero and volume", ErrInvalidOrder)
+	case order.DisplayVolume < 0:
+		return fmt.Errorf("%w: display volume cannot be negative", ErrInvalidOrder)
+	case order.DisplayVolume > 0 && order.Type != "" && order.Type != OrderTypeLimit:
+		return fmt.Errorf("%w: only limit orders can be icebergs", ErrInvalidOrder)
+	case order.PostOnly && order.Type == OrderTypeMarket:
+		return fmt.Errorf("%w: market orders cannot be post-only", ErrInvalidOrder)
+	case order.TimeInForce == TimeInForceIOC && (order.PostOnly || order.DisplayVolume > 0):
+		return fmt.Errorf("%w: IOC orders cannot be post-only or icebergs", ErrInvalidOrder)
+	case order.TimeInForce == TimeInForceIOC && order.Type != "" && order.Type != OrderTypeLimit && order.Type != OrderTypeMarket:
+		return fmt.Errorf("%w: IOC is only valid for limit and market orders", ErrInvalidOrder)
+	case b.auction && order.Type == OrderTypeMarket:
+		return fmt.Errorf("%w: market orders are not accepted during an auction", ErrInvalidOrder)
+	case b.auction && order.TimeInForce == TimeInForceIOC:
+		return fmt.Errorf("%w: IOC orders are not accepted during an auction", ErrInvalidOrder)
+	case (order.Type == "" || order.Type == OrderTypeLimit) && order.Price <= 0 && !b.signedPrices:
+		return fmt.Errorf("%w: limit price must be positive", ErrInvalidOrder)
+	case (order.Type == "" || order.Type == OrderTypeLimit) && !b.onTick(order.Price):
+		return fmt.Errorf("%w: %g with tick %g", ErrInvalidTick, order.Price, b.tickSize)
+	case order.TimeInForce == TimeInForceGTD && order.ExpiresAt.IsZero():
+		return fmt.Errorf("%w: GTD order needs an expiry", ErrInvalidOrder)
+	case order.TimeInForce != TimeInForceGTD && !order.ExpiresAt.IsZero():
+		return fmt.Errorf("%w: expiry is only valid for GTD orders", ErrInvalidOrder)
+	case order.TimeInForce == TimeInForceGTD && !order.ExpiresAt.Truncate(time.Second).After(b.clock()):
+		return fmt.Errorf("%w: expiry %s has already passed", ErrInvalidOrder, order.ExpiresAt.UTC().Format(time.RFC3339))
+	}
+	if _, exists := b.orders[order.OrderID]; exists || b.pausedIndex(order.OrderID) >= 0 {
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, order.OrderID)
+	}
+	return nil
+}
+
+// finite reports whether every value is a number other than an infinity.
+// NaN fails every comparison, so sign checks alone would let it through.
+func finite(values ...float64) bool {
+	for _, v := range values {
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return false
+		}
+	}
+	return true
+}
+
+// postOnlyLocked rejects a post-only order that would match on entry.
+// Nothing matches on entry during an auction, so it always passes there.
+func (b *OrderBook) postOnlyLocked(order TradingOrder) error {
+	if !order.PostOnly || b.auction {
+		return nil
+	}
+	opposite := b.asks
+	if order.Side == SideSell {
+		opposite = b.bids
+	}
+	if len(opposite) > 0 && crosses(&order, opposite[0].price) {
+		return fmt.Errorf("%w: %s %s at %g against %g", ErrWouldTake, order.OrderID, order.Side, order.Price, opposite[0].price)
+	}
+	return nil
+}
+
+// addLocked matches the order and rests any limit remainder. During an
+// auction nothing matches until Uncross. An order with a MinQty only
+// matches when at least that much crosses immediately; otherwise a limit
+// order rests untouched and a market order is discarded. MinQty applies on
+// entry only, so a resting remainder can be filled in any size.
+// Under LotResidualCancel the sub-lot part of the remainder is dropped
+// before resting. An IOC order never rests: its unfilled remainder is
+// recorded as a cancel event carrying the unfilled volume, as is the
+// remainder of a collared market order under CollarRemainderCancel.
+func (b *OrderBook) addLocked(order TradingOrder) []Trade {
+	if order.Type == OrderTypeMarketOnClose {
+		b.arrivals++
+		ro := &restingOrder{TradingOrder: order, arrival: b.arrivals, restedAt: b.opTime}
+		b.orders[order.OrderID] = ro
+		b.moc = append(b.moc, ro)
+		b.changedLocked()
+		return nil
+	}
+	collared := b.collarLocked(&order)
+	var trades []Trade
+	if !b.auction && b.marketableVolume(&order, order.MinQty) >= order.MinQty-volumeEpsilon {
+		trades = b.matchLocked(&order)
+	}
+	if order.TimeInForce == TimeInForceIOC || (collared && b.collarRemainder != CollarRemainderRest) {
+		if order.Volume > volumeEpsilon {
+			b.record(BookEvent{Type: BookEventCancel, OrderID: order.OrderID, Volume: order.Volume})
+			b.metrics.OrdersCanceled(b.commodity, 1)
+		}
+		b.changedLocked()
+		return trades
+	}
+	if b.lotSize > 0 && b.lotResidual == LotResidualCancel {
+		order.Volume = b.wholeLots(order.Volume)
+	}
+	if order.Volume > volumeEpsilon && order.Type != OrderTypeMarket {
+		b.restLocked(order)
+	}
+	b.changedLocked()
+	return trades
+}
+
+// collarLocked turns a market order into a limit order at its collar when
+// the book has a collar and an opposite side to measure it from
+func (b *OrderBook) collarLocked(order *TradingOrder) bool {
+	if order.Type != OrderTypeMarket || b.collarWidth <= 0 || b.auction {
+		return false
+	}
+	if order.Side == SideBuy && len(b.asks) > 0 {
+		order.Type, order.Price = OrderTypeLimit, b.asks[0].price+b.collarWidth
+		return true
+	}
+	if order.Side == SideSell && len(b.bids) > 0 {
+		order.Type, order.Price = OrderTypeLimit, b.bids[0].price-b.collarWidth
+		return true
+	}
+	return false
+}
+
+// matchLocked fills the incoming order against the opposite side, reducing
+// its volume. With a lot size, fills are whole lots and resting orders
+// holding less than a lot are skipped.
+func (b *OrderBook) matchLocked(order *TradingOrder) []Trade {
+	var trades []Trade
+	opposite := &b.asks
+	if order.Side == SideSell {
+		opposite = &b.bids
+	}
+
+	for i := 0; i < len(*opposite) && b.wholeLots(order.Volume) > volumeEpsilon; {
+		level := (*opposite)[i]
+		if !crosses(order, level.price) {
+			break
+		}
+		if b.proRata {
+			trades = append(trades, b.proRataLocked(order, level)...)
+		}
+		var skipped map[*restingOrder]bool
+		for b.wholeLots(order.Volume) > volumeEpsilon {
+			j := b.nextAtLevel(level, skipped)
+			if j < 0 {
+				break
+			}
+			resting := level.orders[j]
+			fill := b.wholeLots(math.Min(order.Volume, resting.Volume))
+			if fill <= volumeEpsilon {
+				if skipped == nil {
+					skipped = make(map[*restingOrder]bool)
+				}
+				skipped[resting] = true
+				continue
+			}
+			trades = append(trades, b.newTrade(order, resting, level.price, fill))
+			order.Volume -= fill
+			resting.Volume -= fill
+			if resting.Volume <= volumeEpsilon {
+				level.orders = append(level.orders[:j], level.orders[j+1:]...)
+				if b.refreshLocked(resting) {
+					level.orders = append(level.orders, resting)
+				} else {
+					delete(b.orders, resting.OrderID)
+				}
+			}
+		}
+		switch {
+		case len(level.orders) > 0:
+			i++
+		case i == 0:
+			*opposite = (*opposite)[1:]
+		default:
+			*opposite = append((*opposite)[:i], (*opposite)[i+1:]...)
+		}
+	}
+	return trades
+}
+
+// wholeLots rounds volume down to a whole number of lots, or returns it
+// unchanged without a lot size
+func (b *OrderBook) wholeLots(volume float64) float64 {
+	if b.lotSize <= 0 {
+		return volume
+	}
+	return math.Floor(volume/b.lotSize+volumeEpsilon) * b.lotSize
+}
+
+// marketableVolume sums opposite volume the order crosses, stopping once
+// limit is reached
+func (b *OrderBook) marketableVolume(order *TradingOrder, limit float64) float64 {
+	if limit <= 0 {
+		return 0
+	}
+	opposite := b.asks
+	if order.Side == SideSell {
+		opposite = b.bids
+	}
+	var volume float64
+	for _, level := range opposite {
+		if !crosses(order, level.price) {
+			break
+		}
+		for _, o := range level.orders {
+			volume += o.Volume
+		}
+		if volume >= limit {
+			break
+		}
+	}
+	return volume
+}
+
+// cloneLocked deep-copies the book state without its event log
+func (b *OrderBook) cloneLocked() *OrderBook {
+	clone := &OrderBook{
+		commodity:       b.commodity,
+		orders:          make(map[string]*restingOrder, len(b.orders)),
+		clock:           b.clock,
+		opTime:          b.clock(),
+		metrics:         NoopMetrics{},
+		seq:             b.seq,
+		tradeSeq:        b.tradeSeq,
+		arrivals:        b.arrivals,
+		amendCross:      b.amendCross,
+		boostAfter:      b.boostAfter,
+		mocRemainder:    b.mocRemainder,
+		pegStep:         b.pegStep,
+		pegRetain:       b.pegRetain,
+		pegBid:          b.pegBid,
+		pegAsk:          b.pegAsk,
+		signedPrices:    b.signedPrices,
+		tickSize:        b.tickSize,
+		tickChange:      b.tickChange,
+		collarWidth:     b.collarWidth,
+		collarRemainder: b.collarRemainder,
+		proRata:         b.proRata,
+		proRataUnit:     b.proRataUnit,
+		refPrice:        b.refPrice,
+		refBand:         b.refBand,
+		onPause:         b.onPause,
+		lotSize:         b.lotSize,
+		lotResidual:     b.lotResidual,
+		jitter:          b.jitter,
+		jitterSeed:      b.jitterSeed,
+		nextExpiry:      b.nextExpiry,
+	}
+	copyLevels := func(levels []*bookLevel) []*bookLevel {
+		copied := make([]*bookLevel, len(levels))
+		for i, level := range levels {
+			orders := make([]*restingOrder, len(level.orders))
+			for j, ro := range level.orders {
+				c := *ro
+				orders[j] = &c
+				clone.orders[c.OrderID] = &c
+			}
+			copied[i] = &bookLevel{price: level.price, orders: orders}
+		}
+		return copied
+	}
+	clone.bids = copyLevels(b.bids)
+	clone.asks = copyLevels(b.asks)
+	for _, ro := range b.moc {
+		c := *ro
+		clone.moc = append(clone.moc, &c)
+		clone.orders[c.OrderID] = &c
+	}
+	return clone
+}
+
+// nextAtLevel returns the index of the order that trades next at a level,
+// ignoring skipped orders, or -1 when every order is skipped
+func (b *OrderBook) nextAtLevel(level *bookLevel, skipped map[*restingOrder]bool) int {
+	first, best := -1, -1
+	now := b.opTime
+	for j, o := range level.orders {
+		if skipped[o] {
+			continue
+		}
+		if first < 0 {
+			first = j
+			if b.boostAfter <= 0 {
+				break
+			}
+		}
+		if now.Sub(o.restedAt) < b.boostAfter {
+			continue
+		}
+		// orders are held in arrival order, so a strict comparison keeps time priority on ties
+		if best < 0 || o.Volume > level.orders[best].Volume+volumeEpsilon {
+			best = j
+		}
+	}
+	if best < 0 {
+		return first
+	}
+	return best
+}
+
+// crosses reports whether an incoming order is marketable against a price
+func crosses(order *TradingOrder, price float64) bool {
+	if order.Type == OrderTypeMarket {
+		return true
+	}
+	if order.Side == SideBuy {
+		return price <= order.Price
+	}
+	return price >= order.Price
+}
+
+// newTrade builds a trade between the aggressor and a resting order
+func (b *OrderBook) newTrade(aggressor *TradingOrder, resting *restingOrder, price, volume float64) Trade {
+	trade := Trade{Price: price, Volume: volume, Aggressor: aggressor.Side}
+	if aggressor.Side == SideBuy {
+		trade.BuyOrderID, trade.SellOrderID = aggressor.OrderID, resting.OrderID
+		trade.BuyClientID, trade.SellClientID = aggressor.ClientID, resting.ClientID
+	} else {
+		trade.BuyOrderID, trade.SellOrderID = resting.OrderID, aggressor.OrderID
+		trade.BuyClientID, trade.SellClientID = resting.ClientID, aggressor.ClientID
+	}
+	return b.recordTrade(trade)
+}
+
+// recordTrade assigns the trade ID and timestamp and records the trade
+func (b *OrderBook) recordTrade(trade Trade) Trade {
+	b.tradeSeq++
+	trade.TradeID = fmt.Sprintf("%s-%d", b.commodity, b.tradeSeq)
+	trade.Commodity = b.commodity
+	trade.Timestamp = b.opTime
+	b.record(BookEvent{Type: BookEventTrade, Trade: &trade})
+	b.metrics.TradeExecuted(b.commodity, trade.Volume)
+	return trade
+}
+
+// changedLocked marks a completed mutation
+func (b *OrderBook) changedLocked() {
+	b.seq++
+	b.metrics.RestingOrders(b.commodity, len(b.orders))
+}
+
+// restLocked places an order at the back of its price level. An iceberg
+// displays its first slice and holds the rest in reserve.
+func (b *OrderBook) restLocked(order TradingOrder) {
+	b.arrivals++
+	ro := &restingOrder{TradingOrder: order, arrival: b.arrivals, restedAt: b.opTime}
+	if order.DisplayVolume > 0 {
+		ro.hidden, ro.Volume = ro.Volume, 0
+		b.sliceLocked(ro)
+	}
+	b.insertLocked(ro)
+}
+
+// refreshLocked reloads a depleted iceberg's display from its reserve with
+// a new arrival, so the refreshed slice queues behind the level. It
+// reports false when there is no reserve left.
+func (b *OrderBook) refreshLocked(ro *restingOrder) bool {
+	if ro.hidden <= volumeEpsilon {
+		return false
+	}
+	b.sliceLocked(ro)
+	b.arrivals++
+	ro.arrival, ro.restedAt = b.arrivals, b.opTime
+	return true
+}
+
+// sliceLocked moves the next display slice from the reserve
+func (b *OrderBook) sliceLocked(ro *restingOrder) {
+	size := ro.DisplayVolume
+	if b.jitter > 0 {
+		size *= 1 + b.jitter*(2*icebergDraw(b.jitterSeed, ro.OrderID, ro.slices)-1)
+	}
+	if b.lotSize > 0 {
+		size = math.Max(b.lotSize, math.Round(size/b.lotSize)*b.lotSize)
+	}
+	if size > ro.hidden-volumeEpsilon {
+		size = ro.hidden
+	}
+	ro.Volume += size
+	ro.hidden -= size
+	ro.slices++
+}
+
+// icebergDraw returns a uniform value in [0, 1) determined by the seed,
+// order and slice number, mixed with splitmix64
+func icebergDraw(seed int64, orderID string, slice int) float64 {
+	h := fnv.New64a()
+	h.Write([]byte(orderID))
+	x := uint64(seed) ^ h.Sum64() + uint64(slice+1)*0x9e3779b97f4a7c15
+	x = (x ^ (x >> 30)) * 0xbf58476d1ce4e5b9
+	x = (x ^ (x >> 27)) * 0x94d049bb133111eb
+	x ^= x >> 31
+	return float64(x>>11) / (1 << 53)
+}
+
+// insertLocked places a resting order within its level by arrival, which
+// is the back of the queue unless the order kept an earlier arrival
+func (b *OrderBook) insertLocked(ro *restingOrder) {
+	b.orders[ro.OrderID] = ro
+	b.trackExpiryLocked(ro)
+
+	levels := b.sideLevels(ro.Side)
+	i := b.levelIndex(ro.Side, ro.Price)
+	if i < len(*levels) && (*levels)[i].price == ro.Price {
+		level := (*levels)[i]
+		j := sort.Search(len(level.orders), func(k int) bool { return level.orders[k].arrival > ro.arrival })
+		level.orders = append(level.orders, nil)
+		copy(level.orders[j+1:], level.orders[j:])
+		level.orders[j] = ro
+		return
+	}
+	level := &bookLevel{price: ro.Price, orders: []*restingOrder{ro}}
+	*levels = append(*levels, nil)
+	copy((*levels)[i+1:], (*levels)[i:])
+	(*levels)[i] = level
+}
+
+// trackExpiryLocked keeps nextExpiry at or before the earliest GTD expiry.
+// Removals leave it early, which only costs ExpireGTD a wasted scan.
+func (b *OrderBook) trackExpiryLocked(ro *restingOrder) {
+	if ro.TimeInForce == TimeInForceGTD && (b.nextExpiry.IsZero() || ro.ExpiresAt.Before(b.nextExpiry)) {
+		b.nextExpiry = ro.ExpiresAt
+	}
+}
+
+// removeLocked takes a resting order off the book
+func (b *OrderBook) removeLocked(ro *restingOrder) {
+	delete(b.orders, ro.OrderID)
+	if ro.Type == OrderTypeMarketOnClose {
+		for j, o := range b.moc {
+			if o == ro {
+				b.moc = append(b.moc[:j], b.moc[j+1:]...)
+				break
+			}
+		}
+		return
+	}
+
+	levels := b.sideLevels(ro.Side)
+	i := b.levelIndex(ro.Side, ro.Price)
+	if i >= len(*levels) || (*levels)[i].price != ro.Price {
+		return
+	}
+	level := (*levels)[i]
+	for j, o := range level.orders {
+		if o == ro {
+			level.orders = append(level.orders[:j], level.orders[j+1:]...)
+			break
+		}
+	}
+	if len(level.orders) == 0 {
+		*levels = append((*levels)[:i], (*levels)[i+1:]...)
+	}
+}
+
+// removeWhereLocked removes all resting orders matching pred in arrival
+// order and returns them with their full volume, hidden reserve included.
+// Orders paused for review that match are cancelled too, after the resting
+// ones and in the order they paused, so a sweep leaves nothing behind that
+// a reviewer could later release.
+func (b *OrderBook) removeWhereLocked(pred func(*restingOrder) bool) []TradingOrder {
+	var matched []*restingOrder
+	for _, ro := range b.orders {
+		if pred(ro) {
+			matched = append(matched, ro)
+		}
+	}
+	sort.Slice(matched, func(i, j int) bool { return matched[i].arrival < matched[j].arrival })
+
+	var removed []TradingOrder
+	for _, ro := range matched {
+		b.record(BookEvent{Type: BookEventCancel, OrderID: ro.OrderID})
+		delete(b.orders, ro.OrderID)
+		order := ro.TradingOrder
+		order.Volume += ro.hidden
+		removed = append(removed, order)
+	}
+	kept := b.paused[:0]
+	for _, p := range b.paused {
+		if !pred(&restingOrder{TradingOrder: p.Order}) {
+			kept = append(kept, p)
+			continue
+		}
+		b.record(BookEvent{Type: BookEventCancel, OrderID: p.Order.OrderID})
+		removed = append(removed, p.Order)
+	}
+	b.paused = kept
+	if len(removed) == 0 {
+		return nil
+	}
+	// One sweep of each side keeps bulk removal linear in book size
+	b.bids = b.sweepLevels(b.bids)
+	b.asks = b.sweepLevels(b.asks)
+	b.moc = b.sweepOrders(b.moc)
+	b.metrics.OrdersCanceled(b.commodity, len(removed))
+	b.changedLocked()
+	return removed
+}
+
+// sweepLevels drops orders no longer in the order index and any emptied levels
+func (b *OrderBook) sweepLevels(levels []*bookLevel) []*bookLevel {
+	kept := levels[:0]
+	for _, level := range levels {
+		level.orders = b.sweepOrders(level.orders)
+		if len(level.orders) > 0 {
+			kept = append(kept, level)
+		}
+	}
+	return kept
+}
+
+// sweepOrders drops orders no longer in the order index
+func (b *OrderBook) sweepOrders(orders []*restingOrder) []*restingOrder {
+	kept := orders[:0]
+	for _, o := range orders {
+		if b.orders[o.OrderID] == o {
+			kept = append(kept, o)
+		}
+	}
+	return kept
+}
+
+// sideLevels returns the level slice for a side
+func (b *OrderBook) sideLevels(side string) *[]*bookLevel {
+	if side == SideBuy {
+		return &b.bids
+	}
+	return &b.asks
+}
+
+// levelIndex returns the position of a price within a side, or where it would be inserted
+func (b *OrderBook) levelIndex(side string, price float64) int {
+	levels := *b.sideLevels(side)
+	if side == SideBuy {
+		return sort.Search(len(levels), func(i int) bool { return levels[i].price <= price })
+	}
+	return sort.Search(len(levels), func(i int) bool { return levels[i].price >= price })
+}
+
+// bestOf returns the top level of a side
+func bestOf(levels []*bookLevel) (price, volume float64, ok bool) {
+	if len(levels) == 0 {
+		return 0, 0, false
+	}
+	for _, o := range levels[0].orders {
+		volume += o.Volume
+	}
+	return levels[0].price, volume, true
+}
+
+// aggregateLevels sums resting volume per price level
+func aggregateLevels(levels []*bookLevel) []PriceLevel {
+	out := make([]PriceLevel, 0, len(levels))
+	for _, level := range levels {
+		pl := PriceLevel{Price: level.price, Orders: len(level.orders)}
+		for _, o := range level.orders {
+			pl.Volume += o.Volume
+		}
+		out = append(out, pl)
+	}
+	return out
+}
@@ -0,0 +1,158 @@
+package integration
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// benchSizes draws order volumes from a named distribution
+var benchSizes = map[string]func(r *rand.Rand) float64{
+	// uniform lots of 1-100
+	"uniform": func(r *rand.Rand) float64 { return float64(1 + r.Intn(100)) },
+	// mostly small orders with an occasional block, like real flow
+	"skewed": func(r *rand.Rand) float64 {
+		if r.Intn(20) == 0 {
+			return float64(500 + r.Intn(500))
+		}
+		return float64(1 + r.Intn(10))
+	},
+}
+
+var benchDepths = []int{10, 100, 1000}
+
+const benchTick = 0.01
+const benchMid = 75.0
+
+// benchOrder builds a passive order priced within depth ticks of the mid
+func benchOrder(r *rand.Rand, id int, depth int, size func(*rand.Rand) float64) TradingOrder {
+	offset := float64(1+r.Intn(depth)) * benchTick
+	order := TradingOrder{OrderID: strconv.Itoa(id), Commodity: "crude_oil", Type: OrderTypeLimit, Volume: size(r)}
+	if r.Intn(2) == 0 {
+		order.Side, order.Price = SideBuy, benchMid-offset
+	} else {
+		order.Side, order.Price = SideSell, benchMid+offset
+	}
+	return order
+}
+
+// seedBook rests two orders per level on each side
+func seedBook(r *rand.Rand, book *OrderBook, depth int, size func(*rand.Rand) float64) int {
+	id := 0
+	for level := 1; level <= depth; level++ {
+		for i := 0; i < 2; i++ {
+			for _, side := range []string{SideBuy, SideSell} {
+				id++
+				price := benchMid - float64(level)*benchTick
+				if side == SideSell {
+					price = benchMid + float64(level)*benchTick
+				}
+				book.Add(TradingOrder{OrderID: "seed-" + strconv.Itoa(id), Side: side, Price: price, Volume: size(r)})
+			}
+		}
+	}
+	return id
+}
+
+// runBookBenchmark runs workload across every depth and size distribution,
+// reporting ops/s alongside the standard ns/op and allocation figures
+func runBookBenchmark(b *testing.B, workload func(b *testing.B, r *rand.Rand, book *OrderBook, depth int, size func(*rand.Rand) float64)) {
+	for _, depth := range benchDepths {
+		for _, dist := range []string{"uniform", "skewed"} {
+			b.Run(fmt.Sprintf("depth=%d/sizes=%s", depth, dist), func(b *testing.B) {
+				r := rand.New(rand.NewSource(1))
+				clock := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+				book := NewOrderBook("crude_oil", WithClock(func() time.Time { return clock }))
+				size := benchSizes[dist]
+				seedBook(r, book, depth, size)
+
+				b.ReportAllocs()
+				start := time.Now()
+				b.ResetTimer()
+				workload(b, r, book, depth, size)
+				b.StopTimer()
+				if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+					b.ReportMetric(float64(b.N)/elapsed, "ops/s")
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkOrderBookAddOnly rests passive orders that never cross
+func BenchmarkOrderBookAddOnly(b *testing.B) {
+	runBookBenchmark(b, func(b *testing.B, r *rand.Rand, book *OrderBook, depth int, size func(*rand.Rand) float64) {
+		for i := 0; i < b.N; i++ {
+			book.Add(benchOrder(r, i, depth, size))
+		}
+	})
+}
+
+// BenchmarkOrderBookHeavyCancel adds orders and cancels nine in ten of them
+func BenchmarkOrderBookHeavyCancel(b *testing.B) {
+	runBookBenchmark(b, func(b *testing.B, r *rand.Rand, book *OrderBook, depth int, size func(*rand.Rand) float64) {
+		live := make([]string, 0, 1024)
+		for i := 0; i < b.N; i++ {
+			if len(live) > 0 && r.Intn(10) < 9 {
+				j := r.Intn(len(live))
+				book.Cancel(live[j])
+				live[j] = live[len(live)-1]
+				live = live[:len(live)-1]
+				continue
+			}
+			order := benchOrder(r, i, depth, size)
+			book.Add(order)
+			live = append(live, order.OrderID)
+		}
+	})
+}
+
+// BenchmarkOrderBookMatchHeavy sends aggressive orders and replenishes the
+// liquidity they take so the book keeps its depth
+func BenchmarkOrderBookMatchHeavy(b *testing.B) {
+	runBookBenchmark(b, func(b *testing.B, r *rand.Rand, book *OrderBook, depth int, size func(*rand.Rand) float64) {
+		for i := 0; i < b.N; i++ {
+			aggressor := benchOrder(r, i, depth, size)
+			passive := aggressor
+			passive.OrderID += "-r"
+			if aggressor.Side == SideBuy {
+				aggressor.Price = benchMid + float64(depth)*benchTick
+				passive.Side, passive.Price = SideSell, benchMid+benchTick
+			} else {
+				aggressor.Price = benchMid - float64(depth)*benchTick
+				passive.Side, passive.Price = SideBuy, benchMid-benchTick
+			}
+			trades, _ := book.Add(aggressor)
+			if len(trades) > 0 {
+				passive.Volume = aggressor.Volume
+				book.Add(passive)
+			}
+		}
+	})
+}
+
+// BenchmarkOrderBookMixed approximates a session: 60% adds, 30% cancels, 10% aggressive
+func BenchmarkOrderBookMixed(b *testing.B) {
+	runBookBenchmark(b, func(b *testing.B, r *rand.Rand, book *OrderBook, depth int, size func(*rand.Rand) float64) {
+		live := make([]string, 0, 1024)
+		for i := 0; i < b.N; i++ {
+			switch n := r.Intn(10); {
+			case n < 3 && len(live) > 0:
+				j := r.Intn(len(live))
+				book.Cancel(live[j])
+				live[j] = live[len(live)-1]
+				live = live[:len(live)-1]
+			case n == 9:
+				order := benchOrder(r, i, depth, size)
+				order.Type = OrderTypeMarket
+				book.Add(order)
+			default:
+				order := benchOrder(r, i, depth, size)
+				book.Add(order)
+				live = append(live, order.OrderID)
+			}
+		}
+	})
+}
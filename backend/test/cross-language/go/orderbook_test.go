@@ -0,0 +1,644 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func amendCrossBook(t *testing.T, opts ...BookOption) *OrderBook {
+	book := NewOrderBook("crude_oil", opts...)
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", Side: SideSell, Price: 75.60, Volume: 50},
+		{OrderID: "bid1", Side: SideBuy, Price: 75.40, Volume: 50},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+	return book
+}
+
+// TestNonFiniteOrdersRejected verifies NaN and infinite quantities and
+// prices never reach the book, including on signed-price books
+func TestNonFiniteOrdersRejected(t *testing.T) {
+	nan, inf := math.NaN(), math.Inf(1)
+	testCases := []struct {
+		name  string
+		order TradingOrder
+	}{
+		{"nan volume", TradingOrder{Volume: nan, Price: 75}},
+		{"nan price", TradingOrder{Volume: 10, Price: nan}},
+		{"infinite price", TradingOrder{Volume: 10, Price: inf}},
+		{"negative infinite price", TradingOrder{Volume: 10, Price: -inf}},
+		{"nan min qty", TradingOrder{Volume: 10, Price: 75, MinQty: nan}},
+		{"infinite display", TradingOrder{Volume: 10, Price: 75, DisplayVolume: inf}},
+		{"nan peg offset", TradingOrder{Volume: 10, Type: OrderTypePegged, PegReference: PegBid, PegOffset: nan}},
+	}
+	book := amendCrossBook(t, WithSignedPrices())
+	for _, tc := range testCases {
+		tc.order.OrderID, tc.order.Side = "bad", SideBuy
+		if _, err := book.Add(tc.order); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("%s: expected ErrInvalidOrder, got %v", tc.name, err)
+		}
+	}
+	if _, err := book.Amend("bid1", nan, 10); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected a NaN amendment rejected, got %v", err)
+	}
+	if err := book.ReduceQuantity("bid1", nan); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected a NaN reduction rejected, got %v", err)
+	}
+	if snap := book.Snapshot(); len(snap.Bids) != 1 || snap.Bids[0].Price != 75.40 || snap.Bids[0].Volume != 50 {
+		t.Errorf("Expected only bid1 left untouched, got %+v", snap.Bids)
+	}
+}
+
+// TestAmendCrossRejected verifies a crossing amendment is refused and the order keeps its terms
+func TestAmendCrossRejected(t *testing.T) {
+	book := amendCrossBook(t, WithAmendCross(AmendCrossReject))
+
+	trades, err := book.Amend("bid1", 75.65, 50)
+	if !errors.Is(err, ErrWouldCross) || len(trades) != 0 {
+		t.Fatalf("Expected ErrWouldCross without trades, got %v, %+v", err, trades)
+	}
+	if order, _ := book.Order("bid1"); order.Price != 75.40 {
+		t.Errorf("Expected rejected amendment to leave price at 75.40, got %g", order.Price)
+	}
+
+	if _, err := book.Amend("bid1", 75.55, 50); err != nil {
+		t.Errorf("Expected a non-crossing amendment to succeed, got %v", err)
+	}
+}
+
+// TestAmendCrossAllowedTrades verifies the default policy lets a crossing amendment trade
+func TestAmendCrossAllowedTrades(t *testing.T) {
+	book := amendCrossBook(t)
+
+	trades, err := book.Amend("bid1", 75.65, 50)
+	if err != nil {
+		t.Fatalf("Expected amendment to be accepted, got %v", err)
+	}
+	if len(trades) != 1 || trades[0].Price != 75.60 || trades[0].SellOrderID != "ask1" {
+		t.Errorf("Expected a trade at 75.60 against ask1, got %+v", trades)
+	}
+}
+
+// TestPriorityBoostAllocation compares allocation at one level with and without the boost
+func TestPriorityBoostAllocation(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	allocate := func(opts ...BookOption) map[string]float64 {
+		now := start
+		book := NewOrderBook("crude_oil", append([]BookOption{WithClock(func() time.Time { return now })}, opts...)...)
+		for _, o := range []struct {
+			id     string
+			volume float64
+			at     time.Duration
+		}{
+			{"small-old", 10, 0},
+			{"large-old", 50, time.Second},
+			{"large-new", 80, 90 * time.Second},
+		} {
+			now = start.Add(o.at)
+			book.Add(TradingOrder{OrderID: o.id, Side: SideBuy, Price: 75.40, Volume: o.volume})
+		}
+
+		now = start.Add(2 * time.Minute)
+		trades, err := book.Add(TradingOrder{OrderID: "sell", Side: SideSell, Price: 75.40, Volume: 40})
+		if err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+		filled := map[string]float64{}
+		for _, tr := range trades {
+			filled[tr.BuyOrderID] += tr.Volume
+		}
+		return filled
+	}
+
+	plain := allocate()
+	if plain["small-old"] != 10 || plain["large-old"] != 30 || plain["large-new"] != 0 {
+		t.Errorf("Expected pure time priority 10/30/0, got %v", plain)
+	}
+
+	// large-new has rested 30s, so only the two older orders are seasoned
+	boosted := allocate(WithPriorityBoost(time.Minute))
+	if boosted["large-old"] != 40 || boosted["small-old"] != 0 || boosted["large-new"] != 0 {
+		t.Errorf("Expected the larger seasoned order to trade first, got %v", boosted)
+	}
+
+	allSeasoned := allocate(WithPriorityBoost(30 * time.Second))
+	if allSeasoned["large-new"] != 40 || allSeasoned["large-old"] != 0 {
+		t.Errorf("Expected the largest seasoned order to take the full fill, got %v", allSeasoned)
+	}
+}
+
+func minQtyBook(t *testing.T) *OrderBook {
+	book := NewOrderBook("crude_oil")
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", Side: SideSell, Price: 75.60, Volume: 30},
+		{OrderID: "ask2", Side: SideSell, Price: 75.70, Volume: 30},
+		{OrderID: "ask3", Side: SideSell, Price: 75.90, Volume: 100},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+	return book
+}
+
+// TestMinQtyJustEnough verifies an order fills when exactly MinQty crosses
+func TestMinQtyJustEnough(t *testing.T) {
+	book := minQtyBook(t)
+
+	trades, err := book.Add(TradingOrder{OrderID: "buy1", Side: SideBuy, Price: 75.70, Volume: 80, MinQty: 60})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	var filled float64
+	for _, trade := range trades {
+		filled += trade.Volume
+	}
+	if filled != 60 {
+		t.Fatalf("Expected 60 filled across two levels, got %g in %+v", filled, trades)
+	}
+	if order, ok := book.Order("buy1"); !ok || order.Volume != 20 {
+		t.Errorf("Expected 20 to rest, got %+v (resting %v)", order, ok)
+	}
+}
+
+// TestMinQtyNotEnough verifies an order below MinQty never partially fills
+func TestMinQtyNotEnough(t *testing.T) {
+	book := minQtyBook(t)
+
+	trades, err := book.Add(TradingOrder{OrderID: "buy1", Side: SideBuy, Price: 75.70, Volume: 80, MinQty: 61})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(trades) != 0 {
+		t.Fatalf("Expected no partial fill below MinQty, got %+v", trades)
+	}
+	if order, ok := book.Order("buy1"); !ok || order.Volume != 80 {
+		t.Errorf("Expected the limit order to rest whole, got %+v (resting %v)", order, ok)
+	}
+	if _, askVolume, _ := book.BestAsk(); askVolume != 30 {
+		t.Errorf("Expected opposing liquidity untouched, got %g at best ask", askVolume)
+	}
+
+	trades, err = book.Add(TradingOrder{OrderID: "mkt1", Side: SideSell, Type: OrderTypeMarket, Volume: 100, MinQty: 90})
+	if err != nil || len(trades) != 0 {
+		t.Fatalf("Expected the market order to cancel without trading, got %v, %+v", err, trades)
+	}
+	if _, ok := book.Order("mkt1"); ok {
+		t.Error("Expected the market order not to rest")
+	}
+
+	if _, err := book.Add(TradingOrder{OrderID: "bad", Side: SideBuy, Price: 75, Volume: 10, MinQty: 11}); !errors.Is(err, ErrInvalidOrder) {
+		t.Errorf("Expected MinQty above volume to be invalid, got %v", err)
+	}
+}
+
+// TestCancelAllForClient verifies one client's orders are cancelled with events while another's rest untouched
+func TestCancelAllForClient(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log))
+	const perClient = 2000
+	for i := 0; i < perClient; i++ {
+		for _, client := range []string{"acme", "gulf"} {
+			side, price := SideBuy, 70+float64(i%50)*0.01
+			if i%2 == 1 {
+				side, price = SideSell, 80+float64(i%50)*0.01
+			}
+			o := TradingOrder{OrderID: fmt.Sprintf("%s-%d", client, i), ClientID: client, Side: side, Price: price, Volume: 1}
+			if _, err := book.Add(o); err != nil {
+				t.Fatalf("Failed to seed book: %v", err)
+			}
+		}
+	}
+	before := len(log.Events())
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		// Concurrent aggression against the other side of the book
+		for i := 0; i < 100; i++ {
+			book.Add(TradingOrder{OrderID: fmt.Sprintf("mkt-%d", i), ClientID: "delta", Side: SideSell, Type: OrderTypeMarket, Volume: 1})
+		}
+	}()
+	cancelled := book.CancelAllForClient("acme")
+	wg.Wait()
+
+	if cancelled < perClient-100 || cancelled > perClient {
+		t.Fatalf("Expected about %d acme orders cancelled, got %d", perClient, cancelled)
+	}
+	cancels := 0
+	for _, ev := range log.Events()[before:] {
+		if ev.Type == BookEventCancel {
+			if !strings.HasPrefix(ev.OrderID, "acme-") {
+				t.Fatalf("Unexpected cancel event for %s", ev.OrderID)
+			}
+			cancels++
+		}
+	}
+	if cancels != cancelled {
+		t.Errorf("Expected %d cancel events, got %d", cancelled, cancels)
+	}
+	if _, ok := book.Order("acme-1"); ok {
+		t.Error("Expected acme orders to be gone")
+	}
+	snap := book.Snapshot()
+	var resting int
+	for _, level := range append(snap.Bids, snap.Asks...) {
+		resting += level.Orders
+	}
+	if resting != 2*perClient-100-cancelled {
+		t.Errorf("Expected only gulf orders left (%d), got %d", 2*perClient-100-cancelled, resting)
+	}
+	if book.CancelAllForClient("acme") != 0 {
+		t.Error("Expected nothing left to cancel")
+	}
+	if _, err := Rebuild(log); err != nil {
+		t.Errorf("Expected the event log to replay, got %v", err)
+	}
+}
+
+// TestPostOnlyRejectedWhenCrossing verifies a crossing post-only order is rejected without trading
+func TestPostOnlyRejectedWhenCrossing(t *testing.T) {
+	book := amendCrossBook(t)
+
+	trades, err := book.Add(TradingOrder{OrderID: "po1", Side: SideBuy, Price: 75.60, Volume: 10, PostOnly: true})
+	if !errors.Is(err, ErrWouldTake) || len(trades) != 0 {
+		t.Fatalf("Expected ErrWouldTake without trades, got %v, %+v", err, trades)
+	}
+	if _, ok := book.Order("po1"); ok {
+		t.Error("Expected the rejected order not to rest")
+	}
+	if _, askVolume, _ := book.BestAsk(); askVolume != 50 {
+		t.Errorf("Expected the offer untouched, got %g", askVolume)
+	}
+	if _, err := book.Simulate(TradingOrder{OrderID: "po2", Side: SideSell, Price: 75.30, Volume: 10, PostOnly: true}); !errors.Is(err, ErrWouldTake) {
+		t.Errorf("Expected Simulate to apply the post-only check, got %v", err)
+	}
+}
+
+// TestPostOnlyRestsSafely verifies a non-crossing post-only order rests and cannot be amended through the spread
+func TestPostOnlyRestsSafely(t *testing.T) {
+	book := amendCrossBook(t)
+
+	trades, err := book.Add(TradingOrder{OrderID: "po1", Side: SideBuy, Price: 75.55, Volume: 10, PostOnly: true})
+	if err != nil || len(trades) != 0 {
+		t.Fatalf("Expected the post-only bid to rest, got %v, %+v", err, trades)
+	}
+	if price, _, _ := book.BestBid(); price != 75.55 {
+		t.Errorf("Expected 75.55 best bid, got %g", price)
+	}
+	if _, err := book.Amend("po1", 75.60, 10); !errors.Is(err, ErrWouldTake) {
+		t.Errorf("Expected a crossing amendment of a post-only order to be rejected, got %v", err)
+	}
+
+	// It still makes when an aggressor arrives
+	trades, _ = book.Add(TradingOrder{OrderID: "sell", Side: SideSell, Type: OrderTypeMarket, Volume: 5})
+	if len(trades) != 1 || trades[0].BuyOrderID != "po1" || trades[0].Aggressor != SideSell {
+		t.Errorf("Expected po1 to provide liquidity, got %+v", trades)
+	}
+}
+
+// TestLotSizeLeavesSubLotUnmatched verifies fills are whole lots and sub-lot remainders rest or cancel by policy
+func TestLotSizeLeavesSubLotUnmatched(t *testing.T) {
+	for _, residual := range []string{LotResidualRest, LotResidualCancel} {
+		t.Run(residual, func(t *testing.T) {
+			book := NewOrderBook("crude_oil", WithLotSize(10, residual))
+			for _, o := range []TradingOrder{
+				{OrderID: "ask1", Side: SideSell, Price: 75.60, Volume: 25},
+				{OrderID: "ask2", Side: SideSell, Price: 75.60, Volume: 10},
+			} {
+				if _, err := book.Add(o); err != nil {
+					t.Fatalf("Failed to seed book: %v", err)
+				}
+			}
+
+			// Under the cancel policy ask1's sub-lot 5 never reaches the book
+			wantAsk1 := 25.0
+			if residual == LotResidualCancel {
+				wantAsk1 = 20
+			}
+			if ask, _ := book.Order("ask1"); ask.Volume != wantAsk1 {
+				t.Fatalf("Expected ask1 to rest %g, got %g", wantAsk1, ask.Volume)
+			}
+
+			// Filling ask1's 25 in full would need a sub-lot fill of 5
+			trades, err := book.Add(TradingOrder{OrderID: "buy1", Side: SideBuy, Price: 75.60, Volume: 47})
+			if err != nil {
+				t.Fatalf("Add failed: %v", err)
+			}
+			var filled float64
+			for _, trade := range trades {
+				if !isMultiple(trade.Volume, 10) {
+					t.Errorf("Odd-lot fill %+v", trade)
+				}
+				filled += trade.Volume
+			}
+			if filled != 30 || len(trades) != 2 || trades[0].SellOrderID != "ask1" || trades[1].SellOrderID != "ask2" {
+				t.Fatalf("Expected 20 from ask1 then 10 from ask2, got %+v", trades)
+			}
+
+			bid, resting := book.Order("buy1")
+			switch residual {
+			case LotResidualRest:
+				if ask, ok := book.Order("ask1"); !ok || ask.Volume != 5 {
+					t.Errorf("Expected ask1's sub-lot 5 left unmatched, got %+v (resting %v)", ask, ok)
+				}
+				if !resting || bid.Volume != 17 {
+					t.Errorf("Expected the 17 remainder to rest, got %+v (resting %v)", bid, resting)
+				}
+			case LotResidualCancel:
+				if !resting || bid.Volume != 10 {
+					t.Errorf("Expected the whole lot of 10 to rest and the sub-lot 7 cancelled, got %+v (resting %v)", bid, resting)
+				}
+			}
+
+			// A sub-lot remainder is never filled
+			if trades, err := book.Add(TradingOrder{OrderID: "buy2", Side: SideBuy, Price: 75.60, Volume: 10}); err != nil || len(trades) != 0 {
+				t.Errorf("Expected no fill against a sub-lot remainder, got %+v, %v", trades, err)
+			}
+		})
+	}
+}
+
+// icebergSlices takes each displayed slice of the iceberg in turn and returns their sizes
+func icebergSlices(t *testing.T, seed int64) []float64 {
+	t.Helper()
+	book := NewOrderBook("crude_oil", WithLotSize(1, LotResidualRest), WithIcebergJitter(0.25, seed))
+	if _, err := book.Add(TradingOrder{OrderID: "ice", Side: SideSell, Price: 75.60, Volume: 1000, DisplayVolume: 100}); err != nil {
+		t.Fatalf("Failed to add iceberg: %v", err)
+	}
+	var slices []float64
+	for i := 0; ; i++ {
+		_, shown, ok := book.BestAsk()
+		if !ok {
+			return slices
+		}
+		slices = append(slices, shown)
+		trades, err := book.Add(TradingOrder{OrderID: fmt.Sprintf("b%d", i), Side: SideBuy, Price: 75.60, Volume: shown})
+		if err != nil || len(trades) != 1 || trades[0].Volume != shown {
+			t.Fatalf("Expected the slice of %g to fill in one trade, got %+v, %v", shown, trades, err)
+		}
+	}
+}
+
+// TestIcebergJitterSeededSlices verifies seeded slice sizes vary within range and the reserve fills exactly
+func TestIcebergJitterSeededSlices(t *testing.T) {
+	slices := icebergSlices(t, 42)
+	want := []float64{101, 106, 110, 123, 82, 100, 109, 77, 80, 87, 25}
+	if fmt.Sprint(slices) != fmt.Sprint(want) {
+		t.Fatalf("Expected slices %v for seed 42, got %v", want, slices)
+	}
+	total := 0.0
+	for i, size := range slices {
+		if i < len(slices)-1 && (size < 75 || size > 125) {
+			t.Errorf("Slice %d of %g outside the jitter range", i, size)
+		}
+		total += size
+	}
+	if total != 1000 {
+		t.Errorf("Expected the iceberg to fill exactly 1000, got %g", total)
+	}
+	if again := icebergSlices(t, 42); fmt.Sprint(again) != fmt.Sprint(want) {
+		t.Errorf("Expected the same seed to repeat its slices, got %v", again)
+	}
+	if other := icebergSlices(t, 7); fmt.Sprint(other) == fmt.Sprint(want) {
+		t.Errorf("Expected another seed to give different slices, got %v", other)
+	}
+
+	// One sweep takes the whole reserve, refreshing behind the level as it goes
+	book := NewOrderBook("crude_oil", WithIcebergJitter(0.25, 42))
+	book.Add(TradingOrder{OrderID: "ice", Side: SideSell, Price: 75.60, Volume: 1000, DisplayVolume: 100})
+	if order, _ := book.Order("ice"); order.Volume != 1000 {
+		t.Errorf("Expected Order to include the hidden reserve, got %g", order.Volume)
+	}
+	trades, err := book.Add(TradingOrder{OrderID: "sweep", Side: SideBuy, Price: 75.60, Volume: 1500})
+	if err != nil {
+		t.Fatalf("Sweep failed: %v", err)
+	}
+	filled := 0.0
+	for _, trade := range trades {
+		filled += trade.Volume
+	}
+	if math.Abs(filled-1000) > volumeEpsilon {
+		t.Errorf("Expected exactly 1000 filled from the iceberg, got %g", filled)
+	}
+	if order, ok := book.Order("sweep"); !ok || math.Abs(order.Volume-500) > volumeEpsilon {
+		t.Errorf("Expected 500 of the sweep to rest, got %+v", order)
+	}
+}
+
+// TestIcebergBulkCancelReturnsReserve verifies bulk removal reports an iceberg's hidden reserve with its shown slice
+func TestIcebergBulkCancelReturnsReserve(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	book.Add(TradingOrder{OrderID: "ice", ClientID: "gulf", Side: SideSell, Price: 75.60, Volume: 1000, DisplayVolume: 100})
+	book.Add(TradingOrder{OrderID: "ask2", ClientID: "gulf", Side: SideSell, Price: 75.70, Volume: 20})
+	if _, err := book.Add(TradingOrder{OrderID: "buy1", Side: SideBuy, Price: 75.60, Volume: 150}); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+
+	// The refreshed slice rejoined the queue after ask2
+	removed := book.CancelAll()
+	if len(removed) != 2 || removed[1].OrderID != "ice" || math.Abs(removed[1].Volume-850) > volumeEpsilon {
+		t.Fatalf("Expected ice cancelled with 850 left including the reserve, got %+v", removed)
+	}
+	if removed[0].Volume != 20 {
+		t.Errorf("Expected ask2 cancelled with 20, got %+v", removed[0])
+	}
+	if _, _, ok := book.BestAsk(); ok {
+		t.Error("Expected the book empty after CancelAll")
+	}
+}
+
+// TestIOCPartialFillCancelsRemainder verifies an IOC takes the available liquidity and cancels the rest
+func TestIOCPartialFillCancelsRemainder(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log))
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", Side: SideSell, Price: 75.50, Volume: 20},
+		{OrderID: "ask2", Side: SideSell, Price: 75.60, Volume: 15},
+		{OrderID: "ask3", Side: SideSell, Price: 75.80, Volume: 50},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+
+	trades, err := book.Add(TradingOrder{OrderID: "ioc", Side: SideBuy, Price: 75.60, Volume: 50, TimeInForce: TimeInForceIOC})
+	if err != nil {
+		t.Fatalf("Expected IOC to be accepted, got %v", err)
+	}
+	if len(trades) != 2 || trades[0].Volume != 20 || trades[1].Volume != 15 {
+		t.Fatalf("Expected fills of 20 and 15, got %+v", trades)
+	}
+	if _, ok := book.Order("ioc"); ok {
+		t.Error("Expected no IOC residual on the book")
+	}
+	if bid, _, ok := book.BestBid(); ok {
+		t.Errorf("Expected no bid after the IOC, got %g", bid)
+	}
+
+	events := log.Events()
+	var fills []float64
+	var cancel *BookEvent
+	for i, ev := range events {
+		switch {
+		case ev.Type == BookEventTrade && ev.Trade.BuyOrderID == "ioc":
+			fills = append(fills, ev.Trade.Volume)
+		case ev.Type == BookEventCancel && ev.OrderID == "ioc":
+			cancel = &events[i]
+		}
+	}
+	if len(fills) != 2 {
+		t.Errorf("Expected two fill events, got %v", fills)
+	}
+	if cancel == nil || cancel.Volume != 15 {
+		t.Fatalf("Expected a cancel event for the unfilled 15, got %+v", cancel)
+	}
+	if last := events[len(events)-1]; last.Seq != cancel.Seq {
+		t.Errorf("Expected the cancel to follow the fills, last event was %+v", last)
+	}
+
+	if _, err := Rebuild(log); err != nil {
+		t.Errorf("Expected the log to replay, got %v", err)
+	}
+}
+
+// TestReduceQuantityKeepsPriority verifies a reduced order stays ahead of later orders at its price
+func TestReduceQuantityKeepsPriority(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log))
+	for _, o := range []TradingOrder{
+		{OrderID: "first", Side: SideSell, Price: 75.50, Volume: 30},
+		{OrderID: "second", Side: SideSell, Price: 75.50, Volume: 30},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+
+	if err := book.ReduceQuantity("first", 20); err != nil {
+		t.Fatalf("Expected reduction to succeed, got %v", err)
+	}
+	if order, _ := book.Order("first"); order.Volume != 10 {
+		t.Errorf("Expected 10 left after the reduction, got %g", order.Volume)
+	}
+	for _, reduceBy := range []float64{10, 15, 0, -1} {
+		if err := book.ReduceQuantity("first", reduceBy); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("Reduce by %g: expected ErrInvalidOrder, got %v", reduceBy, err)
+		}
+	}
+	if err := book.ReduceQuantity("missing", 1); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("Expected ErrOrderNotFound, got %v", err)
+	}
+
+	trades, err := book.Add(TradingOrder{OrderID: "buy", Side: SideBuy, Price: 75.50, Volume: 15})
+	if err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	if len(trades) != 2 || trades[0].SellOrderID != "first" || trades[0].Volume != 10 || trades[1].SellOrderID != "second" {
+		t.Errorf("Expected the reduced order to fill first, got %+v", trades)
+	}
+	if _, err := Rebuild(log); err != nil {
+		t.Errorf("Expected the log to replay, got %v", err)
+	}
+}
+
+// TestReduceQuantityConcurrentFills verifies reductions and fills never oversell an order
+func TestReduceQuantityConcurrentFills(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	if _, err := book.Add(TradingOrder{OrderID: "ask", Side: SideSell, Price: 75.50, Volume: 100}); err != nil {
+		t.Fatalf("Failed to seed book: %v", err)
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var filled, reduced float64
+	for i := 0; i < 20; i++ {
+		wg.Add(2)
+		go func(i int) {
+			defer wg.Done()
+			trades, _ := book.Add(TradingOrder{OrderID: fmt.Sprintf("buy%d", i), Side: SideBuy, Price: 75.50, Volume: 3, TimeInForce: TimeInForceIOC})
+			mu.Lock()
+			for _, trade := range trades {
+				filled += trade.Volume
+			}
+			mu.Unlock()
+		}(i)
+		go func() {
+			defer wg.Done()
+			if book.ReduceQuantity("ask", 2) == nil {
+				mu.Lock()
+				reduced += 2
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	left := 0.0
+	if order, ok := book.Order("ask"); ok {
+		left = order.Volume
+	}
+	if math.Abs(filled+reduced+left-100) > volumeEpsilon {
+		t.Errorf("Expected fills %g, reductions %g and remainder %g to account for 100", filled, reduced, left)
+	}
+}
+
+// TestMarketCollarStopsWalkingTheBook verifies fills stop at the collar and the remainder follows the policy
+func TestMarketCollarStopsWalkingTheBook(t *testing.T) {
+	for _, policy := range []string{CollarRemainderCancel, CollarRemainderRest} {
+		t.Run(policy, func(t *testing.T) {
+			log := NewMemoryEventLog()
+			book := NewOrderBook("natural_gas", WithMarketCollar(0.10, policy), WithEventLog(log))
+			for _, o := range []TradingOrder{
+				{OrderID: "ask1", Side: SideSell, Price: 3.00, Volume: 100},
+				{OrderID: "ask2", Side: SideSell, Price: 3.10, Volume: 100},
+				{OrderID: "ask3", Side: SideSell, Price: 3.50, Volume: 500}, // beyond the collar
+			} {
+				if _, err := book.Add(o); err != nil {
+					t.Fatalf("Failed to seed book: %v", err)
+				}
+			}
+
+			trades, err := book.Add(TradingOrder{OrderID: "mkt", Side: SideBuy, Type: OrderTypeMarket, Volume: 300})
+			if err != nil {
+				t.Fatalf("Market order failed: %v", err)
+			}
+			if len(trades) != 2 || trades[1].Price != 3.10 {
+				t.Fatalf("Expected fills at 3.00 and 3.10 only, got %+v", trades)
+			}
+
+			order, resting := book.Order("mkt")
+			switch policy {
+			case CollarRemainderRest:
+				if !resting || order.Type != OrderTypeLimit || math.Abs(order.Price-3.10) > 1e-9 || order.Volume != 100 {
+					t.Errorf("Expected 100 resting as a limit at the 3.10 collar, got %+v (resting %v)", order, resting)
+				}
+			default:
+				if resting {
+					t.Errorf("Expected the remainder cancelled, got %+v", order)
+				}
+				last := log.Events()[len(log.Events())-1]
+				if last.Type != BookEventCancel || last.OrderID != "mkt" || last.Volume != 100 {
+					t.Errorf("Expected a cancel event for the unfilled 100, got %+v", last)
+				}
+			}
+			if order, _ := book.Order("ask3"); order.Volume != 500 {
+				t.Errorf("Expected liquidity beyond the collar untouched, got %g", order.Volume)
+			}
+			// The replica shares the collar, so replay reproduces the same fills
+			if snap, err := book.SnapshotAt(time.Now()); err != nil || !reflect.DeepEqual(snap.Asks, book.Snapshot().Asks) {
+				t.Errorf("Expected the log to replay to the same book, got %v", err)
+			}
+		})
+	}
+}
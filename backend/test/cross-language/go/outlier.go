@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"log"
+	"math"
+	"sync"
+)
+
+// OutlierConfig configures outlier detection for one commodity
+type OutlierConfig struct {
+	// Window is the number of accepted ticks in the rolling statistics
+	Window int
+	// MaxStdDevs is how far from the rolling mean a tick may be
+	MaxStdDevs float64
+	// MinSamples ticks are accepted unchecked while the window warms up
+	MinSamples int
+	// MinDeviation floors the allowed distance so a perfectly flat series
+	// does not reject the first small move
+	MinDeviation float64
+	// ReseedAfter consecutive rejected ticks that agree with each other are
+	// taken as a genuine level shift and replace the statistics; default 5
+	ReseedAfter int
+}
+
+type outlierStats struct {
+	prices   []float64
+	next     int
+	rejected int
+	run      []float64 // consecutive rejected prices that agree
+}
+
+// OutlierFilter rejects bad prints that deviate too far from a commodity's
+// rolling mean. Only accepted ticks update the statistics, so a burst of bad
+// prints cannot drag the mean toward itself. A market that really moves
+// keeps printing at its new level, so once ReseedAfter rejected ticks in a
+// row lie within the allowed distance of each other the filter re-seeds:
+// the window is moved to the run's level, keeping its spread, the run's
+// prices join it and the tick that completed the run is accepted.
+type OutlierFilter struct {
+	mu        sync.Mutex
+	defaults  OutlierConfig
+	overrides map[string]OutlierConfig
+	stats     map[string]*outlierStats
+	logger    *log.Logger
+}
+
+// NewOutlierFilter creates a filter; logger may be nil to disable logging of rejections
+func NewOutlierFilter(defaults OutlierConfig, logger *log.Logger) *OutlierFilter {
+	return &OutlierFilter{
+		defaults:  defaults,
+		overrides: make(map[string]OutlierConfig),
+		stats:     make(map[string]*outlierStats),
+		logger:    logger,
+	}
+}
+
+// Configure overrides the configuration for one commodity
+func (f *OutlierFilter) Configure(commodity string, config OutlierConfig) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.overrides[commodity] = config
+	delete(f.stats, commodity)
+}
+
+// Accept reports whether the tick passes the filter
+func (f *OutlierFilter) Accept(tick MarketData) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	config, ok := f.overrides[tick.Commodity]
+	if !ok {
+		config = f.defaults
+	}
+	stats, ok := f.stats[tick.Commodity]
+	if !ok {
+		stats = &outlierStats{prices: make([]float64, 0, config.Window)}
+		f.stats[tick.Commodity] = stats
+	}
+
+	if len(stats.prices) >= config.MinSamples && len(stats.prices) > 0 {
+		mean, stddev := meanStdDev(stats.prices)
+		allowed := math.Max(config.MaxStdDevs*stddev, config.MinDeviation)
+		if math.Abs(tick.Price-mean) > allowed {
+			if level, ok := stats.shifted(tick.Price, mean, allowed, config); ok {
+				if f.logger != nil {
+					f.logger.Printf("outlier filter: re-seeded %s at %.4f after consistent rejections, was mean %.4f",
+						tick.Commodity, level, mean)
+				}
+				return true
+			}
+			stats.rejected++
+			if f.logger != nil {
+				f.logger.Printf("outlier filter: rejected %s %.4f from %s, mean %.4f allowed +/-%.4f",
+					tick.Commodity, tick.Price, tick.Exchange, mean, allowed)
+			}
+			return false
+		}
+	}
+	stats.run = stats.run[:0]
+
+	if len(stats.prices) < config.Window {
+		stats.prices = append(stats.prices, tick.Price)
+	} else if config.Window > 0 {
+		stats.prices[stats.next] = tick.Price
+		stats.next = (stats.next + 1) % config.Window
+	}
+	return true
+}
+
+// Rejected returns how many ticks have been rejected for a commodity
+func (f *OutlierFilter) Rejected(commodity string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if stats, ok := f.stats[commodity]; ok {
+		return stats.rejected
+	}
+	return 0
+}
+
+// shifted adds a rejected price to the run of agreeing rejections, starting
+// a new run if it disagrees. Once the run is long enough it moves the window
+// from mean to the run's level, adds the run to it and returns the level.
+func (s *outlierStats) shifted(price, mean, allowed float64, config OutlierConfig) (float64, bool) {
+	reseedAfter := config.ReseedAfter
+	if reseedAfter <= 0 {
+		reseedAfter = 5
+	}
+	s.run = append(s.run, price)
+	level, _ := meanStdDev(s.run)
+	for _, p := range s.run {
+		if math.Abs(p-level) > allowed {
+			s.run = append(s.run[:0], price)
+			level = price
+			break
+		}
+	}
+	if len(s.run) < reseedAfter {
+		return 0, false
+	}
+	for i := range s.prices {
+		s.prices[i] += level - mean
+	}
+	for _, p := range s.run {
+		if len(s.prices) < config.Window {
+			s.prices = append(s.prices, p)
+		} else {
+			s.prices[s.next] = p
+			s.next = (s.next + 1) % config.Window
+		}
+	}
+	s.run = s.run[:0]
+	return level, true
+}
+
+func meanStdDev(values []float64) (mean, stddev float64) {
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	for _, v := range values {
+		stddev += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(stddev / float64(len(values)))
+}
@@ -0,0 +1,100 @@
+package integration
+
+import (
+	"bytes"
+	"log"
+	"strings"
+	"testing"
+)
+
+// TestOutlierFilterRejectsSpike verifies a bad print is filtered without poisoning the statistics
+func TestOutlierFilterRejectsSpike(t *testing.T) {
+	var logged bytes.Buffer
+	filter := NewOutlierFilter(OutlierConfig{Window: 20, MaxStdDevs: 4, MinSamples: 10, MinDeviation: 0.05}, log.New(&logged, "", 0))
+
+	var accepted []float64
+	for _, tick := range recordedTicks(30, 0) {
+		if filter.Accept(tick) {
+			accepted = append(accepted, tick.Price)
+		}
+		if len(accepted) == 15 {
+			for i := 0; i < 3; i++ {
+				spike := tick
+				spike.Price = 7.55 // a gas-sized print on the crude feed
+				if filter.Accept(spike) {
+					t.Fatalf("Expected spike %d to be rejected", i)
+				}
+			}
+		}
+	}
+
+	if len(accepted) != 30 {
+		t.Errorf("Expected all 30 genuine ticks accepted, got %d", len(accepted))
+	}
+	if n := filter.Rejected("crude_oil"); n != 3 {
+		t.Errorf("Expected 3 rejections, got %d", n)
+	}
+	if !strings.Contains(logged.String(), "rejected crude_oil 7.5500") {
+		t.Errorf("Expected the rejection to be logged, got %q", logged.String())
+	}
+}
+
+// TestOutlierFilterPerCommodityConfig verifies a commodity override replaces the defaults
+func TestOutlierFilterPerCommodityConfig(t *testing.T) {
+	filter := NewOutlierFilter(OutlierConfig{Window: 20, MaxStdDevs: 4, MinSamples: 10}, nil)
+	filter.Configure("natural_gas", OutlierConfig{Window: 5, MaxStdDevs: 3, MinSamples: 1, MinDeviation: 0.05})
+
+	gas := MarketData{Commodity: "natural_gas", Price: 2.50}
+	filter.Accept(gas)
+	gas.Price = 2.80
+	if filter.Accept(gas) {
+		t.Error("Expected the natural_gas override to reject a 0.30 jump after one sample")
+	}
+
+	power := MarketData{Commodity: "power", Price: 50}
+	filter.Accept(power)
+	power.Price = 80
+	if !filter.Accept(power) {
+		t.Error("Expected default warm-up to accept ticks for unconfigured commodities")
+	}
+}
+
+// TestOutlierFilterFollowsLevelShift verifies a genuine step change is
+// accepted once enough consistent prints confirm it, while scattered bad
+// prints never re-seed the statistics
+func TestOutlierFilterFollowsLevelShift(t *testing.T) {
+	filter := NewOutlierFilter(OutlierConfig{Window: 20, MaxStdDevs: 4, MinSamples: 10, MinDeviation: 0.05, ReseedAfter: 4}, nil)
+	tick := MarketData{Commodity: "crude_oil"}
+	for i := 0; i < 20; i++ {
+		tick.Price = 75 + float64(i%3)*0.01
+		filter.Accept(tick)
+	}
+
+	// Bad prints that disagree with each other stay rejected
+	for _, price := range []float64{7.55, 755, 7.55, 755, 7.55, 755} {
+		tick.Price = price
+		if filter.Accept(tick) {
+			t.Fatalf("Expected scattered bad print %g rejected", price)
+		}
+	}
+
+	// The market gaps up 3 and keeps trading there
+	var accepted []bool
+	for i := 0; i < 6; i++ {
+		tick.Price = 78 + float64(i%2)*0.01
+		accepted = append(accepted, filter.Accept(tick))
+	}
+	want := []bool{false, false, false, true, true, true}
+	for i := range want {
+		if accepted[i] != want[i] {
+			t.Fatalf("Expected acceptance %v across the shift, got %v", want, accepted)
+		}
+	}
+	tick.Price = 75
+	if filter.Accept(tick) {
+		t.Error("Expected the old level to be an outlier once the filter re-seeded")
+	}
+	if n := filter.Rejected("crude_oil"); n != 10 {
+		t.Errorf("Expected 10 rejections, got %d", n)
+	}
+}
@@ -0,0 +1,140 @@
+package integration
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ParentStatus is a parent order's fill progress rolled up from its children
+type ParentStatus struct {
+	OrderID   string  `json:"order_id"`
+	Volume    float64 `json:"volume"`
+	Filled    float64 `json:"filled"`
+	Remaining float64 `json:"remaining"`
+	AvgPrice  float64 `json:"avg_price"`
+	Complete  bool    `json:"complete"`
+}
+
+type parentState struct {
+	order    TradingOrder
+	filled   float64
+	notional float64
+}
+
+// ParentOrderTracker attributes child fills back to their parent order.
+// Fills may arrive in any order: a fill for a child that has not been linked
+// yet is held until the link arrives, and repeated fills with the same trade
+// ID are counted once.
+type ParentOrderTracker struct {
+	mu       sync.Mutex
+	parents  map[string]*parentState
+	children map[string]string // child order ID -> parent order ID
+	pending  map[string][]FillEvent
+	seen     map[string]bool // child order ID + trade ID
+}
+
+// NewParentOrderTracker creates an empty tracker
+func NewParentOrderTracker() *ParentOrderTracker {
+	return &ParentOrderTracker{
+		parents:  make(map[string]*parentState),
+		children: make(map[string]string),
+		pending:  make(map[string][]FillEvent),
+		seen:     make(map[string]bool),
+	}
+}
+
+// Register starts tracking a parent and links the given children to it
+func (p *ParentOrderTracker) Register(parent TradingOrder, children ...TradingOrder) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, exists := p.parents[parent.OrderID]; exists {
+		return fmt.Errorf("%w: %s", ErrDuplicateOrder, parent.OrderID)
+	}
+	p.parents[parent.OrderID] = &parentState{order: parent}
+	for _, child := range children {
+		p.linkLocked(parent.OrderID, child.OrderID)
+	}
+	return nil
+}
+
+// AddChild links a child order to a registered parent
+func (p *ParentOrderTracker) AddChild(parentID, childID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.parents[parentID]; !ok {
+		return fmt.Errorf("%w: parent %s", ErrOrderNotFound, parentID)
+	}
+	p.linkLocked(parentID, childID)
+	return nil
+}
+
+// ApplyFill records a child fill and returns the parent's updated status.
+// The boolean is false when the child is not linked yet and the fill is held.
+func (p *ParentOrderTracker) ApplyFill(fill FillEvent) (ParentStatus, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	parentID, ok := p.children[fill.OrderID]
+	if !ok {
+		p.pending[fill.OrderID] = append(p.pending[fill.OrderID], fill)
+		return ParentStatus{}, false
+	}
+	p.applyLocked(parentID, fill)
+	return p.statusLocked(parentID), true
+}
+
+// Status returns a parent's current fill status
+func (p *ParentOrderTracker) Status(parentID string) (ParentStatus, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.parents[parentID]; !ok {
+		return ParentStatus{}, false
+	}
+	return p.statusLocked(parentID), true
+}
+
+// Remaining returns the parent's unfilled volume
+func (p *ParentOrderTracker) Remaining(parentID string) float64 {
+	status, _ := p.Status(parentID)
+	return status.Remaining
+}
+
+func (p *ParentOrderTracker) linkLocked(parentID, childID string) {
+	p.children[childID] = parentID
+	for _, fill := range p.pending[childID] {
+		p.applyLocked(parentID, fill)
+	}
+	delete(p.pending, childID)
+}
+
+func (p *ParentOrderTracker) applyLocked(parentID string, fill FillEvent) {
+	key := fill.OrderID + "/" + fill.TradeID
+	if fill.TradeID != "" && p.seen[key] {
+		return
+	}
+	p.seen[key] = true
+	state := p.parents[parentID]
+	state.filled += fill.Volume
+	state.notional += fill.Volume * fill.Price
+}
+
+func (p *ParentOrderTracker) statusLocked(parentID string) ParentStatus {
+	state := p.parents[parentID]
+	status := ParentStatus{
+		OrderID: parentID,
+		Volume:  state.order.Volume,
+		Filled:  state.filled,
+	}
+	status.Remaining = state.order.Volume - state.filled
+	if status.Remaining < volumeEpsilon {
+		status.Remaining = 0
+		status.Complete = true
+	}
+	if state.filled > 0 {
+		status.AvgPrice = state.notional / state.filled
+	}
+	return status
+}
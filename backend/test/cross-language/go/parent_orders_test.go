@@ -0,0 +1,50 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestParentOrderTrackerRollsUpChildFills verifies partial child fills aggregate into the parent
+func TestParentOrderTrackerRollsUpChildFills(t *testing.T) {
+	parent := TradingOrder{OrderID: "twap-1", Commodity: "crude_oil", Side: SideBuy, Volume: 300, Price: 76}
+	scheduler := &TWAPScheduler{Slices: 3, Duration: 3 * time.Minute}
+	children, err := scheduler.Schedule(parent, time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+	tracker := NewParentOrderTracker()
+	if err := tracker.Register(parent, children[0], children[1]); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	fills := []FillEvent{
+		{TradeID: "t1", OrderID: children[0].OrderID, Price: 75.50, Volume: 60},
+		{TradeID: "t4", OrderID: children[2].OrderID, Price: 75.80, Volume: 100}, // arrives before its child is linked
+		{TradeID: "t2", OrderID: children[0].OrderID, Price: 75.60, Volume: 40},
+		{TradeID: "t2", OrderID: children[0].OrderID, Price: 75.60, Volume: 40}, // duplicate delivery
+		{TradeID: "t3", OrderID: children[1].OrderID, Price: 75.70, Volume: 50},
+	}
+	for _, fill := range fills {
+		tracker.ApplyFill(fill)
+	}
+
+	status, _ := tracker.Status("twap-1")
+	if status.Filled != 150 || status.Remaining != 150 || status.Complete {
+		t.Fatalf("Expected 150 filled before the third child is linked, got %+v", status)
+	}
+
+	if err := tracker.AddChild("twap-1", children[2].OrderID); err != nil {
+		t.Fatalf("AddChild failed: %v", err)
+	}
+	status, _ = tracker.ApplyFill(FillEvent{TradeID: "t5", OrderID: children[1].OrderID, Price: 75.90, Volume: 50})
+
+	if !status.Complete || status.Remaining != 0 || tracker.Remaining("twap-1") != 0 {
+		t.Errorf("Expected parent complete, got %+v", status)
+	}
+	expectedAvg := (75.50*60 + 75.60*40 + 75.70*50 + 75.80*100 + 75.90*50) / 300
+	if math.Abs(status.AvgPrice-expectedAvg) > 1e-9 {
+		t.Errorf("Expected average price %.4f, got %.4f", expectedAvg, status.AvgPrice)
+	}
+}
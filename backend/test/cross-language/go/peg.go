@@ -0,0 +1,132 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// WithPegStep ignores reference moves that would change a pegged order's
+// price by less than step, so small flickers do not churn the book
+func WithPegStep(step float64) BookOption {
+	return func(b *OrderBook) {
+		b.pegStep = step
+	}
+}
+
+// WithPegPriority sets whether a repriced peg keeps its original time
+// priority at the new level; by default it joins the back of the queue
+func WithPegPriority(retain bool) BookOption {
+	return func(b *OrderBook) {
+		b.pegRetain = retain
+	}
+}
+
+// UpdatePegReference sets the reference market that pegged orders track
+// and reprices them. A repriced order that crosses the book trades as an
+// aggressor; pegs re-enter in arrival order. Repriced pegs get the entry
+// checks a new order gets: during an auction they only re-queue, a
+// post-only peg that would take is cancelled, and one that would trade
+// outside the reference band is paused.
+func (b *OrderBook) UpdatePegReference(bid, ask float64) []Trade {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.pegBid, b.pegAsk = bid, ask
+
+	var pegs []*restingOrder
+	for _, ro := range b.orders {
+		if ro.Type == OrderTypePegged {
+			pegs = append(pegs, ro)
+		}
+	}
+	sort.Slice(pegs, func(i, j int) bool { return pegs[i].arrival < pegs[j].arrival })
+
+	// Lift every moving peg before re-entering any, so a peg never trades
+	// against another peg's stale price
+	var moving []*restingOrder
+	for _, ro := range pegs {
+		price, ok := b.pegPrice(ro.TradingOrder)
+		if !ok || price == ro.Price || math.Abs(price-ro.Price) < b.pegStep-volumeEpsilon {
+			continue
+		}
+		b.removeLocked(ro)
+		ro.Price = price
+		moving = append(moving, ro)
+	}
+
+	// Band pauses are recorded ahead of the reference move, so a replay
+	// lifts the paused pegs before repricing the rest. A post-only peg never
+	// takes, so it is cancelled below instead.
+	kept := moving[:0]
+	for _, ro := range moving {
+		if ro.PostOnly {
+			kept = append(kept, ro)
+			continue
+		}
+		if paused, ok := b.bandLocked(ro.TradingOrder); ok {
+			b.pauseLocked(paused)
+			continue
+		}
+		kept = append(kept, ro)
+	}
+	b.record(BookEvent{Type: BookEventPegMarket, Bid: bid, Ask: ask})
+
+	var trades []Trade
+	for _, ro := range kept {
+		order := ro.TradingOrder
+		if err := b.postOnlyLocked(order); err != nil {
+			// Replay re-derives the cancel, as for an IOC remainder
+			b.record(BookEvent{Type: BookEventCancel, OrderID: order.OrderID, Volume: order.Volume})
+			b.metrics.OrdersCanceled(b.commodity, 1)
+			continue
+		}
+		if !b.auction {
+			trades = append(trades, b.matchLocked(&order)...)
+		}
+		if order.Volume <= volumeEpsilon {
+			continue
+		}
+		if b.pegRetain {
+			ro.TradingOrder = order
+			b.insertLocked(ro)
+		} else {
+			b.restLocked(order)
+		}
+	}
+	b.changedLocked()
+	return trades
+}
+
+// pegLocked prices a pegged order from the current reference
+func (b *OrderBook) pegLocked(order *TradingOrder) error {
+	if order.Type != OrderTypePegged {
+		return nil
+	}
+	price, ok := b.pegPrice(*order)
+	if !ok {
+		return fmt.Errorf("%w: no %s reference to peg to", ErrInvalidOrder, order.PegReference)
+	}
+	order.Price = price
+	return nil
+}
+
+// pegPrice returns the reference price plus offset, if the reference is set
+func (b *OrderBook) pegPrice(order TradingOrder) (float64, bool) {
+	var ref float64
+	switch order.PegReference {
+	case PegBid:
+		ref = b.pegBid
+	case PegAsk:
+		ref = b.pegAsk
+	case PegMid:
+		if b.pegBid > 0 && b.pegAsk > 0 {
+			ref = (b.pegBid + b.pegAsk) / 2
+		}
+	}
+	price := ref + order.PegOffset
+	if ref <= 0 || price <= 0 {
+		return 0, false
+	}
+	return price, true
+}
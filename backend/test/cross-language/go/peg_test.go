@@ -0,0 +1,139 @@
+package integration
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestPeggedOrderRepricesWithMarket verifies pegs follow the reference, ignore sub-step moves and trade when crossing
+func TestPeggedOrderRepricesWithMarket(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log), WithPegStep(0.01))
+
+	if _, err := book.Add(TradingOrder{OrderID: "peg", Side: SideBuy, Type: OrderTypePegged, PegReference: PegBid, Volume: 10}); !errors.Is(err, ErrInvalidOrder) {
+		t.Fatalf("Expected a peg without a reference market to be rejected, got %v", err)
+	}
+	book.UpdatePegReference(75.40, 75.60)
+	book.Add(TradingOrder{OrderID: "ask1", Side: SideSell, Price: 75.70, Volume: 5})
+	book.Add(TradingOrder{OrderID: "peg-bid", Side: SideBuy, Type: OrderTypePegged, PegReference: PegBid, PegOffset: 0.01, Volume: 10})
+	book.Add(TradingOrder{OrderID: "peg-mid", Side: SideSell, Type: OrderTypePegged, PegReference: PegMid, PegOffset: 0.05, Volume: 10})
+
+	priceOf := func(id string) float64 {
+		o, ok := book.Order(id)
+		if !ok {
+			t.Fatalf("Expected %s resting", id)
+		}
+		return o.Price
+	}
+	near := func(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+	if !near(priceOf("peg-bid"), 75.41) || !near(priceOf("peg-mid"), 75.55) {
+		t.Fatalf("Expected initial pegs at 75.41 and 75.55, got %g and %g", priceOf("peg-bid"), priceOf("peg-mid"))
+	}
+
+	book.UpdatePegReference(75.50, 75.70)
+	if !near(priceOf("peg-bid"), 75.51) || !near(priceOf("peg-mid"), 75.65) {
+		t.Errorf("Expected pegs to follow to 75.51 and 75.65, got %g and %g", priceOf("peg-bid"), priceOf("peg-mid"))
+	}
+
+	// A half-tick flicker stays under the step
+	book.UpdatePegReference(75.505, 75.705)
+	if !near(priceOf("peg-bid"), 75.51) {
+		t.Errorf("Expected no reprice below the step, got %g", priceOf("peg-bid"))
+	}
+
+	// The reference jumps through the resting offer; the bid peg takes it
+	trades := book.UpdatePegReference(75.80, 76.00)
+	if len(trades) != 1 || trades[0].BuyOrderID != "peg-bid" || trades[0].Price != 75.70 || trades[0].Volume != 5 {
+		t.Fatalf("Expected peg-bid to lift ask1 at 75.70, got %+v", trades)
+	}
+	if o, _ := book.Order("peg-bid"); o.Volume != 5 || !near(o.Price, 75.81) {
+		t.Errorf("Expected 5 left pegged at 75.81, got %+v", o)
+	}
+
+	if _, err := Rebuild(log); err != nil {
+		t.Errorf("Expected peg repricing to replay, got %v", err)
+	}
+}
+
+// TestPeggedOrderPriorityOnReprice verifies the priority policy when a peg moves onto an occupied level
+func TestPeggedOrderPriorityOnReprice(t *testing.T) {
+	for _, retain := range []bool{false, true} {
+		book := NewOrderBook("crude_oil", WithPegPriority(retain))
+		book.UpdatePegReference(75.40, 75.60)
+		book.Add(TradingOrder{OrderID: "peg", Side: SideBuy, Type: OrderTypePegged, PegReference: PegBid, Volume: 10})
+		book.Add(TradingOrder{OrderID: "later", Side: SideBuy, Price: 75.45, Volume: 10})
+
+		book.UpdatePegReference(75.45, 75.60)
+		trades, _ := book.Add(TradingOrder{OrderID: "sell", Side: SideSell, Price: 75.45, Volume: 10})
+		want := "later"
+		if retain {
+			want = "peg"
+		}
+		if len(trades) != 1 || trades[0].BuyOrderID != want {
+			t.Errorf("retain=%v: expected %s to trade first, got %+v", retain, want, trades)
+		}
+	}
+}
+
+// TestPeggedOrderRepriceEntryChecks verifies a repriced peg only re-queues
+// during an auction, and is cancelled when post-only or paused when outside
+// the reference band rather than trading through
+func TestPeggedOrderRepriceEntryChecks(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	book.UpdatePegReference(75.40, 75.60)
+	book.Add(TradingOrder{OrderID: "ask1", Side: SideSell, Price: 75.70, Volume: 5})
+	book.Add(TradingOrder{OrderID: "peg", Side: SideBuy, Type: OrderTypePegged, PegReference: PegBid, Volume: 10})
+	book.StartAuction()
+	if trades := book.UpdatePegReference(75.80, 76.00); len(trades) != 0 {
+		t.Fatalf("Expected no trades during the auction, got %+v", trades)
+	}
+	if o, ok := book.Order("peg"); !ok || o.Price != 75.80 || o.Volume != 10 {
+		t.Errorf("Expected the peg re-queued whole at 75.80 during the auction, got %+v", o)
+	}
+
+	log := NewMemoryEventLog()
+	var flagged []PausedOrder
+	book = NewOrderBook("crude_oil", WithEventLog(log),
+		WithReferenceBand(func() (float64, bool) { return 75.50, true }, 0.02,
+			func(p PausedOrder) { flagged = append(flagged, p) }))
+	book.UpdatePegReference(75.40, 75.60)
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", Side: SideSell, Price: 75.70, Volume: 5},
+		{OrderID: "ask2", Side: SideSell, Price: 78.00, Volume: 5}, // outside the band
+		{OrderID: "peg-post", Side: SideBuy, Type: OrderTypePegged, PegReference: PegBid, Volume: 10, PostOnly: true},
+		{OrderID: "peg-band", Side: SideBuy, Type: OrderTypePegged, PegReference: PegBid, PegOffset: -0.10, Volume: 10},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Add %s failed: %v", o.OrderID, err)
+		}
+	}
+
+	// peg-post would lift ask1 and peg-band would sweep through to ask2
+	if trades := book.UpdatePegReference(78.20, 78.40); len(trades) != 0 {
+		t.Fatalf("Expected neither peg to trade, got %+v", trades)
+	}
+	if _, ok := book.Order("peg-post"); ok {
+		t.Error("Expected the post-only peg cancelled once it would take")
+	}
+	if len(flagged) != 1 || flagged[0].Order.OrderID != "peg-band" || flagged[0].Price != 78.00 || math.Abs(flagged[0].Order.Price-78.10) > 1e-9 {
+		t.Errorf("Expected peg-band paused at 78.10 against ask2, got %+v", flagged)
+	}
+	if _, ok := book.Order("peg-band"); ok {
+		t.Error("Expected the paused peg lifted from the book")
+	}
+	if _, vol, _ := book.BestAsk(); vol != 5 {
+		t.Errorf("Expected ask1 untouched, got %g at the best ask", vol)
+	}
+
+	rebuilt, err := Rebuild(log)
+	if err != nil {
+		t.Fatalf("Expected repriced peg checks to replay, got %v", err)
+	}
+	if paused := rebuilt.PausedOrders(); len(paused) != 1 || paused[0].Order.OrderID != "peg-band" {
+		t.Errorf("Expected peg-band paused after replay, got %+v", paused)
+	}
+	if _, ok := rebuilt.Order("peg-band"); ok {
+		t.Error("Expected the paused peg off the rebuilt book")
+	}
+}
@@ -0,0 +1,191 @@
+package integration
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// defaultCompression trades accuracy for memory; higher values keep more centroids
+const defaultCompression = 200
+
+// centroid summarises a cluster of nearby observations
+type centroid struct {
+	mean  float64
+	count float64
+}
+
+// PercentileEstimator estimates quantiles over an unbounded stream with
+// bounded memory using a merging t-digest. The logistic scale function keeps
+// centroids small near the tails so p99 and p999 stay accurate.
+type PercentileEstimator struct {
+	mu          sync.Mutex
+	compression float64
+	centroids   []centroid
+	buffer      []float64
+	total       float64
+	min         float64
+	max         float64
+}
+
+// NewPercentileEstimator creates an estimator; compression <= 0 uses the default
+func NewPercentileEstimator(compression float64) *PercentileEstimator {
+	if compression <= 0 {
+		compression = defaultCompression
+	}
+	return &PercentileEstimator{
+		compression: compression,
+		buffer:      make([]float64, 0, int(compression)*5),
+		min:         math.Inf(1),
+		max:         math.Inf(-1),
+	}
+}
+
+// Add records an observation
+func (e *PercentileEstimator) Add(v float64) {
+	if math.IsNaN(v) {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.buffer = append(e.buffer, v)
+	e.min = math.Min(e.min, v)
+	e.max = math.Max(e.max, v)
+	if len(e.buffer) == cap(e.buffer) {
+		e.mergeLocked()
+	}
+}
+
+// Count returns the number of observations
+func (e *PercentileEstimator) Count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return int(e.total) + len(e.buffer)
+}
+
+// Quantile returns the estimated value at q in [0, 1], or NaN with no data
+func (e *PercentileEstimator) Quantile(q float64) float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.mergeLocked()
+	if e.total == 0 {
+		return math.NaN()
+	}
+	if q <= 0 {
+		return e.min
+	}
+	if q >= 1 {
+		return e.max
+	}
+
+	target := q * e.total
+	cumulative := 0.0
+	prevCenter, prevMean := 0.0, e.min
+	for _, c := range e.centroids {
+		center := cumulative + c.count/2
+		if target < center {
+			return interpolate(target, prevCenter, center, prevMean, c.mean)
+		}
+		cumulative += c.count
+		prevCenter, prevMean = center, c.mean
+	}
+	return interpolate(target, prevCenter, e.total, prevMean, e.max)
+}
+
+// mergeLocked folds buffered observations into the centroid list
+func (e *PercentileEstimator) mergeLocked() {
+	if len(e.buffer) == 0 {
+		return
+	}
+	total := e.total + float64(len(e.buffer))
+	all := make([]centroid, 0, len(e.centroids)+len(e.buffer))
+	all = append(all, e.centroids...)
+	for _, v := range e.buffer {
+		all = append(all, centroid{mean: v, count: 1})
+	}
+	e.buffer = e.buffer[:0]
+	sort.Slice(all, func(i, j int) bool { return all[i].mean < all[j].mean })
+
+	merged := make([]centroid, 0, len(e.centroids)+1)
+	current := all[0]
+	soFar := 0.0
+	kLow := e.scale(0, total)
+	for _, next := range all[1:] {
+		q := (soFar + current.count + next.count) / total
+		if e.scale(q, total)-kLow <= 1 {
+			current.count += next.count
+			current.mean += (next.mean - current.mean) * next.count / current.count
+			continue
+		}
+		soFar += current.count
+		kLow = e.scale(soFar/total, total)
+		merged = append(merged, current)
+		current = next
+	}
+	e.centroids = append(merged, current)
+	e.total = total
+}
+
+// scale is the logistic t-digest scale function k2, which shrinks
+// centroids toward both tails faster than the arcsine variant
+func (e *PercentileEstimator) scale(q, n float64) float64 {
+	q = math.Min(math.Max(q, 1e-15), 1-1e-15)
+	norm := 4*math.Log(math.Max(n/e.compression, 1)) + 24
+	return e.compression / norm * math.Log(q/(1-q))
+}
+
+func interpolate(x, x0, x1, y0, y1 float64) float64 {
+	if x1 <= x0 {
+		return y1
+	}
+	return y0 + (y1-y0)*(x-x0)/(x1-x0)
+}
+
+// SLOObjective requires the latency at Quantile to stay at or below Threshold
+type SLOObjective struct {
+	Name      string
+	Quantile  float64
+	Threshold time.Duration
+}
+
+// SLOStatus reports one objective against observed latency
+type SLOStatus struct {
+	Objective SLOObjective
+	Observed  time.Duration
+	Samples   int
+	Met       bool
+}
+
+// SLOTracker tracks latency objectives from a shared percentile estimator
+type SLOTracker struct {
+	objectives []SLOObjective
+	latencies  *PercentileEstimator
+}
+
+// NewSLOTracker creates a tracker for the given objectives
+func NewSLOTracker(objectives ...SLOObjective) *SLOTracker {
+	return &SLOTracker{objectives: objectives, latencies: NewPercentileEstimator(0)}
+}
+
+// Observe records one request latency
+func (t *SLOTracker) Observe(latency time.Duration) {
+	t.latencies.Add(float64(latency))
+}
+
+// Status evaluates every objective; objectives with no samples are reported as met
+func (t *SLOTracker) Status() []SLOStatus {
+	samples := t.latencies.Count()
+	statuses := make([]SLOStatus, 0, len(t.objectives))
+	for _, objective := range t.objectives {
+		status := SLOStatus{Objective: objective, Samples: samples, Met: true}
+		if samples > 0 {
+			status.Observed = time.Duration(t.latencies.Quantile(objective.Quantile))
+			status.Met = status.Observed <= objective.Threshold
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPercentileEstimatorTailAccuracy verifies tail quantiles of an exponential distribution
+func TestPercentileEstimatorTailAccuracy(t *testing.T) {
+	rng := rand.New(rand.NewSource(42))
+	estimator := NewPercentileEstimator(0)
+	values := make([]float64, 200000)
+	for i := range values {
+		values[i] = rng.ExpFloat64() * 10
+		estimator.Add(values[i])
+	}
+	sort.Float64s(values)
+
+	for _, q := range []float64{0.5, 0.9, 0.99, 0.999} {
+		exact := values[int(q*float64(len(values)))]
+		got := estimator.Quantile(q)
+		if math.Abs(got-exact)/exact > 0.01 {
+			t.Errorf("Quantile %g: expected ~%.4f, got %.4f", q, exact, got)
+		}
+	}
+	if estimator.Quantile(0) != values[0] || estimator.Quantile(1) != values[len(values)-1] {
+		t.Error("Expected q=0 and q=1 to return the exact extremes")
+	}
+	if n := len(estimator.centroids); n > 2*defaultCompression {
+		t.Errorf("Expected bounded centroid count, got %d", n)
+	}
+}
+
+// TestPercentileEstimatorConcurrentAdds verifies concurrent producers are all counted
+func TestPercentileEstimatorConcurrentAdds(t *testing.T) {
+	estimator := NewPercentileEstimator(100)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 1; i <= 1000; i++ {
+				estimator.Add(float64(i))
+				if i%250 == 0 {
+					estimator.Quantile(0.99)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := estimator.Count(); n != 8000 {
+		t.Errorf("Expected 8000 observations, got %d", n)
+	}
+	if p50 := estimator.Quantile(0.5); math.Abs(p50-500) > 10 {
+		t.Errorf("Expected p50 near 500, got %g", p50)
+	}
+}
+
+// TestSLOTrackerReportsBreaches verifies objectives are evaluated from the estimator
+func TestSLOTrackerReportsBreaches(t *testing.T) {
+	tracker := NewSLOTracker(
+		SLOObjective{Name: "order-ack-p99", Quantile: 0.99, Threshold: 20 * time.Millisecond},
+		SLOObjective{Name: "order-ack-p50", Quantile: 0.5, Threshold: 5 * time.Millisecond},
+	)
+	for i := 0; i < 1000; i++ {
+		latency := 2 * time.Millisecond
+		if i%50 == 0 {
+			latency = 80 * time.Millisecond
+		}
+		tracker.Observe(latency)
+	}
+
+	statuses := tracker.Status()
+	if statuses[0].Met {
+		t.Errorf("Expected p99 objective to be breached, observed %v", statuses[0].Observed)
+	}
+	if !statuses[1].Met || statuses[1].Samples != 1000 {
+		t.Errorf("Expected p50 objective to be met over 1000 samples, got %+v", statuses[1])
+	}
+}
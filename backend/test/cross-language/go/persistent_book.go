@@ -0,0 +1,284 @@
+package integration
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+// Book events are persisted one row per event:
+//
+//	CREATE TABLE book_events (
+//		commodity TEXT   NOT NULL,
+//		seq       BIGINT NOT NULL,
+//		type      TEXT   NOT NULL,
+//		payload   JSONB  NOT NULL,
+//		PRIMARY KEY (commodity, seq)
+//	);
+const (
+	insertBookEventSQL = `INSERT INTO book_events (commodity, seq, type, payload) VALUES ($1, $2, $3, $4) ON CONFLICT (commodity, seq) DO NOTHING`
+	selectBookEventSQL = `SELECT payload FROM book_events WHERE commodity = $1 ORDER BY seq`
+)
+
+// PersistenceConfig tunes how book events are written to the database
+type PersistenceConfig struct {
+	BatchSize     int           // events per transaction; defaults to 100
+	RetryInterval time.Duration // wait before retrying a failed batch; defaults to 100ms
+	Logger        *log.Logger
+}
+
+// PersistentEventLog is an EventLog that writes events to the book_events
+// table from a background goroutine. Appends never block on the database.
+// Events are written in sequence order in batches, each batch in one
+// transaction; a failed batch is retried from its first event until it
+// commits. Rows are keyed by commodity and sequence and inserts ignore
+// existing keys, so retrying a batch whose commit outcome was lost cannot
+// duplicate events, and a crash leaves the table holding a gap-free prefix
+// of the log.
+type PersistentEventLog struct {
+	pool   *DBPool
+	config PersistenceConfig
+
+	mu      sync.Mutex
+	events  []BookEvent
+	pending []BookEvent
+	durable uint64
+	lastErr error
+	flushed chan struct{} // closed and replaced after every commit
+
+	wake chan struct{}
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+func newPersistentEventLog(pool *DBPool, config PersistenceConfig, history []BookEvent) *PersistentEventLog {
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.RetryInterval <= 0 {
+		config.RetryInterval = 100 * time.Millisecond
+	}
+	if config.Logger == nil {
+		config.Logger = log.Default()
+	}
+	l := &PersistentEventLog{
+		pool:    pool,
+		config:  config,
+		events:  history,
+		flushed: make(chan struct{}),
+		wake:    make(chan struct{}, 1),
+		done:    make(chan struct{}),
+	}
+	if len(history) > 0 {
+		l.durable = history[len(history)-1].Seq
+	}
+	l.wg.Add(1)
+	go l.run()
+	return l
+}
+
+// Append implements EventLog
+func (l *PersistentEventLog) Append(event BookEvent) {
+	l.mu.Lock()
+	l.events = append(l.events, event)
+	l.pending = append(l.pending, event)
+	l.mu.Unlock()
+
+	select {
+	case l.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Events implements EventLog, including events not yet written
+func (l *PersistentEventLog) Events() []BookEvent {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return append([]BookEvent(nil), l.events...)
+}
+
+// Durable returns the sequence number of the last committed event
+func (l *PersistentEventLog) Durable() uint64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.durable
+}
+
+// Flush waits until every event appended so far has been committed. It
+// returns the most recent write error if ctx expires first.
+func (l *PersistentEventLog) Flush(ctx context.Context) error {
+	l.mu.Lock()
+	var target uint64
+	if len(l.events) > 0 {
+		target = l.events[len(l.events)-1].Seq
+	}
+	l.mu.Unlock()
+
+	for {
+		l.mu.Lock()
+		if l.durable >= target {
+			l.mu.Unlock()
+			return nil
+		}
+		flushed, lastErr := l.flushed, l.lastErr
+		l.mu.Unlock()
+
+		select {
+		case <-flushed:
+		case <-ctx.Done():
+			if lastErr != nil {
+				return fmt.Errorf("flush book events: %w (last write error: %v)", ctx.Err(), lastErr)
+			}
+			return fmt.Errorf("flush book events: %w", ctx.Err())
+		}
+	}
+}
+
+// Close stops the writer after attempting to write pending events once
+func (l *PersistentEventLog) Close() {
+	close(l.done)
+	l.wg.Wait()
+}
+
+func (l *PersistentEventLog) run() {
+	defer l.wg.Done()
+	for {
+		select {
+		case <-l.wake:
+		case <-l.done:
+			l.writePending()
+			return
+		}
+		for !l.writePending() {
+			select {
+			case <-time.After(l.config.RetryInterval):
+			case <-l.done:
+				return
+			}
+		}
+	}
+}
+
+// writePending commits pending events batch by batch, reporting false if a batch failed
+func (l *PersistentEventLog) writePending() bool {
+	for {
+		l.mu.Lock()
+		n := len(l.pending)
+		if n > l.config.BatchSize {
+			n = l.config.BatchSize
+		}
+		batch := append([]BookEvent(nil), l.pending[:n]...)
+		l.mu.Unlock()
+		if len(batch) == 0 {
+			return true
+		}
+
+		err := l.pool.WithTx(context.Background(), func(tx *sql.Tx) error {
+			for _, ev := range batch {
+				payload, err := json.Marshal(ev)
+				if err != nil {
+					return fmt.Errorf("encode event %d: %w", ev.Seq, err)
+				}
+				if _, err := tx.Exec(insertBookEventSQL, ev.Commodity, int64(ev.Seq), ev.Type, payload); err != nil {
+					return fmt.Errorf("insert event %d: %w", ev.Seq, err)
+				}
+			}
+			return nil
+		})
+
+		l.mu.Lock()
+		if err != nil {
+			l.lastErr = err
+			l.mu.Unlock()
+			l.config.Logger.Printf("book events: batch of %d from seq %d failed: %v", len(batch), batch[0].Seq, err)
+			return false
+		}
+		l.pending = l.pending[len(batch):]
+		l.durable = batch[len(batch)-1].Seq
+		l.lastErr = nil
+		close(l.flushed)
+		l.flushed = make(chan struct{})
+		l.mu.Unlock()
+	}
+}
+
+// loadBookEvents reads a commodity's persisted events in sequence order
+func loadBookEvents(ctx context.Context, pool *DBPool, commodity string) ([]BookEvent, error) {
+	rows, err := pool.DB().QueryContext(ctx, selectBookEventSQL, commodity)
+	if err != nil {
+		return nil, fmt.Errorf("load book events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []BookEvent
+	for rows.Next() {
+		var payload []byte
+		if err := rows.Scan(&payload); err != nil {
+			return nil, fmt.Errorf("scan book event: %w", err)
+		}
+		var ev BookEvent
+		if err := json.Unmarshal(payload, &ev); err != nil {
+			return nil, fmt.Errorf("decode book event: %w", err)
+		}
+		if ev.Seq != uint64(len(events))+1 {
+			return nil, fmt.Errorf("book events for %s: expected seq %d, found %d", commodity, len(events)+1, ev.Seq)
+		}
+		events = append(events, ev)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("load book events: %w", err)
+	}
+	return events, nil
+}
+
+// PersistentBook is an OrderBook whose mutations are written to the database
+type PersistentBook struct {
+	*OrderBook
+	log *PersistentEventLog
+}
+
+// OpenPersistentBook loads the commodity's persisted events, rebuilds the
+// book from them and persists every later mutation. With no stored events
+// it starts an empty book. opts must carry the matching options the book
+// ran with, as Rebuild replays under them.
+func OpenPersistentBook(ctx context.Context, pool *DBPool, commodity string, config PersistenceConfig, opts ...BookOption) (*PersistentBook, error) {
+	history, err := loadBookEvents(ctx, pool, commodity)
+	if err != nil {
+		return nil, err
+	}
+	plog := newPersistentEventLog(pool, config, history)
+	opts = append(opts, WithEventLog(plog))
+
+	if len(history) == 0 {
+		return &PersistentBook{OrderBook: NewOrderBook(commodity, opts...), log: plog}, nil
+	}
+	memory := NewMemoryEventLog()
+	for _, ev := range history {
+		memory.Append(ev)
+	}
+	book, err := Rebuild(memory, opts...)
+	if err != nil {
+		plog.Close()
+		return nil, fmt.Errorf("rebuild %s from database: %w", commodity, err)
+	}
+	return &PersistentBook{OrderBook: book, log: plog}, nil
+}
+
+// Flush waits until every mutation so far is committed
+func (p *PersistentBook) Flush(ctx context.Context) error {
+	return p.log.Flush(ctx)
+}
+
+// Durable returns the sequence number of the last committed event
+func (p *PersistentBook) Durable() uint64 {
+	return p.log.Durable()
+}
+
+// Close stops background writes; unflushed events may be lost
+func (p *PersistentBook) Close() {
+	p.log.Close()
+}
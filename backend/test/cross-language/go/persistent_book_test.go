@@ -0,0 +1,135 @@
+package integration
+
+import (
+	"context"
+	"io"
+	"log"
+	"testing"
+	"time"
+)
+
+// newBookEventsPool opens a pool on a fake database of the test's own
+func newBookEventsPool(t *testing.T) (*DBPool, *fakeDatabase) {
+	t.Helper()
+	dsn := "postgres://localhost:5432/" + t.Name()
+	pool, err := NewDBPool(DBPoolConfig{Driver: "fakedb", DatabaseURL: dsn, MaxOpenConns: 2, MaxIdleConns: 2})
+	if err != nil {
+		t.Fatalf("Failed to create pool: %v", err)
+	}
+	t.Cleanup(func() { pool.Close() })
+	return pool, fakeDB.database(dsn)
+}
+
+// TestPersistentBookWritesAndRebuilds verifies events survive failed commits exactly once and rebuild the book
+func TestPersistentBookWritesAndRebuilds(t *testing.T) {
+	pool, db := newBookEventsPool(t)
+	// First batch is lost before commit; the retry's commit applies but its acknowledgement is lost
+	fakeDB.mu.Lock()
+	db.failCommits = []bool{false, true}
+	fakeDB.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	config := PersistenceConfig{BatchSize: 3, RetryInterval: time.Millisecond, Logger: log.New(io.Discard, "", 0)}
+	book, err := OpenPersistentBook(ctx, pool, "crude_oil", config)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", ClientID: "gulf", Side: SideSell, Price: 75.60, Volume: 50},
+		{OrderID: "ask2", ClientID: "gulf", Side: SideSell, Price: 75.70, Volume: 50},
+		{OrderID: "bid1", ClientID: "acme", Side: SideBuy, Price: 75.40, Volume: 40},
+		{OrderID: "buy1", ClientID: "acme", Side: SideBuy, Price: 75.70, Volume: 70},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Add %s failed: %v", o.OrderID, err)
+		}
+	}
+	if err := book.Cancel("bid1"); err != nil {
+		t.Fatalf("Cancel failed: %v", err)
+	}
+	if err := book.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	want := book.Snapshot()
+	events := book.log.Events()
+	book.Close()
+
+	fakeDB.mu.Lock()
+	stored := len(db.rows["crude_oil"])
+	inserts := db.inserts
+	fakeDB.mu.Unlock()
+	if stored != len(events) {
+		t.Fatalf("Expected %d rows with no duplicates, got %d", len(events), stored)
+	}
+	if inserts <= stored {
+		t.Errorf("Expected failed batches to be retried, saw %d inserts for %d rows", inserts, stored)
+	}
+
+	restored, err := OpenPersistentBook(ctx, pool, "crude_oil", config)
+	if err != nil {
+		t.Fatalf("Rebuild failed: %v", err)
+	}
+	defer restored.Close()
+	got := restored.Snapshot()
+	got.Seq, want.Seq = 0, 0
+	if len(got.Asks) != 1 || got.Asks[0] != want.Asks[0] || len(got.Bids) != 0 || len(want.Bids) != 0 {
+		t.Fatalf("Expected rebuilt depth %+v, got %+v", want, got)
+	}
+	if restored.Durable() != uint64(len(events)) {
+		t.Errorf("Expected durable seq %d after load, got %d", len(events), restored.Durable())
+	}
+
+	// Writes continue the sequence after a restart
+	if _, err := restored.Add(TradingOrder{OrderID: "bid2", Side: SideBuy, Price: 75.50, Volume: 5}); err != nil {
+		t.Fatalf("Add after rebuild failed: %v", err)
+	}
+	if err := restored.Flush(ctx); err != nil {
+		t.Fatalf("Flush after rebuild failed: %v", err)
+	}
+	fakeDB.mu.Lock()
+	_, ok := db.rows["crude_oil"][int64(len(events)+1)]
+	fakeDB.mu.Unlock()
+	if !ok {
+		t.Errorf("Expected the next event stored at seq %d", len(events)+1)
+	}
+}
+
+// TestPersistentBookReopensWithMatchingOptions verifies a book run with
+// matching options reopens from the database under the same options
+func TestPersistentBookReopensWithMatchingOptions(t *testing.T) {
+	pool, _ := newBookEventsPool(t)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	config := PersistenceConfig{BatchSize: 4, RetryInterval: time.Millisecond, Logger: log.New(io.Discard, "", 0)}
+	opts := []BookOption{WithLotSize(10, LotResidualCancel), WithProRata(0)}
+
+	book, err := OpenPersistentBook(ctx, pool, "crude_oil", config, opts...)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	for _, o := range []TradingOrder{
+		{OrderID: "ask1", Side: SideSell, Price: 75.60, Volume: 30},
+		{OrderID: "ask2", Side: SideSell, Price: 75.60, Volume: 60},
+		{OrderID: "buy1", Side: SideBuy, Price: 75.60, Volume: 45}, // 40 fills pro-rata, 5 cancelled
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Add %s failed: %v", o.OrderID, err)
+		}
+	}
+	if err := book.Flush(ctx); err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	want := book.Snapshot()
+	book.Close()
+
+	restored, err := OpenPersistentBook(ctx, pool, "crude_oil", config, opts...)
+	if err != nil {
+		t.Fatalf("Expected reopen under the same options to succeed, got %v", err)
+	}
+	defer restored.Close()
+	got := restored.Snapshot()
+	if len(got.Asks) != 1 || got.Asks[0] != want.Asks[0] || got.Asks[0].Volume != 50 {
+		t.Errorf("Expected 50 left at 75.60 as before the restart, got %+v (was %+v)", got.Asks, want.Asks)
+	}
+}
@@ -0,0 +1,224 @@
+package integration
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// PositionUpdate is the latest state of one client's position in a commodity
+type PositionUpdate struct {
+	ClientID  string    `json:"client_id"`
+	Commodity string    `json:"commodity"`
+	Position  Position  `json:"position"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// positionRef identifies one client's position in a commodity
+type positionRef struct {
+	clientID  string
+	commodity string
+}
+
+// PositionFeed streams position changes to subscribers so dashboards need
+// not poll the tracker. Fills are applied through the feed; each changed
+// position is published once the coalescing window since its first
+// unpublished change has elapsed, carrying its value at that moment.
+// Subscribers hold only the latest update per position, so one that falls
+// behind skips straight to current values instead of working a backlog.
+type PositionFeed struct {
+	tracker *PositionTracker
+	window  time.Duration
+	clock   func() time.Time
+
+	mu    sync.Mutex
+	dirty map[positionRef]time.Time // position -> first unpublished change
+	subs  map[*PositionSubscription]bool
+}
+
+// NewPositionFeed creates a feed over tracker. A window of zero publishes
+// every change as it is applied; a nil clock uses time.Now.
+func NewPositionFeed(tracker *PositionTracker, window time.Duration, clock func() time.Time) *PositionFeed {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &PositionFeed{
+		tracker: tracker,
+		window:  window,
+		clock:   clock,
+		dirty:   make(map[positionRef]time.Time),
+		subs:    make(map[*PositionSubscription]bool),
+	}
+}
+
+// ApplyTrade updates the tracker for both sides of a trade and marks the
+// changed positions
+func (f *PositionFeed) ApplyTrade(trade Trade) {
+	f.tracker.ApplyTrade(trade)
+	var changed []positionRef
+	if trade.BuyClientID != "" {
+		changed = append(changed, positionRef{trade.BuyClientID, trade.Commodity})
+	}
+	if trade.SellClientID != "" {
+		changed = append(changed, positionRef{trade.SellClientID, trade.Commodity})
+	}
+	f.changed(changed...)
+}
+
+// ApplyFill updates the tracker for one client's fill and marks the position
+func (f *PositionFeed) ApplyFill(clientID, commodity, side string, volume, price float64) {
+	f.tracker.ApplyFill(clientID, commodity, side, volume, price)
+	f.changed(positionRef{clientID, commodity})
+}
+
+func (f *PositionFeed) changed(refs ...positionRef) {
+	now := f.clock()
+	f.mu.Lock()
+	for _, ref := range refs {
+		if _, ok := f.dirty[ref]; !ok {
+			f.dirty[ref] = now
+		}
+	}
+	f.mu.Unlock()
+	if f.window <= 0 {
+		f.Flush()
+	}
+}
+
+// Flush publishes every changed position whose coalescing window has
+// elapsed and returns how many updates were published
+func (f *PositionFeed) Flush() int {
+	now := f.clock()
+	f.mu.Lock()
+	var due []positionRef
+	for ref, since := range f.dirty {
+		if now.Sub(since) >= f.window {
+			due = append(due, ref)
+			delete(f.dirty, ref)
+		}
+	}
+	subs := make([]*PositionSubscription, 0, len(f.subs))
+	for sub := range f.subs {
+		subs = append(subs, sub)
+	}
+	f.mu.Unlock()
+	sort.Slice(due, func(i, j int) bool {
+		if due[i].clientID != due[j].clientID {
+			return due[i].clientID < due[j].clientID
+		}
+		return due[i].commodity < due[j].commodity
+	})
+
+	for _, ref := range due {
+		pos, ok := f.tracker.Position(ref.clientID, ref.commodity)
+		if !ok {
+			continue
+		}
+		update := PositionUpdate{ClientID: ref.clientID, Commodity: ref.commodity, Position: pos, Timestamp: now}
+		for _, sub := range subs {
+			sub.offer(ref, update)
+		}
+	}
+	return len(due)
+}
+
+// Run flushes every interval until ctx is done
+func (f *PositionFeed) Run(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			f.Flush()
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Subscribe registers a subscriber for one client's positions, or every
+// client's when clientID is empty
+func (f *PositionFeed) Subscribe(clientID string) *PositionSubscription {
+	sub := &PositionSubscription{
+		feed:     f,
+		clientID: clientID,
+		pending:  make(map[positionRef]PositionUpdate),
+		ready:    make(chan struct{}, 1),
+	}
+	f.mu.Lock()
+	f.subs[sub] = true
+	f.mu.Unlock()
+	return sub
+}
+
+// PositionSubscription receives position updates from a PositionFeed
+type PositionSubscription struct {
+	feed     *PositionFeed
+	clientID string
+
+	mu      sync.Mutex
+	pending map[positionRef]PositionUpdate
+	order   []positionRef // pending positions in the order they first became pending
+	ready   chan struct{}
+}
+
+// offer replaces any unread update for the same position
+func (s *PositionSubscription) offer(ref positionRef, update PositionUpdate) {
+	if s.clientID != "" && s.clientID != update.ClientID {
+		return
+	}
+	s.mu.Lock()
+	if _, ok := s.pending[ref]; !ok {
+		s.order = append(s.order, ref)
+	}
+	s.pending[ref] = update
+	s.mu.Unlock()
+	select {
+	case s.ready <- struct{}{}:
+	default:
+	}
+}
+
+// Ready is signalled when updates are waiting to be read
+func (s *PositionSubscription) Ready() <-chan struct{} {
+	return s.ready
+}
+
+// Updates takes the waiting updates, the latest for each position, without
+// blocking
+func (s *PositionSubscription) Updates() []PositionUpdate {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.order) == 0 {
+		return nil
+	}
+	out := make([]PositionUpdate, len(s.order))
+	for i, ref := range s.order {
+		out[i] = s.pending[ref]
+		delete(s.pending, ref)
+	}
+	s.order = s.order[:0]
+	return out
+}
+
+// Next blocks until updates are waiting or ctx is done
+func (s *PositionSubscription) Next(ctx context.Context) ([]PositionUpdate, error) {
+	for {
+		if updates := s.Updates(); len(updates) > 0 {
+			return updates, nil
+		}
+		select {
+		case <-s.ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// Close stops delivery to the subscription
+func (s *PositionSubscription) Close() {
+	s.feed.mu.Lock()
+	delete(s.feed.subs, s)
+	s.feed.mu.Unlock()
+}
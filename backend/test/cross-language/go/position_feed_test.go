@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestPositionFeedCoalescesFills verifies rapid fills publish one update per position with the latest value
+func TestPositionFeedCoalescesFills(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	feed := NewPositionFeed(NewPositionTracker(), 100*time.Millisecond, func() time.Time { return now })
+	all := feed.Subscribe("")
+	alice := feed.Subscribe("alice")
+	defer all.Close()
+	defer alice.Close()
+
+	for _, volume := range []float64{10, 5, 15} {
+		feed.ApplyTrade(Trade{Commodity: "crude_oil", BuyClientID: "alice", SellClientID: "bob", Price: 75, Volume: volume})
+		now = now.Add(20 * time.Millisecond)
+		feed.Flush()
+	}
+	if updates := all.Updates(); len(updates) != 0 {
+		t.Fatalf("Expected fills inside the window to be held back, got %+v", updates)
+	}
+
+	now = now.Add(50 * time.Millisecond)
+	if n := feed.Flush(); n != 2 {
+		t.Fatalf("Expected alice and bob to publish once each, got %d", n)
+	}
+	updates := all.Updates()
+	if len(updates) != 2 || updates[0].ClientID != "alice" || updates[0].Position.Volume != 30 || updates[1].Position.Volume != -30 {
+		t.Fatalf("Expected coalesced positions of 30 and -30, got %+v", updates)
+	}
+	if !updates[0].Timestamp.Equal(now) {
+		t.Errorf("Expected the update stamped at publication, got %v", updates[0].Timestamp)
+	}
+	if only := alice.Updates(); len(only) != 1 || only[0].ClientID != "alice" {
+		t.Errorf("Expected the client subscription to see only alice, got %+v", only)
+	}
+
+	// A subscriber that does not read keeps only the latest value
+	for _, volume := range []float64{1, 2} {
+		feed.ApplyFill("alice", "crude_oil", SideSell, volume, 76)
+		now = now.Add(200 * time.Millisecond)
+		feed.Flush()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	latest, err := alice.Next(ctx)
+	if err != nil || len(latest) != 1 || latest[0].Position.Volume != 27 {
+		t.Errorf("Expected a single latest position of 27, got %+v, %v", latest, err)
+	}
+}
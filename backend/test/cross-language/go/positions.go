@@ -0,0 +1,180 @@
+package integration
+
+import (
+	"math"
+	"sort"
+	"sync"
+)
+
+// Position is a client's net holding in one commodity
+type Position struct {
+	Commodity   string  `json:"commodity"`
+	Volume      float64 `json:"volume"` // positive long, negative short
+	AvgPrice    float64 `json:"avg_price"`
+	MarkPrice   float64 `json:"mark_price"`
+	RealizedPnL float64 `json:"realized_pnl"`
+}
+
+// CommodityExposure is one commodity line in an exposure report
+type CommodityExposure struct {
+	Commodity string  `json:"commodity"`
+	Volume    float64 `json:"volume"`
+	AvgPrice  float64 `json:"avg_price"`
+	Notional  float64 `json:"notional"`
+}
+
+// ExposureReport is a per-client rollup of open positions
+type ExposureReport struct {
+	ClientID      string              `json:"client_id"`
+	OpenPositions int                 `json:"open_positions"`
+	TotalNotional float64             `json:"total_notional"`
+	Commodities   []CommodityExposure `json:"commodities"`
+}
+
+// PositionTracker maintains net positions keyed by client and commodity
+type PositionTracker struct {
+	mu        sync.RWMutex
+	positions map[string]map[string]*Position
+}
+
+// NewPositionTracker creates an empty tracker
+func NewPositionTracker() *PositionTracker {
+	return &PositionTracker{positions: make(map[string]map[string]*Position)}
+}
+
+// ApplyTrade updates both counterparties of a trade. Sides without a
+// client ID are ignored.
+func (p *PositionTracker) ApplyTrade(trade Trade) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if trade.BuyClientID != "" {
+		p.applyLocked(trade.BuyClientID, trade.Commodity, trade.Volume, trade.Price)
+	}
+	if trade.SellClientID != "" {
+		p.applyLocked(trade.SellClientID, trade.Commodity, -trade.Volume, trade.Price)
+	}
+}
+
+// ApplyFill updates a single client's position for a fill on the given side
+func (p *PositionTracker) ApplyFill(clientID, commodity, side string, volume, price float64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if side == SideSell {
+		volume = -volume
+	}
+	p.applyLocked(clientID, commodity, volume, price)
+}
+
+// applyLocked applies a signed volume change, maintaining the average entry
+// price and realizing PnL on reductions
+func (p *PositionTracker) applyLocked(clientID, commodity string, delta, price float64) {
+	book, ok := p.positions[clientID]
+	if !ok {
+		book = make(map[string]*Position)
+		p.positions[clientID] = book
+	}
+	pos, ok := book[commodity]
+	if !ok {
+		pos = &Position{Commodity: commodity}
+		book[commodity] = pos
+	}
+
+	pos.MarkPrice = price
+	switch {
+	case pos.Volume == 0 || sameSign(pos.Volume, delta):
+		total := pos.Volume + delta
+		pos.AvgPrice = (pos.AvgPrice*math.Abs(pos.Volume) + price*math.Abs(delta)) / math.Abs(total)
+		pos.Volume = total
+	default:
+		closed := math.Min(math.Abs(delta), math.Abs(pos.Volume))
+		if pos.Volume > 0 {
+			pos.RealizedPnL += closed * (price - pos.AvgPrice)
+		} else {
+			pos.RealizedPnL += closed * (pos.AvgPrice - price)
+		}
+		pos.Volume += delta
+		switch {
+		case math.Abs(pos.Volume) <= volumeEpsilon:
+			pos.Volume = 0
+			pos.AvgPrice = 0
+		case !sameSign(pos.Volume, -delta):
+			pos.AvgPrice = price // flipped through flat
+		}
+	}
+}
+
+// Position returns a copy of a client's position in a commodity
+func (p *PositionTracker) Position(clientID, commodity string) (Position, bool) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	pos, ok := p.positions[clientID][commodity]
+	if !ok {
+		return Position{}, false
+	}
+	return *pos, true
+}
+
+// Clients returns the IDs of every client with tracked positions, sorted
+func (p *PositionTracker) Clients() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	clients := make([]string, 0, len(p.positions))
+	for id := range p.positions {
+		clients = append(clients, id)
+	}
+	sort.Strings(clients)
+	return clients
+}
+
+// Snapshot copies every client's positions under a single read lock, so
+// the result is consistent across clients
+func (p *PositionTracker) Snapshot() map[string][]Position {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	out := make(map[string][]Position, len(p.positions))
+	for clientID, book := range p.positions {
+		positions := make([]Position, 0, len(book))
+		for _, pos := range book {
+			positions = append(positions, *pos)
+		}
+		out[clientID] = positions
+	}
+	return out
+}
+
+// ClientExposure returns a consistent snapshot of a client's open positions.
+// Unknown clients yield an empty report.
+func (p *PositionTracker) ClientExposure(clientID string) ExposureReport {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	report := ExposureReport{ClientID: clientID, Commodities: []CommodityExposure{}}
+	for _, pos := range p.positions[clientID] {
+		if pos.Volume == 0 {
+			continue
+		}
+		notional := math.Abs(pos.Volume) * pos.MarkPrice
+		report.Commodities = append(report.Commodities, CommodityExposure{
+			Commodity: pos.Commodity,
+			Volume:    pos.Volume,
+			AvgPrice:  pos.AvgPrice,
+			Notional:  notional,
+		})
+		report.TotalNotional += notional
+	}
+	report.OpenPositions = len(report.Commodities)
+	sort.Slice(report.Commodities, func(i, j int) bool {
+		return report.Commodities[i].Commodity < report.Commodities[j].Commodity
+	})
+	return report
+}
+
+// sameSign reports whether two non-zero values share a sign
+func sameSign(a, b float64) bool {
+	return (a > 0) == (b > 0)
+}
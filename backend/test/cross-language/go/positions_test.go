@@ -0,0 +1,99 @@
+package integration
+
+import (
+	"math"
+	"sync"
+	"testing"
+)
+
+// TestClientExposureConcurrentTrading verifies per-client rollups under concurrent trading
+func TestClientExposureConcurrentTrading(t *testing.T) {
+	tracker := NewPositionTracker()
+	const tradesPerClient = 200
+
+	var wg sync.WaitGroup
+	for _, client := range []string{"client_a", "client_b", "client_c"} {
+		wg.Add(1)
+		go func(client string) {
+			defer wg.Done()
+			for i := 0; i < tradesPerClient; i++ {
+				tracker.ApplyTrade(Trade{Commodity: "crude_oil", Price: 75, Volume: 1, BuyClientID: client, SellClientID: "market_maker"})
+				if i%2 == 0 {
+					tracker.ApplyFill(client, "natural_gas", SideSell, 10, 3)
+				}
+			}
+		}(client)
+	}
+
+	// Readers must always see internally consistent reports while trading runs.
+	stop := make(chan struct{})
+	readerDone := make(chan struct{})
+	go func() {
+		defer close(readerDone)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			report := tracker.ClientExposure("client_a")
+			sum := 0.0
+			for _, c := range report.Commodities {
+				sum += c.Notional
+			}
+			if math.Abs(sum-report.TotalNotional) > volumeEpsilon {
+				t.Errorf("Inconsistent snapshot: total %f, sum of lines %f", report.TotalNotional, sum)
+				return
+			}
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	<-readerDone
+
+	report := tracker.ClientExposure("client_b")
+	if report.OpenPositions != 2 {
+		t.Fatalf("Expected 2 open positions, got %d", report.OpenPositions)
+	}
+	crude, gas := report.Commodities[0], report.Commodities[1]
+	if crude.Commodity != "crude_oil" || crude.Volume != tradesPerClient {
+		t.Errorf("Expected crude_oil long %d, got %+v", tradesPerClient, crude)
+	}
+	if gas.Commodity != "natural_gas" || gas.Volume != -1000 {
+		t.Errorf("Expected natural_gas short 1000, got %+v", gas)
+	}
+	wantNotional := tradesPerClient*75.0 + 1000*3.0
+	if report.TotalNotional != wantNotional {
+		t.Errorf("Expected total notional %f, got %f", wantNotional, report.TotalNotional)
+	}
+
+	mm := tracker.ClientExposure("market_maker")
+	if mm.Commodities[0].Volume != -3*tradesPerClient {
+		t.Errorf("Expected market maker short %d, got %f", 3*tradesPerClient, mm.Commodities[0].Volume)
+	}
+}
+
+// TestClientExposureUnknownClient verifies unknown clients return an empty report
+func TestClientExposureUnknownClient(t *testing.T) {
+	report := NewPositionTracker().ClientExposure("nobody")
+	if report.OpenPositions != 0 || report.TotalNotional != 0 || len(report.Commodities) != 0 {
+		t.Errorf("Expected empty report, got %+v", report)
+	}
+}
+
+// TestPositionRealizedPnL verifies reductions realize PnL against the average entry
+func TestPositionRealizedPnL(t *testing.T) {
+	tracker := NewPositionTracker()
+	tracker.ApplyFill("c1", "crude_oil", SideBuy, 10, 70)
+	tracker.ApplyFill("c1", "crude_oil", SideBuy, 10, 80)
+	tracker.ApplyFill("c1", "crude_oil", SideSell, 15, 90)
+
+	pos, _ := tracker.Position("c1", "crude_oil")
+	if pos.Volume != 5 || pos.AvgPrice != 75 {
+		t.Errorf("Expected 5 @ 75, got %f @ %f", pos.Volume, pos.AvgPrice)
+	}
+	if pos.RealizedPnL != 225 {
+		t.Errorf("Expected realized PnL 225, got %f", pos.RealizedPnL)
+	}
+}
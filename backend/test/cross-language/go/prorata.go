@@ -0,0 +1,103 @@
+package integration
+
+import (
+	"math"
+	"math/bits"
+	"sort"
+)
+
+// AllocateProRata splits total across sizes in proportion to each size,
+// in whole multiples of unit, by the largest-remainder method. Every size
+// first gets its share rounded down; the units that rounding leaves over
+// go one each to the sizes with the largest fractional shares, earlier
+// sizes first on equal fractions. The allocations therefore add up to
+// exactly total in whole units, none exceeds its size, and the result
+// depends only on the inputs. Sizes count only whole units and total is
+// capped at their sum.
+func AllocateProRata(total float64, sizes []float64, unit float64) []float64 {
+	allocs := make([]float64, len(sizes))
+	if unit <= 0 || total <= 0 {
+		return allocs
+	}
+	units := make([]uint64, len(sizes))
+	var sum uint64
+	for i, size := range sizes {
+		if size > 0 {
+			units[i] = uint64(math.Floor(size/unit + volumeEpsilon))
+			sum += units[i]
+		}
+	}
+	want := uint64(math.Floor(total/unit + volumeEpsilon))
+	if want >= sum {
+		for i, n := range units {
+			allocs[i] = float64(n) * unit
+		}
+		return allocs
+	}
+
+	// Shares are want*n/sum; the integer remainders compare exactly
+	remainders := make([]uint64, len(units))
+	order := make([]int, len(units))
+	var given uint64
+	for i, n := range units {
+		hi, lo := bits.Mul64(want, n)
+		quo, rem := bits.Div64(hi, lo, sum)
+		allocs[i] = float64(quo)
+		remainders[i] = rem
+		given += quo
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]] > remainders[order[b]] })
+	for _, i := range order[:want-given] {
+		allocs[i]++
+	}
+	for i := range allocs {
+		allocs[i] *= unit
+	}
+	return allocs
+}
+
+// proRataLocked fills the order across a level in proportion to resting
+// volume, in whole allocation units, before time priority takes any
+// residue. It only acts when the order is smaller than the level; an order
+// taking the whole level fills every order in full either way.
+func (b *OrderBook) proRataLocked(order *TradingOrder, level *bookLevel) []Trade {
+	unit := b.proRataUnit
+	if unit <= 0 {
+		unit = b.lotSize
+	}
+	if unit <= 0 {
+		unit = 1
+	}
+	sizes := make([]float64, len(level.orders))
+	var available float64
+	for j, ro := range level.orders {
+		sizes[j] = ro.Volume
+		available += ro.Volume
+	}
+	if len(level.orders) < 2 || order.Volume >= available-volumeEpsilon {
+		return nil
+	}
+
+	var trades []Trade
+	allocs := AllocateProRata(order.Volume, sizes, unit)
+	kept := level.orders[:0]
+	var refreshed []*restingOrder
+	for j, resting := range level.orders {
+		if allocs[j] > 0 {
+			trades = append(trades, b.newTrade(order, resting, level.price, allocs[j]))
+			order.Volume -= allocs[j]
+			resting.Volume -= allocs[j]
+		}
+		switch {
+		case resting.Volume > volumeEpsilon:
+			kept = append(kept, resting)
+		case b.refreshLocked(resting):
+			refreshed = append(refreshed, resting)
+		default:
+			delete(b.orders, resting.OrderID)
+		}
+	}
+	level.orders = append(kept, refreshed...)
+	return trades
+}
@@ -0,0 +1,108 @@
+package integration
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestAllocateProRataConservesRoundedUnit verifies the largest-remainder
+// method hands out the unit naive rounding loses
+func TestAllocateProRataConservesRoundedUnit(t *testing.T) {
+	sizes := []float64{30, 30, 30}
+	var naive float64
+	for _, size := range sizes {
+		naive += math.Round(10 * size / 90)
+	}
+	if naive != 9 {
+		t.Fatalf("Expected naive rounding of 10 across three equal orders to give 9, got %g", naive)
+	}
+
+	allocs := AllocateProRata(10, sizes, 1)
+	if want := []float64{4, 3, 3}; !reflect.DeepEqual(allocs, want) {
+		t.Errorf("Expected %v, got %v", want, allocs)
+	}
+
+	// Unequal sizes: exact shares 4.6, 3.6, 1.8 sum to 10 but round to 11
+	allocs = AllocateProRata(10, []float64{46, 36, 18}, 1)
+	if want := []float64{5, 3, 2}; !reflect.DeepEqual(allocs, want) {
+		t.Errorf("Expected largest remainders .8 and .6 to take the spare units, got %v", allocs)
+	}
+}
+
+// TestAllocateProRataIsDeterministic verifies totals are exact, allocations
+// never exceed sizes and repeated runs agree
+func TestAllocateProRataIsDeterministic(t *testing.T) {
+	sizes := []float64{7, 13, 1, 29, 50, 3}
+	for total := 0.0; total <= 110; total++ {
+		allocs := AllocateProRata(total, sizes, 1)
+		var sum float64
+		for i, a := range allocs {
+			if a > sizes[i] || a != math.Trunc(a) {
+				t.Fatalf("Expected whole allocation within size %g, got %g for total %g", sizes[i], a, total)
+			}
+			sum += a
+		}
+		if want := math.Min(total, 103); sum != want {
+			t.Errorf("Expected allocations to sum to %g, got %g", want, sum)
+		}
+		if again := AllocateProRata(total, sizes, 1); !reflect.DeepEqual(allocs, again) {
+			t.Errorf("Expected identical allocations for total %g, got %v and %v", total, allocs, again)
+		}
+	}
+
+	// Allocations are whole units of the unit, not of 1
+	allocs := AllocateProRata(1.5, []float64{1, 1, 1}, 0.5)
+	if want := []float64{0.5, 0.5, 0.5}; !reflect.DeepEqual(allocs, want) {
+		t.Errorf("Expected %v, got %v", want, allocs)
+	}
+}
+
+// TestProRataBookSplitsLevelByVolume verifies a pro-rata book fills resting
+// orders in proportion to size and keeps the fill volume exact
+func TestProRataBookSplitsLevelByVolume(t *testing.T) {
+	base := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := base
+	book := NewOrderBook("crude_oil", WithProRata(0),
+		WithClock(func() time.Time { return now }),
+		WithEventLog(NewMemoryEventLog()))
+
+	for _, o := range []TradingOrder{
+		{OrderID: "s1", Volume: 30, Price: 75, Side: SideSell, Type: OrderTypeLimit},
+		{OrderID: "s2", Volume: 30, Price: 75, Side: SideSell, Type: OrderTypeLimit},
+		{OrderID: "s3", Volume: 30, Price: 75, Side: SideSell, Type: OrderTypeLimit},
+	} {
+		now = now.Add(time.Second)
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Expected resting sell to be accepted, got %v", err)
+		}
+	}
+
+	now = now.Add(time.Second)
+	trades, err := book.Add(TradingOrder{OrderID: "b1", Volume: 10, Price: 75, Side: SideBuy, Type: OrderTypeLimit})
+	if err != nil {
+		t.Fatalf("Expected buy to be accepted, got %v", err)
+	}
+	got := map[string]float64{}
+	var total float64
+	for _, tr := range trades {
+		got[tr.SellOrderID] += tr.Volume
+		total += tr.Volume
+	}
+	if want := map[string]float64{"s1": 4, "s2": 3, "s3": 3}; !reflect.DeepEqual(got, want) || total != 10 {
+		t.Errorf("Expected fills %v totalling 10, got %v totalling %g", want, got, total)
+	}
+	if _, vol, _ := book.BestAsk(); vol != 80 {
+		t.Errorf("Expected 80 left at the best ask, got %g", vol)
+	}
+
+	// The replayed book allocates the same way
+	snap, err := book.SnapshotAt(now)
+	if err != nil {
+		t.Fatalf("Expected snapshot replay to succeed, got %v", err)
+	}
+	if !reflect.DeepEqual(snap, book.Snapshot()) {
+		t.Errorf("Expected replayed snapshot %+v to match book %+v", snap, book.Snapshot())
+	}
+}
@@ -0,0 +1,86 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Quote guard errors
+var (
+	ErrCrossesQuote = errors.New("order price crosses the quote beyond threshold")
+	ErrNoQuote      = errors.New("no quote available")
+)
+
+// QuoteSource provides the current best bid and ask. OrderBook implements it.
+type QuoteSource interface {
+	BestBid() (price, volume float64, ok bool)
+	BestAsk() (price, volume float64, ok bool)
+}
+
+// QuoteGuardConfig controls how far a marketable order may cross the quote
+type QuoteGuardConfig struct {
+	MaxCross    float64 `json:"max_cross"`     // allowed price distance through the quote
+	Block       bool    `json:"block"`         // reject instead of flagging
+	PassNoQuote bool    `json:"pass_no_quote"` // allow orders when the opposite side is empty
+}
+
+// QuoteCheck is the outcome of a quote guard check
+type QuoteCheck struct {
+	Flagged   bool
+	Reference float64
+	Reason    string
+}
+
+// QuoteGuard cross-checks limit prices against the opposite best quote
+type QuoteGuard struct {
+	quotes QuoteSource
+	config QuoteGuardConfig
+}
+
+// NewQuoteGuard creates a guard reading quotes from the given source
+func NewQuoteGuard(quotes QuoteSource, config QuoteGuardConfig) *QuoteGuard {
+	return &QuoteGuard{quotes: quotes, config: config}
+}
+
+// Check inspects an order before submission. A buy priced above the ask (or
+// a sell below the bid) by more than MaxCross is flagged, or rejected with
+// ErrCrossesQuote when the guard is blocking. Market orders carry no price
+// and are not checked.
+func (g *QuoteGuard) Check(order TradingOrder) (QuoteCheck, error) {
+	if order.Type == OrderTypeMarket {
+		return QuoteCheck{}, nil
+	}
+
+	var quote float64
+	var ok bool
+	if order.Side == SideBuy {
+		quote, _, ok = g.quotes.BestAsk()
+	} else {
+		quote, _, ok = g.quotes.BestBid()
+	}
+	if !ok {
+		if g.config.PassNoQuote {
+			return QuoteCheck{}, nil
+		}
+		return g.violation(QuoteCheck{Flagged: true, Reason: "no opposite quote"}, ErrNoQuote)
+	}
+
+	crossBy := order.Price - quote
+	if order.Side == SideSell {
+		crossBy = quote - order.Price
+	}
+	if crossBy <= g.config.MaxCross+volumeEpsilon {
+		return QuoteCheck{Reference: quote}, nil
+	}
+
+	reason := fmt.Sprintf("%s at %g crosses quote %g by %g (max %g)", order.Side, order.Price, quote, crossBy, g.config.MaxCross)
+	return g.violation(QuoteCheck{Flagged: true, Reference: quote, Reason: reason}, ErrCrossesQuote)
+}
+
+// violation returns the check as a flag or as an error depending on mode
+func (g *QuoteGuard) violation(check QuoteCheck, err error) (QuoteCheck, error) {
+	if g.config.Block {
+		return check, fmt.Errorf("%w: %s", err, check.Reason)
+	}
+	return check, nil
+}
@@ -0,0 +1,73 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+)
+
+func newQuotedBook(t *testing.T) *OrderBook {
+	t.Helper()
+	book := NewOrderBook("crude_oil")
+	for _, o := range []TradingOrder{
+		{OrderID: "bid_1", Side: SideBuy, Type: OrderTypeLimit, Price: 75.40, Volume: 100},
+		{OrderID: "ask_1", Side: SideSell, Type: OrderTypeLimit, Price: 75.60, Volume: 100},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Failed to seed book: %v", err)
+		}
+	}
+	return book
+}
+
+// TestQuoteGuardCrossing verifies reasonable crossing passes and unreasonable crossing is blocked
+func TestQuoteGuardCrossing(t *testing.T) {
+	guard := NewQuoteGuard(newQuotedBook(t), QuoteGuardConfig{MaxCross: 0.25, Block: true})
+
+	testCases := []struct {
+		name    string
+		order   TradingOrder
+		wantErr error
+	}{
+		{"buy within threshold", TradingOrder{Side: SideBuy, Price: 75.80}, nil},
+		{"sell within threshold", TradingOrder{Side: SideSell, Price: 75.20}, nil},
+		{"buy below ask", TradingOrder{Side: SideBuy, Price: 75.00}, nil},
+		{"buy far above ask", TradingOrder{Side: SideBuy, Price: 80.00}, ErrCrossesQuote},
+		{"sell far below bid", TradingOrder{Side: SideSell, Price: 70.00}, ErrCrossesQuote},
+		{"market order", TradingOrder{Side: SideBuy, Type: OrderTypeMarket}, nil},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := guard.Check(tc.order)
+			if !errors.Is(err, tc.wantErr) {
+				t.Errorf("Expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
+
+// TestQuoteGuardFlagMode verifies non-blocking mode flags without rejecting
+func TestQuoteGuardFlagMode(t *testing.T) {
+	guard := NewQuoteGuard(newQuotedBook(t), QuoteGuardConfig{MaxCross: 0.25})
+
+	check, err := guard.Check(TradingOrder{Side: SideBuy, Price: 80.00})
+	if err != nil {
+		t.Fatalf("Expected flag mode not to reject, got %v", err)
+	}
+	if !check.Flagged || check.Reference != 75.60 {
+		t.Errorf("Expected flagged check against ask 75.60, got %+v", check)
+	}
+}
+
+// TestQuoteGuardNoQuote verifies empty-book behavior is configurable
+func TestQuoteGuardNoQuote(t *testing.T) {
+	empty := NewOrderBook("natural_gas")
+	order := TradingOrder{Side: SideBuy, Price: 3.25}
+
+	if _, err := NewQuoteGuard(empty, QuoteGuardConfig{Block: true, PassNoQuote: true}).Check(order); err != nil {
+		t.Errorf("Expected no-quote pass, got %v", err)
+	}
+	if _, err := NewQuoteGuard(empty, QuoteGuardConfig{Block: true}).Check(order); !errors.Is(err, ErrNoQuote) {
+		t.Errorf("Expected ErrNoQuote, got %v", err)
+	}
+}
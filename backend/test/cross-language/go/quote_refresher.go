@@ -0,0 +1,148 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// QuoteFunc prices a two-sided quote around a reference mid. Market makers
+// supply their own so inventory skew is applied on every refresh.
+type QuoteFunc func(mid float64) (bid, ask float64)
+
+// SkewedQuote quotes halfSpread either side of mid, shifted against current
+// inventory by skewPerUnit so a long maker quotes lower and a short maker higher
+func SkewedQuote(halfSpread, skewPerUnit float64, inventory func() float64) QuoteFunc {
+	return func(mid float64) (float64, float64) {
+		shift := skewPerUnit * inventory()
+		return mid - halfSpread - shift, mid + halfSpread - shift
+	}
+}
+
+// MakerQuote is a market maker's live two-sided quote
+type MakerQuote struct {
+	ClientID    string
+	BidOrderID  string
+	AskOrderID  string
+	Bid         float64
+	Ask         float64
+	Volume      float64
+	PlacedMid   float64
+	RefreshedAt time.Time
+}
+
+type makerState struct {
+	MakerQuote
+	quote QuoteFunc
+}
+
+// QuoteRefresher cancels and replaces market-maker quotes once the reference
+// mid has moved at least Threshold from where they were placed. A quote is
+// refreshed at most once per MinInterval so fast markets cannot cause
+// unbounded cancel/replace churn.
+type QuoteRefresher struct {
+	mu          sync.Mutex
+	book        *OrderBook
+	threshold   float64
+	minInterval time.Duration
+	clock       func() time.Time
+	makers      map[string]*makerState
+	seq         int
+}
+
+// NewQuoteRefresher creates a refresher placing quotes on book
+func NewQuoteRefresher(book *OrderBook, threshold float64, minInterval time.Duration, clock func() time.Time) *QuoteRefresher {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &QuoteRefresher{
+		book:        book,
+		threshold:   threshold,
+		minInterval: minInterval,
+		clock:       clock,
+		makers:      make(map[string]*makerState),
+	}
+}
+
+// Place quotes volume on both sides around mid and starts tracking the quote
+func (r *QuoteRefresher) Place(clientID string, volume, mid float64, quote QuoteFunc) (MakerQuote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.makers[clientID]; exists {
+		return MakerQuote{}, fmt.Errorf("%w: maker %s already quoting", ErrDuplicateOrder, clientID)
+	}
+	state := &makerState{MakerQuote: MakerQuote{ClientID: clientID, Volume: volume}, quote: quote}
+	if err := r.placeLocked(state, mid); err != nil {
+		return MakerQuote{}, err
+	}
+	r.makers[clientID] = state
+	return state.MakerQuote, nil
+}
+
+// OnMid handles a new reference mid and returns the quotes it refreshed
+func (r *QuoteRefresher) OnMid(mid float64) ([]MakerQuote, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.clock()
+	var refreshed []MakerQuote
+	for _, clientID := range sortedMakerIDs(r.makers) {
+		state := r.makers[clientID]
+		if math.Abs(mid-state.PlacedMid) < r.threshold-volumeEpsilon || now.Sub(state.RefreshedAt) < r.minInterval {
+			continue
+		}
+		for _, id := range []string{state.BidOrderID, state.AskOrderID} {
+			if err := r.book.Cancel(id); err != nil && !errors.Is(err, ErrOrderNotFound) {
+				return refreshed, err
+			}
+		}
+		if err := r.placeLocked(state, mid); err != nil {
+			return refreshed, err
+		}
+		refreshed = append(refreshed, state.MakerQuote)
+	}
+	return refreshed, nil
+}
+
+// Quote returns a maker's current quote
+func (r *QuoteRefresher) Quote(clientID string) (MakerQuote, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	state, ok := r.makers[clientID]
+	if !ok {
+		return MakerQuote{}, false
+	}
+	return state.MakerQuote, true
+}
+
+func (r *QuoteRefresher) placeLocked(state *makerState, mid float64) error {
+	bid, ask := state.quote(mid)
+	r.seq++
+	bidID := fmt.Sprintf("%s-bid-%d", state.ClientID, r.seq)
+	askID := fmt.Sprintf("%s-ask-%d", state.ClientID, r.seq)
+	if _, err := r.book.Add(TradingOrder{OrderID: bidID, ClientID: state.ClientID, Side: SideBuy, Type: OrderTypeLimit, Price: bid, Volume: state.Volume}); err != nil {
+		return err
+	}
+	if _, err := r.book.Add(TradingOrder{OrderID: askID, ClientID: state.ClientID, Side: SideSell, Type: OrderTypeLimit, Price: ask, Volume: state.Volume}); err != nil {
+		r.book.Cancel(bidID)
+		return err
+	}
+	state.BidOrderID, state.AskOrderID = bidID, askID
+	state.Bid, state.Ask = bid, ask
+	state.PlacedMid = mid
+	state.RefreshedAt = r.clock()
+	return nil
+}
+
+func sortedMakerIDs(makers map[string]*makerState) []string {
+	ids := make([]string, 0, len(makers))
+	for id := range makers {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestQuoteRefresherRefreshesAtThreshold verifies cancel/replace happens once the mid moves far enough
+func TestQuoteRefresherRefreshesAtThreshold(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	book := NewOrderBook("crude_oil")
+	refresher := NewQuoteRefresher(book, 0.10, time.Second, func() time.Time { return now })
+	inventory := 20.0
+	quote := SkewedQuote(0.05, 0.001, func() float64 { return inventory })
+
+	if _, err := refresher.Place("mm1", 10, 75.00, quote); err != nil {
+		t.Fatalf("Failed to place quote: %v", err)
+	}
+
+	steps := []struct {
+		mid     float64
+		advance time.Duration
+		refresh bool
+	}{
+		{75.05, 2 * time.Second, false},        // below threshold
+		{75.10, 2 * time.Second, true},         // at threshold
+		{75.30, 500 * time.Millisecond, false}, // moved but inside min interval
+		{75.30, time.Second, true},             // interval elapsed
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		refreshed, err := refresher.OnMid(step.mid)
+		if err != nil {
+			t.Fatalf("Step %d: unexpected error %v", i, err)
+		}
+		if (len(refreshed) == 1) != step.refresh {
+			t.Fatalf("Step %d at mid %.2f: expected refresh=%v, got %+v", i, step.mid, step.refresh, refreshed)
+		}
+	}
+
+	q, _ := refresher.Quote("mm1")
+	// Long 20 lots skews both sides down by 0.02
+	if math.Abs(q.Bid-75.23) > 1e-9 || math.Abs(q.Ask-75.33) > 1e-9 {
+		t.Errorf("Expected skewed quote 75.23/75.33, got %.4f/%.4f", q.Bid, q.Ask)
+	}
+	if bid, _, _ := book.BestBid(); bid != q.Bid {
+		t.Errorf("Expected book best bid %.4f, got %.4f", q.Bid, bid)
+	}
+	if snap := book.Snapshot(); len(snap.Bids) != 1 || len(snap.Asks) != 1 {
+		t.Errorf("Expected stale quotes to be cancelled, got %+v", snap)
+	}
+}
@@ -0,0 +1,120 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// RateAnomalyConfig configures order rate anomaly detection
+type RateAnomalyConfig struct {
+	// Window is the bucket over which order rates are measured
+	Window time.Duration
+	// Multiple of the baseline rate that counts as a spike
+	Multiple float64
+	// Alpha is the EWMA weight of each completed window; small values adapt slowly
+	Alpha float64
+	// WarmupWindows must complete before a client can alert
+	WarmupWindows int
+	// MinOrders in a window before a spike is considered, so quiet clients
+	// with a near-zero baseline do not alert on a handful of orders
+	MinOrders int
+}
+
+// RateAnomaly reports a client whose order rate spiked above its baseline
+type RateAnomaly struct {
+	ClientID string
+	Observed float64 // orders per second in the current window
+	Expected float64 // baseline orders per second
+	At       time.Time
+}
+
+// Alert converts the anomaly into a notifier alert
+func (a RateAnomaly) Alert() Alert {
+	return Alert{
+		Severity:  SeverityWarning,
+		Title:     "order rate anomaly: " + a.ClientID,
+		Detail:    fmt.Sprintf("observed %.1f orders/s against expected %.1f orders/s", a.Observed, a.Expected),
+		Timestamp: a.At,
+	}
+}
+
+type clientRate struct {
+	bucketStart time.Time
+	count       int
+	baseline    float64 // orders per window
+	windows     int
+	alerted     bool
+}
+
+// RateAnomalyDetector models each client's normal order rate as an EWMA of
+// per-window counts and flags a window whose count exceeds the configured
+// multiple of that baseline. The baseline only absorbs completed windows, so
+// a burst cannot raise its own threshold, and at most one anomaly is
+// reported per client per window.
+type RateAnomalyDetector struct {
+	mu      sync.Mutex
+	config  RateAnomalyConfig
+	clients map[string]*clientRate
+}
+
+// NewRateAnomalyDetector creates a detector
+func NewRateAnomalyDetector(config RateAnomalyConfig) *RateAnomalyDetector {
+	if config.Window <= 0 {
+		config.Window = time.Second
+	}
+	if config.Alpha <= 0 || config.Alpha > 1 {
+		config.Alpha = 0.1
+	}
+	return &RateAnomalyDetector{config: config, clients: make(map[string]*clientRate)}
+}
+
+// Observe records an order from a client at time at and reports an anomaly
+// the first time the current window exceeds the client's threshold
+func (d *RateAnomalyDetector) Observe(clientID string, at time.Time) (RateAnomaly, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	window := d.config.Window
+	state, ok := d.clients[clientID]
+	if !ok {
+		state = &clientRate{bucketStart: at.Truncate(window)}
+		d.clients[clientID] = state
+	}
+	if elapsed := at.Sub(state.bucketStart); elapsed >= window {
+		d.closeWindow(state, float64(state.count))
+		// Fully idle windows since then decay the baseline toward zero
+		idle := int(elapsed/window) - 1
+		state.baseline *= math.Pow(1-d.config.Alpha, float64(idle))
+		state.windows += idle
+		state.bucketStart = state.bucketStart.Add(time.Duration(idle+1) * window)
+		state.count = 0
+		state.alerted = false
+	}
+	state.count++
+
+	if state.alerted || state.windows < d.config.WarmupWindows || state.count < d.config.MinOrders {
+		return RateAnomaly{}, false
+	}
+	if float64(state.count) <= d.config.Multiple*state.baseline {
+		return RateAnomaly{}, false
+	}
+	state.alerted = true
+	perSecond := float64(time.Second) / float64(window)
+	return RateAnomaly{
+		ClientID: clientID,
+		Observed: float64(state.count) * perSecond,
+		Expected: state.baseline * perSecond,
+		At:       at,
+	}, true
+}
+
+func (d *RateAnomalyDetector) closeWindow(state *clientRate, count float64) {
+	if state.windows == 0 {
+		state.baseline = count
+	} else {
+		state.baseline = d.config.Alpha*count + (1-d.config.Alpha)*state.baseline
+	}
+	state.windows++
+}
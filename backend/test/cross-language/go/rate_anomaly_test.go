@@ -0,0 +1,63 @@
+package integration
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func rateAnomalyConfig() RateAnomalyConfig {
+	return RateAnomalyConfig{Window: time.Second, Multiple: 3, Alpha: 0.1, WarmupWindows: 5, MinOrders: 10}
+}
+
+// feedOrders submits n orders evenly spread across the window starting at start
+func feedOrders(d *RateAnomalyDetector, client string, start time.Time, n int) []RateAnomaly {
+	var anomalies []RateAnomaly
+	for i := 0; i < n; i++ {
+		if a, ok := d.Observe(client, start.Add(time.Duration(i)*time.Second/time.Duration(n))); ok {
+			anomalies = append(anomalies, a)
+		}
+	}
+	return anomalies
+}
+
+// TestRateAnomalyDetectorBurst verifies a sudden burst raises a single alert
+func TestRateAnomalyDetectorBurst(t *testing.T) {
+	detector := NewRateAnomalyDetector(rateAnomalyConfig())
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	for w := 0; w < 30; w++ {
+		if a := feedOrders(detector, "algo-7", start.Add(time.Duration(w)*time.Second), 10); len(a) != 0 {
+			t.Fatalf("Window %d: unexpected anomaly at steady rate: %+v", w, a)
+		}
+	}
+
+	anomalies := feedOrders(detector, "algo-7", start.Add(30*time.Second), 200)
+	if len(anomalies) != 1 {
+		t.Fatalf("Expected one anomaly for the burst, got %d", len(anomalies))
+	}
+	a := anomalies[0]
+	if a.Observed != 31 || a.Expected != 10 {
+		t.Errorf("Expected alert at 31 orders/s against 10 expected, got %+v", a)
+	}
+	if detail := a.Alert().Detail; !strings.Contains(detail, "observed 31.0") || !strings.Contains(detail, "expected 10.0") {
+		t.Errorf("Expected alert detail to carry both rates, got %q", detail)
+	}
+}
+
+// TestRateAnomalyDetectorGradualGrowth verifies a slowly rising rate adapts the baseline without alerting
+func TestRateAnomalyDetectorGradualGrowth(t *testing.T) {
+	detector := NewRateAnomalyDetector(rateAnomalyConfig())
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	rate := 10.0
+	for w := 0; w < 120; w++ {
+		if a := feedOrders(detector, "algo-7", start.Add(time.Duration(w)*time.Second), int(rate)); len(a) != 0 {
+			t.Fatalf("Window %d at %.0f orders/s: unexpected anomaly %+v", w, rate, a)
+		}
+		rate *= 1.02
+	}
+	if rate < 100 {
+		t.Fatalf("Expected the rate to grow tenfold, got %.0f", rate)
+	}
+}
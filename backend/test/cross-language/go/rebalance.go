@@ -0,0 +1,76 @@
+package integration
+
+import (
+	"math"
+	"sort"
+)
+
+// Rebalancer computes the orders that move a portfolio to target weights
+type Rebalancer struct {
+	// Specs supplies lot sizes; volumes are rounded to whole lots
+	Specs *ContractSpecs
+	// MinTradeValue skips trades whose notional is below this amount
+	MinTradeValue float64
+}
+
+// Rebalance returns buy and sell orders, sorted by commodity, that move the
+// current positions toward the target weights. Portfolio value is the
+// marked value of the current positions. Commodities held but absent from
+// the targets are sold down to zero; commodities without a price are skipped.
+func (r *Rebalancer) Rebalance(current, targets, prices map[string]float64) []TradingOrder {
+	total := 0.0
+	for commodity, volume := range current {
+		total += volume * prices[commodity]
+	}
+
+	commodities := make(map[string]bool, len(current)+len(targets))
+	for commodity := range current {
+		commodities[commodity] = true
+	}
+	for commodity := range targets {
+		commodities[commodity] = true
+	}
+	names := make([]string, 0, len(commodities))
+	for commodity := range commodities {
+		names = append(names, commodity)
+	}
+	sort.Strings(names)
+
+	var orders []TradingOrder
+	for _, commodity := range names {
+		price, ok := prices[commodity]
+		if !ok || price <= 0 {
+			continue
+		}
+		delta := total*targets[commodity]/price - current[commodity]
+		delta = r.roundToLot(commodity, delta)
+		if math.Abs(delta) < volumeEpsilon || math.Abs(delta)*price < r.MinTradeValue {
+			continue
+		}
+		side := SideBuy
+		if delta < 0 {
+			side = SideSell
+		}
+		orders = append(orders, TradingOrder{
+			OrderID:   "rebalance-" + commodity,
+			Commodity: commodity,
+			Volume:    math.Abs(delta),
+			Price:     price,
+			Side:      side,
+			Type:      OrderTypeLimit,
+		})
+	}
+	return orders
+}
+
+// roundToLot rounds a signed volume to the nearest whole lot
+func (r *Rebalancer) roundToLot(commodity string, volume float64) float64 {
+	if r.Specs == nil {
+		return volume
+	}
+	spec, ok := r.Specs.Get(commodity)
+	if !ok || spec.LotSize <= 0 {
+		return volume
+	}
+	return math.Round(volume/spec.LotSize) * spec.LotSize
+}
@@ -0,0 +1,54 @@
+package integration
+
+import (
+	"math"
+	"testing"
+)
+
+// TestRebalanceRestoresTargets verifies drifted weights are traded back to target
+func TestRebalanceRestoresTargets(t *testing.T) {
+	rebalancer := &Rebalancer{
+		Specs: NewContractSpecs(
+			ContractSpec{Commodity: "crude_oil", LotSize: 10},
+			ContractSpec{Commodity: "natural_gas", LotSize: 100},
+			ContractSpec{Commodity: "heating_oil", LotSize: 10},
+		),
+		MinTradeValue: 500,
+	}
+	prices := map[string]float64{"crude_oil": 80, "natural_gas": 2.5, "heating_oil": 2.4}
+	// Crude rallied: 700*80 = 56000, gas 10000*2.5 = 25000, heating oil 8300*2.4 = 19920 (total 100920)
+	current := map[string]float64{"crude_oil": 700, "natural_gas": 10000, "heating_oil": 8300}
+	targets := map[string]float64{"crude_oil": 0.5, "natural_gas": 0.3, "heating_oil": 0.2}
+
+	orders := rebalancer.Rebalance(current, targets, prices)
+	if len(orders) != 2 {
+		t.Fatalf("Expected heating oil to be skipped below the trade threshold, got %+v", orders)
+	}
+	if o := orders[0]; o.Commodity != "crude_oil" || o.Side != SideSell || o.Volume != 70 {
+		t.Errorf("Expected to sell 70 crude_oil, got %+v", o)
+	}
+	if o := orders[1]; o.Commodity != "natural_gas" || o.Side != SideBuy || o.Volume != 2100 {
+		t.Errorf("Expected to buy 2100 natural_gas, got %+v", o)
+	}
+
+	after := map[string]float64{}
+	for k, v := range current {
+		after[k] = v
+	}
+	for _, o := range orders {
+		if o.Side == SideBuy {
+			after[o.Commodity] += o.Volume
+		} else {
+			after[o.Commodity] -= o.Volume
+		}
+	}
+	total := 0.0
+	for k, v := range after {
+		total += v * prices[k]
+	}
+	for commodity, target := range targets {
+		if weight := after[commodity] * prices[commodity] / total; math.Abs(weight-target) > 0.01 {
+			t.Errorf("Expected %s weight near %.2f after rebalance, got %.4f", commodity, target, weight)
+		}
+	}
+}
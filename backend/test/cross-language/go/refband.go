@@ -0,0 +1,128 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrPriceBandPaused is returned for an order held for review because it
+// would have executed outside the reference price band
+var ErrPriceBandPaused = errors.New("execution outside reference price band, order paused for review")
+
+// ReferencePriceFunc returns the price executions are checked against, such
+// as an index or the last trade, and false when there is none
+type ReferencePriceFunc func() (float64, bool)
+
+// PausedOrder is an aggressive order held off the book for review
+type PausedOrder struct {
+	Order     TradingOrder `json:"order"`
+	Reference float64      `json:"reference"`
+	Price     float64      `json:"price"` // first execution price outside the band
+	PausedAt  time.Time    `json:"paused_at"`
+}
+
+// Alert converts the paused order into a notifier alert for the reviewer
+func (p PausedOrder) Alert() Alert {
+	return Alert{
+		Severity:  SeverityWarning,
+		Commodity: p.Order.Commodity,
+		Title:     "order paused outside price band: " + p.Order.OrderID,
+		Detail: fmt.Sprintf("%s %g would execute at %g against reference %g (%.2f%% away)",
+			p.Order.Side, p.Order.Volume, p.Price, p.Reference, 100*math.Abs(p.Price-p.Reference)/p.Reference),
+		Timestamp: p.PausedAt,
+	}
+}
+
+// WithReferenceBand checks every incoming order that would cross the book
+// against a reference price. If any execution it would make is priced more
+// than band (a fraction, e.g. 0.05 for 5%) from the reference, nothing is
+// executed: the order is paused for review, onPause is told, and Add
+// returns ErrPriceBandPaused. A reviewer then releases it with
+// ReleasePaused or rejects it with Cancel. Orders are not checked when the
+// reference has no price. reference and onPause are called with the book
+// locked and must not call back into it.
+func WithReferenceBand(reference ReferencePriceFunc, band float64, onPause func(PausedOrder)) BookOption {
+	return func(b *OrderBook) {
+		b.refPrice = reference
+		b.refBand = band
+		b.onPause = onPause
+	}
+}
+
+// bandLocked reports whether order would execute outside the reference
+// band, walking the levels it would take as matching would
+func (b *OrderBook) bandLocked(order TradingOrder) (PausedOrder, bool) {
+	if b.refPrice == nil || b.refBand <= 0 || b.auction || order.Type == OrderTypeMarketOnClose {
+		return PausedOrder{}, false
+	}
+	reference, ok := b.refPrice()
+	if !ok || reference <= 0 {
+		return PausedOrder{}, false
+	}
+	b.collarLocked(&order)
+	opposite := b.asks
+	if order.Side == SideSell {
+		opposite = b.bids
+	}
+	remaining := order.Volume
+	for _, level := range opposite {
+		if remaining <= volumeEpsilon || !crosses(&order, level.price) {
+			break
+		}
+		if math.Abs(level.price-reference) > b.refBand*reference+volumeEpsilon {
+			return PausedOrder{Order: order, Reference: reference, Price: level.price}, true
+		}
+		for _, o := range level.orders {
+			remaining -= o.Volume
+		}
+	}
+	return PausedOrder{}, false
+}
+
+// pauseLocked holds an order for review
+func (b *OrderBook) pauseLocked(paused PausedOrder) {
+	b.record(BookEvent{Type: BookEventPause, OrderID: paused.Order.OrderID, Order: &paused.Order,
+		Price: paused.Price, Reference: paused.Reference})
+	paused.PausedAt = b.opTime
+	b.paused = append(b.paused, paused)
+	b.changedLocked()
+	if b.onPause != nil {
+		b.onPause(paused)
+	}
+}
+
+// pausedIndex returns the position of a paused order, or -1
+func (b *OrderBook) pausedIndex(orderID string) int {
+	for i, p := range b.paused {
+		if p.Order.OrderID == orderID {
+			return i
+		}
+	}
+	return -1
+}
+
+// PausedOrders returns the orders awaiting review in the order they paused
+func (b *OrderBook) PausedOrders() []PausedOrder {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]PausedOrder(nil), b.paused...)
+}
+
+// ReleasePaused approves a paused order, which is matched at once against
+// the book as it now stands without a further band check
+func (b *OrderBook) ReleasePaused(orderID string) ([]Trade, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	i := b.pausedIndex(orderID)
+	if i < 0 {
+		return nil, fmt.Errorf("%w: no paused order %s", ErrOrderNotFound, orderID)
+	}
+	order := b.paused[i].Order
+	b.paused = append(b.paused[:i], b.paused[i+1:]...)
+	b.record(BookEvent{Type: BookEventRelease, OrderID: orderID})
+	b.metrics.OrderAdded(b.commodity)
+	return b.addLocked(order), nil
+}
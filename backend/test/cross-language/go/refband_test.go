@@ -0,0 +1,186 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestReferenceBandPausesOutOfBandCross verifies a cross that would
+// execute outside the band is paused with nothing traded, and that review
+// can release or reject it
+func TestReferenceBandPausesOutOfBandCross(t *testing.T) {
+	now := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	reference := 75.0
+	var flagged []PausedOrder
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil",
+		WithClock(func() time.Time { return now }),
+		WithEventLog(log),
+		WithReferenceBand(func() (float64, bool) { return reference, true }, 0.02,
+			func(p PausedOrder) { flagged = append(flagged, p) }))
+
+	for _, o := range []TradingOrder{
+		{OrderID: "s1", Side: SideSell, Price: 75.5, Volume: 10},
+		{OrderID: "s2", Side: SideSell, Price: 76.0, Volume: 10},
+		{OrderID: "s3", Side: SideSell, Price: 78.0, Volume: 10}, // 4% above the reference
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Expected %s to rest, got %v", o.OrderID, err)
+		}
+	}
+
+	// Within the band the cross executes normally
+	trades, err := book.Add(TradingOrder{OrderID: "b1", Side: SideBuy, Type: OrderTypeMarket, Volume: 5})
+	if err != nil || len(trades) != 1 || trades[0].Price != 75.5 {
+		t.Fatalf("Expected an in-band fill at 75.5, got %+v (%v)", trades, err)
+	}
+
+	// Sweeping to 78 would breach the band, so nothing executes
+	now = now.Add(time.Second)
+	trades, err = book.Add(TradingOrder{OrderID: "b2", Side: SideBuy, Price: 78, Volume: 30})
+	if !errors.Is(err, ErrPriceBandPaused) || len(trades) != 0 {
+		t.Fatalf("Expected the order to pause without trades, got %+v (%v)", trades, err)
+	}
+	if _, vol, _ := book.BestAsk(); vol != 5 {
+		t.Errorf("Expected the book untouched with 5 left at the best ask, got %g", vol)
+	}
+	if len(flagged) != 1 || flagged[0].Price != 78 || flagged[0].Reference != 75 || !flagged[0].PausedAt.Equal(now) {
+		t.Fatalf("Expected b2 flagged at 78 against 75, got %+v", flagged)
+	}
+	if alert := flagged[0].Alert(); alert.Commodity != "crude_oil" || alert.Title != "order paused outside price band: b2" {
+		t.Errorf("Expected a review alert for b2, got %+v", alert)
+	}
+	if paused := book.PausedOrders(); len(paused) != 1 || paused[0].Order.OrderID != "b2" {
+		t.Errorf("Expected b2 awaiting review, got %+v", paused)
+	}
+	if _, err := book.Add(TradingOrder{OrderID: "b2", Side: SideBuy, Price: 75, Volume: 1}); !errors.Is(err, ErrDuplicateOrder) {
+		t.Errorf("Expected a paused order id to stay reserved, got %v", err)
+	}
+
+	// A reviewer approves it and it sweeps the book as it now stands
+	trades, err = book.ReleasePaused("b2")
+	if err != nil || len(trades) != 3 {
+		t.Fatalf("Expected the released order to sweep three orders, got %+v (%v)", trades, err)
+	}
+	if len(book.PausedOrders()) != 0 {
+		t.Error("Expected no paused orders after release")
+	}
+
+	// A rejected order is simply cancelled
+	if _, err := book.Add(TradingOrder{OrderID: "s4", Side: SideSell, Price: 80, Volume: 10}); err != nil {
+		t.Fatalf("Expected s4 to rest, got %v", err)
+	}
+	if _, err := book.Add(TradingOrder{OrderID: "b3", Side: SideBuy, Type: OrderTypeMarket, Volume: 5}); !errors.Is(err, ErrPriceBandPaused) {
+		t.Fatalf("Expected b3 to pause, got %v", err)
+	}
+	if err := book.Cancel("b3"); err != nil || len(book.PausedOrders()) != 0 {
+		t.Errorf("Expected cancelling b3 to reject it, got %v", err)
+	}
+
+	// The log replays the review decisions without the band configured
+	rebuilt, err := Rebuild(log)
+	if err != nil {
+		t.Fatalf("Expected rebuild to succeed, got %v", err)
+	}
+	if got, want := rebuilt.Snapshot(), book.Snapshot(); len(got.Asks) != len(want.Asks) || got.Asks[0] != want.Asks[0] {
+		t.Errorf("Expected rebuilt asks %+v, got %+v", want.Asks, got.Asks)
+	}
+}
+
+// TestReferenceBandChecksSimulateAndAmend verifies a simulated order and an
+// amendment that would trade outside the band are refused like a new order
+func TestReferenceBandChecksSimulateAndAmend(t *testing.T) {
+	log := NewMemoryEventLog()
+	var flagged []PausedOrder
+	book := NewOrderBook("crude_oil", WithEventLog(log),
+		WithReferenceBand(func() (float64, bool) { return 75.0, true }, 0.02,
+			func(p PausedOrder) { flagged = append(flagged, p) }))
+	for _, o := range []TradingOrder{
+		{OrderID: "s1", Side: SideSell, Price: 75.5, Volume: 10},
+		{OrderID: "s2", Side: SideSell, Price: 78.0, Volume: 10}, // 4% above the reference
+		{OrderID: "b1", Side: SideBuy, Price: 74.0, Volume: 15},
+	} {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Expected %s to rest, got %v", o.OrderID, err)
+		}
+	}
+	before := book.Snapshot()
+
+	trades, err := book.Simulate(TradingOrder{OrderID: "sim", Side: SideBuy, Price: 78, Volume: 15})
+	if !errors.Is(err, ErrPriceBandPaused) || len(trades) != 0 {
+		t.Fatalf("Expected the simulation to report a pause, got %+v (%v)", trades, err)
+	}
+	if len(flagged) != 0 || len(book.PausedOrders()) != 0 {
+		t.Errorf("Expected a simulation to pause nothing, got %+v", flagged)
+	}
+
+	trades, err = book.Amend("b1", 78, 15)
+	if !errors.Is(err, ErrPriceBandPaused) || len(trades) != 0 {
+		t.Fatalf("Expected the amendment to pause, got %+v (%v)", trades, err)
+	}
+	if len(flagged) != 1 || flagged[0].Order.OrderID != "b1" || flagged[0].Order.Price != 78 || flagged[0].Price != 78 {
+		t.Errorf("Expected b1 paused at its amended price, got %+v", flagged)
+	}
+	if _, ok := book.Order("b1"); ok {
+		t.Error("Expected the paused order off the book")
+	}
+	if got := book.Snapshot(); len(got.Asks) != len(before.Asks) || got.Asks[0] != before.Asks[0] {
+		t.Errorf("Expected the offers untouched, got %+v", got.Asks)
+	}
+
+	rebuilt, err := Rebuild(log)
+	if err != nil {
+		t.Fatalf("Expected rebuild to succeed, got %v", err)
+	}
+	if paused := rebuilt.PausedOrders(); len(paused) != 1 || paused[0].Order.OrderID != "b1" {
+		t.Errorf("Expected b1 paused after replay, got %+v", paused)
+	}
+	if _, ok := rebuilt.Order("b1"); ok {
+		t.Error("Expected the paused order off the rebuilt book")
+	}
+}
+
+// TestReferenceBandBulkCancelsSweepPaused verifies client and book-wide
+// cancels and the kill switch take paused orders out of review
+func TestReferenceBandBulkCancelsSweepPaused(t *testing.T) {
+	log := NewMemoryEventLog()
+	book := NewOrderBook("crude_oil", WithEventLog(log),
+		WithReferenceBand(func() (float64, bool) { return 75.0, true }, 0.02, nil))
+	for _, o := range []TradingOrder{
+		{OrderID: "s1", ClientID: "gulf", Side: SideSell, Price: 78.0, Volume: 10}, // 4% above the reference
+		{OrderID: "b1", ClientID: "acme", Side: SideBuy, Price: 78.0, Volume: 5},
+		{OrderID: "b2", ClientID: "delta", Side: SideBuy, Price: 78.0, Volume: 5},
+	} {
+		if _, err := book.Add(o); err != nil && !errors.Is(err, ErrPriceBandPaused) {
+			t.Fatalf("Add %s failed: %v", o.OrderID, err)
+		}
+	}
+	if len(book.PausedOrders()) != 2 {
+		t.Fatalf("Expected b1 and b2 paused, got %+v", book.PausedOrders())
+	}
+
+	if n := book.CancelAllForClient("acme"); n != 1 {
+		t.Errorf("Expected acme's paused order cancelled, got %d", n)
+	}
+	if _, err := book.ReleasePaused("b1"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("Expected b1 gone from review, got %v", err)
+	}
+
+	ks := NewCommodityKillSwitch(nil, book)
+	cancelled := ks.Engage("crude_oil", KillSwitchOptions{CancelResting: true})
+	if len(cancelled) != 2 || cancelled[0].OrderID != "s1" || cancelled[1].OrderID != "b2" {
+		t.Errorf("Expected s1 then the paused b2 cancelled, got %+v", cancelled)
+	}
+	if len(book.PausedOrders()) != 0 {
+		t.Errorf("Expected nothing left to review, got %+v", book.PausedOrders())
+	}
+
+	rebuilt, err := Rebuild(log)
+	if err != nil {
+		t.Fatalf("Expected rebuild to succeed, got %v", err)
+	}
+	if len(rebuilt.PausedOrders()) != 0 || len(rebuilt.Snapshot().Asks) != 0 {
+		t.Errorf("Expected an empty rebuilt book, got %+v", rebuilt.Snapshot())
+	}
+}
@@ -0,0 +1,149 @@
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// sharedPoolTenant keys the books shared by shared-pool participants
+const sharedPoolTenant = "*shared*"
+
+type registryKey struct {
+	tenant    string
+	commodity string
+}
+
+// TenantStats summarises a tenant's order books
+type TenantStats struct {
+	Tenant        string   `json:"tenant"`
+	Commodities   []string `json:"commodities"`
+	RestingOrders int      `json:"resting_orders"`
+}
+
+// BookRegistry isolates order flow per tenant by keeping a separate book
+// for each tenant and commodity. Tenants configured as shared-pool
+// participants trade in a common book per commodity instead, and only with
+// each other. Every operation is scoped by tenant ID.
+type BookRegistry struct {
+	mu     sync.Mutex
+	opts   []BookOption
+	books  map[registryKey]*OrderBook
+	shared map[string]bool
+	owners map[registryKey]map[string]bool // tenant+commodity -> order IDs placed in the shared pool
+}
+
+// NewBookRegistry creates a registry; opts are applied to every book it creates
+func NewBookRegistry(opts ...BookOption) *BookRegistry {
+	return &BookRegistry{
+		opts:   opts,
+		books:  make(map[registryKey]*OrderBook),
+		shared: make(map[string]bool),
+		owners: make(map[registryKey]map[string]bool),
+	}
+}
+
+// SetSharedPool opts a tenant in or out of the shared pool. It applies to
+// orders submitted afterwards; resting orders stay where they are.
+func (r *BookRegistry) SetSharedPool(tenant string, shared bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.shared[tenant] = shared
+}
+
+// Submit adds an order to the tenant's book for its commodity
+func (r *BookRegistry) Submit(tenant string, order TradingOrder) ([]Trade, error) {
+	r.mu.Lock()
+	book := r.bookLocked(tenant, order.Commodity)
+	shared := r.shared[tenant]
+	r.mu.Unlock()
+
+	trades, err := book.Add(order)
+	if err != nil || !shared {
+		return trades, err
+	}
+	r.mu.Lock()
+	key := registryKey{tenant, order.Commodity}
+	if r.owners[key] == nil {
+		r.owners[key] = make(map[string]bool)
+	}
+	r.owners[key][order.OrderID] = true
+	r.mu.Unlock()
+	return trades, nil
+}
+
+// Cancel cancels a tenant's order. Orders belonging to other tenants are
+// reported as not found.
+func (r *BookRegistry) Cancel(tenant, commodity, orderID string) error {
+	r.mu.Lock()
+	key := registryKey{tenant, commodity}
+	owned := r.owners[key][orderID]
+	book, isolated := r.books[key]
+	if owned {
+		book = r.books[registryKey{sharedPoolTenant, commodity}]
+		delete(r.owners[key], orderID)
+	}
+	r.mu.Unlock()
+
+	if !owned && !isolated {
+		return fmt.Errorf("%w: %s", ErrOrderNotFound, orderID)
+	}
+	return book.Cancel(orderID)
+}
+
+// Snapshot returns the book the tenant currently trades in for a commodity
+func (r *BookRegistry) Snapshot(tenant, commodity string) BookSnapshot {
+	r.mu.Lock()
+	book := r.bookLocked(tenant, commodity)
+	r.mu.Unlock()
+	return book.Snapshot()
+}
+
+// Stats returns counts limited to the tenant's own books and orders
+func (r *BookRegistry) Stats(tenant string) TenantStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stats := TenantStats{Tenant: tenant}
+	commodities := make(map[string]bool)
+	for key, book := range r.books {
+		if key.tenant != tenant {
+			continue
+		}
+		commodities[key.commodity] = true
+		snap := book.Snapshot()
+		for _, level := range append(snap.Bids, snap.Asks...) {
+			stats.RestingOrders += level.Orders
+		}
+	}
+	for key, ids := range r.owners {
+		if key.tenant != tenant {
+			continue
+		}
+		book := r.books[registryKey{sharedPoolTenant, key.commodity}]
+		for id := range ids {
+			if _, resting := book.Order(id); resting {
+				stats.RestingOrders++
+				commodities[key.commodity] = true
+			}
+		}
+	}
+	for commodity := range commodities {
+		stats.Commodities = append(stats.Commodities, commodity)
+	}
+	sort.Strings(stats.Commodities)
+	return stats
+}
+
+func (r *BookRegistry) bookLocked(tenant, commodity string) *OrderBook {
+	key := registryKey{tenant, commodity}
+	if r.shared[tenant] {
+		key.tenant = sharedPoolTenant
+	}
+	book, ok := r.books[key]
+	if !ok {
+		book = NewOrderBook(commodity, r.opts...)
+		r.books[key] = book
+	}
+	return book
+}
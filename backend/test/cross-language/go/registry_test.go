@@ -0,0 +1,49 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestBookRegistryIsolatesTenants verifies orders never match across tenants unless both share the pool
+func TestBookRegistryIsolatesTenants(t *testing.T) {
+	registry := NewBookRegistry()
+
+	if _, err := registry.Submit("desk-a", TradingOrder{OrderID: "a-ask", Commodity: "crude_oil", Side: SideSell, Price: 75.50, Volume: 10}); err != nil {
+		t.Fatalf("Submit failed: %v", err)
+	}
+	trades, err := registry.Submit("desk-b", TradingOrder{OrderID: "b-bid", Commodity: "crude_oil", Side: SideBuy, Price: 75.60, Volume: 10})
+	if err != nil || len(trades) != 0 {
+		t.Fatalf("Expected no cross-tenant match, got %+v, %v", trades, err)
+	}
+
+	if snap := registry.Snapshot("desk-b", "crude_oil"); len(snap.Asks) != 0 || len(snap.Bids) != 1 {
+		t.Errorf("Expected desk-b to see only its own bid, got %+v", snap)
+	}
+	if err := registry.Cancel("desk-b", "crude_oil", "a-ask"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("Expected desk-b unable to cancel desk-a's order, got %v", err)
+	}
+	if stats := registry.Stats("desk-a"); stats.RestingOrders != 1 || len(stats.Commodities) != 1 {
+		t.Errorf("Expected desk-a stats scoped to its own order, got %+v", stats)
+	}
+
+	registry.SetSharedPool("desk-c", true)
+	registry.SetSharedPool("desk-d", true)
+	registry.Submit("desk-c", TradingOrder{OrderID: "c-ask", Commodity: "crude_oil", Side: SideSell, Price: 75.50, Volume: 10})
+	trades, _ = registry.Submit("desk-d", TradingOrder{OrderID: "d-bid", Commodity: "crude_oil", Side: SideBuy, Price: 75.50, Volume: 4})
+	if len(trades) != 1 || trades[0].SellOrderID != "c-ask" {
+		t.Errorf("Expected shared-pool participants to match, got %+v", trades)
+	}
+	if stats := registry.Stats("desk-c"); stats.RestingOrders != 1 {
+		t.Errorf("Expected desk-c to own one resting shared order, got %+v", stats)
+	}
+	if stats := registry.Stats("desk-d"); stats.RestingOrders != 0 {
+		t.Errorf("Expected desk-d to own nothing resting, got %+v", stats)
+	}
+	if err := registry.Cancel("desk-d", "crude_oil", "c-ask"); !errors.Is(err, ErrOrderNotFound) {
+		t.Errorf("Expected desk-d unable to cancel desk-c's shared order, got %v", err)
+	}
+	if err := registry.Cancel("desk-c", "crude_oil", "c-ask"); err != nil {
+		t.Errorf("Expected desk-c to cancel its own shared order, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReplaySource replays recorded market data, preserving the original
+// inter-tick spacing scaled by a speed multiplier
+type ReplaySource struct {
+	ticks []MarketData
+
+	mu        sync.Mutex
+	pos       int
+	speed     float64
+	paused    bool
+	skipDelay bool
+	changed   chan struct{} // closed and replaced whenever controls change
+}
+
+// NewReplaySource creates a replay over ticks ordered by timestamp
+func NewReplaySource(ticks []MarketData) *ReplaySource {
+	sorted := make([]MarketData, len(ticks))
+	copy(sorted, ticks)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Timestamp.Before(sorted[j].Timestamp)
+	})
+	return &ReplaySource{
+		ticks:   sorted,
+		speed:   1,
+		changed: make(chan struct{}),
+	}
+}
+
+// SetSpeed changes the replay rate; 2 replays twice as fast. A multiplier
+// of zero or less replays without delays. The change applies from the next
+// tick onwards.
+func (r *ReplaySource) SetSpeed(multiplier float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.speed = multiplier
+	r.notifyLocked()
+}
+
+// Seek moves the replay to the first tick at or after t. That tick is
+// emitted immediately and pacing resumes from there. Seeking to the tick
+// already next in line changes nothing, so its wait is kept.
+func (r *ReplaySource) Seek(t time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	pos := sort.Search(len(r.ticks), func(i int) bool {
+		return !r.ticks[i].Timestamp.Before(t)
+	})
+	if pos == r.pos {
+		return
+	}
+	r.pos = pos
+	r.skipDelay = true
+	r.notifyLocked()
+}
+
+// Pause stops emitting ticks until Resume is called
+func (r *ReplaySource) Pause() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = true
+	r.notifyLocked()
+}
+
+// Resume continues a paused replay
+func (r *ReplaySource) Resume() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.paused = false
+	r.notifyLocked()
+}
+
+// Run emits ticks to out until the recording is exhausted or ctx is done.
+// It does not close out.
+func (r *ReplaySource) Run(ctx context.Context, out chan<- MarketData) error {
+	for {
+		r.mu.Lock()
+		changed := r.changed
+		if r.paused {
+			r.mu.Unlock()
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-changed:
+				continue
+			}
+		}
+		if r.pos >= len(r.ticks) {
+			r.mu.Unlock()
+			return nil
+		}
+		pos := r.pos
+		delay := r.delayLocked(pos)
+		r.mu.Unlock()
+
+		if delay > 0 {
+			elapsed, err := r.wait(ctx, delay, pos, changed)
+			if err != nil {
+				return err
+			}
+			if !elapsed {
+				continue
+			}
+		}
+
+		r.mu.Lock()
+		if r.paused || r.pos != pos {
+			r.mu.Unlock()
+			continue
+		}
+		tick := r.ticks[pos]
+		r.pos++
+		r.skipDelay = false
+		r.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case out <- tick:
+		}
+	}
+}
+
+// wait sleeps for delay before emitting tick pos. It reports false if a
+// pause or seek arrives first, so Run re-evaluates from the top; a speed
+// change alone keeps the current wait. The timer is stopped on every exit.
+func (r *ReplaySource) wait(ctx context.Context, delay time.Duration, pos int, changed <-chan struct{}) (bool, error) {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-timer.C:
+			return true, nil
+		case <-changed:
+			r.mu.Lock()
+			interrupted := r.paused || r.pos != pos
+			changed = r.changed
+			r.mu.Unlock()
+			if interrupted {
+				return false, nil
+			}
+		}
+	}
+}
+
+// delayLocked returns the scaled wait before emitting tick pos
+func (r *ReplaySource) delayLocked(pos int) time.Duration {
+	if pos == 0 || r.skipDelay || r.speed <= 0 {
+		return 0
+	}
+	gap := r.ticks[pos].Timestamp.Sub(r.ticks[pos-1].Timestamp)
+	return time.Duration(float64(gap) / r.speed)
+}
+
+// notifyLocked wakes a waiting Run loop
+func (r *ReplaySource) notifyLocked() {
+	close(r.changed)
+	r.changed = make(chan struct{})
+}
@@ -0,0 +1,168 @@
+package integration
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func recordedTicks(n int, spacing time.Duration) []MarketData {
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	ticks := make([]MarketData, n)
+	for i := range ticks {
+		ticks[i] = MarketData{
+			Commodity: "crude_oil",
+			Price:     75.00 + float64(i)*0.01,
+			Volume:    int64(100 + i),
+			Exchange:  "NYMEX",
+			Timestamp: start.Add(time.Duration(i) * spacing),
+		}
+	}
+	return ticks
+}
+
+func runReplay(t *testing.T, source *ReplaySource, out chan MarketData) (time.Duration, []MarketData) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	done := make(chan error, 1)
+	begin := time.Now()
+	go func() { done <- source.Run(ctx, out) }()
+
+	var got []MarketData
+	for {
+		select {
+		case tick := <-out:
+			got = append(got, tick)
+		case err := <-done:
+			if err != nil {
+				t.Fatalf("Replay failed: %v", err)
+			}
+			return time.Since(begin), got
+		}
+	}
+}
+
+// TestReplaySeek verifies seeking fast-forwards to the first tick at or after the target
+func TestReplaySeek(t *testing.T) {
+	ticks := recordedTicks(10, time.Millisecond)
+	source := NewReplaySource(ticks)
+	source.Seek(ticks[6].Timestamp.Add(-time.Microsecond))
+
+	_, got := runReplay(t, source, make(chan MarketData, len(ticks)))
+	if len(got) != 4 {
+		t.Fatalf("Expected 4 ticks after seek, got %d", len(got))
+	}
+	if !got[0].Timestamp.Equal(ticks[6].Timestamp) {
+		t.Errorf("Expected first tick at %v, got %v", ticks[6].Timestamp, got[0].Timestamp)
+	}
+}
+
+// TestReplaySeekToCursorKeepsPacing verifies seeking to the tick already next in line does not skip its wait
+func TestReplaySeekToCursorKeepsPacing(t *testing.T) {
+	ticks := recordedTicks(3, time.Hour)
+	source := NewReplaySource(ticks)
+	out := make(chan MarketData, len(ticks))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go source.Run(ctx, out)
+
+	receiveTicks(t, out, 1)
+	source.Pause()
+	time.Sleep(10 * time.Millisecond) // Run has left the wait and is parked
+	source.Seek(ticks[1].Timestamp)
+	source.Resume()
+	select {
+	case tick := <-out:
+		t.Fatalf("Expected the next tick to keep its hour-long wait, got %+v", tick)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+// TestReplayPauseResume verifies no ticks are emitted while paused
+func TestReplayPauseResume(t *testing.T) {
+	ticks := recordedTicks(5, 5*time.Millisecond)
+	source := NewReplaySource(ticks)
+	source.Pause()
+
+	out := make(chan MarketData, len(ticks))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- source.Run(ctx, out) }()
+
+	select {
+	case tick := <-out:
+		t.Fatalf("Expected no ticks while paused, got %+v", tick)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	source.Resume()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Replay failed: %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Replay did not finish after resume")
+	}
+	if len(out) != len(ticks) {
+		t.Errorf("Expected %d ticks after resume, got %d", len(ticks), len(out))
+	}
+}
+
+// TestReplayDoubleSpeed verifies a 2x run takes roughly half the recorded time
+func TestReplayDoubleSpeed(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping timing test in short mode")
+	}
+	ticks := recordedTicks(11, 20*time.Millisecond) // 200ms recorded span
+
+	source := NewReplaySource(ticks)
+	source.SetSpeed(2)
+	elapsed, got := runReplay(t, source, make(chan MarketData, len(ticks)))
+
+	if len(got) != len(ticks) {
+		t.Fatalf("Expected %d ticks, got %d", len(ticks), len(got))
+	}
+	if elapsed < 90*time.Millisecond || elapsed > 170*time.Millisecond {
+		t.Errorf("Expected 2x replay to take about 100ms, took %v", elapsed)
+	}
+}
+
+// TestReplayControlsAfterSpeedChange verifies a wait that saw a speed change still honours a later seek and cancellation
+func TestReplayControlsAfterSpeedChange(t *testing.T) {
+	ticks := recordedTicks(4, time.Hour)
+	source := NewReplaySource(ticks)
+	out := make(chan MarketData, len(ticks))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- source.Run(ctx, out) }()
+
+	receiveTicks(t, out, 1)
+	time.Sleep(10 * time.Millisecond) // Run is now waiting out the hour
+	source.SetSpeed(2)
+	time.Sleep(10 * time.Millisecond)
+	source.Seek(ticks[2].Timestamp)
+	if got := receiveTicks(t, out, 1); !got[0].Timestamp.Equal(ticks[2].Timestamp) {
+		t.Fatalf("Expected the seek to cut the wait short, got %+v", got[0])
+	}
+
+	source.Seek(ticks[1].Timestamp)
+	receiveTicks(t, out, 1)
+	time.Sleep(10 * time.Millisecond)
+	source.SetSpeed(1)
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Replay did not stop on cancellation after a speed change")
+	}
+}
@@ -0,0 +1,220 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// Position limit errors
+var (
+	ErrPositionLimit       = errors.New("position limit exceeded")
+	ErrReservationNotFound = errors.New("reservation not found")
+	ErrReservationLapsed   = errors.New("reservation lapsed")
+)
+
+// defaultLapsedRetention is how long a lapsed reservation is remembered for
+// late fills unless SetLapsedRetention says otherwise
+const defaultLapsedRetention = time.Hour
+
+// Reservation holds an order's potential position impact until it resolves
+type Reservation struct {
+	ID        string
+	ClientID  string
+	Commodity string
+	Side      string
+	Volume    float64
+	ExpiresAt time.Time
+}
+
+// PositionLimiter enforces per-commodity net position limits per client.
+// Orders reserve their worst-case impact before processing so concurrent
+// orders cannot each pass the check and jointly breach the limit.
+// Unresolved reservations lapse after the configured TTL, which frees their
+// headroom, but a fill reported after that still reaches the position. A
+// lapsed reservation is forgotten once the lapsed retention has passed
+// since its expiry, so fills reported later than that are not found.
+type PositionLimiter struct {
+	mu           sync.Mutex
+	limits       map[string]float64
+	ttl          time.Duration
+	retention    time.Duration
+	clock        func() time.Time
+	positions    map[string]map[string]float64 // client -> commodity -> net volume
+	reservations map[string]*Reservation
+	lapsed       map[string]*Reservation // expired but not yet resolved
+}
+
+// NewPositionLimiter creates a limiter with absolute net limits per commodity.
+// Commodities without a limit are unrestricted.
+func NewPositionLimiter(limits map[string]float64, ttl time.Duration, clock func() time.Time) *PositionLimiter {
+	if clock == nil {
+		clock = time.Now
+	}
+	copied := make(map[string]float64, len(limits))
+	for commodity, limit := range limits {
+		copied[commodity] = limit
+	}
+	return &PositionLimiter{
+		limits:       copied,
+		ttl:          ttl,
+		retention:    defaultLapsedRetention,
+		clock:        clock,
+		positions:    make(map[string]map[string]float64),
+		reservations: make(map[string]*Reservation),
+		lapsed:       make(map[string]*Reservation),
+	}
+}
+
+// SetLapsedRetention sets how long after expiry a lapsed reservation still
+// accepts late fills; the default is an hour
+func (l *PositionLimiter) SetLapsedRetention(retention time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.retention = retention
+}
+
+// Reserve checks the order against the limit, counting open reservations on
+// the same side as if they had filled, and holds its volume if it fits
+func (l *PositionLimiter) Reserve(order TradingOrder) (Reservation, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expireLocked()
+	_, open := l.reservations[order.OrderID]
+	_, lapsed := l.lapsed[order.OrderID]
+	if open || lapsed {
+		return Reservation{}, fmt.Errorf("%w: %s", ErrDuplicateOrder, order.OrderID)
+	}
+
+	if limit, ok := l.limits[order.Commodity]; ok {
+		worst := l.positions[order.ClientID][order.Commodity] + l.pendingLocked(order.ClientID, order.Commodity, order.Side)
+		if order.Side == SideBuy {
+			worst += order.Volume
+		} else {
+			worst -= order.Volume
+		}
+		if math.Abs(worst) > limit+volumeEpsilon {
+			return Reservation{}, fmt.Errorf("%w: %s %s would reach %g against limit %g",
+				ErrPositionLimit, order.ClientID, order.Commodity, worst, limit)
+		}
+	}
+
+	r := &Reservation{
+		ID:        order.OrderID,
+		ClientID:  order.ClientID,
+		Commodity: order.Commodity,
+		Side:      order.Side,
+		Volume:    order.Volume,
+		ExpiresAt: l.clock().Add(l.ttl),
+	}
+	l.reservations[r.ID] = r
+	return *r, nil
+}
+
+// Commit applies filled volume to the position. Any unfilled volume stays
+// reserved until the reservation is committed in full or released. A fill
+// against a lapsed reservation is still applied, and reported with
+// ErrReservationLapsed since it was not held against the limit.
+func (l *PositionLimiter) Commit(id string, filled float64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expireLocked()
+	reservations := l.reservations
+	r, ok := reservations[id]
+	lapsed := !ok
+	if lapsed {
+		reservations = l.lapsed
+		if r, ok = reservations[id]; !ok {
+			return fmt.Errorf("%w: %s", ErrReservationNotFound, id)
+		}
+	}
+	if filled > r.Volume {
+		filled = r.Volume
+	}
+	commodities, ok := l.positions[r.ClientID]
+	if !ok {
+		commodities = make(map[string]float64)
+		l.positions[r.ClientID] = commodities
+	}
+	if r.Side == SideBuy {
+		commodities[r.Commodity] += filled
+	} else {
+		commodities[r.Commodity] -= filled
+	}
+	r.Volume -= filled
+	if r.Volume < volumeEpsilon {
+		delete(reservations, id)
+	}
+	if lapsed {
+		return fmt.Errorf("%w: %s filled %g after expiry", ErrReservationLapsed, id, filled)
+	}
+	return nil
+}
+
+// Release drops a reservation after a reject or cancel
+func (l *PositionLimiter) Release(id string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.expireLocked()
+	_, open := l.reservations[id]
+	_, lapsed := l.lapsed[id]
+	if !open && !lapsed {
+		return fmt.Errorf("%w: %s", ErrReservationNotFound, id)
+	}
+	delete(l.reservations, id)
+	delete(l.lapsed, id)
+	return nil
+}
+
+// Position returns a client's committed net position in a commodity
+func (l *PositionLimiter) Position(clientID, commodity string) float64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.positions[clientID][commodity]
+}
+
+// Reserved returns the number of open reservations after expiring stale ones
+func (l *PositionLimiter) Reserved() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.expireLocked()
+	return len(l.reservations)
+}
+
+// pendingLocked returns the signed volume reserved by a client on one side
+func (l *PositionLimiter) pendingLocked(clientID, commodity, side string) float64 {
+	pending := 0.0
+	for _, r := range l.reservations {
+		if r.ClientID != clientID || r.Commodity != commodity || r.Side != side {
+			continue
+		}
+		if side == SideBuy {
+			pending += r.Volume
+		} else {
+			pending -= r.Volume
+		}
+	}
+	return pending
+}
+
+// expireLocked lapses expired reservations and forgets lapsed ones past
+// their retention
+func (l *PositionLimiter) expireLocked() {
+	now := l.clock()
+	for id, r := range l.reservations {
+		if !now.Before(r.ExpiresAt) {
+			delete(l.reservations, id)
+			l.lapsed[id] = r
+		}
+	}
+	for id, r := range l.lapsed {
+		if !now.Before(r.ExpiresAt.Add(l.retention)) {
+			delete(l.lapsed, id)
+		}
+	}
+}
@@ -0,0 +1,127 @@
+package integration
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestPositionLimiterConcurrentReservations verifies only one of two racing orders passes the limit
+func TestPositionLimiterConcurrentReservations(t *testing.T) {
+	limiter := NewPositionLimiter(map[string]float64{"crude_oil": 1000}, time.Minute, nil)
+	if _, err := limiter.Reserve(TradingOrder{OrderID: "seed", ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Volume: 400}); err != nil {
+		t.Fatalf("Seed reservation failed: %v", err)
+	}
+	if err := limiter.Commit("seed", 400); err != nil {
+		t.Fatalf("Seed commit failed: %v", err)
+	}
+
+	for round := 0; round < 50; round++ {
+		ids := []string{"race-a", "race-b"}
+		errs := make([]error, len(ids))
+		start := make(chan struct{})
+		var wg sync.WaitGroup
+		for i, id := range ids {
+			wg.Add(1)
+			go func(i int, id string) {
+				defer wg.Done()
+				<-start
+				_, errs[i] = limiter.Reserve(TradingOrder{OrderID: id, ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Volume: 500})
+			}(i, id)
+		}
+		close(start)
+		wg.Wait()
+
+		passed := 0
+		for i, err := range errs {
+			if err == nil {
+				passed++
+				limiter.Release(ids[i])
+			} else if !errors.Is(err, ErrPositionLimit) {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+		}
+		if passed != 1 {
+			t.Fatalf("Round %d: expected exactly one order to pass, got %d", round, passed)
+		}
+	}
+}
+
+// TestPositionLimiterCommitReleaseAndTimeout verifies reservation lifecycle
+func TestPositionLimiterCommitReleaseAndTimeout(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	limiter := NewPositionLimiter(map[string]float64{"natural_gas": 100}, 10*time.Second, func() time.Time { return now })
+
+	limiter.Reserve(TradingOrder{OrderID: "s1", ClientID: "acme", Commodity: "natural_gas", Side: SideSell, Volume: 80})
+	limiter.Commit("s1", 30) // 50 still reserved
+
+	if _, err := limiter.Reserve(TradingOrder{OrderID: "s2", ClientID: "acme", Commodity: "natural_gas", Side: SideSell, Volume: 30}); !errors.Is(err, ErrPositionLimit) {
+		t.Fatalf("Expected the outstanding remainder to count against the limit, got %v", err)
+	}
+	if _, err := limiter.Reserve(TradingOrder{OrderID: "b1", ClientID: "acme", Commodity: "natural_gas", Side: SideBuy, Volume: 130}); err != nil {
+		t.Fatalf("Expected an offsetting buy to fit, got %v", err)
+	}
+	limiter.Release("b1")
+
+	now = now.Add(11 * time.Second)
+	if n := limiter.Reserved(); n != 0 {
+		t.Errorf("Expected the stale reservation to lapse, got %d open", n)
+	}
+	if _, err := limiter.Reserve(TradingOrder{OrderID: "s2", ClientID: "acme", Commodity: "natural_gas", Side: SideSell, Volume: 70}); err != nil {
+		t.Errorf("Expected capacity to return after timeout, got %v", err)
+	}
+	if pos := limiter.Position("acme", "natural_gas"); pos != -30 {
+		t.Errorf("Expected committed position -30, got %g", pos)
+	}
+
+	// A fill that arrives after the reservation lapsed still counts
+	if err := limiter.Commit("s1", 10); !errors.Is(err, ErrReservationLapsed) {
+		t.Errorf("Expected ErrReservationLapsed for a lapsed reservation, got %v", err)
+	}
+	if pos := limiter.Position("acme", "natural_gas"); pos != -40 {
+		t.Errorf("Expected the late fill applied for a position of -40, got %g", pos)
+	}
+	if err := limiter.Release("s1"); err != nil {
+		t.Errorf("Expected the lapsed remainder to release, got %v", err)
+	}
+	if err := limiter.Commit("s1", 10); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("Expected ErrReservationNotFound once released, got %v", err)
+	}
+}
+
+// TestPositionLimiterForgetsLapsedReservations verifies lapsed reservations
+// that are never resolved are dropped once their retention has passed
+func TestPositionLimiterForgetsLapsedReservations(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	limiter := NewPositionLimiter(nil, 10*time.Second, func() time.Time { return now })
+	limiter.SetLapsedRetention(time.Minute)
+
+	for _, id := range []string{"b1", "b2"} {
+		if _, err := limiter.Reserve(TradingOrder{OrderID: id, ClientID: "acme", Commodity: "crude_oil", Side: SideBuy, Volume: 10}); err != nil {
+			t.Fatalf("Reserve %s failed: %v", id, err)
+		}
+	}
+
+	// Within the retention a late fill still lands
+	now = now.Add(30 * time.Second)
+	if err := limiter.Commit("b1", 10); !errors.Is(err, ErrReservationLapsed) {
+		t.Errorf("Expected a late fill accepted as lapsed, got %v", err)
+	}
+
+	// Past it the unresolved reservation is gone
+	now = now.Add(time.Minute)
+	limiter.Reserved()
+	limiter.mu.Lock()
+	kept := len(limiter.lapsed)
+	limiter.mu.Unlock()
+	if kept != 0 {
+		t.Errorf("Expected no lapsed reservations retained, got %d", kept)
+	}
+	if err := limiter.Commit("b2", 10); !errors.Is(err, ErrReservationNotFound) {
+		t.Errorf("Expected ErrReservationNotFound past retention, got %v", err)
+	}
+	if pos := limiter.Position("acme", "crude_oil"); pos != 10 {
+		t.Errorf("Expected only the fill inside retention counted, got %g", pos)
+	}
+}
@@ -0,0 +1,112 @@
+package integration
+
+import (
+	"errors"
+	"hash/fnv"
+	"sync"
+)
+
+// ErrSequencerClosed is returned for requests made after Close
+var ErrSequencerClosed = errors.New("order sequencer closed")
+
+// SequencerConfig sizes a ClientSequencer
+type SequencerConfig struct {
+	Partitions int `json:"partitions"` // workers running in parallel; default 8
+	QueueSize  int `json:"queue_size"` // pending requests per partition; default 64
+}
+
+// SequencedResult is the outcome of a sequenced request. Cancels carry only
+// the error.
+type SequencedResult struct {
+	Result SubmitResult
+	Err    error
+}
+
+// ClientSequencer processes each client's requests in the order they were
+// made. Clients are hashed onto partitions, each drained by one worker, so
+// one client's requests never overtake each other while different clients
+// are processed in parallel. A cancel queued after a new order therefore
+// always finds it.
+type ClientSequencer struct {
+	gateway    *OrderGateway
+	mu         sync.RWMutex
+	closed     bool
+	partitions []chan func()
+	wg         sync.WaitGroup
+}
+
+// NewClientSequencer starts the partition workers in front of gateway
+func NewClientSequencer(gateway *OrderGateway, config SequencerConfig) *ClientSequencer {
+	if config.Partitions <= 0 {
+		config.Partitions = 8
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 64
+	}
+	s := &ClientSequencer{gateway: gateway, partitions: make([]chan func(), config.Partitions)}
+	for i := range s.partitions {
+		tasks := make(chan func(), config.QueueSize)
+		s.partitions[i] = tasks
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			for task := range tasks {
+				task()
+			}
+		}()
+	}
+	return s
+}
+
+// Submit queues a new order behind the client's earlier requests. The
+// result is delivered on the returned channel once the order is processed.
+func (s *ClientSequencer) Submit(order TradingOrder, opts SubmitOptions) <-chan SequencedResult {
+	done := make(chan SequencedResult, 1)
+	s.enqueue(order.ClientID, done, func() {
+		result, err := s.gateway.Submit(order, opts)
+		done <- SequencedResult{Result: result, Err: err}
+	})
+	return done
+}
+
+// Cancel queues a cancel behind the client's earlier requests. A client
+// can only cancel its own orders; any other order is left resting and
+// ErrNotOrderOwner returned.
+func (s *ClientSequencer) Cancel(clientID, orderID string) <-chan SequencedResult {
+	done := make(chan SequencedResult, 1)
+	s.enqueue(clientID, done, func() {
+		done <- SequencedResult{Err: s.gateway.CancelForClient(clientID, orderID)}
+	})
+	return done
+}
+
+// Close stops accepting requests and waits for queued ones to finish
+func (s *ClientSequencer) Close() {
+	s.mu.Lock()
+	if !s.closed {
+		s.closed = true
+		for _, tasks := range s.partitions {
+			close(tasks)
+		}
+	}
+	s.mu.Unlock()
+	s.wg.Wait()
+}
+
+// enqueue hands the task to the client's partition, blocking while the
+// partition is full so the request keeps its place
+func (s *ClientSequencer) enqueue(clientID string, done chan SequencedResult, task func()) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		done <- SequencedResult{Err: ErrSequencerClosed}
+		return
+	}
+	s.partitions[s.partition(clientID)] <- task
+}
+
+func (s *ClientSequencer) partition(clientID string) int {
+	h := fnv.New32a()
+	h.Write([]byte(clientID))
+	return int(h.Sum32() % uint32(len(s.partitions)))
+}
@@ -0,0 +1,70 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+// TestClientSequencerKeepsNewBeforeCancel verifies each client's cancel is processed after its earlier new order
+func TestClientSequencerKeepsNewBeforeCancel(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	seq := NewClientSequencer(NewOrderGateway(book, nil), SequencerConfig{Partitions: 4, QueueSize: 4})
+
+	const clients, orders = 8, 50
+	var wg sync.WaitGroup
+	errs := make(chan error, clients*orders*2)
+	for c := 0; c < clients; c++ {
+		wg.Add(1)
+		go func(client string) {
+			defer wg.Done()
+			var pending []<-chan SequencedResult
+			for i := 0; i < orders; i++ {
+				id := fmt.Sprintf("%s-%d", client, i)
+				pending = append(pending,
+					seq.Submit(TradingOrder{OrderID: id, ClientID: client, Side: SideBuy, Price: 75, Volume: 1}, SubmitOptions{}),
+					seq.Cancel(client, id))
+			}
+			for _, done := range pending {
+				if res := <-done; res.Err != nil {
+					errs <- fmt.Errorf("%s: %w", client, res.Err)
+				}
+			}
+		}(fmt.Sprintf("client%d", c))
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("Request processed out of order: %v", err)
+	}
+	if depth := book.Snapshot(); len(depth.Bids) != 0 {
+		t.Errorf("Expected every order cancelled, got %+v", depth.Bids)
+	}
+
+	seq.Close()
+	if res := <-seq.Cancel("client0", "late"); !errors.Is(res.Err, ErrSequencerClosed) {
+		t.Errorf("Expected ErrSequencerClosed after Close, got %v", res.Err)
+	}
+}
+
+// TestClientSequencerRefusesOtherClientsCancels verifies a client cannot
+// cancel an order belonging to another client through its own lane
+func TestClientSequencerRefusesOtherClientsCancels(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	seq := NewClientSequencer(NewOrderGateway(book, nil), SequencerConfig{Partitions: 2})
+	defer seq.Close()
+
+	if res := <-seq.Submit(TradingOrder{OrderID: "a1", ClientID: "acme", Side: SideBuy, Price: 75, Volume: 10}, SubmitOptions{}); res.Err != nil {
+		t.Fatalf("Submit failed: %v", res.Err)
+	}
+	if res := <-seq.Cancel("gulf", "a1"); !errors.Is(res.Err, ErrNotOrderOwner) {
+		t.Errorf("Expected ErrNotOrderOwner for another client's order, got %v", res.Err)
+	}
+	if _, ok := book.Order("a1"); !ok {
+		t.Fatal("Expected acme's order still resting")
+	}
+	if res := <-seq.Cancel("acme", "a1"); res.Err != nil {
+		t.Errorf("Expected the owner's cancel to succeed, got %v", res.Err)
+	}
+}
@@ -0,0 +1,185 @@
+package integration
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TradingSession defines the daily trading hours for a commodity. Open and
+// Close are wall-clock times of day, so a 16:30 close stays at 16:30 on the
+// days clocks change.
+type TradingSession struct {
+	Location *time.Location
+	Open     time.Duration // time of day on the local clock
+	Close    time.Duration // time of day on the local clock
+}
+
+// SessionCalendar holds trading sessions per commodity. Sessions run on
+// weekdays only.
+type SessionCalendar struct {
+	mu       sync.RWMutex
+	sessions map[string]TradingSession
+}
+
+// NewSessionCalendar creates a calendar from per-commodity sessions
+func NewSessionCalendar(sessions map[string]TradingSession) *SessionCalendar {
+	c := &SessionCalendar{sessions: make(map[string]TradingSession, len(sessions))}
+	for commodity, session := range sessions {
+		if session.Location == nil {
+			session.Location = time.UTC
+		}
+		c.sessions[commodity] = session
+	}
+	return c
+}
+
+// Session returns the configured session for a commodity
+func (c *SessionCalendar) Session(commodity string) (TradingSession, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	session, ok := c.sessions[commodity]
+	return session, ok
+}
+
+// NextClose returns the first session close strictly after t
+func (c *SessionCalendar) NextClose(commodity string, t time.Time) (time.Time, error) {
+	session, ok := c.Session(commodity)
+	if !ok {
+		return time.Time{}, fmt.Errorf("no trading session configured for %s", commodity)
+	}
+
+	local := t.In(session.Location)
+	for i := 0; i < 8; i++ {
+		d := time.Date(local.Year(), local.Month(), local.Day()+i, 0, 0, 0, 0, session.Location)
+		if d.Weekday() == time.Saturday || d.Weekday() == time.Sunday {
+			continue
+		}
+		closeAt := atTimeOfDay(d, session.Close)
+		if closeAt.After(t) {
+			return closeAt, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("no session close found for %s after %v", commodity, t)
+}
+
+// IsOpen reports whether the commodity's session is open at t
+func (c *SessionCalendar) IsOpen(commodity string, t time.Time) bool {
+	session, ok := c.Session(commodity)
+	if !ok {
+		return false
+	}
+	local := t.In(session.Location)
+	if local.Weekday() == time.Saturday || local.Weekday() == time.Sunday {
+		return false
+	}
+	offset := timeOfDay(local)
+	return offset >= session.Open && offset < session.Close
+}
+
+// timeOfDay returns t's wall-clock time of day in its location. On days the
+// clocks change this differs from the time elapsed since midnight.
+func timeOfDay(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond())
+}
+
+// atTimeOfDay returns the instant on day's date when the local clock reads
+// offset
+func atTimeOfDay(day time.Time, offset time.Duration) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(offset), day.Location())
+}
+
+// ExpiryEvent records an order removed by the reaper
+type ExpiryEvent struct {
+	OrderID   string    `json:"order_id"`
+	ClientID  string    `json:"client_id,omitempty"`
+	Commodity string    `json:"commodity"`
+	Volume    float64   `json:"volume"`
+	Reason    string    `json:"reason"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// OrderReaper expires DAY orders when each commodity's session closes and
+// GTD orders at their expiry. It only compares the clock against the next
+// known close per commodity, and each book against its earliest GTD expiry,
+// so resting orders are scanned when something is due rather than on every
+// tick.
+type OrderReaper struct {
+	mu        sync.Mutex
+	calendar  *SessionCalendar
+	books     map[string]*OrderBook
+	nextClose map[string]time.Time
+}
+
+// NewOrderReaper creates a reaper for the given books starting at now
+func NewOrderReaper(calendar *SessionCalendar, books []*OrderBook, now time.Time) (*OrderReaper, error) {
+	r := &OrderReaper{
+		calendar:  calendar,
+		books:     make(map[string]*OrderBook, len(books)),
+		nextClose: make(map[string]time.Time, len(books)),
+	}
+	for _, book := range books {
+		closeAt, err := calendar.NextClose(book.Commodity(), now)
+		if err != nil {
+			return nil, err
+		}
+		r.books[book.Commodity()] = book
+		r.nextClose[book.Commodity()] = closeAt
+	}
+	return r, nil
+}
+
+// Tick advances the reaper to now, expiring GTD orders that have reached
+// their date and DAY orders on every book whose session has closed, and
+// returns the resulting expiry events
+func (r *OrderReaper) Tick(now time.Time) ([]ExpiryEvent, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []ExpiryEvent
+	for _, commodity := range sortedBookKeys(r.books) {
+		for _, order := range r.books[commodity].ExpireGTD(now) {
+			events = append(events, ExpiryEvent{
+				OrderID:   order.OrderID,
+				ClientID:  order.ClientID,
+				Commodity: commodity,
+				Volume:    order.Volume,
+				Reason:    "good till date",
+				Timestamp: order.ExpiresAt,
+			})
+		}
+
+		closeAt := r.nextClose[commodity]
+		if now.Before(closeAt) {
+			continue
+		}
+		for _, order := range r.books[commodity].ExpireOrders(TimeInForceDay) {
+			events = append(events, ExpiryEvent{
+				OrderID:   order.OrderID,
+				ClientID:  order.ClientID,
+				Commodity: commodity,
+				Volume:    order.Volume,
+				Reason:    "session close",
+				Timestamp: closeAt,
+			})
+		}
+		next, err := r.calendar.NextClose(commodity, now)
+		if err != nil {
+			return events, err
+		}
+		r.nextClose[commodity] = next
+	}
+	return events, nil
+}
+
+// sortedBookKeys returns map keys in a stable order
+func sortedBookKeys(books map[string]*OrderBook) []string {
+	keys := make([]string, 0, len(books))
+	for k := range books {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,201 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestReaperExpiresDayOrdersAtSessionClose verifies DAY orders expire at close while GTC orders survive
+func TestReaperExpiresDayOrdersAtSessionClose(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	calendar := NewSessionCalendar(map[string]TradingSession{
+		"crude_oil":   {Location: newYork, Open: 9 * time.Hour, Close: 14*time.Hour + 30*time.Minute},
+		"natural_gas": {Location: newYork, Open: 9 * time.Hour, Close: 17 * time.Hour},
+	})
+
+	crude := NewOrderBook("crude_oil")
+	gas := NewOrderBook("natural_gas")
+	seed := []struct {
+		book  *OrderBook
+		order TradingOrder
+	}{
+		{crude, TradingOrder{OrderID: "day_1", Side: SideBuy, Price: 75.00, Volume: 10, TimeInForce: TimeInForceDay}},
+		{crude, TradingOrder{OrderID: "gtc_1", Side: SideBuy, Price: 74.00, Volume: 10, TimeInForce: TimeInForceGTC}},
+		{crude, TradingOrder{OrderID: "day_2", Side: SideSell, Price: 76.00, Volume: 5, TimeInForce: TimeInForceDay}},
+		{crude, TradingOrder{OrderID: "plain", Side: SideSell, Price: 77.00, Volume: 5}},
+		{gas, TradingOrder{OrderID: "gas_day", Side: SideBuy, Price: 3.00, Volume: 100, TimeInForce: TimeInForceDay}},
+	}
+	for _, s := range seed {
+		if _, err := s.book.Add(s.order); err != nil {
+			t.Fatalf("Failed to seed %s: %v", s.order.OrderID, err)
+		}
+	}
+
+	start := time.Date(2024, 3, 5, 10, 0, 0, 0, newYork) // Tuesday
+	reaper, err := NewOrderReaper(calendar, []*OrderBook{crude, gas}, start)
+	if err != nil {
+		t.Fatalf("Failed to create reaper: %v", err)
+	}
+
+	events, _ := reaper.Tick(start.Add(4 * time.Hour))
+	if len(events) != 0 {
+		t.Fatalf("Expected no expiries before close, got %d", len(events))
+	}
+
+	events, err = reaper.Tick(time.Date(2024, 3, 5, 14, 30, 0, 0, newYork))
+	if err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(events) != 2 || events[0].OrderID != "day_1" || events[1].OrderID != "day_2" {
+		t.Fatalf("Expected day_1 and day_2 to expire, got %+v", events)
+	}
+
+	for _, id := range []string{"day_1", "day_2"} {
+		if _, ok := crude.Order(id); ok {
+			t.Errorf("Expected %s to be expired", id)
+		}
+	}
+	for _, id := range []string{"gtc_1", "plain"} {
+		if _, ok := crude.Order(id); !ok {
+			t.Errorf("Expected %s to survive rollover", id)
+		}
+	}
+	if _, ok := gas.Order("gas_day"); !ok {
+		t.Error("Expected natural gas DAY order to rest until its own close")
+	}
+
+	events, _ = reaper.Tick(time.Date(2024, 3, 5, 17, 0, 1, 0, newYork))
+	if len(events) != 1 || events[0].OrderID != "gas_day" {
+		t.Errorf("Expected gas_day to expire at gas close, got %+v", events)
+	}
+}
+
+// TestSessionCalendarSkipsWeekend verifies Friday's next close after the session is Monday
+func TestSessionCalendarSkipsWeekend(t *testing.T) {
+	calendar := NewSessionCalendar(map[string]TradingSession{
+		"crude_oil": {Open: 13 * time.Hour, Close: 19 * time.Hour},
+	})
+	friday := time.Date(2024, 3, 8, 20, 0, 0, 0, time.UTC)
+
+	next, err := calendar.NextClose("crude_oil", friday)
+	if err != nil {
+		t.Fatalf("NextClose failed: %v", err)
+	}
+	want := time.Date(2024, 3, 11, 19, 0, 0, 0, time.UTC)
+	if !next.Equal(want) {
+		t.Errorf("Expected next close %v, got %v", want, next)
+	}
+	if calendar.IsOpen("crude_oil", friday) {
+		t.Error("Expected session to be closed after Friday close")
+	}
+}
+
+// TestSessionCalendarKeepsWallClockOnDSTDay verifies the session keeps its
+// local hours on a weekday when the clocks go forward at midnight
+func TestSessionCalendarKeepsWallClockOnDSTDay(t *testing.T) {
+	cairo, err := time.LoadLocation("Africa/Cairo")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	calendar := NewSessionCalendar(map[string]TradingSession{
+		"natural_gas": {Location: cairo, Open: 10 * time.Hour, Close: 14*time.Hour + 30*time.Minute},
+	})
+	// Friday 2024-04-26 starts at 01:00 local, an hour short
+	morning := time.Date(2024, 4, 26, 9, 0, 0, 0, cairo)
+
+	next, err := calendar.NextClose("natural_gas", morning)
+	if err != nil {
+		t.Fatalf("NextClose failed: %v", err)
+	}
+	if want := time.Date(2024, 4, 26, 14, 30, 0, 0, cairo); !next.Equal(want) {
+		t.Errorf("Expected the close at 14:30 local, got %v", next)
+	}
+	if !calendar.IsOpen("natural_gas", time.Date(2024, 4, 26, 10, 30, 0, 0, cairo)) {
+		t.Error("Expected the session open at 10:30 local")
+	}
+	if calendar.IsOpen("natural_gas", time.Date(2024, 4, 26, 14, 45, 0, 0, cairo)) {
+		t.Error("Expected the session closed at 14:45 local")
+	}
+}
+
+// TestReaperExpiresGTDOrdersAtTheirDate verifies GTD orders expire mid-session at their own time
+func TestReaperExpiresGTDOrdersAtTheirDate(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	calendar := NewSessionCalendar(map[string]TradingSession{
+		"crude_oil": {Location: newYork, Open: 9 * time.Hour, Close: 14*time.Hour + 30*time.Minute},
+	})
+	now := time.Date(2024, 3, 5, 10, 0, 0, 0, newYork)
+	crude := NewOrderBook("crude_oil", WithClock(func() time.Time { return now }))
+
+	// 12:00 New York is 02:00 the next day in Tokyo; sub-second precision is dropped
+	expiresAt := time.Date(2024, 3, 6, 2, 0, 0, 900_000_000, tokyo)
+	for _, o := range []TradingOrder{
+		{OrderID: "gtd", Side: SideBuy, Price: 75.00, Volume: 10, TimeInForce: TimeInForceGTD, ExpiresAt: expiresAt},
+		{OrderID: "day", Side: SideBuy, Price: 74.00, Volume: 10, TimeInForce: TimeInForceDay},
+	} {
+		if _, err := crude.Add(o); err != nil {
+			t.Fatalf("Failed to seed %s: %v", o.OrderID, err)
+		}
+	}
+	if order, _ := crude.Order("gtd"); !order.ExpiresAt.Equal(time.Date(2024, 3, 5, 17, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected expiry kept to the second in UTC, got %v", order.ExpiresAt)
+	}
+
+	reaper, err := NewOrderReaper(calendar, []*OrderBook{crude}, now)
+	if err != nil {
+		t.Fatalf("Failed to create reaper: %v", err)
+	}
+	if events, _ := reaper.Tick(time.Date(2024, 3, 5, 11, 59, 59, 0, newYork)); len(events) != 0 {
+		t.Fatalf("Expected nothing to expire before noon, got %+v", events)
+	}
+	events, err := reaper.Tick(time.Date(2024, 3, 5, 12, 0, 0, 0, newYork))
+	if err != nil {
+		t.Fatalf("Tick failed: %v", err)
+	}
+	if len(events) != 1 || events[0].OrderID != "gtd" || events[0].Reason != "good till date" {
+		t.Fatalf("Expected the GTD order to expire at noon, got %+v", events)
+	}
+	if _, ok := crude.Order("gtd"); ok {
+		t.Error("Expected the GTD order to be removed")
+	}
+	if _, ok := crude.Order("day"); !ok {
+		t.Error("Expected the DAY order to rest until the close")
+	}
+}
+
+// TestGTDOrderRejectedWhenAlreadyExpired verifies submissions with a past or missing expiry are refused
+func TestGTDOrderRejectedWhenAlreadyExpired(t *testing.T) {
+	now := time.Date(2024, 3, 5, 15, 0, 0, 0, time.UTC)
+	crude := NewOrderBook("crude_oil", WithClock(func() time.Time { return now }))
+
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	for _, o := range []TradingOrder{
+		// 14:59:59 London is one second before now
+		{OrderID: "past", Side: SideBuy, Price: 75, Volume: 1, TimeInForce: TimeInForceGTD, ExpiresAt: time.Date(2024, 3, 5, 14, 59, 59, 0, london)},
+		// Truncated to the second this is exactly now
+		{OrderID: "now", Side: SideBuy, Price: 75, Volume: 1, TimeInForce: TimeInForceGTD, ExpiresAt: now.Add(500 * time.Millisecond)},
+		{OrderID: "missing", Side: SideBuy, Price: 75, Volume: 1, TimeInForce: TimeInForceGTD},
+		{OrderID: "gtc", Side: SideBuy, Price: 75, Volume: 1, ExpiresAt: now.Add(time.Hour)},
+	} {
+		if _, err := crude.Add(o); !errors.Is(err, ErrInvalidOrder) {
+			t.Errorf("%s: expected ErrInvalidOrder, got %v", o.OrderID, err)
+		}
+	}
+	if _, err := crude.Add(TradingOrder{OrderID: "ok", Side: SideBuy, Price: 75, Volume: 1, TimeInForce: TimeInForceGTD, ExpiresAt: now.Add(time.Second)}); err != nil {
+		t.Errorf("Expected a future expiry to be accepted, got %v", err)
+	}
+}
@@ -0,0 +1,136 @@
+package integration
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// currencyMinorUnits maps ISO 4217 codes to their settlement decimal places
+var currencyMinorUnits = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CHF": 2,
+	"CAD": 2,
+	"AUD": 2,
+	"CNY": 2,
+	"AED": 2,
+	"SAR": 2,
+	"QAR": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+}
+
+// RoundSettlement rounds an amount to the currency's minor units using
+// banker's rounding (round half to even). Rounding is done on the decimal
+// representation so values such as 2.675 round as written.
+func RoundSettlement(amount float64, currency string) (float64, error) {
+	places, ok := currencyMinorUnits[currency]
+	if !ok {
+		return 0, fmt.Errorf("unknown settlement currency %q", currency)
+	}
+
+	exact, ok := new(big.Rat).SetString(strconv.FormatFloat(amount, 'f', -1, 64))
+	if !ok {
+		return 0, fmt.Errorf("cannot round non-finite amount %v", amount)
+	}
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(places)), nil)
+	exact.Mul(exact, new(big.Rat).SetInt(scale))
+
+	// Split into quotient and remainder, then apply half-even on the remainder.
+	num, den := exact.Num(), exact.Denom()
+	quo, rem := new(big.Int).QuoRem(num, den, new(big.Int))
+	twiceRem := new(big.Int).Mul(new(big.Int).Abs(rem), big.NewInt(2))
+	switch cmp := twiceRem.Cmp(den); {
+	case cmp > 0, cmp == 0 && quo.Bit(0) == 1:
+		if num.Sign() < 0 {
+			quo.Sub(quo, big.NewInt(1))
+		} else {
+			quo.Add(quo, big.NewInt(1))
+		}
+	}
+
+	rounded, _ := new(big.Rat).SetFrac(quo, scale).Float64()
+	return rounded, nil
+}
+
+// SettlementInstruction is the aggregated amount due for one commodity
+type SettlementInstruction struct {
+	Commodity  string  `json:"commodity"`
+	Currency   string  `json:"currency"`
+	Volume     float64 `json:"volume"`
+	Amount     float64 `json:"amount"`
+	TradeCount int     `json:"trade_count"`
+}
+
+// SettlementBatcher accumulates trades and emits rounded settlement instructions
+type SettlementBatcher struct {
+	mu         sync.Mutex
+	currencies map[string]string // commodity -> settlement currency
+	pending    map[string]*SettlementInstruction
+}
+
+// NewSettlementBatcher creates a batcher with the settlement currency per commodity
+func NewSettlementBatcher(currencies map[string]string) *SettlementBatcher {
+	return &SettlementBatcher{
+		currencies: currencies,
+		pending:    make(map[string]*SettlementInstruction),
+	}
+}
+
+// Add queues a trade for settlement. Trades stamped with an FX rate are
+// converted at that recorded rate, never the current one.
+func (b *SettlementBatcher) Add(trade Trade) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	currency, ok := b.currencies[trade.Commodity]
+	if !ok {
+		return fmt.Errorf("no settlement currency configured for %s", trade.Commodity)
+	}
+	rate := 1.0
+	if trade.FXRate > 0 {
+		if trade.SettlementCurrency != currency {
+			return fmt.Errorf("trade %s stamped for %s, %s settles in %s",
+				trade.TradeID, trade.SettlementCurrency, trade.Commodity, currency)
+		}
+		rate = trade.FXRate
+	}
+	inst, ok := b.pending[trade.Commodity]
+	if !ok {
+		inst = &SettlementInstruction{Commodity: trade.Commodity, Currency: currency}
+		b.pending[trade.Commodity] = inst
+	}
+	inst.Volume += trade.Volume
+	inst.Amount += trade.Price * trade.Volume * rate
+	inst.TradeCount++
+	return nil
+}
+
+// Flush returns the pending instructions with amounts rounded to minor
+// units, ordered by commodity, and resets the batch
+func (b *SettlementBatcher) Flush() ([]SettlementInstruction, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	out := make([]SettlementInstruction, 0, len(b.pending))
+	for _, inst := range b.pending {
+		rounded, err := RoundSettlement(inst.Amount, inst.Currency)
+		if err != nil {
+			return nil, fmt.Errorf("settle %s: %w", inst.Commodity, err)
+		}
+		settled := *inst
+		settled.Amount = rounded
+		out = append(out, settled)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Commodity < out[j].Commodity })
+
+	b.pending = make(map[string]*SettlementInstruction)
+	return out, nil
+}
@@ -0,0 +1,104 @@
+package integration
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultSettlementLag is the business-day lag for commodities without a rule
+const defaultSettlementLag = 2
+
+// SettlementRule is how a commodity settles: Lag business days after the
+// trade date (1 for T+1) on Market's calendar. The trade date is taken in
+// Location, UTC when nil.
+type SettlementRule struct {
+	Market   string
+	Lag      int
+	Location *time.Location
+}
+
+// SettlementCalendar computes settlement dates from per-commodity rules and
+// per-market holidays. Weekends are never business days.
+type SettlementCalendar struct {
+	mu       sync.RWMutex
+	rules    map[string]SettlementRule
+	holidays map[string]map[string]bool // market -> YYYY-MM-DD
+}
+
+// NewSettlementCalendar creates a calendar from per-commodity rules
+func NewSettlementCalendar(rules map[string]SettlementRule) *SettlementCalendar {
+	c := &SettlementCalendar{
+		rules:    make(map[string]SettlementRule, len(rules)),
+		holidays: make(map[string]map[string]bool),
+	}
+	for commodity, rule := range rules {
+		c.SetRule(commodity, rule)
+	}
+	return c
+}
+
+// SetRule sets how a commodity settles
+func (c *SettlementCalendar) SetRule(commodity string, rule SettlementRule) {
+	if rule.Location == nil {
+		rule.Location = time.UTC
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.rules[commodity] = rule
+}
+
+// AddHolidays marks dates as market holidays. Only the calendar date of
+// each value matters.
+func (c *SettlementCalendar) AddHolidays(market string, dates ...time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	days, ok := c.holidays[market]
+	if !ok {
+		days = make(map[string]bool)
+		c.holidays[market] = days
+	}
+	for _, d := range dates {
+		days[d.Format("2006-01-02")] = true
+	}
+}
+
+// IsBusinessDay reports whether the date is a weekday and not a holiday
+// of the market
+func (c *SettlementCalendar) IsBusinessDay(market string, date time.Time) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.businessDayLocked(market, date)
+}
+
+func (c *SettlementCalendar) businessDayLocked(market string, date time.Time) bool {
+	if date.Weekday() == time.Saturday || date.Weekday() == time.Sunday {
+		return false
+	}
+	return !c.holidays[market][date.Format("2006-01-02")]
+}
+
+// SettlementDate returns midnight, in the rule's location, of the day the
+// trade settles. A trade date that is not a business day rolls forward to
+// the next one before the lag is counted, and the lag counts business days
+// only. Commodities without a rule settle T+2 on weekdays.
+func (c *SettlementCalendar) SettlementDate(commodity string, tradeDate time.Time) time.Time {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	rule, ok := c.rules[commodity]
+	if !ok {
+		rule = SettlementRule{Lag: defaultSettlementLag, Location: time.UTC}
+	}
+	local := tradeDate.In(rule.Location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, rule.Location)
+	for !c.businessDayLocked(rule.Market, day) {
+		day = day.AddDate(0, 0, 1)
+	}
+	for lag := rule.Lag; lag > 0; {
+		day = day.AddDate(0, 0, 1)
+		if c.businessDayLocked(rule.Market, day) {
+			lag--
+		}
+	}
+	return day
+}
@@ -0,0 +1,40 @@
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// TestSettlementDateRollsOverHolidayWeekend verifies lags skip a weekend and the market's holidays
+func TestSettlementDateRollsOverHolidayWeekend(t *testing.T) {
+	london, err := time.LoadLocation("Europe/London")
+	if err != nil {
+		t.Skipf("Timezone data unavailable: %v", err)
+	}
+	date := func(m time.Month, d int) time.Time { return time.Date(2024, m, d, 0, 0, 0, 0, london) }
+	calendar := NewSettlementCalendar(map[string]SettlementRule{
+		"brent":     {Market: "ICE", Lag: 1, Location: london},
+		"crude_oil": {Market: "NYMEX", Lag: 2, Location: london},
+	})
+	// Easter 2024: Good Friday and Easter Monday close ICE; NYMEX only Good Friday
+	calendar.AddHolidays("ICE", date(time.March, 29), date(time.April, 1))
+	calendar.AddHolidays("NYMEX", date(time.March, 29))
+
+	thursday := time.Date(2024, 3, 28, 16, 30, 0, 0, london)
+	tests := []struct {
+		commodity string
+		trade     time.Time
+		want      time.Time
+	}{
+		{"brent", thursday, date(time.April, 2)},
+		{"crude_oil", thursday, date(time.April, 2)},
+		{"brent", time.Date(2024, 3, 30, 10, 0, 0, 0, london), date(time.April, 3)}, // Saturday rolls to Tuesday first
+		{"crude_oil", date(time.March, 26), date(time.March, 28)},
+		{"power", time.Date(2024, 3, 28, 12, 0, 0, 0, time.UTC), time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)}, // default T+2, weekends only
+	}
+	for _, tt := range tests {
+		if got := calendar.SettlementDate(tt.commodity, tt.trade); !got.Equal(tt.want) {
+			t.Errorf("%s traded %s: expected settlement %s, got %s", tt.commodity, tt.trade, tt.want.Format("2006-01-02"), got.Format("2006-01-02"))
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package integration
+
+import "testing"
+
+// TestRoundSettlement verifies banker's rounding to each currency's minor units
+func TestRoundSettlement(t *testing.T) {
+	testCases := []struct {
+		name     string
+		amount   float64
+		currency string
+		expected float64
+	}{
+		{"USD half down to even", 2.665, "USD", 2.66},
+		{"USD half up to even", 2.675, "USD", 2.68},
+		{"USD plain", 1234.5678, "USD", 1234.57},
+		{"USD negative half", -0.125, "USD", -0.12},
+		{"JPY half to even", 1502.5, "JPY", 1502},
+		{"JPY half up to even", 1503.5, "JPY", 1504},
+		{"BHD three places", 10.0005, "BHD", 10.000},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := RoundSettlement(tc.amount, tc.currency)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+			if got != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+// TestRoundSettlementUnknownCurrency verifies unknown currencies error instead of guessing
+func TestRoundSettlementUnknownCurrency(t *testing.T) {
+	if _, err := RoundSettlement(10.5, "XYZ"); err == nil {
+		t.Error("Expected error for unknown currency")
+	}
+}
+
+// TestSettlementBatcherRoundsOutput verifies batched instructions are rounded per currency
+func TestSettlementBatcherRoundsOutput(t *testing.T) {
+	batcher := NewSettlementBatcher(map[string]string{
+		"crude_oil": "USD",
+		"lng":       "JPY",
+	})
+	trades := []Trade{
+		{Commodity: "crude_oil", Price: 75.505, Volume: 1},
+		{Commodity: "crude_oil", Price: 75.5, Volume: 1},
+		{Commodity: "lng", Price: 1800.5, Volume: 1},
+	}
+	for _, trade := range trades {
+		if err := batcher.Add(trade); err != nil {
+			t.Fatalf("Failed to add trade: %v", err)
+		}
+	}
+	if err := batcher.Add(Trade{Commodity: "power", Price: 1, Volume: 1}); err == nil {
+		t.Error("Expected error for commodity without settlement currency")
+	}
+
+	instructions, err := batcher.Flush()
+	if err != nil {
+		t.Fatalf("Flush failed: %v", err)
+	}
+	if len(instructions) != 2 {
+		t.Fatalf("Expected 2 instructions, got %d", len(instructions))
+	}
+	if instructions[0].Amount != 151.0 || instructions[0].TradeCount != 2 {
+		t.Errorf("Expected crude_oil amount 151.00 over 2 trades, got %+v", instructions[0])
+	}
+	if instructions[1].Amount != 1800 {
+		t.Errorf("Expected lng amount 1800 JPY, got %v", instructions[1].Amount)
+	}
+}
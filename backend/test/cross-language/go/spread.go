@@ -0,0 +1,165 @@
+package integration
+
+import "fmt"
+
+// SpreadDefinition describes a two-leg spread instrument. Buying the spread
+// buys the front leg and sells the back leg in equal volume, so the net
+// price is the front leg price minus the back leg price.
+type SpreadDefinition struct {
+	Name     string `json:"name"`
+	FrontLeg string `json:"front_leg"`
+	BackLeg  string `json:"back_leg"`
+}
+
+// SpreadFill is a matched spread trade and the leg trades it generates
+type SpreadFill struct {
+	Spread Trade
+	Front  Trade
+	Back   Trade
+}
+
+// SpreadBook matches spread orders against each other by net price. Orders
+// are ordinary TradingOrders whose Price is the net spread, which may be
+// zero or negative. Each match is broken into leg trades: the back leg is
+// priced at its current market and the front leg at that price plus the
+// net spread, falling back to anchoring on the front leg when the back leg
+// has no market.
+type SpreadBook struct {
+	def   SpreadDefinition
+	book  *OrderBook
+	front QuoteSource
+	back  QuoteSource
+}
+
+// NewSpreadBook creates a book for def, reading leg markets from front and
+// back, typically the outright OrderBooks. Options configure the underlying
+// net price book.
+func NewSpreadBook(def SpreadDefinition, front, back QuoteSource, opts ...BookOption) (*SpreadBook, error) {
+	if def.Name == "" || def.FrontLeg == "" || def.BackLeg == "" || def.FrontLeg == def.BackLeg {
+		return nil, fmt.Errorf("invalid spread definition %+v: needs a name and two distinct legs", def)
+	}
+	opts = append([]BookOption{WithSignedPrices()}, opts...)
+	return &SpreadBook{
+		def:   def,
+		book:  NewOrderBook(def.Name, opts...),
+		front: front,
+		back:  back,
+	}, nil
+}
+
+// Definition returns the spread definition
+func (s *SpreadBook) Definition() SpreadDefinition {
+	return s.def
+}
+
+// Add submits a spread order. It is rejected with ErrNoQuote when neither
+// leg has a market to price leg trades from.
+func (s *SpreadBook) Add(order TradingOrder) ([]SpreadFill, error) {
+	legs, err := s.legPricer()
+	if err != nil {
+		return nil, err
+	}
+	trades, err := s.book.Add(order)
+	if err != nil {
+		return nil, err
+	}
+	return s.fills(trades, legs), nil
+}
+
+// Amend changes a resting spread order's net price or volume, as
+// OrderBook.Amend does. An amendment that trades needs a leg market.
+func (s *SpreadBook) Amend(orderID string, price, volume float64) ([]SpreadFill, error) {
+	legs, err := s.legPricer()
+	if err != nil {
+		return nil, err
+	}
+	trades, err := s.book.Amend(orderID, price, volume)
+	if err != nil {
+		return nil, err
+	}
+	return s.fills(trades, legs), nil
+}
+
+// Cancel removes a resting spread order
+func (s *SpreadBook) Cancel(orderID string) error {
+	return s.book.Cancel(orderID)
+}
+
+// BestBid returns the highest net price bid for the spread
+func (s *SpreadBook) BestBid() (price, volume float64, ok bool) {
+	return s.book.BestBid()
+}
+
+// BestAsk returns the lowest net price offered for the spread
+func (s *SpreadBook) BestAsk() (price, volume float64, ok bool) {
+	return s.book.BestAsk()
+}
+
+// Snapshot returns the aggregated net price depth
+func (s *SpreadBook) Snapshot() BookSnapshot {
+	return s.book.Snapshot()
+}
+
+// legPricer captures the leg markets once, so every fill from one operation
+// is priced off the same reference
+func (s *SpreadBook) legPricer() (func(net float64) (front, back float64), error) {
+	if back, ok := referencePrice(s.back); ok {
+		return func(net float64) (float64, float64) { return back + net, back }, nil
+	}
+	if front, ok := referencePrice(s.front); ok {
+		return func(net float64) (float64, float64) { return front, front - net }, nil
+	}
+	return nil, fmt.Errorf("%w: no market in %s or %s to price spread %s",
+		ErrNoQuote, s.def.FrontLeg, s.def.BackLeg, s.def.Name)
+}
+
+// fills breaks spread trades into leg trades. The spread buyer buys the
+// front leg and sells the back leg.
+func (s *SpreadBook) fills(trades []Trade, legs func(net float64) (front, back float64)) []SpreadFill {
+	fills := make([]SpreadFill, 0, len(trades))
+	for _, t := range trades {
+		frontPrice, backPrice := legs(t.Price)
+
+		front := t
+		front.TradeID = t.TradeID + "-" + s.def.FrontLeg
+		front.Commodity = s.def.FrontLeg
+		front.Price = frontPrice
+
+		back := t
+		back.TradeID = t.TradeID + "-" + s.def.BackLeg
+		back.Commodity = s.def.BackLeg
+		back.Price = backPrice
+		back.BuyOrderID, back.SellOrderID = t.SellOrderID, t.BuyOrderID
+		back.BuyClientID, back.SellClientID = t.SellClientID, t.BuyClientID
+		back.Aggressor = oppositeSide(t.Aggressor)
+
+		fills = append(fills, SpreadFill{Spread: t, Front: front, Back: back})
+	}
+	return fills
+}
+
+// referencePrice is the quote midpoint, or the one side quoted
+func referencePrice(q QuoteSource) (float64, bool) {
+	if q == nil {
+		return 0, false
+	}
+	bid, _, okBid := q.BestBid()
+	ask, _, okAsk := q.BestAsk()
+	switch {
+	case okBid && okAsk:
+		return (bid + ask) / 2, true
+	case okBid:
+		return bid, true
+	case okAsk:
+		return ask, true
+	}
+	return 0, false
+}
+
+// oppositeSide returns the other side of a trade
+func oppositeSide(side string) string {
+	if side == SideBuy {
+		return SideSell
+	}
+	return SideBuy
+}
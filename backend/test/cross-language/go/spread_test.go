@@ -0,0 +1,90 @@
+package integration
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func spreadLegBooks(t *testing.T) (front, back *OrderBook) {
+	t.Helper()
+	front, back = NewOrderBook("crude_oil_m1"), NewOrderBook("crude_oil_m2")
+	for _, o := range []TradingOrder{
+		{OrderID: "f-bid", Side: SideBuy, Price: 74.9, Volume: 10},
+		{OrderID: "f-ask", Side: SideSell, Price: 75.1, Volume: 10},
+	} {
+		if _, err := front.Add(o); err != nil {
+			t.Fatalf("Failed to seed front leg: %v", err)
+		}
+	}
+	for _, o := range []TradingOrder{
+		{OrderID: "b-bid", Side: SideBuy, Price: 75.4, Volume: 10},
+		{OrderID: "b-ask", Side: SideSell, Price: 75.6, Volume: 10},
+	} {
+		if _, err := back.Add(o); err != nil {
+			t.Fatalf("Failed to seed back leg: %v", err)
+		}
+	}
+	return front, back
+}
+
+// TestSpreadBookMatchesIntoLegTrades verifies opposing spread orders match on net price and break into consistent legs
+func TestSpreadBookMatchesIntoLegTrades(t *testing.T) {
+	front, back := spreadLegBooks(t)
+	spreads, err := NewSpreadBook(SpreadDefinition{Name: "crude_m1_m2", FrontLeg: "crude_oil_m1", BackLeg: "crude_oil_m2"}, front, back)
+	if err != nil {
+		t.Fatalf("Failed to create spread book: %v", err)
+	}
+
+	// Contango spread quoted negative: front trades below back
+	if fills, err := spreads.Add(TradingOrder{OrderID: "s1", ClientID: "acme", Side: SideSell, Price: -0.4, Volume: 5}); err != nil || len(fills) != 0 {
+		t.Fatalf("Expected resting spread offer, got %v, %v", fills, err)
+	}
+	fills, err := spreads.Add(TradingOrder{OrderID: "b1", ClientID: "gulf", Side: SideBuy, Price: -0.3, Volume: 3})
+	if err != nil {
+		t.Fatalf("Spread buy failed: %v", err)
+	}
+	if len(fills) != 1 {
+		t.Fatalf("Expected one spread fill, got %+v", fills)
+	}
+	fill := fills[0]
+	if fill.Spread.Price != -0.4 || fill.Spread.Volume != 3 {
+		t.Errorf("Expected spread trade of 3 at -0.4, got %+v", fill.Spread)
+	}
+
+	// Back leg at its mid of 75.5, front leg at 75.5 - 0.4
+	if fill.Back.Price != 75.5 || math.Abs(fill.Front.Price-75.1) > 1e-9 {
+		t.Errorf("Expected legs at 75.1/75.5, got %g/%g", fill.Front.Price, fill.Back.Price)
+	}
+	if math.Abs(fill.Front.Price-fill.Back.Price-fill.Spread.Price) > 1e-9 {
+		t.Errorf("Leg prices %g - %g do not give the net spread %g", fill.Front.Price, fill.Back.Price, fill.Spread.Price)
+	}
+	if fill.Front.Commodity != "crude_oil_m1" || fill.Front.BuyClientID != "gulf" || fill.Front.SellClientID != "acme" {
+		t.Errorf("Expected the spread buyer to buy the front leg, got %+v", fill.Front)
+	}
+	if fill.Back.Commodity != "crude_oil_m2" || fill.Back.BuyClientID != "acme" || fill.Back.SellClientID != "gulf" ||
+		fill.Back.Aggressor != SideSell {
+		t.Errorf("Expected the spread buyer to sell the back leg, got %+v", fill.Back)
+	}
+	if fill.Front.Volume != 3 || fill.Back.Volume != 3 {
+		t.Errorf("Expected leg volumes to match the spread, got %g/%g", fill.Front.Volume, fill.Back.Volume)
+	}
+	if _, volume, ok := spreads.BestAsk(); !ok || volume != 2 {
+		t.Errorf("Expected 2 left on the spread offer, got %g", volume)
+	}
+}
+
+// TestSpreadBookNeedsLegMarket verifies spread orders are refused when no leg can be priced
+func TestSpreadBookNeedsLegMarket(t *testing.T) {
+	spreads, err := NewSpreadBook(SpreadDefinition{Name: "crude_m1_m2", FrontLeg: "crude_oil_m1", BackLeg: "crude_oil_m2"},
+		NewOrderBook("crude_oil_m1"), NewOrderBook("crude_oil_m2"))
+	if err != nil {
+		t.Fatalf("Failed to create spread book: %v", err)
+	}
+	if _, err := spreads.Add(TradingOrder{OrderID: "s1", Side: SideSell, Price: 0.5, Volume: 1}); !errors.Is(err, ErrNoQuote) {
+		t.Errorf("Expected ErrNoQuote, got %v", err)
+	}
+	if _, err := NewSpreadBook(SpreadDefinition{Name: "bad", FrontLeg: "crude_oil", BackLeg: "crude_oil"}, nil, nil); err == nil {
+		t.Error("Expected a spread on one leg to be rejected")
+	}
+}
@@ -0,0 +1,164 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+)
+
+// StressScenario is a set of simultaneous relative price shocks, -0.2 for
+// a 20% fall. With a Driver, commodities not shocked directly move with
+// the driver through the engine's correlations and volatilities, so one
+// configured shock can stand for a correlated move across the portfolio.
+type StressScenario struct {
+	Name   string             `json:"name"`
+	Shocks map[string]float64 `json:"shocks"`
+	Driver string             `json:"driver,omitempty"`
+}
+
+// StressConfig supplies the market data used to propagate driver shocks
+type StressConfig struct {
+	Volatility   map[string]float64 // daily return volatility per commodity
+	Correlations *CorrelationMatrix
+}
+
+// CommodityImpact is one commodity's contribution to a scenario
+type CommodityImpact struct {
+	Commodity string  `json:"commodity"`
+	NetVolume float64 `json:"net_volume"`
+	MarkPrice float64 `json:"mark_price"`
+	Shock     float64 `json:"shock"`
+	PnL       float64 `json:"pnl"`
+}
+
+// StressResult is the PnL impact of one scenario on a portfolio
+type StressResult struct {
+	Scenario    string            `json:"scenario"`
+	PnL         float64           `json:"pnl"`
+	Commodities []CommodityImpact `json:"commodities"`
+}
+
+// StressEngine applies configured shock scenarios to positions
+type StressEngine struct {
+	config    StressConfig
+	scenarios []StressScenario
+}
+
+// NewStressEngine creates an engine, validating every scenario
+func NewStressEngine(config StressConfig, scenarios []StressScenario) (*StressEngine, error) {
+	names := make(map[string]bool, len(scenarios))
+	for _, s := range scenarios {
+		switch {
+		case s.Name == "":
+			return nil, fmt.Errorf("stress scenario without a name")
+		case names[s.Name]:
+			return nil, fmt.Errorf("duplicate stress scenario %q", s.Name)
+		case len(s.Shocks) == 0:
+			return nil, fmt.Errorf("stress scenario %q has no shocks", s.Name)
+		}
+		for commodity, shock := range s.Shocks {
+			if shock <= -1 {
+				return nil, fmt.Errorf("stress scenario %q: %s shock %g takes the price below zero", s.Name, commodity, shock)
+			}
+		}
+		if s.Driver != "" {
+			if _, ok := s.Shocks[s.Driver]; !ok {
+				return nil, fmt.Errorf("stress scenario %q: driver %s is not shocked", s.Name, s.Driver)
+			}
+			if config.Volatility[s.Driver] <= 0 || config.Correlations == nil {
+				return nil, fmt.Errorf("stress scenario %q: driver %s needs a volatility and correlations", s.Name, s.Driver)
+			}
+		}
+		names[s.Name] = true
+	}
+	return &StressEngine{config: config, scenarios: append([]StressScenario(nil), scenarios...)}, nil
+}
+
+// LoadStressScenarios reads a JSON array of scenarios
+func LoadStressScenarios(path string) ([]StressScenario, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("open stress scenarios: %w", err)
+	}
+	defer f.Close()
+	return ParseStressScenariosJSON(f)
+}
+
+// ParseStressScenariosJSON parses [{"name": "crude crash", "shocks": {"crude_oil": -0.2}}]
+func ParseStressScenariosJSON(r io.Reader) ([]StressScenario, error) {
+	var scenarios []StressScenario
+	if err := json.NewDecoder(r).Decode(&scenarios); err != nil {
+		return nil, fmt.Errorf("decode stress scenarios json: %w", err)
+	}
+	return scenarios, nil
+}
+
+// Scenarios returns the configured scenarios in order
+func (e *StressEngine) Scenarios() []StressScenario {
+	return append([]StressScenario(nil), e.scenarios...)
+}
+
+// Run applies every scenario to the positions, netting them per commodity
+// at their mark prices. Results follow scenario order and commodities are
+// sorted by name.
+func (e *StressEngine) Run(positions []Position) []StressResult {
+	net := make(map[string]*CommodityImpact)
+	for _, pos := range positions {
+		if pos.Volume == 0 {
+			continue
+		}
+		c, ok := net[pos.Commodity]
+		if !ok {
+			c = &CommodityImpact{Commodity: pos.Commodity, MarkPrice: pos.MarkPrice}
+			net[pos.Commodity] = c
+		}
+		c.NetVolume += pos.Volume
+	}
+	commodities := make([]string, 0, len(net))
+	for commodity := range net {
+		commodities = append(commodities, commodity)
+	}
+	sort.Strings(commodities)
+
+	results := make([]StressResult, 0, len(e.scenarios))
+	for _, s := range e.scenarios {
+		result := StressResult{Scenario: s.Name}
+		for _, commodity := range commodities {
+			impact := *net[commodity]
+			impact.Shock = e.shock(s, commodity)
+			impact.PnL = impact.NetVolume * impact.MarkPrice * impact.Shock
+			result.PnL += impact.PnL
+			result.Commodities = append(result.Commodities, impact)
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// RunTracker stresses the firm's combined positions across every client
+func (e *StressEngine) RunTracker(tracker *PositionTracker) []StressResult {
+	var positions []Position
+	for _, clientPositions := range tracker.Snapshot() {
+		positions = append(positions, clientPositions...)
+	}
+	return e.Run(positions)
+}
+
+// shock returns the scenario's move for a commodity: its own shock if
+// configured, otherwise the driver's shock scaled by correlation and
+// relative volatility, the expected move given the driver's
+func (e *StressEngine) shock(s StressScenario, commodity string) float64 {
+	if shock, ok := s.Shocks[commodity]; ok {
+		return shock
+	}
+	if s.Driver == "" {
+		return 0
+	}
+	corr, ok := e.config.Correlations.Get(s.Driver, commodity)
+	if !ok {
+		return 0
+	}
+	return corr * e.config.Volatility[commodity] / e.config.Volatility[s.Driver] * s.Shocks[s.Driver]
+}
@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"math"
+	"testing"
+)
+
+// TestStressEngineScenarioImpact verifies direct, multi-commodity and correlated shocks on a sample portfolio
+func TestStressEngineScenarioImpact(t *testing.T) {
+	scenarios, err := LoadStressScenarios("testdata/stress_scenarios.json")
+	if err != nil {
+		t.Fatalf("Failed to load scenarios: %v", err)
+	}
+	corr, err := LoadCorrelationMatrix("testdata/correlations.csv")
+	if err != nil {
+		t.Fatalf("Failed to load correlations: %v", err)
+	}
+	engine, err := NewStressEngine(StressConfig{
+		Volatility:   map[string]float64{"crude_oil": 0.02, "natural_gas": 0.04, "heating_oil": 0.025},
+		Correlations: corr,
+	}, scenarios)
+	if err != nil {
+		t.Fatalf("Failed to create engine: %v", err)
+	}
+
+	tracker := NewPositionTracker()
+	tracker.ApplyFill("fund_a", "crude_oil", SideBuy, 1000, 80)     // 80,000 long
+	tracker.ApplyFill("fund_b", "crude_oil", SideSell, 250, 80)     // 20,000 short
+	tracker.ApplyFill("fund_a", "natural_gas", SideSell, 10000, 3)  // 30,000 short
+	tracker.ApplyFill("fund_b", "heating_oil", SideBuy, 20000, 2.5) // 50,000 long
+
+	results := engine.RunTracker(tracker)
+	// crude net 60,000, gas -30,000, heating oil 50,000
+	oilShock := -0.20
+	gasSpill := 0.45 * 0.04 / 0.02 * oilShock
+	heatSpill := 0.82 * 0.025 / 0.02 * oilShock
+	want := map[string]float64{
+		"crude crash":  60000 * -0.20,
+		"gas squeeze":  -30000 * 0.50,
+		"winter spike": 60000*0.10 - 30000*0.50,
+		"oil shock":    60000*oilShock - 30000*gasSpill + 50000*heatSpill,
+	}
+	if len(results) != len(want) {
+		t.Fatalf("Expected %d results, got %d", len(want), len(results))
+	}
+	for _, r := range results {
+		if math.Abs(r.PnL-want[r.Scenario]) > 1e-6 {
+			t.Errorf("%s: expected PnL %.2f, got %.2f", r.Scenario, want[r.Scenario], r.PnL)
+		}
+	}
+	if gas := results[3].Commodities[2]; gas.Commodity != "natural_gas" || math.Abs(gas.Shock-gasSpill) > 1e-12 {
+		t.Errorf("Expected gas to move %g with crude, got %+v", gasSpill, gas)
+	}
+
+	if _, err := NewStressEngine(StressConfig{}, []StressScenario{{Name: "x", Shocks: map[string]float64{"crude_oil": -0.1}, Driver: "crude_oil"}}); err == nil {
+		t.Error("Expected a driver without correlations to be rejected")
+	}
+}
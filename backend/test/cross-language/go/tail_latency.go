@@ -0,0 +1,301 @@
+package integration
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// TailLatencyConfig configures a tail latency run
+type TailLatencyConfig struct {
+	// Operations is the number of measured operations; default 100000
+	Operations int
+	// Warmup operations run first and are not measured; default a tenth
+	// of Operations
+	Warmup int
+	// Depth is the number of price levels seeded on each side; default 100
+	Depth int
+	// Seed fixes the workload so runs differ only by timing; default 1
+	Seed int64
+	// Options configure the book under test
+	Options []BookOption
+}
+
+// TailLatencyReport summarises per-operation matching latency. Percentiles
+// are exact nearest-rank values over every measured operation.
+type TailLatencyReport struct {
+	Operations int           `json:"operations"`
+	Mean       time.Duration `json:"mean_ns"`
+	P50        time.Duration `json:"p50_ns"`
+	P99        time.Duration `json:"p99_ns"`
+	P999       time.Duration `json:"p999_ns"`
+	Max        time.Duration `json:"max_ns"`
+	// GC activity during the measured operations
+	GCCycles     int           `json:"gc_cycles"`
+	GCPauseTotal time.Duration `json:"gc_pause_total_ns"`
+	GCPauseMax   time.Duration `json:"gc_pause_max_ns"`
+	// GCOverlapped counts operations in flight during a stop-the-world
+	// pause, and TailGCOverlapped those of them at or above p99
+	GCOverlapped     int `json:"gc_overlapped"`
+	TailGCOverlapped int `json:"tail_gc_overlapped"`
+}
+
+// String formats the report on one line
+func (r TailLatencyReport) String() string {
+	return fmt.Sprintf("%d ops: mean %s p50 %s p99 %s p999 %s max %s; %d GCs paused %s (max %s), %d ops hit a pause, %d of them in the p99 tail",
+		r.Operations, r.Mean, r.P50, r.P99, r.P999, r.Max,
+		r.GCCycles, r.GCPauseTotal, r.GCPauseMax, r.GCOverlapped, r.TailGCOverlapped)
+}
+
+// Regressions compares the tail against a baseline and describes each of
+// p99 and p999 that is more than tolerance (0.2 for 20%) above it. The
+// median and mean are deliberately not compared: a change can leave them
+// flat while the tail degrades.
+func (r TailLatencyReport) Regressions(baseline TailLatencyReport, tolerance float64) []string {
+	var flagged []string
+	for _, q := range []struct {
+		name      string
+		got, base time.Duration
+	}{
+		{"p99", r.P99, baseline.P99},
+		{"p999", r.P999, baseline.P999},
+	} {
+		if q.base > 0 && float64(q.got) > float64(q.base)*(1+tolerance) {
+			flagged = append(flagged, fmt.Sprintf("%s %s is %.0f%% above baseline %s",
+				q.name, q.got, 100*(float64(q.got)/float64(q.base)-1), q.base))
+		}
+	}
+	return flagged
+}
+
+// LoadTailLatencyBaseline reads a report saved as JSON
+func LoadTailLatencyBaseline(path string) (TailLatencyReport, error) {
+	var baseline TailLatencyReport
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return baseline, fmt.Errorf("read tail latency baseline: %w", err)
+	}
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return baseline, fmt.Errorf("decode tail latency baseline %s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// tailWorkload generates a session-like mix of 60% passive adds, 30%
+// cancels and 10% aggressive orders, mostly small with occasional blocks.
+// Cancels only target orders still resting, so they measure real removals
+// rather than lookups of orders the aggressive flow already filled.
+type tailWorkload struct {
+	r      *rand.Rand
+	book   *OrderBook
+	depth  int
+	live   []string
+	next   int
+	missed int // cancels that found nothing to remove
+}
+
+// newTailWorkload creates the workload for config over a seeded book
+func newTailWorkload(config TailLatencyConfig) *tailWorkload {
+	clock := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	opts := append([]BookOption{WithClock(func() time.Time { return clock })}, config.Options...)
+	w := &tailWorkload{
+		r:     rand.New(rand.NewSource(config.Seed)),
+		book:  NewOrderBook("crude_oil", opts...),
+		depth: config.Depth,
+	}
+	w.seed()
+	return w
+}
+
+const (
+	tailMid  = 75.0
+	tailTick = 0.01
+)
+
+func (w *tailWorkload) size() float64 {
+	if w.r.Intn(20) == 0 {
+		return float64(500 + w.r.Intn(500))
+	}
+	return float64(1 + w.r.Intn(10))
+}
+
+func (w *tailWorkload) order() TradingOrder {
+	w.next++
+	offset := float64(1+w.r.Intn(w.depth)) * tailTick
+	order := TradingOrder{OrderID: strconv.Itoa(w.next), Type: OrderTypeLimit, Volume: w.size()}
+	if w.r.Intn(2) == 0 {
+		order.Side, order.Price = SideBuy, tailMid-offset
+	} else {
+		order.Side, order.Price = SideSell, tailMid+offset
+	}
+	return order
+}
+
+func (w *tailWorkload) seed() {
+	for level := 1; level <= w.depth; level++ {
+		for i := 0; i < 2; i++ {
+			offset := float64(level) * tailTick
+			w.add(TradingOrder{OrderID: "seed-b" + strconv.Itoa(level*2+i), Side: SideBuy, Price: tailMid - offset, Volume: w.size()})
+			w.add(TradingOrder{OrderID: "seed-s" + strconv.Itoa(level*2+i), Side: SideSell, Price: tailMid + offset, Volume: w.size()})
+		}
+	}
+}
+
+func (w *tailWorkload) add(order TradingOrder) {
+	w.book.Add(order)
+	w.live = append(w.live, order.OrderID)
+}
+
+// resting picks a random order still on the book, forgetting any it finds
+// that have since filled
+func (w *tailWorkload) resting() (string, bool) {
+	for len(w.live) > 0 {
+		j := w.r.Intn(len(w.live))
+		id := w.live[j]
+		w.live[j] = w.live[len(w.live)-1]
+		w.live = w.live[:len(w.live)-1]
+		if _, ok := w.book.Order(id); ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// step prepares the next operation; only the returned function is timed
+func (w *tailWorkload) step() func() {
+	n := w.r.Intn(10)
+	if n < 3 {
+		if id, ok := w.resting(); ok {
+			return func() {
+				if w.book.Cancel(id) != nil {
+					w.missed++
+				}
+			}
+		}
+	}
+	switch {
+	case n == 9:
+		order := w.order()
+		// cross up to a few levels through the touch
+		through := float64(w.r.Intn(5)) * tailTick
+		if order.Side == SideBuy {
+			order.Price = tailMid + tailTick + through
+		} else {
+			order.Price = tailMid - tailTick - through
+		}
+		order.TimeInForce = TimeInForceIOC
+		return func() { w.book.Add(order) }
+	default:
+		order := w.order()
+		w.live = append(w.live, order.OrderID)
+		return func() { w.book.Add(order) }
+	}
+}
+
+// RunTailLatency times every operation of a seeded session-like workload
+// against a fresh book and reports the latency distribution with the GC
+// pauses that fell inside the measured run. Latencies and start times are
+// preallocated so the harness itself does not allocate while measuring.
+func RunTailLatency(config TailLatencyConfig) TailLatencyReport {
+	if config.Operations <= 0 {
+		config.Operations = 100000
+	}
+	if config.Warmup <= 0 {
+		config.Warmup = config.Operations / 10
+	}
+	if config.Depth <= 0 {
+		config.Depth = 100
+	}
+	if config.Seed == 0 {
+		config.Seed = 1
+	}
+	w := newTailWorkload(config)
+	for i := 0; i < config.Warmup; i++ {
+		w.step()()
+	}
+
+	latencies := make([]time.Duration, config.Operations)
+	starts := make([]time.Duration, config.Operations)
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	began := time.Now()
+	for i := range latencies {
+		op := w.step()
+		start := time.Now()
+		op()
+		latencies[i] = time.Since(start)
+		starts[i] = start.Sub(began)
+	}
+	runtime.ReadMemStats(&after)
+
+	report := TailLatencyReport{Operations: config.Operations}
+	pauses := gcPausesSince(&before, &after, began)
+	report.GCCycles = int(after.NumGC - before.NumGC)
+	report.GCPauseTotal = time.Duration(after.PauseTotalNs - before.PauseTotalNs)
+
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	report.Mean = total / time.Duration(len(sorted))
+	report.P50 = nearestRank(sorted, 0.50)
+	report.P99 = nearestRank(sorted, 0.99)
+	report.P999 = nearestRank(sorted, 0.999)
+	report.Max = sorted[len(sorted)-1]
+
+	for _, p := range pauses {
+		if p.end-p.start > report.GCPauseMax {
+			report.GCPauseMax = p.end - p.start
+		}
+	}
+	for i, start := range starts {
+		end := start + latencies[i]
+		for _, p := range pauses {
+			if start < p.end && end > p.start {
+				report.GCOverlapped++
+				if latencies[i] >= report.P99 {
+					report.TailGCOverlapped++
+				}
+				break
+			}
+		}
+	}
+	return report
+}
+
+type gcPause struct {
+	start, end time.Duration // offsets from the start of the run
+}
+
+// gcPausesSince returns the stop-the-world pauses recorded between two
+// MemStats readings, up to the 256 the runtime keeps
+func gcPausesSince(before, after *runtime.MemStats, began time.Time) []gcPause {
+	cycles := after.NumGC - before.NumGC
+	if cycles > uint32(len(after.PauseNs)) {
+		cycles = uint32(len(after.PauseNs))
+	}
+	pauses := make([]gcPause, 0, cycles)
+	for k := uint32(0); k < cycles; k++ {
+		i := (after.NumGC - 1 - k) % uint32(len(after.PauseNs))
+		end := time.Duration(int64(after.PauseEnd[i]) - began.UnixNano())
+		pauses = append(pauses, gcPause{start: end - time.Duration(after.PauseNs[i]), end: end})
+	}
+	return pauses
+}
+
+// nearestRank returns the q quantile of sorted latencies
+func nearestRank(sorted []time.Duration, q float64) time.Duration {
+	i := int(math.Ceil(q*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
@@ -0,0 +1,101 @@
+package integration
+
+import (
+	"runtime/debug"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestTailLatencyReportsPercentilesAndGC verifies the harness measures
+// every operation and surfaces GC pauses that land in the run
+func TestTailLatencyReportsPercentilesAndGC(t *testing.T) {
+	// Collect on almost every allocation so the run is sure to see pauses
+	defer debug.SetGCPercent(debug.SetGCPercent(1))
+
+	report := RunTailLatency(TailLatencyConfig{Operations: 20000, Depth: 50})
+	if report.Operations != 20000 {
+		t.Errorf("Expected 20000 measured operations, got %d", report.Operations)
+	}
+	if !(report.P50 > 0 && report.P50 <= report.P99 && report.P99 <= report.P999 && report.P999 <= report.Max) {
+		t.Errorf("Expected ordered positive percentiles, got %s", report)
+	}
+	if report.GCCycles == 0 || report.GCPauseTotal <= 0 || report.GCPauseMax <= 0 {
+		t.Errorf("Expected GC pauses in the report, got %s", report)
+	}
+	if report.TailGCOverlapped > report.GCOverlapped {
+		t.Errorf("Expected tail GC hits to be a subset of all GC hits, got %s", report)
+	}
+}
+
+// TestTailLatencyCancelsRestingOrders verifies every cancel in the
+// workload removes an order that is still on the book
+func TestTailLatencyCancelsRestingOrders(t *testing.T) {
+	w := newTailWorkload(TailLatencyConfig{Depth: 20, Seed: 7})
+	for i := 0; i < 20000; i++ {
+		w.step()()
+	}
+	if w.missed != 0 {
+		t.Errorf("Expected every cancel to find a resting order, %d missed", w.missed)
+	}
+	if _, ok := w.resting(); !ok {
+		t.Error("Expected orders left resting for later cancels")
+	}
+}
+
+// TestTailLatencyRegressionsFlagTailOnly verifies only p99 and p999 beyond
+// tolerance are flagged
+func TestTailLatencyRegressionsFlagTailOnly(t *testing.T) {
+	baseline, err := LoadTailLatencyBaseline("testdata/tail_latency_baseline.json")
+	if err != nil {
+		t.Fatalf("Failed to load baseline: %v", err)
+	}
+
+	median := baseline
+	median.P50 *= 3
+	median.Mean *= 3
+	if flagged := median.Regressions(baseline, 0.2); len(flagged) != 0 {
+		t.Errorf("Expected a slower median alone not to be flagged, got %v", flagged)
+	}
+
+	within := baseline
+	within.P99 = baseline.P99 * 11 / 10
+	if flagged := within.Regressions(baseline, 0.2); len(flagged) != 0 {
+		t.Errorf("Expected a 10%% p99 move within tolerance, got %v", flagged)
+	}
+
+	tail := baseline
+	tail.P999 = baseline.P999 * 2
+	flagged := tail.Regressions(baseline, 0.2)
+	if len(flagged) != 1 || !strings.HasPrefix(flagged[0], "p999 ") || !strings.Contains(flagged[0], "100% above baseline") {
+		t.Errorf("Expected p999 flagged at 100%% above baseline, got %v", flagged)
+	}
+}
+
+// BenchmarkOrderBookTailLatency reports p50, p99 and p999 matching latency
+// over a session-like workload and, when run for the baseline's operation
+// count, logs any tail regression against the documented baseline
+func BenchmarkOrderBookTailLatency(b *testing.B) {
+	start := time.Now()
+	report := RunTailLatency(TailLatencyConfig{Operations: b.N})
+	b.ReportMetric(float64(report.P50.Nanoseconds()), "p50-ns")
+	b.ReportMetric(float64(report.P99.Nanoseconds()), "p99-ns")
+	b.ReportMetric(float64(report.P999.Nanoseconds()), "p999-ns")
+	b.ReportMetric(float64(report.GCCycles), "gcs")
+	b.ReportMetric(float64(report.TailGCOverlapped), "gc-tail-ops")
+	if elapsed := time.Since(start).Seconds(); elapsed > 0 {
+		b.ReportMetric(float64(b.N)/elapsed, "ops/s")
+	}
+
+	baseline, err := LoadTailLatencyBaseline("testdata/tail_latency_baseline.json")
+	if err != nil {
+		b.Fatalf("Failed to load baseline: %v", err)
+	}
+	// The book grows over a run, so only runs of the baseline's length compare
+	if b.N != baseline.Operations {
+		return
+	}
+	for _, regression := range report.Regressions(baseline, 0.2) {
+		b.Logf("tail regression: %s", regression)
+	}
+}
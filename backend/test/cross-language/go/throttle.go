@@ -0,0 +1,121 @@
+package integration
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// VolatilityRateFunc maps realized volatility to a permitted order rate per second
+type VolatilityRateFunc func(volatility float64) float64
+
+// VolatilityStep lowers the rate once volatility reaches Above
+type VolatilityStep struct {
+	Above float64 `json:"above"`
+	Rate  float64 `json:"rate"`
+}
+
+// SteppedRates returns a mapping that allows base below the first step and
+// the rate of the highest step reached otherwise
+func SteppedRates(base float64, steps ...VolatilityStep) VolatilityRateFunc {
+	sorted := append([]VolatilityStep(nil), steps...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Above < sorted[j].Above })
+	return func(volatility float64) float64 {
+		rate := base
+		for _, step := range sorted {
+			if volatility < step.Above {
+				break
+			}
+			rate = step.Rate
+		}
+		return rate
+	}
+}
+
+// AdaptiveThrottle is a token bucket whose refill rate follows market
+// volatility. The rate is recomputed on every price, so it lags the market
+// only by the estimator's smoothing. When the rate drops, tokens saved up
+// beyond the new burst are discarded so tightening takes effect at once.
+type AdaptiveThrottle struct {
+	mu         sync.Mutex
+	estimator  *VolatilityEstimator
+	rateFor    VolatilityRateFunc
+	burstFor   time.Duration // burst capacity as a span of the current rate
+	clock      func() time.Time
+	rate       float64
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewAdaptiveThrottle creates a throttle allowing burst's worth of orders
+// at the current rate; a nil clock uses time.Now
+func NewAdaptiveThrottle(estimator *VolatilityEstimator, rateFor VolatilityRateFunc, burst time.Duration, clock func() time.Time) *AdaptiveThrottle {
+	if burst <= 0 {
+		burst = time.Second
+	}
+	if clock == nil {
+		clock = time.Now
+	}
+	t := &AdaptiveThrottle{
+		estimator: estimator,
+		rateFor:   rateFor,
+		burstFor:  burst,
+		clock:     clock,
+		rate:      rateFor(estimator.Volatility()),
+	}
+	t.tokens = t.capacity()
+	t.lastRefill = clock()
+	return t
+}
+
+// OnPrice feeds a price to the volatility estimator and adjusts the rate
+func (t *AdaptiveThrottle) OnPrice(price float64) {
+	volatility := t.estimator.Observe(price)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.refillLocked()
+	t.rate = t.rateFor(volatility)
+	if capacity := t.capacity(); t.tokens > capacity {
+		t.tokens = capacity
+	}
+}
+
+// Allow consumes a token if one is available
+func (t *AdaptiveThrottle) Allow() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.refillLocked()
+	if t.tokens < 1 {
+		return false
+	}
+	t.tokens--
+	return true
+}
+
+// Rate returns the permitted orders per second
+func (t *AdaptiveThrottle) Rate() float64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.rate
+}
+
+func (t *AdaptiveThrottle) capacity() float64 {
+	capacity := t.rate * t.burstFor.Seconds()
+	if capacity < 1 && t.rate > 0 {
+		capacity = 1
+	}
+	return capacity
+}
+
+func (t *AdaptiveThrottle) refillLocked() {
+	now := t.clock()
+	if elapsed := now.Sub(t.lastRefill); elapsed > 0 {
+		t.tokens += elapsed.Seconds() * t.rate
+		if capacity := t.capacity(); t.tokens > capacity {
+			t.tokens = capacity
+		}
+	}
+	t.lastRefill = now
+}
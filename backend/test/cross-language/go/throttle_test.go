@@ -0,0 +1,78 @@
+package integration
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func countAllowed(throttle *AdaptiveThrottle, attempts int) int {
+	allowed := 0
+	for i := 0; i < attempts; i++ {
+		if throttle.Allow() {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+// TestAdaptiveThrottleTightensOnVolatility verifies a volatility spike lowers the permitted rate and calm restores it
+func TestAdaptiveThrottleTightensOnVolatility(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	rates := SteppedRates(100, VolatilityStep{Above: 0.005, Rate: 50}, VolatilityStep{Above: 0.02, Rate: 10})
+	throttle := NewAdaptiveThrottle(NewVolatilityEstimator(0.5), rates, time.Second, func() time.Time { return now })
+
+	for _, p := range []float64{75.00, 75.01, 75.00, 75.01} {
+		throttle.OnPrice(p)
+	}
+	if rate := throttle.Rate(); rate != 100 {
+		t.Fatalf("Expected the calm rate of 100/s, got %g", rate)
+	}
+	if allowed := countAllowed(throttle, 200); allowed != 100 {
+		t.Fatalf("Expected a calm burst of 100, got %d", allowed)
+	}
+
+	// A 5% swing spikes volatility
+	throttle.OnPrice(78.75)
+	throttle.OnPrice(75.00)
+	if rate := throttle.Rate(); rate != 10 {
+		t.Fatalf("Expected the spike to cut the rate to 10/s, got %g", rate)
+	}
+	now = now.Add(time.Second)
+	if allowed := countAllowed(throttle, 200); allowed != 10 {
+		t.Errorf("Expected 10 orders in the next second, got %d", allowed)
+	}
+
+	// Calm prices decay the estimate and loosen the throttle
+	for i := 0; i < 20; i++ {
+		throttle.OnPrice(75.00)
+	}
+	if rate := throttle.Rate(); rate != 100 {
+		t.Errorf("Expected the rate to recover to 100/s, got %g", rate)
+	}
+}
+
+// TestAdaptiveThrottleConcurrent verifies concurrent callers never exceed the bucket
+func TestAdaptiveThrottleConcurrent(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	throttle := NewAdaptiveThrottle(NewVolatilityEstimator(0), SteppedRates(40), time.Second, func() time.Time { return now })
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			n := countAllowed(throttle, 20)
+			throttle.OnPrice(75)
+			mu.Lock()
+			allowed += n
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if allowed != 40 {
+		t.Errorf("Expected exactly 40 orders admitted, got %d", allowed)
+	}
+}
@@ -0,0 +1,56 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// ErrNoTimestampOffset is returned in strict mode for exchanges without a configured offset
+var ErrNoTimestampOffset = errors.New("no timestamp offset configured")
+
+// TimestampAligner rewrites MarketData timestamps to exchange trade time.
+// Each exchange's offset is how far its published timestamp runs behind
+// trade time: publish-time venues have a positive offset, trade-time venues
+// zero, and a negative offset corrects a venue whose clock runs early. The
+// published timestamp is kept in OriginalTimestamp, and ticks that already
+// carry one are not shifted again.
+type TimestampAligner struct {
+	mu      sync.RWMutex
+	offsets map[string]time.Duration
+	strict  bool
+}
+
+// NewTimestampAligner creates an aligner. Exchanges without an offset are
+// rejected when strict and passed through at offset zero otherwise.
+func NewTimestampAligner(offsets map[string]time.Duration, strict bool) *TimestampAligner {
+	a := &TimestampAligner{offsets: make(map[string]time.Duration, len(offsets)), strict: strict}
+	for exchange, offset := range offsets {
+		a.offsets[exchange] = offset
+	}
+	return a
+}
+
+// SetOffset adds or replaces an exchange's offset
+func (a *TimestampAligner) SetOffset(exchange string, offset time.Duration) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.offsets[exchange] = offset
+}
+
+// Align returns tick with its timestamp moved to exchange trade time
+func (a *TimestampAligner) Align(tick MarketData) (MarketData, error) {
+	if !tick.OriginalTimestamp.IsZero() {
+		return tick, nil
+	}
+	a.mu.RLock()
+	offset, ok := a.offsets[tick.Exchange]
+	a.mu.RUnlock()
+	if !ok && a.strict {
+		return MarketData{}, fmt.Errorf("%w: %s", ErrNoTimestampOffset, tick.Exchange)
+	}
+	tick.OriginalTimestamp = tick.Timestamp
+	tick.Timestamp = tick.Timestamp.Add(-offset)
+	return tick, nil
+}
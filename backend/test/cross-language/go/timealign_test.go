@@ -0,0 +1,44 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestTimestampAlignerTwoExchanges verifies ticks from venues with different offsets land on one trade-time axis
+func TestTimestampAlignerTwoExchanges(t *testing.T) {
+	aligner := NewTimestampAligner(map[string]time.Duration{
+		"ICE":   250 * time.Millisecond, // publish time
+		"NYMEX": -20 * time.Millisecond, // clock runs early
+	}, false)
+	tradeTime := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	ticks := []MarketData{
+		{Commodity: "crude_oil", Price: 75.50, Exchange: "ICE", Timestamp: tradeTime.Add(250 * time.Millisecond)},
+		{Commodity: "crude_oil", Price: 75.51, Exchange: "NYMEX", Timestamp: tradeTime.Add(-20 * time.Millisecond)},
+	}
+	for _, tick := range ticks {
+		aligned, err := aligner.Align(tick)
+		if err != nil {
+			t.Fatalf("Align %s failed: %v", tick.Exchange, err)
+		}
+		if !aligned.Timestamp.Equal(tradeTime) {
+			t.Errorf("%s: expected trade time %v, got %v", tick.Exchange, tradeTime, aligned.Timestamp)
+		}
+		if !aligned.OriginalTimestamp.Equal(tick.Timestamp) {
+			t.Errorf("%s: expected original %v preserved, got %v", tick.Exchange, tick.Timestamp, aligned.OriginalTimestamp)
+		}
+		if again, _ := aligner.Align(aligned); !again.Timestamp.Equal(tradeTime) {
+			t.Errorf("%s: expected aligning twice to be a no-op, got %v", tick.Exchange, again.Timestamp)
+		}
+	}
+
+	unknown := MarketData{Exchange: "DME", Timestamp: tradeTime}
+	if aligned, err := aligner.Align(unknown); err != nil || !aligned.Timestamp.Equal(tradeTime) {
+		t.Errorf("Expected a missing offset to pass through, got %+v, %v", aligned, err)
+	}
+	strict := NewTimestampAligner(nil, true)
+	if _, err := strict.Align(unknown); !errors.Is(err, ErrNoTimestampOffset) {
+		t.Errorf("Expected ErrNoTimestampOffset in strict mode, got %v", err)
+	}
+}
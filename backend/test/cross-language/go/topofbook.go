@@ -0,0 +1,88 @@
+package integration
+
+import (
+	"context"
+	"time"
+)
+
+// TopOfBook is the best bid and ask; an empty side has zero price and size
+type TopOfBook struct {
+	Commodity string    `json:"commodity"`
+	Bid       float64   `json:"bid"`
+	BidSize   float64   `json:"bid_size"`
+	Ask       float64   `json:"ask"`
+	AskSize   float64   `json:"ask_size"`
+	Seq       uint64    `json:"seq"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (t TopOfBook) sameQuote(other TopOfBook) bool {
+	return t.Bid == other.Bid && t.BidSize == other.BidSize && t.Ask == other.Ask && t.AskSize == other.AskSize
+}
+
+// TopOfBookStream emits a TopOfBook only when the best bid or ask price or
+// size changes. Each check reads a single book snapshot, so bid and ask are
+// always consistent with each other. With a debounce interval, changes that
+// arrive within the interval of the last emission are held back and the
+// top as of the first poll after it elapses is emitted.
+type TopOfBookStream struct {
+	book     *OrderBook
+	debounce time.Duration
+	clock    func() time.Time
+
+	last      TopOfBook
+	emitted   bool
+	emittedAt time.Time
+}
+
+// NewTopOfBookStream creates a stream over book; debounce of zero emits every change
+func NewTopOfBookStream(book *OrderBook, debounce time.Duration, clock func() time.Time) *TopOfBookStream {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &TopOfBookStream{book: book, debounce: debounce, clock: clock}
+}
+
+// Poll checks the book and returns a top-of-book event if one is due.
+// Poll is not safe for concurrent use; Run calls it from a single goroutine.
+func (s *TopOfBookStream) Poll() (TopOfBook, bool) {
+	now := s.clock()
+	snap := s.book.Snapshot()
+	top := TopOfBook{Commodity: snap.Commodity, Seq: snap.Seq, Timestamp: now}
+	if len(snap.Bids) > 0 {
+		top.Bid, top.BidSize = snap.Bids[0].Price, snap.Bids[0].Volume
+	}
+	if len(snap.Asks) > 0 {
+		top.Ask, top.AskSize = snap.Asks[0].Price, snap.Asks[0].Volume
+	}
+
+	if s.emitted && top.sameQuote(s.last) {
+		return TopOfBook{}, false
+	}
+	if s.emitted && s.debounce > 0 && now.Sub(s.emittedAt) < s.debounce {
+		return TopOfBook{}, false
+	}
+	s.last, s.emitted, s.emittedAt = top, true, now
+	return top, true
+}
+
+// Run polls the book every interval and sends events to out until ctx is
+// done. It does not close out.
+func (s *TopOfBookStream) Run(ctx context.Context, interval time.Duration, out chan<- TopOfBook) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if top, ok := s.Poll(); ok {
+			select {
+			case out <- top:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTopOfBookStreamSuppressesDeepChanges verifies only top-of-book changes are emitted
+func TestTopOfBookStreamSuppressesDeepChanges(t *testing.T) {
+	book := NewOrderBook("crude_oil")
+	stream := NewTopOfBookStream(book, 0, nil)
+
+	if top, ok := stream.Poll(); !ok || top.Bid != 0 || top.Ask != 0 {
+		t.Fatalf("Expected an initial empty top of book, got %+v, %v", top, ok)
+	}
+
+	mustAdd := func(o TradingOrder) {
+		if _, err := book.Add(o); err != nil {
+			t.Fatalf("Add %s failed: %v", o.OrderID, err)
+		}
+	}
+
+	mustAdd(TradingOrder{OrderID: "b1", Side: SideBuy, Price: 75.40, Volume: 10})
+	if top, ok := stream.Poll(); !ok || top.Bid != 75.40 || top.BidSize != 10 || top.Ask != 0 {
+		t.Fatalf("Expected bid to appear with an empty ask side, got %+v", top)
+	}
+
+	mustAdd(TradingOrder{OrderID: "b2", Side: SideBuy, Price: 75.20, Volume: 30})
+	if top, ok := stream.Poll(); ok {
+		t.Errorf("Expected a deeper bid not to emit, got %+v", top)
+	}
+
+	mustAdd(TradingOrder{OrderID: "a1", Side: SideSell, Price: 75.60, Volume: 20})
+	if top, ok := stream.Poll(); !ok || top.Ask != 75.60 || top.AskSize != 20 {
+		t.Errorf("Expected ask to emit, got %+v", top)
+	}
+
+	book.Cancel("b2")
+	if _, ok := stream.Poll(); ok {
+		t.Error("Expected removing a deep level not to emit")
+	}
+
+	book.Cancel("a1")
+	if top, ok := stream.Poll(); !ok || top.Ask != 0 || top.AskSize != 0 {
+		t.Errorf("Expected the ask side emptying to emit zero values, got %+v", top)
+	}
+}
+
+// TestTopOfBookStreamDebounce verifies rapid changes collapse to the latest top
+func TestTopOfBookStreamDebounce(t *testing.T) {
+	now := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+	book := NewOrderBook("crude_oil")
+	stream := NewTopOfBookStream(book, 100*time.Millisecond, func() time.Time { return now })
+	stream.Poll()
+
+	for i, price := range []float64{75.40, 75.41, 75.42} {
+		book.Add(TradingOrder{OrderID: string(rune('a' + i)), Side: SideBuy, Price: price, Volume: 10})
+		now = now.Add(10 * time.Millisecond)
+		if top, ok := stream.Poll(); ok {
+			t.Fatalf("Expected change %d to be debounced, got %+v", i, top)
+		}
+	}
+
+	now = now.Add(100 * time.Millisecond)
+	if top, ok := stream.Poll(); !ok || top.Bid != 75.42 {
+		t.Errorf("Expected the latest bid 75.42 after the debounce interval, got %+v, %v", top, ok)
+	}
+}
@@ -0,0 +1,75 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTradeThrough is returned when an execution would trade through a better
+// price available at another venue
+var ErrTradeThrough = errors.New("execution would trade through a better price")
+
+// VenueQuote is one venue's best bid and ask; zero sizes mean no quote on that side
+type VenueQuote struct {
+	Venue   string
+	Bid     float64
+	BidSize float64
+	Ask     float64
+	AskSize float64
+}
+
+// ConsolidatedQuoteSource supplies the current quotes for a commodity across venues
+type ConsolidatedQuoteSource interface {
+	VenueQuotes(commodity string) []VenueQuote
+}
+
+// Trade-through handling modes
+const (
+	TradeThroughBlock   = "block"
+	TradeThroughReroute = "reroute"
+)
+
+// Route is where and at what price an execution should take place
+type Route struct {
+	Venue    string
+	Price    float64
+	Rerouted bool
+}
+
+// TradeThroughGuard checks an intended execution against the consolidated
+// best quote and blocks it or reroutes it to the venue showing the better price
+type TradeThroughGuard struct {
+	quotes ConsolidatedQuoteSource
+	mode   string
+}
+
+// NewTradeThroughGuard creates a guard; an empty mode blocks
+func NewTradeThroughGuard(quotes ConsolidatedQuoteSource, mode string) *TradeThroughGuard {
+	if mode == "" {
+		mode = TradeThroughBlock
+	}
+	return &TradeThroughGuard{quotes: quotes, mode: mode}
+}
+
+// Check validates executing order at price on venue. Buys trade through when
+// another venue offers below price; sells when another venue bids above it.
+// Ties keep the intended venue.
+func (g *TradeThroughGuard) Check(venue string, order TradingOrder, price float64) (Route, error) {
+	route := Route{Venue: venue, Price: price}
+	for _, q := range g.quotes.VenueQuotes(order.Commodity) {
+		if q.Venue == venue {
+			continue
+		}
+		switch {
+		case order.Side == SideBuy && q.AskSize > 0 && q.Ask < route.Price:
+			route = Route{Venue: q.Venue, Price: q.Ask, Rerouted: true}
+		case order.Side == SideSell && q.BidSize > 0 && q.Bid > route.Price:
+			route = Route{Venue: q.Venue, Price: q.Bid, Rerouted: true}
+		}
+	}
+	if route.Rerouted && g.mode == TradeThroughBlock {
+		return Route{}, fmt.Errorf("%w: %s %s at %g on %s, %s shows %g",
+			ErrTradeThrough, order.Side, order.Commodity, price, venue, route.Venue, route.Price)
+	}
+	return route, nil
+}
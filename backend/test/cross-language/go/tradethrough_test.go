@@ -0,0 +1,52 @@
+package integration
+
+import (
+	"errors"
+	"testing"
+)
+
+type fakeConsolidatedQuotes map[string][]VenueQuote
+
+func (f fakeConsolidatedQuotes) VenueQuotes(commodity string) []VenueQuote {
+	return f[commodity]
+}
+
+func tradeThroughQuotes() fakeConsolidatedQuotes {
+	return fakeConsolidatedQuotes{"crude_oil": {
+		{Venue: "NYMEX", Bid: 75.40, BidSize: 100, Ask: 75.60, AskSize: 100},
+		{Venue: "ICE", Bid: 75.45, BidSize: 50, Ask: 75.55, AskSize: 50},
+		{Venue: "DME", Bid: 75.50, BidSize: 20, Ask: 75.50, AskSize: 0},
+	}}
+}
+
+// TestTradeThroughGuardReroutes verifies an order is sent to the venue with the better price
+func TestTradeThroughGuardReroutes(t *testing.T) {
+	guard := NewTradeThroughGuard(tradeThroughQuotes(), TradeThroughReroute)
+
+	route, err := guard.Check("NYMEX", TradingOrder{Commodity: "crude_oil", Side: SideBuy, Volume: 10}, 75.60)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !route.Rerouted || route.Venue != "ICE" || route.Price != 75.55 {
+		t.Errorf("Expected reroute to ICE at 75.55, got %+v", route)
+	}
+
+	route, _ = guard.Check("NYMEX", TradingOrder{Commodity: "crude_oil", Side: SideSell, Volume: 10}, 75.40)
+	if route.Venue != "DME" || route.Price != 75.50 {
+		t.Errorf("Expected sell rerouted to the best bid at DME, got %+v", route)
+	}
+
+	route, _ = guard.Check("ICE", TradingOrder{Commodity: "crude_oil", Side: SideBuy, Volume: 10}, 75.55)
+	if route.Rerouted || route.Venue != "ICE" {
+		t.Errorf("Expected the best venue to keep the order, got %+v", route)
+	}
+}
+
+// TestTradeThroughGuardBlocks verifies block mode rejects a trade-through
+func TestTradeThroughGuardBlocks(t *testing.T) {
+	guard := NewTradeThroughGuard(tradeThroughQuotes(), TradeThroughBlock)
+
+	if _, err := guard.Check("NYMEX", TradingOrder{Commodity: "crude_oil", Side: SideBuy, Volume: 10}, 75.60); !errors.Is(err, ErrTradeThrough) {
+		t.Errorf("Expected ErrTradeThrough, got %v", err)
+	}
+}
@@ -0,0 +1,138 @@
+package integration
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// ImpactParams configures the market impact model for one commodity
+type ImpactParams struct {
+	Coefficient float64   `json:"coefficient"` // fraction of price paid at full participation
+	Liquidity   float64   `json:"liquidity"`   // expected tradable volume per slice interval
+	Exponent    float64   `json:"exponent"`    // impact curvature; 0.5 is the square-root law
+	Profile     []float64 `json:"profile"`     // optional relative liquidity across the schedule
+}
+
+// CostModel estimates expected market impact cost for order slices
+type CostModel struct {
+	params map[string]ImpactParams
+}
+
+// NewCostModel creates a cost model from per-commodity parameters
+func NewCostModel(params map[string]ImpactParams) *CostModel {
+	return &CostModel{params: params}
+}
+
+// SliceCost estimates the impact cost of trading volume in slice i of n
+func (m *CostModel) SliceCost(commodity string, i, n int, volume, price float64) (float64, error) {
+	p, ok := m.params[commodity]
+	if !ok {
+		return 0, fmt.Errorf("no impact parameters for commodity %s", commodity)
+	}
+	if volume <= 0 {
+		return 0, nil
+	}
+	liquidity := p.liquidityAt(i, n)
+	if liquidity <= 0 {
+		return 0, fmt.Errorf("commodity %s has no liquidity in slice %d", commodity, i)
+	}
+	return p.Coefficient * price * volume * math.Pow(volume/liquidity, p.Exponent), nil
+}
+
+// ScheduleCost sums the estimated impact cost of a full slice schedule
+func (m *CostModel) ScheduleCost(commodity string, volumes []float64, price float64) (float64, error) {
+	total := 0.0
+	for i, v := range volumes {
+		cost, err := m.SliceCost(commodity, i, len(volumes), v, price)
+		if err != nil {
+			return 0, err
+		}
+		total += cost
+	}
+	return total, nil
+}
+
+// OptimalSlices splits total volume across n slices to minimize expected
+// impact. With cost proportional to v^(1+e)/L^e the optimum trades in
+// proportion to each slice's expected liquidity.
+func (m *CostModel) OptimalSlices(commodity string, total float64, n int) ([]float64, error) {
+	p, ok := m.params[commodity]
+	if !ok {
+		return nil, fmt.Errorf("no impact parameters for commodity %s", commodity)
+	}
+
+	weights := make([]float64, n)
+	sum := 0.0
+	for i := range weights {
+		weights[i] = p.liquidityAt(i, n)
+		sum += weights[i]
+	}
+	if sum <= 0 {
+		return nil, fmt.Errorf("commodity %s has no liquidity", commodity)
+	}
+
+	slices := make([]float64, n)
+	allocated := 0.0
+	for i, w := range weights {
+		slices[i] = total * w / sum
+		allocated += slices[i]
+	}
+	slices[n-1] += total - allocated // keep the total exact
+	return slices, nil
+}
+
+// liquidityAt maps slice i of n onto the configured liquidity profile
+func (p ImpactParams) liquidityAt(i, n int) float64 {
+	if len(p.Profile) == 0 {
+		return p.Liquidity
+	}
+	return p.Liquidity * p.Profile[i*len(p.Profile)/n]
+}
+
+// TWAPScheduler splits a parent order into time-sliced child orders
+type TWAPScheduler struct {
+	Slices    int
+	Duration  time.Duration
+	CostModel *CostModel // optional; nil slices uniformly
+}
+
+// Schedule returns the child orders for a parent starting at start. Each
+// child is timestamped with the time it should be released.
+func (s *TWAPScheduler) Schedule(parent TradingOrder, start time.Time) ([]TradingOrder, error) {
+	if s.Slices <= 0 {
+		return nil, fmt.Errorf("twap requires at least one slice")
+	}
+	if parent.Volume <= 0 {
+		return nil, fmt.Errorf("parent order %s has no volume", parent.OrderID)
+	}
+
+	volumes := uniformSlices(parent.Volume, s.Slices)
+	if s.CostModel != nil {
+		adaptive, err := s.CostModel.OptimalSlices(parent.Commodity, parent.Volume, s.Slices)
+		if err != nil {
+			return nil, err
+		}
+		volumes = adaptive
+	}
+
+	interval := s.Duration / time.Duration(s.Slices)
+	children := make([]TradingOrder, 0, s.Slices)
+	for i, v := range volumes {
+		child := parent
+		child.OrderID = fmt.Sprintf("%s-%d", parent.OrderID, i+1)
+		child.Volume = v
+		child.Timestamp = start.Add(time.Duration(i) * interval)
+		children = append(children, child)
+	}
+	return children, nil
+}
+
+// uniformSlices splits total into n equal slices
+func uniformSlices(total float64, n int) []float64 {
+	slices := make([]float64, n)
+	for i := range slices {
+		slices[i] = total / float64(n)
+	}
+	return slices
+}
@@ -0,0 +1,85 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestTWAPAdaptiveSlicingReducesCost compares adaptive and uniform schedules for the same order
+func TestTWAPAdaptiveSlicingReducesCost(t *testing.T) {
+	model := NewCostModel(map[string]ImpactParams{
+		"crude_oil": {
+			Coefficient: 0.01,
+			Liquidity:   5000,
+			Exponent:    0.5,
+			Profile:     []float64{2.0, 1.0, 0.5, 1.0, 2.5}, // liquid open and close
+		},
+	})
+	parent := TradingOrder{
+		OrderID:   "parent_1",
+		Commodity: "crude_oil",
+		Volume:    10000,
+		Price:     75.50,
+		Side:      SideBuy,
+		Type:      OrderTypeLimit,
+	}
+	start := time.Date(2024, 1, 2, 14, 0, 0, 0, time.UTC)
+
+	uniform := &TWAPScheduler{Slices: 5, Duration: 50 * time.Minute}
+	adaptive := &TWAPScheduler{Slices: 5, Duration: 50 * time.Minute, CostModel: model}
+
+	uniformChildren, err := uniform.Schedule(parent, start)
+	if err != nil {
+		t.Fatalf("Uniform schedule failed: %v", err)
+	}
+	adaptiveChildren, err := adaptive.Schedule(parent, start)
+	if err != nil {
+		t.Fatalf("Adaptive schedule failed: %v", err)
+	}
+
+	uniformCost, err := model.ScheduleCost("crude_oil", childVolumes(uniformChildren), parent.Price)
+	if err != nil {
+		t.Fatalf("Failed to cost uniform schedule: %v", err)
+	}
+	adaptiveCost, err := model.ScheduleCost("crude_oil", childVolumes(adaptiveChildren), parent.Price)
+	if err != nil {
+		t.Fatalf("Failed to cost adaptive schedule: %v", err)
+	}
+
+	if adaptiveCost >= uniformCost {
+		t.Errorf("Expected adaptive cost %f to be below uniform cost %f", adaptiveCost, uniformCost)
+	}
+
+	total := 0.0
+	for i, child := range adaptiveChildren {
+		total += child.Volume
+		wantAt := start.Add(time.Duration(i) * 10 * time.Minute)
+		if !child.Timestamp.Equal(wantAt) {
+			t.Errorf("Expected slice %d at %v, got %v", i, wantAt, child.Timestamp)
+		}
+	}
+	if math.Abs(total-parent.Volume) > volumeEpsilon {
+		t.Errorf("Expected adaptive slices to sum to %f, got %f", parent.Volume, total)
+	}
+	if adaptiveChildren[4].Volume <= adaptiveChildren[2].Volume {
+		t.Error("Expected the most liquid slice to trade more than the least liquid one")
+	}
+}
+
+// TestTWAPUnknownCommodity verifies a missing cost configuration is reported
+func TestTWAPUnknownCommodity(t *testing.T) {
+	scheduler := &TWAPScheduler{Slices: 3, Duration: time.Hour, CostModel: NewCostModel(nil)}
+	_, err := scheduler.Schedule(TradingOrder{OrderID: "p", Commodity: "power", Volume: 10}, time.Now())
+	if err == nil {
+		t.Error("Expected error for commodity without impact parameters")
+	}
+}
+
+func childVolumes(children []TradingOrder) []float64 {
+	volumes := make([]float64, len(children))
+	for i, c := range children {
+		volumes[i] = c.Volume
+	}
+	return volumes
+}
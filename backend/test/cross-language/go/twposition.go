@@ -0,0 +1,84 @@
+package integration
+
+import (
+	"sync"
+	"time"
+)
+
+// positionSample is a position level that took effect at a point in time
+type positionSample struct {
+	at     time.Time
+	volume float64
+}
+
+// TimeWeightedPosition integrates position over time per commodity so
+// margin can use the average held rather than the latest level
+type TimeWeightedPosition struct {
+	mu        sync.Mutex
+	history   map[string][]positionSample
+	retention time.Duration
+	clock     func() time.Time
+}
+
+// NewTimeWeightedPosition creates a tracker keeping enough history to answer
+// windows up to retention long
+func NewTimeWeightedPosition(retention time.Duration, clock func() time.Time) *TimeWeightedPosition {
+	if clock == nil {
+		clock = time.Now
+	}
+	return &TimeWeightedPosition{
+		history:   make(map[string][]positionSample),
+		retention: retention,
+		clock:     clock,
+	}
+}
+
+// Update records that the commodity's position became volume at time at.
+// It must be called on every position change.
+func (p *TimeWeightedPosition) Update(commodity string, volume float64, at time.Time) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	samples := append(p.history[commodity], positionSample{at: at, volume: volume})
+	// Keep the last sample before the retention cutoff: it defines the level
+	// held at the start of the oldest answerable window.
+	cutoff := at.Add(-p.retention)
+	drop := 0
+	for drop+1 < len(samples) && !samples[drop+1].at.After(cutoff) {
+		drop++
+	}
+	p.history[commodity] = samples[drop:]
+}
+
+// Average returns each commodity's time-weighted position over the window
+// ending now. Time before a commodity's first update counts as flat.
+func (p *TimeWeightedPosition) Average(window time.Duration) map[string]float64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	end := p.clock()
+	start := end.Add(-window)
+	out := make(map[string]float64, len(p.history))
+	if window <= 0 {
+		return out
+	}
+
+	for commodity, samples := range p.history {
+		integral := 0.0
+		for i, s := range samples {
+			from := s.at
+			if from.Before(start) {
+				from = start
+			}
+			to := end
+			if i+1 < len(samples) && samples[i+1].at.Before(end) {
+				to = samples[i+1].at
+			}
+			if to.After(from) {
+				integral += s.volume * to.Sub(from).Seconds()
+			}
+		}
+		out[commodity] = integral / window.Seconds()
+	}
+	return out
+}
@@ -0,0 +1,53 @@
+package integration
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestTimeWeightedPositionMidWindowChange verifies the average weights each level by time held
+func TestTimeWeightedPositionMidWindowChange(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	tracker := NewTimeWeightedPosition(24*time.Hour, func() time.Time { return now })
+
+	tracker.Update("crude_oil", 100, start)
+	tracker.Update("natural_gas", -50, start.Add(30*time.Minute))
+	tracker.Update("crude_oil", 400, start.Add(45*time.Minute))
+	now = start.Add(time.Hour)
+
+	avg := tracker.Average(time.Hour)
+	// crude: 100 for 45m then 400 for 15m = 175; gas: flat 30m then -50 for 30m = -25
+	if math.Abs(avg["crude_oil"]-175) > 1e-9 {
+		t.Errorf("Expected crude_oil average 175, got %f", avg["crude_oil"])
+	}
+	if math.Abs(avg["natural_gas"]+25) > 1e-9 {
+		t.Errorf("Expected natural_gas average -25, got %f", avg["natural_gas"])
+	}
+
+	// A window starting after the change sees only the new level.
+	if avg := tracker.Average(10 * time.Minute); math.Abs(avg["crude_oil"]-400) > 1e-9 {
+		t.Errorf("Expected crude_oil 10m average 400, got %f", avg["crude_oil"])
+	}
+}
+
+// TestTimeWeightedPositionRetention verifies old samples are pruned without losing the carried level
+func TestTimeWeightedPositionRetention(t *testing.T) {
+	start := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	now := start
+	tracker := NewTimeWeightedPosition(time.Hour, func() time.Time { return now })
+
+	for i := 0; i < 10; i++ {
+		tracker.Update("crude_oil", float64(i), start.Add(time.Duration(i)*time.Hour))
+	}
+	now = start.Add(9*time.Hour + 30*time.Minute)
+
+	if n := len(tracker.history["crude_oil"]); n > 2 {
+		t.Errorf("Expected history pruned to 2 samples, got %d", n)
+	}
+	avg := tracker.Average(time.Hour)
+	if math.Abs(avg["crude_oil"]-8.5) > 1e-9 {
+		t.Errorf("Expected carried-in level to give 8.5, got %f", avg["crude_oil"])
+	}
+}
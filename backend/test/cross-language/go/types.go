@@ -0,0 +1,74 @@
+package integration
+
+import "time"
+
+// Order sides
+const (
+	SideBuy  = "buy"
+	SideSell = "sell"
+)
+
+// Order types
+const (
+	OrderTypeLimit  = "limit"
+	OrderTypeMarket = "market"
+	// OrderTypeMarketOnClose is held off the book until the closing uncross
+	OrderTypeMarketOnClose = "market_on_close"
+	// OrderTypePegged is a limit order whose price follows a reference market
+	OrderTypePegged = "pegged"
+)
+
+// Peg references
+const (
+	PegBid = "bid"
+	PegAsk = "ask"
+	PegMid = "mid"
+)
+
+// Time in force values. Orders without a time in force are treated as GTC.
+const (
+	TimeInForceGTC = "GTC"
+	TimeInForceDay = "DAY"
+	// TimeInForceGTD orders rest until their ExpiresAt
+	TimeInForceGTD = "GTD"
+	// TimeInForceIOC orders match what they can on entry and cancel the
+	// rest; they never rest on the book
+	TimeInForceIOC = "IOC"
+)
+
+// TradingOrder represents a trading order structure
+type TradingOrder struct {
+	OrderID     string    `json:"order_id"`
+	ClientID    string    `json:"client_id,omitempty"`
+	Commodity   string    `json:"commodity"`
+	Volume      float64   `json:"volume"`
+	Price       float64   `json:"price"`
+	Side        string    `json:"side"`
+	Type        string    `json:"type"`
+	TimeInForce string    `json:"time_in_force,omitempty"`
+	Exchange    string    `json:"exchange,omitempty"` // target venue; empty uses the commodity default
+	Timestamp   time.Time `json:"timestamp"`
+	// MinQty is the smallest immediate execution the order accepts on entry
+	MinQty float64 `json:"min_qty,omitempty"`
+	// Pegged orders track PegReference (bid, ask or mid) plus PegOffset
+	PegReference string  `json:"peg_reference,omitempty"`
+	PegOffset    float64 `json:"peg_offset,omitempty"`
+	// PostOnly orders are rejected rather than take liquidity on entry
+	PostOnly bool `json:"post_only,omitempty"`
+	// DisplayVolume makes a limit order an iceberg showing at most this
+	// much at once; the rest is a hidden reserve that refreshes the display
+	DisplayVolume float64 `json:"display_volume,omitempty"`
+	// ExpiresAt is when a GTD order expires, kept to whole seconds in UTC
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// MarketData represents market data point structure
+type MarketData struct {
+	Commodity string    `json:"commodity"`
+	Price     float64   `json:"price"`
+	Volume    int64     `json:"volume"`
+	Exchange  string    `json:"exchange"`
+	Timestamp time.Time `json:"timestamp"` // exchange trade time once aligned
+	// OriginalTimestamp is the timestamp as published, kept after alignment
+	OriginalTimestamp time.Time `json:"original_timestamp,omitempty"`
+}
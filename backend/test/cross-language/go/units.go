@@ -0,0 +1,58 @@
+package integration
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrUnknownCommodity is returned when a commodity has no configuration
+// (conversion factor, exchange mapping)
+var ErrUnknownCommodity = errors.New("unknown commodity")
+
+// DefaultEnergyFactors gives MMBtu per native trading unit: barrels for
+// crude and heating oil, MCF for natural gas, gallons for gasoline
+var DefaultEnergyFactors = map[string]float64{
+	"crude_oil":   5.8,
+	"heating_oil": 5.825,
+	"natural_gas": 1.037,
+	"gasoline":    0.120,
+}
+
+// UnitConverter converts commodity volumes to a common energy unit
+type UnitConverter struct {
+	mu      sync.RWMutex
+	factors map[string]float64
+}
+
+// NewUnitConverter creates a converter from MMBtu-per-unit factors; nil uses
+// DefaultEnergyFactors
+func NewUnitConverter(factors map[string]float64) *UnitConverter {
+	if factors == nil {
+		factors = DefaultEnergyFactors
+	}
+	copied := make(map[string]float64, len(factors))
+	for commodity, factor := range factors {
+		copied[commodity] = factor
+	}
+	return &UnitConverter{factors: copied}
+}
+
+// SetFactor sets the MMBtu per native unit for a commodity
+func (c *UnitConverter) SetFactor(commodity string, mmbtuPerUnit float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.factors[commodity] = mmbtuPerUnit
+}
+
+// ToMMBtu converts a volume in the commodity's native unit to MMBtu
+func (c *UnitConverter) ToMMBtu(volume float64, commodity string) (float64, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	factor, ok := c.factors[commodity]
+	if !ok {
+		return 0, fmt.Errorf("%w: no energy factor for %s", ErrUnknownCommodity, commodity)
+	}
+	return volume * factor, nil
+}
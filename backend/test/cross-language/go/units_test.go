@@ -0,0 +1,36 @@
+package integration
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestUnitConverterToMMBtu verifies crude barrels and gas MCF convert to MMBtu
+func TestUnitConverterToMMBtu(t *testing.T) {
+	converter := NewUnitConverter(nil)
+
+	crude, err := converter.ToMMBtu(1000, "crude_oil")
+	if err != nil || math.Abs(crude-5800) > 1e-9 {
+		t.Errorf("Expected 1000 bbl crude = 5800 MMBtu, got %g, %v", crude, err)
+	}
+	gas, err := converter.ToMMBtu(10000, "natural_gas")
+	if err != nil || math.Abs(gas-10370) > 1e-9 {
+		t.Errorf("Expected 10000 MCF gas = 10370 MMBtu, got %g, %v", gas, err)
+	}
+
+	converter.SetFactor("natural_gas", 1.02)
+	if gas, _ := converter.ToMMBtu(10000, "natural_gas"); math.Abs(gas-10200) > 1e-9 {
+		t.Errorf("Expected the configured factor to apply, got %g", gas)
+	}
+	if DefaultEnergyFactors["natural_gas"] != 1.037 {
+		t.Error("Expected SetFactor not to modify the defaults")
+	}
+}
+
+// TestUnitConverterUnknownCommodity verifies unknown commodities are errors
+func TestUnitConverterUnknownCommodity(t *testing.T) {
+	if _, err := NewUnitConverter(nil).ToMMBtu(10, "power"); !errors.Is(err, ErrUnknownCommodity) {
+		t.Errorf("Expected ErrUnknownCommodity, got %v", err)
+	}
+}
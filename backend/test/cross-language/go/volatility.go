@@ -0,0 +1,55 @@
+package integration
+
+import (
+	"math"
+	"sync"
+)
+
+// VolatilityEstimator tracks realized volatility as an exponentially
+// weighted moving average of squared log returns (RiskMetrics style). The
+// result is per observation interval, not annualized.
+type VolatilityEstimator struct {
+	mu       sync.Mutex
+	lambda   float64
+	last     float64
+	variance float64
+	samples  int
+}
+
+// NewVolatilityEstimator creates an estimator with decay lambda in (0, 1);
+// other values use 0.94
+func NewVolatilityEstimator(lambda float64) *VolatilityEstimator {
+	if lambda <= 0 || lambda >= 1 {
+		lambda = 0.94
+	}
+	return &VolatilityEstimator{lambda: lambda}
+}
+
+// Observe records a price and returns the updated volatility. Non-positive
+// prices are ignored.
+func (v *VolatilityEstimator) Observe(price float64) float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if price <= 0 {
+		return math.Sqrt(v.variance)
+	}
+	if v.last > 0 {
+		r := math.Log(price / v.last)
+		if v.samples == 0 {
+			v.variance = r * r
+		} else {
+			v.variance = v.lambda*v.variance + (1-v.lambda)*r*r
+		}
+		v.samples++
+	}
+	v.last = price
+	return math.Sqrt(v.variance)
+}
+
+// Volatility returns the current estimate; zero until two prices are seen
+func (v *VolatilityEstimator) Volatility() float64 {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return math.Sqrt(v.variance)
+}